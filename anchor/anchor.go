@@ -0,0 +1,56 @@
+// Package anchor commits roots to external trust anchors, like a notary or a public blockchain, so anyone can later
+// check that a root existed at a point in time, and that it wasn't replaced since.
+//
+// An Anchor returns a Receipt for every anchored root, which is kept next to the root and verified later with the
+// same anchor. Notary is a reference implementation anchoring roots with an HTTP notary served by NewNotaryHandler.
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// Receipt proves that a root was committed to an anchor at a point in time.
+type Receipt struct {
+	// Type is the kind of anchor that issued the receipt, like NotaryReceiptType.
+	Type      string    `json:"type"`
+	Root      []byte    `json:"root"`
+	Timestamp time.Time `json:"timestamp"`
+	// Proof is what the anchor needs to verify the receipt, like the signature of a notary.
+	Proof []byte `json:"proof"`
+}
+
+// Anchor commits roots to an external trust anchor.
+type Anchor interface {
+	// Anchor commits a root and returns the receipt of the commitment.
+	Anchor(ctx context.Context, root []byte) (*Receipt, error)
+	// VerifyReceipt checks that a receipt was issued by the anchor for the root.
+	VerifyReceipt(ctx context.Context, root []byte, r *Receipt) error
+}
+
+// Run anchors the root returned by root every interval until the context is done, and passes the receipts to
+// receipts. Roots that are nil, or the same as the last anchored root, are skipped. Errors anchoring are passed to
+// errs, which can be nil, and the next interval retries. Returns the error of the context.
+func Run(ctx context.Context, a Anchor, interval time.Duration, root func() []byte, receipts func(*Receipt), errs func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last []byte
+	for {
+		if r := root(); r != nil && !bytes.Equal(r, last) {
+			receipt, err := a.Anchor(ctx, r)
+			switch {
+			case err == nil:
+				last = bytes.Clone(r)
+				receipts(receipt)
+			case errs != nil && ctx.Err() == nil:
+				errs(err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
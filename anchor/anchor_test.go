@@ -0,0 +1,165 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+var _ Anchor = (*Notary)(nil)
+
+func newNotary(t *testing.T) *Notary {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv := httptest.NewServer(NewNotaryHandler(key))
+	t.Cleanup(srv.Close)
+	return NewNotary(srv.URL, pub, srv.Client())
+}
+
+func TestNotary(t *testing.T) {
+	n := newNotary(t)
+	tree, err := gomerkletree.BuildMerkleTreeBytes([][]byte{[]byte("a"), []byte("b")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := tree.Root()
+
+	before := time.Now().Add(-time.Second)
+	r, err := n.Anchor(context.Background(), root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Type != NotaryReceiptType || !bytes.Equal(r.Root, root) || r.Timestamp.Before(before) {
+		t.Errorf("unexpected receipt %+v", r)
+	}
+
+	// receipts are kept next to the root, and verified later
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Receipt
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := n.VerifyReceipt(context.Background(), root, &decoded); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	other := bytes.Clone(root)
+	other[0] ^= 1
+	if err := n.VerifyReceipt(context.Background(), other, r); err == nil {
+		t.Errorf("expected error for another root")
+	}
+	backdated := *r
+	backdated.Timestamp = r.Timestamp.Add(-time.Hour)
+	if err := n.VerifyReceipt(context.Background(), root, &backdated); err == nil {
+		t.Errorf("expected error for changed timestamp")
+	}
+	if err := newNotary(t).VerifyReceipt(context.Background(), root, r); err == nil {
+		t.Errorf("expected error for another notary")
+	}
+}
+
+func TestNotary_Errors(t *testing.T) {
+	n := newNotary(t)
+	if _, err := n.Anchor(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "invalid root") {
+		t.Errorf("expected invalid root error, got %v", err)
+	}
+
+	// a notary signing with another key than expected
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewNotary(n.url, pub, n.http).Anchor(context.Background(), []byte("root")); err == nil {
+		t.Errorf("expected error for invalid signature")
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := NewNotary(srv.URL, pub, nil).Anchor(context.Background(), []byte("root")); err == nil {
+		t.Errorf("expected error for missing notary")
+	}
+}
+
+// flakyAnchor fails its first anchor, and records the roots it anchors.
+type flakyAnchor struct {
+	mu     sync.Mutex
+	failed bool
+	roots  [][]byte
+}
+
+func (a *flakyAnchor) Anchor(_ context.Context, root []byte) (*Receipt, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.failed {
+		a.failed = true
+		return nil, errors.New("unavailable")
+	}
+	a.roots = append(a.roots, root)
+	return &Receipt{Root: root}, nil
+}
+
+func (a *flakyAnchor) VerifyReceipt(context.Context, []byte, *Receipt) error {
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	a := &flakyAnchor{}
+	var mu sync.Mutex
+	current := []byte("first")
+	root := func() []byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	receipts := make(chan *Receipt)
+	var errs []error
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, a, time.Millisecond, root, func(r *Receipt) {
+			receipts <- r
+		}, func(err error) {
+			errs = append(errs, err)
+		})
+	}()
+
+	if r := <-receipts; string(r.Root) != "first" {
+		t.Errorf("expected first, got %s", r.Root)
+	}
+	mu.Lock()
+	current = []byte("second")
+	mu.Unlock()
+	if r := <-receipts; string(r.Root) != "second" {
+		t.Errorf("expected second, got %s", r.Root)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+
+	// the unchanged root is only anchored once, after the failure was retried
+	if len(a.roots) != 2 {
+		t.Errorf("expected 2 anchored roots, got %d", len(a.roots))
+	}
+	// the failure was reported
+	if len(errs) != 1 || errs[0].Error() != "unavailable" {
+		t.Errorf("expected the failure to be reported, got %v", errs)
+	}
+}
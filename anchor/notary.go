@@ -0,0 +1,155 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jeltjongsma/go-merkletree/internal/jsonhttp"
+)
+
+// NotaryReceiptType is the type of the receipts of a Notary.
+const NotaryReceiptType = "notary"
+
+// maxRootSize bounds the roots a notary signs.
+const maxRootSize = 64
+
+// notaryMessage returns what a notary signs for a root at a timestamp:
+//
+//	"gomerkletree notary v1\n" | timestamp (8 bytes) | root
+//
+// with the timestamp in milliseconds since the Unix epoch, in big endian.
+func notaryMessage(root []byte, timestamp time.Time) []byte {
+	b := []byte("gomerkletree notary v1\n")
+	b = binary.BigEndian.AppendUint64(b, uint64(timestamp.UnixMilli()))
+	return append(b, root...)
+}
+
+type notaryRequest struct {
+	Root string `json:"root"`
+}
+
+type notaryResponse struct {
+	Root      string `json:"root"`
+	Timestamp int64  `json:"timestamp"` // milliseconds since the Unix epoch
+	Signature string `json:"signature"`
+}
+
+// NewNotaryHandler returns a handler of a notary, which timestamps and signs the roots posted to it with the private key.
+// It responds to POST / with {"root": "..."}, with {"root": "...", "timestamp": ..., "signature": "..."},
+// with the root and signature hex-encoded, and the timestamp in milliseconds since the Unix epoch.
+func NewNotaryHandler(key ed25519.PrivateKey) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{$}", func(w http.ResponseWriter, r *http.Request) {
+		var req notaryRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+			jsonhttp.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		root, err := hex.DecodeString(req.Root)
+		if err != nil {
+			jsonhttp.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(root) == 0 || len(root) > maxRootSize {
+			jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid root"))
+			return
+		}
+		timestamp := time.Now().UTC().Truncate(time.Millisecond)
+		jsonhttp.WriteJSON(w, http.StatusOK, notaryResponse{
+			Root:      req.Root,
+			Timestamp: timestamp.UnixMilli(),
+			Signature: hex.EncodeToString(ed25519.Sign(key, notaryMessage(root, timestamp))),
+		})
+	})
+	return mux
+}
+
+// Notary anchors roots with a notary served by NewNotaryHandler, which signs them with a timestamp.
+// Its receipts can be verified offline with the public key of the notary.
+type Notary struct {
+	url  string
+	key  ed25519.PublicKey
+	http *http.Client
+}
+
+// NewNotary returns an anchor for the notary at url with the given public key, sending requests with c
+// (http.DefaultClient if nil).
+func NewNotary(url string, key ed25519.PublicKey, c *http.Client) *Notary {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	return &Notary{
+		url:  url,
+		key:  key,
+		http: c,
+	}
+}
+
+// Anchor posts the root to the notary, and returns its receipt after verifying it.
+func (n *Notary) Anchor(ctx context.Context, root []byte) (*Receipt, error) {
+	body, err := json.Marshal(notaryRequest{Root: hex.EncodeToString(root)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := n.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, jsonhttp.ResponseError(res)
+	}
+	var v notaryResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, jsonhttp.MaxResponseSize)).Decode(&v); err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(v.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &Receipt{
+		Type:      NotaryReceiptType,
+		Root:      bytes.Clone(root),
+		Timestamp: time.UnixMilli(v.Timestamp).UTC(),
+		Proof:     signature,
+	}
+	if err := n.VerifyReceipt(ctx, root, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// VerifyReceipt checks that the receipt is signed by the notary for the root. It doesn't contact the notary.
+func (n *Notary) VerifyReceipt(_ context.Context, root []byte, r *Receipt) error {
+	if r == nil {
+		return errors.New("nil receipt")
+	}
+	if r.Type != NotaryReceiptType {
+		return errors.New("not a notary receipt")
+	}
+	if !bytes.Equal(r.Root, root) {
+		return errors.New("receipt not for root")
+	}
+	if len(n.key) != ed25519.PublicKeySize {
+		return errors.New("invalid public key")
+	}
+	if !ed25519.Verify(n.key, notaryMessage(root, r.Timestamp), r.Proof) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
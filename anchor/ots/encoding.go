@@ -0,0 +1,225 @@
+package ots
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// magic is the header of detached timestamp files (.ots).
+var magic = []byte("\x00OpenTimestamps\x00\x00Proof\x00\xbf\x89\xe2\xe8\x84\xe8\x92\x94")
+
+const fileVersion = 1
+
+// DetachedTimestamp is the timestamp of a file, as stored in .ots files: the digest of the file, computed with
+// a hash operation, and the timestamp of the digest.
+type DetachedTimestamp struct {
+	HashOp    byte
+	Timestamp *Timestamp
+}
+
+// Digest returns the digest of the file.
+func (d *DetachedTimestamp) Digest() []byte {
+	return d.Timestamp.Msg
+}
+
+// MarshalBinary encodes the detached timestamp like an .ots file:
+// magic | version (varuint) | hash operation | digest | timestamp.
+func (d *DetachedTimestamp) MarshalBinary() ([]byte, error) {
+	if d == nil || d.Timestamp == nil {
+		return nil, errors.New("nil timestamp")
+	}
+	if n := digestLength(d.HashOp); n == 0 || len(d.Timestamp.Msg) != n {
+		return nil, errors.New("invalid digest")
+	}
+	b := append([]byte(nil), magic...)
+	b = binary.AppendUvarint(b, fileVersion)
+	b = append(b, d.HashOp)
+	b = append(b, d.Timestamp.Msg...)
+	return appendTimestamp(b, d.Timestamp, 0)
+}
+
+// UnmarshalBinary decodes an .ots file.
+func (d *DetachedTimestamp) UnmarshalBinary(data []byte) error {
+	if d == nil {
+		return errors.New("nil timestamp")
+	}
+	if !bytes.HasPrefix(data, magic) {
+		return errors.New("not an OpenTimestamps file")
+	}
+	dec := decoder{b: data[len(magic):]}
+	if version := dec.varUint(); dec.err == nil && version != fileVersion {
+		return errors.New("unsupported version")
+	}
+	hashOp := dec.byte()
+	n := digestLength(hashOp)
+	if dec.err == nil && n == 0 {
+		return errors.New("unknown hash operation")
+	}
+	digest := dec.next(n)
+	t := dec.timestamp(bytes.Clone(digest), 0)
+	if dec.err != nil {
+		return dec.err
+	}
+	if len(dec.b) != 0 {
+		return errors.New("trailing data")
+	}
+	*d = DetachedTimestamp{HashOp: hashOp, Timestamp: t}
+	return nil
+}
+
+// MarshalBinary encodes the timestamp without its message, like calendars serve timestamps.
+// Attestations and branches are encoded in the order they are in.
+func (t *Timestamp) MarshalBinary() ([]byte, error) {
+	return appendTimestamp(nil, t, 0)
+}
+
+// UnmarshalTimestamp decodes a timestamp encoded by Timestamp.MarshalBinary of the message msg.
+func UnmarshalTimestamp(data, msg []byte) (*Timestamp, error) {
+	dec := decoder{b: data}
+	t := dec.timestamp(bytes.Clone(msg), 0)
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	if len(dec.b) != 0 {
+		return nil, errors.New("trailing data")
+	}
+	return t, nil
+}
+
+// appendTimestamp encodes the attestations and branches of a timestamp, every one but the last prefixed with 0xff:
+// attestations as 0x00 | tag | payload length (varuint) | payload, and branches as operation | timestamp.
+func appendTimestamp(b []byte, t *Timestamp, depth int) ([]byte, error) {
+	if t == nil || depth > maxDepth {
+		return nil, errors.New("invalid timestamp")
+	}
+	n := len(t.Attestations) + len(t.Branches)
+	if n == 0 {
+		return nil, errors.New("timestamp without attestations")
+	}
+	for i, a := range t.Attestations {
+		if i < n-1 {
+			b = append(b, 0xff)
+		}
+		b = append(b, 0x00)
+		b = append(b, a.Tag[:]...)
+		b = appendVarBytes(b, a.Payload)
+	}
+	for i, branch := range t.Branches {
+		if len(t.Attestations)+i < n-1 {
+			b = append(b, 0xff)
+		}
+		if _, err := branch.Op.Apply(nil); err != nil {
+			return nil, err
+		}
+		b = append(b, branch.Op.Tag)
+		if branch.Op.binary() {
+			b = appendVarBytes(b, branch.Op.Arg)
+		}
+		var err error
+		if b, err = appendTimestamp(b, branch.Timestamp, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendVarBytes(b, x []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(x)))
+	return append(b, x...)
+}
+
+// decoder reads values of OpenTimestamps from a byte slice, remembering the first error it encounters.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.b) {
+		d.err = errors.New("unexpected end of data")
+		return nil
+	}
+	x := d.b[:n:n]
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *decoder) byte() byte {
+	x := d.next(1)
+	if x == nil {
+		return 0
+	}
+	return x[0]
+}
+
+func (d *decoder) varUint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errors.New("invalid varuint")
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *decoder) varBytes(max int) []byte {
+	n := d.varUint()
+	if d.err == nil && n > uint64(max) {
+		d.err = errors.New("value too long")
+		return nil
+	}
+	return bytes.Clone(d.next(int(n)))
+}
+
+// timestamp decodes the timestamp of msg.
+func (d *decoder) timestamp(msg []byte, depth int) *Timestamp {
+	if depth > maxDepth {
+		d.err = errors.New("timestamp too deep")
+		return nil
+	}
+	t := &Timestamp{Msg: msg}
+	for d.err == nil {
+		tag := d.byte()
+		last := tag != 0xff
+		if !last {
+			tag = d.byte()
+		}
+		if d.err != nil {
+			return nil
+		}
+		if tag == 0x00 {
+			var a Attestation
+			copy(a.Tag[:], d.next(len(a.Tag)))
+			a.Payload = d.varBytes(maxPayloadLength)
+			t.Attestations = append(t.Attestations, a)
+		} else {
+			op := Op{Tag: tag}
+			if op.binary() {
+				op.Arg = d.varBytes(maxMsgLength)
+			}
+			if d.err != nil {
+				return nil
+			}
+			result, err := op.Apply(msg)
+			if err != nil {
+				d.err = err
+				return nil
+			}
+			t.Branches = append(t.Branches, Branch{Op: op, Timestamp: d.timestamp(result, depth+1)})
+		}
+		if last {
+			break
+		}
+	}
+	if d.err != nil {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,313 @@
+// Package ots reads, writes and verifies OpenTimestamps proofs, so roots anchored with OpenTimestamps calendars can be
+// checked, and inclusion proofs can extend the timestamp of a root to its leaves.
+//
+// A Timestamp is a tree of operations starting at a message: every path of operations ends in attestations, like
+// a Bitcoin block header whose merkle root is the message at that point. FromProof prepends the path from a leaf to
+// the root, as operations of the RFC 6962 hash strategy, to the timestamp of the root.
+package ots
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"slices"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// Tags of the operations.
+const (
+	OpSHA1      = 0x02
+	OpRIPEMD160 = 0x03
+	OpSHA256    = 0x08
+	OpKeccak256 = 0x67
+	OpAppend    = 0xf0
+	OpPrepend   = 0xf1
+	OpReverse   = 0xf2
+	OpHexlify   = 0xf3
+)
+
+// Limits of OpenTimestamps on messages and the depth of timestamps.
+const (
+	maxMsgLength     = 4096
+	maxPayloadLength = 8192
+	maxDepth         = 256
+)
+
+// Op is an operation on a message. Append and prepend have an argument, the others don't.
+type Op struct {
+	Tag byte
+	Arg []byte
+}
+
+// Append returns the operation appending arg to the message.
+func Append(arg []byte) Op {
+	return Op{Tag: OpAppend, Arg: arg}
+}
+
+// Prepend returns the operation prepending arg to the message.
+func Prepend(arg []byte) Op {
+	return Op{Tag: OpPrepend, Arg: arg}
+}
+
+// SHA256 returns the operation hashing the message with SHA-256.
+func SHA256() Op {
+	return Op{Tag: OpSHA256}
+}
+
+func (op Op) binary() bool {
+	return op.Tag == OpAppend || op.Tag == OpPrepend
+}
+
+// Apply returns the result of the operation on msg.
+func (op Op) Apply(msg []byte) ([]byte, error) {
+	var result []byte
+	switch op.Tag {
+	case OpSHA1:
+		sum := sha1.Sum(msg)
+		result = sum[:]
+	case OpRIPEMD160:
+		h := ripemd160.New()
+		h.Write(msg)
+		result = h.Sum(nil)
+	case OpSHA256:
+		sum := sha256.Sum256(msg)
+		result = sum[:]
+	case OpKeccak256:
+		h := sha3.NewLegacyKeccak256()
+		h.Write(msg)
+		result = h.Sum(nil)
+	case OpAppend:
+		result = slices.Concat(msg, op.Arg)
+	case OpPrepend:
+		result = slices.Concat(op.Arg, msg)
+	case OpReverse:
+		result = slices.Clone(msg)
+		slices.Reverse(result)
+	case OpHexlify:
+		result = []byte(hex.EncodeToString(msg))
+	default:
+		return nil, errors.New("unknown operation")
+	}
+	if len(result) > maxMsgLength {
+		return nil, errors.New("message too long")
+	}
+	return result, nil
+}
+
+// digestLength returns the length of the digest of a hash operation, or 0 for other operations.
+func digestLength(tag byte) int {
+	switch tag {
+	case OpSHA1, OpRIPEMD160:
+		return 20
+	case OpSHA256, OpKeccak256:
+		return 32
+	}
+	return 0
+}
+
+// Attestation attests that a message existed at some point in time. The payload depends on the tag.
+type Attestation struct {
+	Tag     [8]byte
+	Payload []byte
+}
+
+// Tags of the attestations.
+var (
+	// BitcoinTag attests that the message is the merkle root of the Bitcoin block header at a height.
+	BitcoinTag = [8]byte{0x05, 0x88, 0x96, 0x0d, 0x73, 0xd7, 0x19, 0x01}
+	// LitecoinTag attests that the message is the merkle root of the Litecoin block header at a height.
+	LitecoinTag = [8]byte{0x06, 0x86, 0x9a, 0x0d, 0x73, 0xd7, 0x1b, 0x45}
+	// PendingTag means a calendar will anchor the message, and can be asked for the complete timestamp at its URI.
+	PendingTag = [8]byte{0x83, 0xdf, 0xe3, 0x0d, 0x2e, 0xf9, 0x0c, 0x8e}
+)
+
+// BitcoinAttestation returns the attestation of the Bitcoin block header at a height.
+func BitcoinAttestation(height uint64) Attestation {
+	return Attestation{Tag: BitcoinTag, Payload: binary.AppendUvarint(nil, height)}
+}
+
+// PendingAttestation returns the attestation of a calendar that will anchor the message.
+func PendingAttestation(uri string) Attestation {
+	return Attestation{Tag: PendingTag, Payload: appendVarBytes(nil, []byte(uri))}
+}
+
+// BitcoinHeight returns the height of a Bitcoin attestation.
+func (a Attestation) BitcoinHeight() (uint64, bool) {
+	if a.Tag != BitcoinTag {
+		return 0, false
+	}
+	height, n := binary.Uvarint(a.Payload)
+	return height, n > 0
+}
+
+// PendingURI returns the URI of the calendar of a pending attestation.
+func (a Attestation) PendingURI() (string, bool) {
+	if a.Tag != PendingTag {
+		return "", false
+	}
+	d := decoder{b: a.Payload}
+	uri := d.varBytes(maxPayloadLength)
+	return string(uri), d.err == nil
+}
+
+// Timestamp commits Msg to its attestations, and to the attestations of the timestamps of its branches.
+type Timestamp struct {
+	Msg          []byte
+	Attestations []Attestation
+	Branches     []Branch
+}
+
+// Branch is an operation on the message of a timestamp, and the timestamp of the result.
+type Branch struct {
+	Op        Op
+	Timestamp *Timestamp
+}
+
+// Attested is an attestation of a timestamp with the message it attests.
+type Attested struct {
+	Msg         []byte
+	Attestation Attestation
+}
+
+// All returns the attestations of the timestamp and all its branches with the messages they attest.
+// The messages are computed from the message of the timestamp, ignoring those stored in the branches.
+func (t *Timestamp) All() ([]Attested, error) {
+	if t == nil {
+		return nil, errors.New("nil timestamp")
+	}
+	var all []Attested
+	var walk func(t *Timestamp, msg []byte, depth int) error
+	walk = func(t *Timestamp, msg []byte, depth int) error {
+		if t == nil || depth > maxDepth {
+			return errors.New("invalid timestamp")
+		}
+		for _, a := range t.Attestations {
+			all = append(all, Attested{Msg: msg, Attestation: a})
+		}
+		for _, b := range t.Branches {
+			result, err := b.Op.Apply(msg)
+			if err != nil {
+				return err
+			}
+			if err := walk(b.Timestamp, result, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(t, t.Msg, 0); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// VerifyBitcoin checks the Bitcoin attestations of a timestamp against the merkle roots of block headers, which
+// merkleRoot returns by height in the byte order of the header (the reverse of how explorers display them).
+// Every Bitcoin attestation must match, so the result doesn't depend on the order of the branches.
+// Returns the lowest attested height: the message existed when that block was mined.
+// Other attestations, like pending ones, are ignored.
+func VerifyBitcoin(t *Timestamp, merkleRoot func(height uint64) ([]byte, error)) (uint64, error) {
+	all, err := t.All()
+	if err != nil {
+		return 0, err
+	}
+	found := false
+	var lowest uint64
+	for _, a := range all {
+		height, ok := a.Attestation.BitcoinHeight()
+		if !ok {
+			continue
+		}
+		root, err := merkleRoot(height)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.Equal(root, a.Msg) {
+			return 0, errors.New("attestation does not match block header")
+		}
+		if !found || height < lowest {
+			found, lowest = true, height
+		}
+	}
+	if !found {
+		return 0, errors.New("no bitcoin attestation")
+	}
+	return lowest, nil
+}
+
+// PathOps returns the operations that compute the root of an inclusion proof from the data of the leaf, like the
+// RFC 6962 hash strategy (the default one) does:
+//
+//	leaf:                        prepend 0x00, sha256
+//	sibling on the left:         prepend 0x01 | sibling, sha256
+//	sibling on the right:        prepend 0x01, append sibling, sha256
+//
+// Returns an error if the operations don't compute the root of the proof, e.g. for proofs of another hash strategy.
+func PathOps(leaf []byte, p *gomerkletree.Proof) ([]Op, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	ops := []Op{Prepend([]byte{0}), SHA256()}
+	for i, sibling := range p.Siblings() {
+		if p.Directions()[i] {
+			ops = append(ops, Prepend(slices.Concat([]byte{1}, sibling)), SHA256())
+		} else {
+			ops = append(ops, Prepend([]byte{1}), Append(sibling), SHA256())
+		}
+	}
+
+	msg := leaf
+	for _, op := range ops {
+		var err error
+		if msg, err = op.Apply(msg); err != nil {
+			return nil, err
+		}
+	}
+	if !bytes.Equal(msg, p.Root()) {
+		return nil, errors.New("proof not for the RFC 6962 hash strategy")
+	}
+	return ops, nil
+}
+
+// FromProof returns the timestamp of the data of a leaf, given its inclusion proof and the timestamp of the root.
+// The message of the root timestamp is either the root, or its SHA-256 digest, like when the root was stamped as a file.
+func FromProof(leaf []byte, p *gomerkletree.Proof, root *Timestamp) (*Timestamp, error) {
+	if root == nil {
+		return nil, errors.New("nil timestamp")
+	}
+	ops, err := PathOps(leaf, p)
+	if err != nil {
+		return nil, err
+	}
+	switch rootHash := p.Root(); {
+	case bytes.Equal(root.Msg, rootHash):
+	case bytes.Equal(root.Msg, sha256Sum(rootHash)):
+		ops = append(ops, SHA256())
+	default:
+		return nil, errors.New("timestamp not for root")
+	}
+
+	// build the chain backwards from the root timestamp
+	t := root
+	msgs := make([][]byte, len(ops))
+	msg := leaf
+	for i, op := range ops {
+		msgs[i] = msg
+		msg, _ = op.Apply(msg) // applied by PathOps
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		t = &Timestamp{Msg: msgs[i], Branches: []Branch{{Op: ops[i], Timestamp: t}}}
+	}
+	return t, nil
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
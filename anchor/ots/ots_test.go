@@ -0,0 +1,291 @@
+package ots
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+func TestOp_Apply(t *testing.T) {
+	tests := []struct {
+		op       Op
+		msg      string
+		expected string
+	}{
+		{Append([]byte("b")), "a", hex.EncodeToString([]byte("ab"))},
+		{Prepend([]byte("b")), "a", hex.EncodeToString([]byte("ba"))},
+		{Op{Tag: OpReverse}, "abc", hex.EncodeToString([]byte("cba"))},
+		{Op{Tag: OpHexlify}, "\x01\xff", hex.EncodeToString([]byte("01ff"))},
+		{SHA256(), "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Op{Tag: OpSHA1}, "", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{Op{Tag: OpRIPEMD160}, "", "9c1185a5c5e9fc54612808977ee8f548b2258d31"},
+		{Op{Tag: OpKeccak256}, "", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+	}
+	for _, tt := range tests {
+		result, err := tt.op.Apply([]byte(tt.msg))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hex.EncodeToString(result) != tt.expected {
+			t.Errorf("op %#x: expected %s, got %x", tt.op.Tag, tt.expected, result)
+		}
+	}
+
+	if _, err := (Op{Tag: 0x42}).Apply(nil); err == nil {
+		t.Errorf("expected error for unknown operation")
+	}
+	if _, err := Append(make([]byte, maxMsgLength)).Apply([]byte("a")); err == nil {
+		t.Errorf("expected error for too long message")
+	}
+}
+
+func TestAttestation(t *testing.T) {
+	if height, ok := BitcoinAttestation(358391).BitcoinHeight(); !ok || height != 358391 {
+		t.Errorf("expected height 358391, got %d", height)
+	}
+	if _, ok := PendingAttestation("https://calendar").BitcoinHeight(); ok {
+		t.Errorf("expected no height for pending attestation")
+	}
+	if uri, ok := PendingAttestation("https://calendar").PendingURI(); !ok || uri != "https://calendar" {
+		t.Errorf("expected https://calendar, got %s", uri)
+	}
+}
+
+// newTimestamp returns a timestamp of msg with a pending attestation, and a branch attested in bitcoin block 100.
+func newTimestamp(msg []byte) *Timestamp {
+	stamped := sha256Sum(append(bytes.Clone(msg), "nonce"...))
+	return &Timestamp{
+		Msg:          msg,
+		Attestations: []Attestation{PendingAttestation("https://calendar")},
+		Branches: []Branch{{
+			Op: Append([]byte("nonce")),
+			Timestamp: &Timestamp{
+				Msg: append(bytes.Clone(msg), "nonce"...),
+				Branches: []Branch{{
+					Op:        SHA256(),
+					Timestamp: &Timestamp{Msg: stamped, Attestations: []Attestation{BitcoinAttestation(100)}},
+				}},
+			},
+		}},
+	}
+}
+
+func TestTimestamp_Binary(t *testing.T) {
+	msg := sha256Sum([]byte("file"))
+	ts := newTimestamp(msg)
+	b, err := ts.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// pending attestation (not last), append, sha256 and bitcoin attestation (last)
+	expected := "ff00" + hex.EncodeToString(PendingTag[:]) + "11" + "10" + hex.EncodeToString([]byte("https://calendar")) +
+		"f005" + hex.EncodeToString([]byte("nonce")) + "08" +
+		"00" + hex.EncodeToString(BitcoinTag[:]) + "01" + "64"
+	if hex.EncodeToString(b) != expected {
+		t.Errorf("expected %s, got %x", expected, b)
+	}
+
+	decoded, err := UnmarshalTimestamp(b, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b2, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(b, b2) {
+		t.Errorf("expected %x, got %x", b, b2)
+	}
+	all, err := decoded.All()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 2 || !bytes.Equal(all[0].Msg, msg) || !bytes.Equal(all[1].Msg, ts.Branches[0].Timestamp.Branches[0].Timestamp.Msg) {
+		t.Errorf("unexpected attestations %+v", all)
+	}
+
+	if _, err := UnmarshalTimestamp(append(b, 0), msg); err == nil {
+		t.Errorf("expected error for trailing data")
+	}
+	if _, err := UnmarshalTimestamp(b[:len(b)-1], msg); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+	if _, err := UnmarshalTimestamp([]byte{0x42}, msg); err == nil {
+		t.Errorf("expected error for unknown operation")
+	}
+	if _, err := (&Timestamp{Msg: msg}).MarshalBinary(); err == nil {
+		t.Errorf("expected error for timestamp without attestations")
+	}
+}
+
+func TestDetachedTimestamp(t *testing.T) {
+	digest := sha256Sum([]byte("file"))
+	d := &DetachedTimestamp{HashOp: OpSHA256, Timestamp: newTimestamp(digest)}
+	b, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(b, magic) || b[len(magic)] != 1 || b[len(magic)+1] != OpSHA256 {
+		t.Errorf("unexpected header %x", b[:len(magic)+2])
+	}
+
+	var decoded DetachedTimestamp
+	if err := decoded.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.HashOp != OpSHA256 || !bytes.Equal(decoded.Digest(), digest) {
+		t.Errorf("unexpected detached timestamp %x %x", decoded.HashOp, decoded.Digest())
+	}
+
+	if err := decoded.UnmarshalBinary(b[1:]); err == nil {
+		t.Errorf("expected error for missing magic")
+	}
+	version := bytes.Clone(b)
+	version[len(magic)] = 2
+	if err := decoded.UnmarshalBinary(version); err == nil {
+		t.Errorf("expected error for unsupported version")
+	}
+	d.HashOp = OpSHA1
+	if _, err := d.MarshalBinary(); err == nil {
+		t.Errorf("expected error for digest of another length")
+	}
+}
+
+func TestVerifyBitcoin(t *testing.T) {
+	ts := newTimestamp(sha256Sum([]byte("file")))
+	blockRoot := ts.Branches[0].Timestamp.Branches[0].Timestamp.Msg
+	headers := map[uint64][]byte{100: blockRoot}
+	merkleRoot := func(height uint64) ([]byte, error) {
+		root, ok := headers[height]
+		if !ok {
+			return nil, errors.New("unknown block")
+		}
+		return root, nil
+	}
+
+	height, err := VerifyBitcoin(ts, merkleRoot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if height != 100 {
+		t.Errorf("expected height 100, got %d", height)
+	}
+
+	headers[100] = sha256Sum([]byte("other"))
+	if _, err := VerifyBitcoin(ts, merkleRoot); err == nil {
+		t.Errorf("expected error for mismatching block header")
+	}
+	pending := &Timestamp{Msg: ts.Msg, Attestations: ts.Attestations}
+	if _, err := VerifyBitcoin(pending, merkleRoot); err == nil {
+		t.Errorf("expected error for pending timestamp")
+	}
+}
+
+func TestVerifyBitcoin_Order(t *testing.T) {
+	msg := sha256Sum([]byte("file"))
+	attest := func(suffix string, height uint64) Branch {
+		return Branch{Op: Append([]byte(suffix)), Timestamp: &Timestamp{
+			Msg:          append(bytes.Clone(msg), suffix...),
+			Attestations: []Attestation{BitcoinAttestation(height)},
+		}}
+	}
+	low, high := attest("low", 100), attest("high", 200)
+	headers := map[uint64][]byte{100: low.Timestamp.Msg, 200: high.Timestamp.Msg}
+	merkleRoot := func(height uint64) ([]byte, error) {
+		return headers[height], nil
+	}
+
+	for _, branches := range [][]Branch{{low, high}, {high, low}} {
+		ts := &Timestamp{Msg: msg, Branches: branches}
+		headers[200] = high.Timestamp.Msg
+		if height, err := VerifyBitcoin(ts, merkleRoot); err != nil || height != 100 {
+			t.Errorf("expected height 100, got %d (%v)", height, err)
+		}
+
+		// a mismatch fails, whether it is the lowest attestation or not
+		headers[200] = sha256Sum([]byte("other"))
+		if _, err := VerifyBitcoin(ts, merkleRoot); err == nil {
+			t.Errorf("expected error for mismatching block header")
+		}
+	}
+}
+
+func TestFromProof(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree, err := gomerkletree.BuildMerkleTreeBytes(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := tree.Root()
+
+	for _, rootMsg := range [][]byte{root, sha256Sum(root)} {
+		rootTimestamp := newTimestamp(rootMsg)
+		blockRoot := rootTimestamp.Branches[0].Timestamp.Branches[0].Timestamp.Msg
+		for i, leaf := range data {
+			p, err := tree.ProofBytes(leaf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ts, err := FromProof(leaf, p, rootTimestamp)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(ts.Msg, leaf) {
+				t.Errorf("leaf %d: expected message %s, got %s", i, leaf, ts.Msg)
+			}
+
+			// the timestamp of the leaf survives encoding, and is attested by the block of the root
+			b, err := ts.MarshalBinary()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, err := UnmarshalTimestamp(b, leaf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			height, err := VerifyBitcoin(decoded, func(uint64) ([]byte, error) { return blockRoot, nil })
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if height != 100 {
+				t.Errorf("expected height 100, got %d", height)
+			}
+		}
+	}
+}
+
+func TestFromProof_Errors(t *testing.T) {
+	tree, err := gomerkletree.BuildMerkleTreeBytes([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := tree.ProofBytes([]byte("b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := FromProof([]byte("b"), p, newTimestamp(sha256Sum([]byte("other")))); err == nil {
+		t.Errorf("expected error for timestamp of another root")
+	}
+	if _, err := FromProof([]byte("a"), p, newTimestamp(tree.Root())); err == nil {
+		t.Errorf("expected error for another leaf")
+	}
+
+	// proofs of other hash strategies can't be expressed as operations
+	bitcoin, err := gomerkletree.BuildMerkleTreeBytes([][]byte{[]byte("a"), []byte("b"), []byte("c")},
+		gomerkletree.WithHashStrategy(gomerkletree.BitcoinHashStrategy{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err = bitcoin.ProofBytes([]byte("b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := PathOps([]byte("b"), p); err == nil {
+		t.Errorf("expected error for proof of another hash strategy")
+	}
+}
@@ -0,0 +1,236 @@
+// Package audit monitors a transparency log. An Auditor polls the signed tree heads of the log, checks that every
+// new head is signed by the log and consistent with the previous one, and spot-checks inclusion proofs of random
+// leaves against it. Failed checks are reported to a callback, so forks and rewrites of the log can be alerted on.
+//
+// A log served by httpapi.NewHandler can be audited with an httpapi.Client as Source.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+// Source is the log that is audited.
+type Source interface {
+	SignedHead(ctx context.Context) (*gomerkletree.SignedTreeHead, error)
+	ConsistencyProof(ctx context.Context, oldSize, newSize int) (*gomerkletree.ConsistencyProof, error)
+	// InclusionProof returns the proof of the i-th leaf against the tree head with the given size.
+	InclusionProof(ctx context.Context, i, size int) (*gomerkletree.Proof, error)
+	LeafHash(ctx context.Context, i int) ([]byte, error)
+}
+
+// Check is a check of the auditor that can fail.
+type Check int
+
+const (
+	// CheckSignature checks that a tree head is signed by the log.
+	CheckSignature Check = iota
+	// CheckConsistency checks that a tree head extends the previous tree head.
+	CheckConsistency
+	// CheckInclusion checks that the log can prove a leaf is included in a tree head.
+	CheckInclusion
+)
+
+func (c Check) String() string {
+	switch c {
+	case CheckSignature:
+		return "signature"
+	case CheckConsistency:
+		return "consistency"
+	case CheckInclusion:
+		return "inclusion"
+	}
+	return "unknown"
+}
+
+// Alert reports a failed check of a tree head. Previous is the last verified head, nil before the first one.
+type Alert struct {
+	Check    Check
+	Head     *gomerkletree.SignedTreeHead
+	Previous *gomerkletree.SignedTreeHead
+	Err      error
+}
+
+// Option configures an Auditor.
+type Option func(*Auditor)
+
+// WithSamples sets the number of random leaves whose inclusion is checked in every new tree head, 1 by default.
+func WithSamples(n int) Option {
+	return func(a *Auditor) {
+		a.samples = max(n, 0)
+	}
+}
+
+// WithTrustedHead starts auditing from a tree head that was verified before, e.g. by a previous run of the auditor.
+func WithTrustedHead(head *gomerkletree.SignedTreeHead) Option {
+	return func(a *Auditor) {
+		a.head = head
+	}
+}
+
+// Auditor audits a log. It is safe for concurrent use.
+type Auditor struct {
+	src     Source
+	key     ed25519.PublicKey
+	alert   func(Alert)
+	samples int
+
+	mu   sync.Mutex
+	head *gomerkletree.SignedTreeHead // last verified head
+}
+
+// New returns an auditor for the log at src, whose tree heads are signed with the given public key.
+// Failed checks are reported to alert, which can be nil.
+func New(src Source, key ed25519.PublicKey, alert func(Alert), opts ...Option) *Auditor {
+	a := &Auditor{
+		src:     src,
+		key:     key,
+		alert:   alert,
+		samples: 1,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Head returns the last verified tree head, or nil if no head was verified yet.
+func (a *Auditor) Head() *gomerkletree.SignedTreeHead {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.head
+}
+
+// Run polls the log every interval until the context is done, and returns the error of the context.
+// Errors fetching from the log are ignored, the next poll retries; failed checks are reported to the alert callback.
+func (a *Auditor) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the latest signed tree head of the log and checks it. A head that passes all checks becomes
+// the head the next poll is checked against. Failed checks are reported to the alert callback, and returned as error,
+// like errors fetching from the log.
+// The log is queried without holding the lock, so concurrent polls (and Head) don't wait for each other's requests.
+func (a *Auditor) Poll(ctx context.Context) error {
+	prev := a.Head()
+	head, err := a.src.SignedHead(ctx)
+	if err != nil {
+		return err
+	}
+	if err := gomerkletree.VerifyTreeHead(head, a.key); err != nil {
+		return a.fail(CheckSignature, head, prev, err)
+	}
+	if err := a.checkConsistency(ctx, prev, head); err != nil {
+		return err
+	}
+	if err := a.checkInclusion(ctx, prev, head); err != nil {
+		return err
+	}
+
+	for {
+		a.mu.Lock()
+		current := a.head
+		if current == prev {
+			a.head = head
+		}
+		a.mu.Unlock()
+		if current == prev {
+			return nil
+		}
+
+		// another poll verified a head in the meantime: keep the newer of both, after checking it extends the other
+		if current.Size >= head.Size {
+			return a.checkConsistency(ctx, head, current)
+		}
+		if err := a.checkConsistency(ctx, current, head); err != nil {
+			return err
+		}
+		prev = current
+	}
+}
+
+// checkConsistency checks that the head extends the previous verified head.
+func (a *Auditor) checkConsistency(ctx context.Context, prev, head *gomerkletree.SignedTreeHead) error {
+	switch {
+	case prev == nil || prev.Size == 0:
+		return nil
+	case head.Size < prev.Size:
+		return a.fail(CheckConsistency, head, prev, errors.New("tree shrank"))
+	case head.Size == prev.Size:
+		if !bytes.Equal(head.Root, prev.Root) {
+			return a.fail(CheckConsistency, head, prev, errors.New("different roots for the same tree size"))
+		}
+		return nil
+	}
+
+	proof, err := a.src.ConsistencyProof(ctx, prev.Size, head.Size)
+	if err != nil {
+		return err
+	}
+	if proof.OldSize() != prev.Size || proof.NewSize() != head.Size {
+		return a.fail(CheckConsistency, head, prev, errors.New("proof does not match tree heads"))
+	}
+	if err := gomerkletree.VerifyConsistency(prev.Root, head.Root, proof); err != nil {
+		return a.fail(CheckConsistency, head, prev, err)
+	}
+	return nil
+}
+
+// checkInclusion checks the inclusion proofs of random leaves of a head that is newer than the previous verified head.
+// The auditor doesn't know the entries of the log, so it checks that the leaf hash the log returns is proven at
+// its index, i.e. that the log can back its root with a tree.
+func (a *Auditor) checkInclusion(ctx context.Context, prev, head *gomerkletree.SignedTreeHead) error {
+	if head.Size == 0 || (prev != nil && head.Size == prev.Size) {
+		return nil
+	}
+	for range a.samples {
+		i := rand.IntN(head.Size)
+		hash, err := a.src.LeafHash(ctx, i)
+		if err != nil {
+			return err
+		}
+		proof, err := a.src.InclusionProof(ctx, i, head.Size)
+		if err != nil {
+			return err
+		}
+		if !proof.MatchesIndex(i, head.Size) {
+			return a.fail(CheckInclusion, head, prev, errors.New("proof not for index"))
+		}
+		if !bytes.Equal(proof.Root(), head.Root) {
+			return a.fail(CheckInclusion, head, prev, errors.New("proof not for tree head"))
+		}
+		if err := gomerkletree.VerifyProofFromHash(hash, proof); err != nil {
+			return a.fail(CheckInclusion, head, prev, err)
+		}
+	}
+	return nil
+}
+
+// fail reports a failed check of a head against the previous verified head to the alert callback and returns its error.
+func (a *Auditor) fail(check Check, head, prev *gomerkletree.SignedTreeHead, err error) error {
+	if a.alert != nil {
+		a.alert(Alert{
+			Check:    check,
+			Head:     head,
+			Previous: prev,
+			Err:      err,
+		})
+	}
+	return err
+}
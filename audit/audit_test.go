@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/httpapi"
+	"github.com/jeltjongsma/go-merkletree/log"
+)
+
+type entry string
+
+func (e entry) Bytes() []byte {
+	return []byte(e)
+}
+
+// logSource reads a log directly, without serving it.
+type logSource struct {
+	l *log.Log
+}
+
+func (s logSource) SignedHead(ctx context.Context) (*gomerkletree.SignedTreeHead, error) {
+	return s.l.SignedHead(), nil
+}
+
+func (s logSource) ConsistencyProof(ctx context.Context, oldSize, newSize int) (*gomerkletree.ConsistencyProof, error) {
+	return s.l.ConsistencyProof(oldSize, newSize)
+}
+
+func (s logSource) InclusionProof(ctx context.Context, i, size int) (*gomerkletree.Proof, error) {
+	return s.l.InclusionProof(i, size)
+}
+
+func (s logSource) LeafHash(ctx context.Context, i int) ([]byte, error) {
+	return s.l.LeafHash(i)
+}
+
+// badLeafSource returns a leaf hash that is not in the log.
+type badLeafSource struct {
+	logSource
+}
+
+func (s badLeafSource) LeafHash(ctx context.Context, i int) ([]byte, error) {
+	return bytes.Repeat([]byte{1}, 32), nil
+}
+
+// blockingSource waits for release before returning a tree head.
+type blockingSource struct {
+	logSource
+	release chan struct{}
+}
+
+func (s blockingSource) SignedHead(ctx context.Context) (*gomerkletree.SignedTreeHead, error) {
+	<-s.release
+	return s.logSource.SignedHead(ctx)
+}
+
+func newLog(t *testing.T, key ed25519.PrivateKey, entries ...string) *log.Log {
+	t.Helper()
+	l, err := log.New(gomerkletree.NewMemoryStore(), key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	add(t, l, entries...)
+	return l
+}
+
+func add(t *testing.T, l *log.Log, entries ...string) {
+	t.Helper()
+	for _, e := range entries {
+		l.Add(entry(e))
+	}
+	if _, err := l.Integrate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAuditor_Poll(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	l := newLog(t, key)
+	server := httptest.NewServer(httpapi.NewHandler(l))
+	defer server.Close()
+
+	var alerts []Alert
+	a := New(httpapi.NewClient(server.URL, nil), l.PublicKey(), func(alert Alert) {
+		alerts = append(alerts, alert)
+	}, WithSamples(3))
+
+	for i := range 5 {
+		var entries []string
+		for j := range i * 3 {
+			entries = append(entries, fmt.Sprint(i, j))
+		}
+		add(t, l, entries...)
+
+		if err := a.Poll(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if size, root, _ := l.Head(); a.Head().Size != size || !bytes.Equal(a.Head().Root, root) {
+			t.Errorf("expected verified head of size %d, got %d", size, a.Head().Size)
+		}
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestAuditor_Alerts(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	ctx := context.Background()
+
+	t.Run("signature", func(t *testing.T) {
+		var alerts []Alert
+		other, _, _ := ed25519.GenerateKey(nil)
+		a := New(logSource{newLog(t, key, "a")}, other, func(alert Alert) {
+			alerts = append(alerts, alert)
+		})
+		if err := a.Poll(ctx); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+		if len(alerts) != 1 || alerts[0].Check != CheckSignature {
+			t.Errorf("expected signature alert, got %v", alerts)
+		}
+		if a.Head() != nil {
+			t.Errorf("expected no verified head")
+		}
+	})
+
+	t.Run("fork", func(t *testing.T) {
+		var alerts []Alert
+		l := newLog(t, key, "a", "b", "c")
+		a := New(logSource{l}, l.PublicKey(), func(alert Alert) {
+			alerts = append(alerts, alert)
+		})
+		if err := a.Poll(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		verified := a.Head()
+
+		// the log signs a head that rewrites its history
+		a.src = logSource{newLog(t, key, "a", "x", "c", "d")}
+		if err := a.Poll(ctx); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+		if len(alerts) != 1 || alerts[0].Check != CheckConsistency || alerts[0].Previous != verified {
+			t.Errorf("expected consistency alert, got %v", alerts)
+		}
+		var verr *gomerkletree.VerificationError
+		if !errors.As(alerts[0].Err, &verr) {
+			t.Errorf("expected verification error, got %v", alerts[0].Err)
+		}
+
+		// same size, different root
+		a.src = logSource{newLog(t, key, "a", "x", "c")}
+		if err := a.Poll(ctx); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+		// smaller tree
+		a.src = logSource{newLog(t, key, "a")}
+		if err := a.Poll(ctx); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+		if len(alerts) != 3 || a.Head() != verified {
+			t.Errorf("expected 3 alerts and the first head to stay verified, got %d alerts", len(alerts))
+		}
+	})
+
+	t.Run("inclusion", func(t *testing.T) {
+		var alerts []Alert
+		l := newLog(t, key, "a", "b", "c")
+		a := New(badLeafSource{logSource{l}}, l.PublicKey(), func(alert Alert) {
+			alerts = append(alerts, alert)
+		})
+		if err := a.Poll(ctx); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+		if len(alerts) != 1 || alerts[0].Check != CheckInclusion {
+			t.Errorf("expected inclusion alert, got %v", alerts)
+		}
+	})
+}
+
+func TestAuditor_Concurrent(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	l := newLog(t, key, "a", "b")
+	src := blockingSource{logSource{l}, make(chan struct{})}
+
+	var alerts []Alert
+	var mu sync.Mutex
+	a := New(src, l.PublicKey(), func(alert Alert) {
+		mu.Lock()
+		defer mu.Unlock()
+		alerts = append(alerts, alert)
+	}, WithSamples(2))
+
+	errs := make(chan error)
+	for range 4 {
+		go func() {
+			errs <- a.Poll(context.Background())
+		}()
+	}
+
+	// the head can be read while polls wait for the log
+	done := make(chan struct{})
+	go func() {
+		a.Head()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Head blocked by a pending poll")
+	}
+
+	for i := range 4 {
+		add(t, l, fmt.Sprint(i))
+		src.release <- struct{}{}
+	}
+	for range 4 {
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if size, root, _ := l.Head(); a.Head().Size != size || !bytes.Equal(a.Head().Root, root) {
+		t.Errorf("expected the newest head of size %d, got %d", size, a.Head().Size)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestAuditor_Run(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	l := newLog(t, key, "a", "b")
+	head := l.SignedHead()
+	add(t, l, "c")
+
+	a := New(logSource{l}, l.PublicKey(), nil, WithTrustedHead(head))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := a.Run(ctx, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if a.Head().Size != 3 {
+		t.Errorf("expected verified head of size 3, got %d", a.Head().Size)
+	}
+}
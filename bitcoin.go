@@ -0,0 +1,152 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+)
+
+// BitcoinHashStrategy hashes like the merkle trees of Bitcoin blocks: leaves are serialized transactions, hashed into
+// their txid as SHA-256(SHA-256(tx)), and internal nodes are hashed as SHA-256(SHA-256(left || right)), without
+// domain separation. Combine it with WithDuplication, or use BuildBitcoinMerkleTree for a tree over txids.
+type BitcoinHashStrategy struct{}
+
+func (h BitcoinHashStrategy) HashLeaf(l []byte) []byte {
+	return doubleSHA256(l)
+}
+
+func (h BitcoinHashStrategy) HashInternal(l, r []byte) []byte {
+	return doubleSHA256(l, r)
+}
+
+func doubleSHA256(msg ...[]byte) []byte {
+	d := sha256.New()
+	for _, m := range msg {
+		d.Write(m)
+	}
+	sum := sha256.Sum256(d.Sum(nil))
+	return sum[:]
+}
+
+// BuildBitcoinMerkleTree builds the merkle tree of a block from the txids of its transactions, in the internal byte
+// order (see ParseBitcoinHash). Its root is the merkle root of the block header.
+//
+// Duplicating the last node of odd levels means a transaction list ending in a repeated pair of transactions (or
+// subtrees) has the same root as the list without the repetition (CVE-2012-2459). Such mutated lists are rejected,
+// like Bitcoin Core does, so a block can't be validated with transactions it doesn't commit to.
+func BuildBitcoinMerkleTree(txids [][]byte) (*MerkleTree, error) {
+	if len(txids) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	for _, txid := range txids {
+		if len(txid) != sha256.Size {
+			return nil, errors.New("invalid txid length")
+		}
+	}
+	tree, err := BuildMerkleTreeFromHashes(txids, WithHashStrategy(BitcoinHashStrategy{}), WithDuplication())
+	if err != nil {
+		return nil, err
+	}
+	if bitcoinMutated(tree.root) {
+		return nil, errors.New("mutated transaction list (CVE-2012-2459)")
+	}
+	return tree, nil
+}
+
+// bitcoinMutated reports whether two distinct children of a node have the same hash, which only happens when a
+// transaction list repeats what duplication would have added.
+func bitcoinMutated(n *Node) bool {
+	if n.left == nil {
+		return false
+	}
+	if n.right != n.left && hashEqual(n.left.h, n.right.h) {
+		return true
+	}
+	return bitcoinMutated(n.left) || (n.right != n.left && bitcoinMutated(n.right))
+}
+
+// BitcoinHeaderMerkleRoot returns the merkle root in a serialized 80 byte block header, in the internal byte order.
+func BitcoinHeaderMerkleRoot(header []byte) ([]byte, error) {
+	if len(header) != 80 {
+		return nil, errors.New("invalid header length")
+	}
+	return bytes.Clone(header[36:68]), nil
+}
+
+// VerifyBitcoinProof checks an SPV proof that the transaction with the given txid is the index-th of the numTx
+// transactions of the block with the given 80 byte header. The proof must have the siblings of the txid from the leaf
+// up, including the txid itself where a node is the last of its level and paired with itself.
+// A sibling that equals the node it is paired with anywhere else is rejected as a mutation (CVE-2012-2459).
+func VerifyBitcoinProof(txid []byte, index, numTx int, p *Proof, header []byte) error {
+	if p == nil {
+		return errors.New("no proof")
+	}
+	root, err := BitcoinHeaderMerkleRoot(header)
+	if err != nil {
+		return err
+	}
+	if len(txid) != sha256.Size {
+		return errors.New("invalid txid length")
+	}
+	if numTx <= 0 || index < 0 || index >= numTx {
+		return errors.New("index out of range")
+	}
+	if len(p.siblings) != len(p.left) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	hash := txid
+	level := 0
+	for i, size := index, numTx; size > 1; i, size = i/2, (size+1)/2 {
+		if level >= len(p.siblings) {
+			return errors.New("proof too short")
+		}
+		sibling, left := p.siblings[level], p.left[level]
+		duplicated := i%2 == 0 && i+1 == size
+		if left != (i%2 != 0) {
+			return errors.New("proof not for index")
+		}
+		if equal := hashEqual(sibling, hash); duplicated && !equal {
+			return errors.New("duplicated node not paired with itself")
+		} else if !duplicated && equal {
+			return errors.New("mutated proof (CVE-2012-2459)")
+		}
+		if left {
+			hash = doubleSHA256(sibling, hash)
+		} else {
+			hash = doubleSHA256(hash, sibling)
+		}
+		level++
+	}
+	if level != len(p.siblings) {
+		return errors.New("proof too long")
+	}
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root)
+	}
+	return nil
+}
+
+// ParseBitcoinHash parses a txid or block hash as shown by block explorers and Bitcoin Core, which display hashes
+// in reverse byte order, and returns it in the internal byte order used in blocks and merkle trees.
+func ParseBitcoinHash(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != sha256.Size {
+		return nil, errors.New("invalid hash length")
+	}
+	slices.Reverse(b)
+	return b, nil
+}
+
+// FormatBitcoinHash formats a hash in the internal byte order as shown by block explorers, the reverse of
+// ParseBitcoinHash.
+func FormatBitcoinHash(h []byte) string {
+	r := slices.Clone(h)
+	slices.Reverse(r)
+	return hex.EncodeToString(r)
+}
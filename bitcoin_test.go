@@ -0,0 +1,125 @@
+package gomerkletree
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// Block 100000 of the Bitcoin main chain.
+var (
+	bitcoinBlockHash = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	bitcoinRoot      = "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+	bitcoinTxIDs     = []string{
+		"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+		"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+		"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+	}
+)
+
+func bitcoinHeader(t *testing.T) []byte {
+	t.Helper()
+	prev, _ := ParseBitcoinHash("000000000002d01c1fccc21636b607dfd930d31d01c3a62104612a1719011250")
+	root, _ := ParseBitcoinHash(bitcoinRoot)
+	header := binary.LittleEndian.AppendUint32(nil, 1) // version
+	header = append(header, prev...)
+	header = append(header, root...)
+	header = binary.LittleEndian.AppendUint32(header, 1293623863) // time
+	header = binary.LittleEndian.AppendUint32(header, 0x1b04864c) // bits
+	header = binary.LittleEndian.AppendUint32(header, 274148111)  // nonce
+
+	// the header is the one of the block
+	if hash := FormatBitcoinHash(doubleSHA256(header)); hash != bitcoinBlockHash {
+		t.Fatalf("expected block hash %s, got %s", bitcoinBlockHash, hash)
+	}
+	return header
+}
+
+func bitcoinTxIDBytes(t *testing.T, s []string) [][]byte {
+	t.Helper()
+	txids := make([][]byte, len(s))
+	for i, txid := range s {
+		b, err := ParseBitcoinHash(txid)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		txids[i] = b
+	}
+	return txids
+}
+
+func TestBitcoin_BlockMerkleRoot(t *testing.T) {
+	header := bitcoinHeader(t)
+	txids := bitcoinTxIDBytes(t, bitcoinTxIDs)
+
+	tree, err := BuildBitcoinMerkleTree(txids)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if root := FormatBitcoinHash(tree.Root()); root != bitcoinRoot {
+		t.Errorf("expected %s, got %s", bitcoinRoot, root)
+	}
+
+	for i, txid := range txids {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyBitcoinProof(txid, i, len(txids), proof, header); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyBitcoinProof(txid, (i+1)%len(txids), len(txids), proof, header); err == nil {
+			t.Errorf("expected error for wrong index")
+		}
+	}
+
+	// a serialized transaction hashes into its txid
+	if h := (BitcoinHashStrategy{}).HashLeaf([]byte("tx")); len(h) != 32 {
+		t.Errorf("expected 32 byte txid, got %d", len(h))
+	}
+
+	if _, err := BitcoinHeaderMerkleRoot(header[:79]); err == nil {
+		t.Errorf("expected error for short header")
+	}
+	if _, err := ParseBitcoinHash("00"); err == nil {
+		t.Errorf("expected error for short hash")
+	}
+}
+
+func TestBitcoin_Mutation(t *testing.T) {
+	header := bitcoinHeader(t)
+	txids := bitcoinTxIDBytes(t, bitcoinTxIDs[:3])
+
+	tree, err := BuildBitcoinMerkleTree(txids)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// repeating the last transaction gives the same root, and is rejected
+	mutated := append(txids, txids[2])
+	unchecked, err := BuildMerkleTreeFromHashes(mutated, WithHashStrategy(BitcoinHashStrategy{}), WithDuplication())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if root := unchecked.Root(); FormatBitcoinHash(root) != FormatBitcoinHash(tree.Root()) {
+		t.Fatalf("expected the mutated list to have the same root")
+	}
+	if _, err := BuildBitcoinMerkleTree(mutated); err == nil {
+		t.Errorf("expected error for mutated transaction list")
+	}
+
+	// the proof of the duplicated transaction pairs it with itself, which is only valid at the end of a level
+	proof, _ := tree.ProofByIndex(2)
+	root := tree.Root()
+	mutatedHeader := append(append(append([]byte{}, header[:36]...), root...), header[68:]...)
+	if err := VerifyBitcoinProof(txids[2], 2, 3, proof, mutatedHeader); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := VerifyBitcoinProof(txids[2], 2, 4, proof, mutatedHeader); err == nil {
+		t.Errorf("expected error for mutated proof")
+	}
+
+	if _, err := BuildBitcoinMerkleTree([][]byte{{1}}); err == nil {
+		t.Errorf("expected error for invalid txid")
+	}
+}
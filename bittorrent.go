@@ -0,0 +1,133 @@
+package gomerkletree
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// BlockSize is the size of the leaf blocks of BitTorrent v2 (BEP 52) file trees.
+const BlockSize = 16 << 10
+
+// BitTorrentHashStrategy hashes leaves and internal nodes with plain SHA-256, without domain separation,
+// as specified by BEP 52.
+type BitTorrentHashStrategy struct{}
+
+func (h BitTorrentHashStrategy) HashLeaf(l []byte) []byte {
+	return hashing.HashSHA256(l)
+}
+
+func (h BitTorrentHashStrategy) HashInternal(l, r []byte) []byte {
+	bytes := append(append([]byte{}, l...), r...)
+	return hashing.HashSHA256(bytes)
+}
+
+// TorrentTree is the merkle tree of a file in a BitTorrent v2 torrent (BEP 52). The file is split into blocks of 16 KiB,
+// and the tree is padded with zero hashes to a power of two leaves, so its root matches the `pieces root` of torrent clients.
+type TorrentTree struct {
+	tree   *MerkleTree
+	blocks int
+}
+
+// BuildTorrentTree reads a file and builds its BitTorrent v2 merkle tree.
+// Returns an error for an empty file, which has no pieces root.
+func BuildTorrentTree(r io.Reader) (*TorrentTree, error) {
+	hash := BitTorrentHashStrategy{}
+	hashes, err := readChunkHashes(r, BlockSize, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return nil, errors.New("no data")
+	}
+
+	blocks := len(hashes)
+	for range 1<<bits.Len(uint(blocks-1)) - blocks {
+		hashes = append(hashes, make([]byte, 32))
+	}
+	return &TorrentTree{
+		tree:   buildFromLeafHashes(hashes, newConfig([]Option{WithHashStrategy(hash)})),
+		blocks: blocks,
+	}, nil
+}
+
+// PiecesRoot returns the root of the tree, the `pieces root` of the file.
+func (t *TorrentTree) PiecesRoot() []byte {
+	if t == nil {
+		return nil
+	}
+	return t.tree.Root()
+}
+
+// NumBlocks returns the number of 16 KiB blocks of the file, without padding.
+func (t *TorrentTree) NumBlocks() int {
+	if t == nil {
+		return 0
+	}
+	return t.blocks
+}
+
+// BlockProof generates a proof for the i-th block, to verify with a Chunk leaf.
+func (t *TorrentTree) BlockProof(i int) (*Proof, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= t.blocks {
+		return nil, errors.New("index out of range")
+	}
+	return t.tree.ProofByIndex(i)
+}
+
+// PieceLayer returns the hashes of every piece of the file, i.e. the layer of the tree whose nodes span pieceLength bytes,
+// as stored in the `piece layers` of a torrent. Files that fit in a single piece have the pieces root as their only piece hash.
+// The piece length must be a power of two of at least 16 KiB.
+func (t *TorrentTree) PieceLayer(pieceLength int) ([][]byte, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	k, err := blocksPerPiece(pieceLength)
+	if err != nil {
+		return nil, err
+	}
+	if k >= t.tree.NumLeaves() {
+		return [][]byte{t.PiecesRoot()}, nil
+	}
+
+	var layer [][]byte
+	for start := 0; start < t.blocks; start += k {
+		h, err := t.tree.SubtreeRoot(start, start+k)
+		if err != nil {
+			return nil, err
+		}
+		layer = append(layer, h)
+	}
+	return layer, nil
+}
+
+// PieceProof generates a proof for the hash of the given piece up to the pieces root, to verify with VerifySubtreeProof,
+// like the hashes a peer sends in response to a hash request.
+func (t *TorrentTree) PieceProof(piece, pieceLength int) (*Proof, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	k, err := blocksPerPiece(pieceLength)
+	if err != nil {
+		return nil, err
+	}
+	if piece < 0 || piece*k >= t.blocks {
+		return nil, errors.New("index out of range")
+	}
+	if k >= t.tree.NumLeaves() {
+		return t.tree.SubtreeProof(0, t.tree.NumLeaves())
+	}
+	return t.tree.SubtreeProof(piece*k, (piece+1)*k)
+}
+
+func blocksPerPiece(pieceLength int) (int, error) {
+	if pieceLength < BlockSize || pieceLength&(pieceLength-1) != 0 {
+		return 0, errors.New("invalid piece length")
+	}
+	return pieceLength / BlockSize, nil
+}
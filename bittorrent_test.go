@@ -0,0 +1,128 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func torrentData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestTorrentTree_PiecesRoot(t *testing.T) {
+	sum := func(b ...[]byte) []byte {
+		h := sha256.New()
+		for _, x := range b {
+			h.Write(x)
+		}
+		return h.Sum(nil)
+	}
+
+	// a single short block is its own root
+	small := torrentData(100)
+	tree, err := BuildTorrentTree(bytes.NewReader(small))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := sum(small); !bytes.Equal(tree.PiecesRoot(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.PiecesRoot())
+	}
+
+	// three blocks are padded with a zero hash
+	data := torrentData(2*BlockSize + 10)
+	tree, err = BuildTorrentTree(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b0, b1, b2 := sum(data[:BlockSize]), sum(data[BlockSize:2*BlockSize]), sum(data[2*BlockSize:])
+	expected := sum(sum(b0, b1), sum(b2, make([]byte, 32)))
+	if !bytes.Equal(tree.PiecesRoot(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.PiecesRoot())
+	}
+
+	if tree.NumBlocks() != 3 {
+		t.Errorf("expected 3 blocks, got %d", tree.NumBlocks())
+	}
+
+	proof, err := tree.BlockProof(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(Chunk(data[2*BlockSize:]), proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := tree.BlockProof(3); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestTorrentTree_PieceLayer(t *testing.T) {
+	data := torrentData(5*BlockSize + 1)
+	tree, err := BuildTorrentTree(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pieceLength := 2 * BlockSize
+	layer, err := tree.PieceLayer(pieceLength)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(layer) != 3 {
+		t.Fatalf("expected 3 pieces, got %d", len(layer))
+	}
+
+	// the last piece has one real block, padded with a zero hash
+	h := BitTorrentHashStrategy{}
+	last := h.HashInternal(h.HashLeaf(data[4*BlockSize:5*BlockSize]), h.HashLeaf(data[5*BlockSize:]))
+	if !bytes.Equal(layer[2], last) {
+		t.Errorf("expected %x, got %x", last, layer[2])
+	}
+
+	for i, piece := range layer {
+		proof, err := tree.PieceProof(i, pieceLength)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := VerifySubtreeProof(piece, proof); err != nil {
+			t.Errorf("piece %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if _, err := tree.PieceProof(3, pieceLength); err == nil {
+		t.Errorf("expected error")
+	}
+
+	// the whole file fits in a single piece
+	layer, err = tree.PieceLayer(16 * BlockSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(layer) != 1 || !bytes.Equal(layer[0], tree.PiecesRoot()) {
+		t.Errorf("expected the pieces root as only piece")
+	}
+
+	for _, pieceLength := range []int{0, BlockSize / 2, 3 * BlockSize} {
+		if _, err := tree.PieceLayer(pieceLength); err == nil {
+			t.Errorf("piece length %d: expected error", pieceLength)
+		}
+	}
+}
+
+func TestTorrentTree_Empty(t *testing.T) {
+	if _, err := BuildTorrentTree(bytes.NewReader(nil)); err == nil {
+		t.Errorf("expected error")
+	}
+}
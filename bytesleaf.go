@@ -0,0 +1,37 @@
+package gomerkletree
+
+// BytesLeaf is a leaf of raw bytes, so byte slices can be used as leaves without defining a type.
+type BytesLeaf []byte
+
+func (b BytesLeaf) Bytes() []byte {
+	return b
+}
+
+// BuildMerkleTreeBytes builds a merkle tree with byte slices as leaves, like BuildMerkleTree.
+// Returns an error for empty input and nil byte slices.
+func BuildMerkleTreeBytes(data [][]byte, opts ...Option) (*MerkleTree, error) {
+	return BuildMerkleTree(bytesLeaves(data), opts...)
+}
+
+// ProofBytes generates a proof for a leaf of raw bytes, like Proof.
+func (m *MerkleTree) ProofBytes(b []byte) (*Proof, error) {
+	return m.Proof(BytesLeaf(b))
+}
+
+// VerifyExistsBytes looks up a leaf of raw bytes and verifies the tree, like VerifyExists.
+func (m *MerkleTree) VerifyExistsBytes(b []byte) (*Node, error) {
+	return m.VerifyExists(BytesLeaf(b))
+}
+
+// VerifyProofBytes checks if a proof is valid for a leaf of raw bytes, like VerifyProof.
+func VerifyProofBytes(b []byte, p *Proof) error {
+	return VerifyProof(BytesLeaf(b), p)
+}
+
+func bytesLeaves(data [][]byte) []Leaf {
+	leaves := make([]Leaf, len(data))
+	for i, b := range data {
+		leaves[i] = BytesLeaf(b)
+	}
+	return leaves
+}
@@ -0,0 +1,60 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_BuildBytes(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), {}}
+
+	tree, err := BuildMerkleTreeBytes(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{""}})
+	if !bytes.Equal(tree.Root(), expected.Root()) {
+		t.Errorf("expected root %x, got %x", expected.Root(), tree.Root())
+	}
+
+	for _, b := range data {
+		proof, err := tree.ProofBytes(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyProofBytes(b, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := tree.VerifyExistsBytes(b); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := tree.ProofBytes([]byte("d")); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if _, err := tree.VerifyExistsBytes([]byte("d")); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	proof, _ := tree.ProofBytes([]byte("a"))
+	if err := VerifyProofBytes([]byte("b"), proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	s := NewSyncTree(tree)
+	if _, err := s.ProofBytes([]byte("b")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := s.VerifyExistsBytes([]byte("b")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := BuildMerkleTreeBytes(nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if _, err := BuildMerkleTreeBytes([][]byte{[]byte("a"), nil}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
@@ -0,0 +1,295 @@
+package gomerkletree
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// Major types and simple values of CBOR (RFC 8949).
+const (
+	cborUint  = 0 << 5
+	cborBytes = 2 << 5
+	cborArray = 4 << 5
+	cborMap   = 5 << 5
+	cborFalse = 0xf4
+	cborTrue  = 0xf5
+)
+
+// Keys of the CBOR maps. Small integers, like in COSE, keep the encoding compact.
+const (
+	cborKeyProofRoot       = 1
+	cborKeyProofSiblings   = 2
+	cborKeyProofDirections = 3
+
+	cborKeyHeadSize      = 1
+	cborKeyHeadRoot      = 2
+	cborKeyHeadTimestamp = 3
+	cborKeyHeadSignature = 4
+)
+
+// MarshalCBOR encodes the proof as the CBOR map {1: root, 2: [siblings...], 3: [directions...]},
+// with the hashes as byte strings and every direction a boolean that is true if the sibling is a left child.
+// The encoding is deterministic (RFC 8949, section 4.2.1). The hash strategy is not part of the encoding.
+func (p *Proof) MarshalCBOR() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if len(p.siblings) != len(p.left) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	b := appendCBORHead(nil, cborMap, 3)
+	b = appendCBORHead(b, cborUint, cborKeyProofRoot)
+	b = appendCBORBytes(b, p.root)
+	b = appendCBORHead(b, cborUint, cborKeyProofSiblings)
+	b = appendCBORHead(b, cborArray, uint64(len(p.siblings)))
+	for _, sibling := range p.siblings {
+		b = appendCBORBytes(b, sibling)
+	}
+	b = appendCBORHead(b, cborUint, cborKeyProofDirections)
+	b = appendCBORHead(b, cborArray, uint64(len(p.left)))
+	for _, isLeft := range p.left {
+		if isLeft {
+			b = append(b, cborTrue)
+		} else {
+			b = append(b, cborFalse)
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalCBOR decodes a proof encoded by MarshalCBOR. Only the deterministic encoding is accepted.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *Proof) UnmarshalCBOR(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	d.cborMap(3)
+	d.cborKey(cborKeyProofRoot)
+	root := d.cborBytes()
+	d.cborKey(cborKeyProofSiblings)
+	siblings := make([][]byte, d.cborLength(cborArray))
+	for i := range siblings {
+		siblings[i] = d.cborBytes()
+	}
+	d.cborKey(cborKeyProofDirections)
+	left := make([]bool, d.cborLength(cborArray))
+	for i := range left {
+		left[i] = d.cborBool()
+	}
+	if err := d.cborEnd(); err != nil {
+		return err
+	}
+	if len(siblings) != len(left) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	*p = Proof{
+		root:         root,
+		siblings:     siblings,
+		left:         left,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// MarshalCBOR encodes the tree head as the CBOR map {1: size, 2: root, 3: timestamp},
+// with the timestamp in milliseconds since the Unix epoch, like in Bytes.
+// The encoding is deterministic (RFC 8949, section 4.2.1).
+func (h TreeHead) MarshalCBOR() ([]byte, error) {
+	b := appendCBORHead(nil, cborMap, 3)
+	return h.appendCBOR(b)
+}
+
+// UnmarshalCBOR decodes a tree head encoded by MarshalCBOR. Only the deterministic encoding is accepted.
+func (h *TreeHead) UnmarshalCBOR(data []byte) error {
+	if h == nil {
+		return errors.New("nil tree head")
+	}
+	d := decoder{b: data}
+	d.cborMap(3)
+	head := d.cborTreeHead()
+	if err := d.cborEnd(); err != nil {
+		return err
+	}
+	*h = head
+	return nil
+}
+
+// MarshalCBOR encodes the signed tree head like TreeHead.MarshalCBOR, with the signature as byte string at key 4:
+// {1: size, 2: root, 3: timestamp, 4: signature}.
+func (s *SignedTreeHead) MarshalCBOR() ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("nil tree head")
+	}
+	b := appendCBORHead(nil, cborMap, 4)
+	b, err := s.TreeHead.appendCBOR(b)
+	if err != nil {
+		return nil, err
+	}
+	b = appendCBORHead(b, cborUint, cborKeyHeadSignature)
+	return appendCBORBytes(b, s.Signature), nil
+}
+
+// UnmarshalCBOR decodes a signed tree head encoded by MarshalCBOR. Only the deterministic encoding is accepted.
+// The signature is not verified; use VerifyTreeHead.
+func (s *SignedTreeHead) UnmarshalCBOR(data []byte) error {
+	if s == nil {
+		return errors.New("nil tree head")
+	}
+	d := decoder{b: data}
+	d.cborMap(4)
+	head := d.cborTreeHead()
+	d.cborKey(cborKeyHeadSignature)
+	signature := d.cborBytes()
+	if err := d.cborEnd(); err != nil {
+		return err
+	}
+	*s = SignedTreeHead{TreeHead: head, Signature: signature}
+	return nil
+}
+
+// appendCBOR appends the entries of the tree head to a CBOR map.
+func (h TreeHead) appendCBOR(b []byte) ([]byte, error) {
+	if h.Size < 0 {
+		return nil, errors.New("invalid tree size")
+	}
+	ms := h.Timestamp.UnixMilli()
+	if ms < 0 {
+		return nil, errors.New("invalid timestamp")
+	}
+	b = appendCBORHead(b, cborUint, cborKeyHeadSize)
+	b = appendCBORHead(b, cborUint, uint64(h.Size))
+	b = appendCBORHead(b, cborUint, cborKeyHeadRoot)
+	b = appendCBORBytes(b, h.Root)
+	b = appendCBORHead(b, cborUint, cborKeyHeadTimestamp)
+	return appendCBORHead(b, cborUint, uint64(ms)), nil
+}
+
+// appendCBORHead appends the head of a data item: the major type and the argument n, in its shortest form.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+func appendCBORBytes(b, x []byte) []byte {
+	b = appendCBORHead(b, cborBytes, uint64(len(x)))
+	return append(b, x...)
+}
+
+// cborHead reads the head of a data item of the given major type, and returns its argument.
+// Arguments that are not in their shortest form, and indefinite lengths, are rejected.
+func (d *decoder) cborHead(major byte) uint64 {
+	initial := d.byte()
+	if d.err != nil {
+		return 0
+	}
+	if initial&0xe0 != major {
+		d.err = errors.New("unexpected CBOR type")
+		return 0
+	}
+	var n, least uint64
+	switch info := initial & 0x1f; {
+	case info < 24:
+		return uint64(info)
+	case info == 24:
+		n, least = uint64(d.byte()), 24
+	case info == 25:
+		n, least = uint64(d.uint16()), math.MaxUint8+1
+	case info == 26:
+		if x := d.next(4); x != nil {
+			n = uint64(binary.BigEndian.Uint32(x))
+		}
+		least = math.MaxUint16 + 1
+	case info == 27:
+		n, least = d.uint64(), math.MaxUint32+1
+	default:
+		d.err = errors.New("unsupported CBOR length")
+		return 0
+	}
+	if d.err == nil && n < least {
+		d.err = errors.New("non-deterministic CBOR encoding")
+		return 0
+	}
+	return n
+}
+
+// cborLength reads the head of a byte string or array, whose length has to fit in the remaining data.
+func (d *decoder) cborLength(major byte) int {
+	n := d.cborHead(major)
+	if d.err == nil && n > uint64(len(d.b)) {
+		d.err = errors.New("unexpected end of data")
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) cborBytes() []byte {
+	return append([]byte(nil), d.next(d.cborLength(cborBytes))...)
+}
+
+func (d *decoder) cborBool() bool {
+	switch d.byte() {
+	case cborTrue:
+		return true
+	case cborFalse:
+	default:
+		if d.err == nil {
+			d.err = errors.New("unexpected CBOR type")
+		}
+	}
+	return false
+}
+
+// cborMap reads the head of a map with n entries.
+func (d *decoder) cborMap(n uint64) {
+	if x := d.cborHead(cborMap); d.err == nil && x != n {
+		d.err = errors.New("unexpected CBOR map size")
+	}
+}
+
+// cborKey reads the key of the next map entry, which has to be key. Since the keys are read in ascending order,
+// maps with unknown, duplicate or unsorted keys are rejected.
+func (d *decoder) cborKey(key uint64) {
+	if x := d.cborHead(cborUint); d.err == nil && x != key {
+		d.err = errors.New("unexpected CBOR map key")
+	}
+}
+
+func (d *decoder) cborTreeHead() TreeHead {
+	d.cborKey(cborKeyHeadSize)
+	size := d.cborHead(cborUint)
+	d.cborKey(cborKeyHeadRoot)
+	root := d.cborBytes()
+	d.cborKey(cborKeyHeadTimestamp)
+	ms := d.cborHead(cborUint)
+	if d.err == nil && (size > math.MaxInt || ms > math.MaxInt64) {
+		d.err = errors.New("CBOR integer out of range")
+	}
+	return TreeHead{Size: int(size), Root: root, Timestamp: time.UnixMilli(int64(ms)).UTC()}
+}
+
+// cborEnd returns the first error of the decoder, or an error if there is data after the decoded item.
+func (d *decoder) cborEnd() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestProof_CBOR(t *testing.T) {
+	p := NewProof([]byte{0xaa}, [][]byte{{0x01}, {0x02, 0x03}}, []bool{true, false}, nil)
+	b, err := p.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// {1: h'aa', 2: [h'01', h'0203'], 3: [true, false]}
+	if expected := "a30141aa028241014202030382f5f4"; hex.EncodeToString(b) != expected {
+		t.Errorf("expected %s, got %x", expected, b)
+	}
+
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}, &TestLeaf{"e"}}
+	tree := mustBuildMerkleTree(t, data)
+	for _, x := range data {
+		p, err := tree.Proof(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := p.MarshalCBOR()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var decoded Proof
+		if err := decoded.UnmarshalCBOR(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := decoded.Verify(x); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestProof_CBOR_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":             "",
+		"trailing data":     "a30141aa028241014202030382f5f400",
+		"truncated":         "a30141aa028241014202030382f5",
+		"non-shortest key":  "a3180141aa028241014202030382f5f4",
+		"unsorted keys":     "a30282410142020301" + "41aa" + "0382f5f4",
+		"unknown key":       "a30141aa0282410142020304" + "82f5f4",
+		"lengths mismatch":  "a30141aa028241014202030381f5",
+		"indefinite length": "a30141aa029f410142020303ff82f5f4",
+		"not a boolean":     "a30141aa0282410142020303820100",
+		"huge array":        "a30141aa029bffffffffffffffff",
+	}
+	for name, h := range tests {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		var p Proof
+		if err := p.UnmarshalCBOR(b); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestTreeHead_CBOR(t *testing.T) {
+	h := TreeHead{Size: 5, Root: []byte{0xaa}, Timestamp: time.UnixMilli(1000).UTC()}
+	b, err := h.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// {1: 5, 2: h'aa', 3: 1000}
+	if expected := "a301050241aa031903e8"; hex.EncodeToString(b) != expected {
+		t.Errorf("expected %s, got %x", expected, b)
+	}
+	var decoded TreeHead
+	if err := decoded.UnmarshalCBOR(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Size != h.Size || !bytes.Equal(decoded.Root, h.Root) || !decoded.Timestamp.Equal(h.Timestamp) {
+		t.Errorf("expected %+v, got %+v", h, decoded)
+	}
+
+	if _, err := (TreeHead{Size: -1}).MarshalCBOR(); err == nil {
+		t.Errorf("expected error for negative size")
+	}
+	if _, err := (TreeHead{Timestamp: time.UnixMilli(-1)}).MarshalCBOR(); err == nil {
+		t.Errorf("expected error for timestamp before the epoch")
+	}
+	// a timestamp that doesn't fit in int64
+	if err := decoded.UnmarshalCBOR([]byte{0xa3, 0x01, 0x05, 0x02, 0x41, 0xaa, 0x03, 0x1b, 0xff, 0, 0, 0, 0, 0, 0, 0}); err == nil {
+		t.Errorf("expected error for timestamp out of range")
+	}
+}
+
+func TestSignedTreeHead_CBOR(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}})
+	sth, err := SignTreeHead(TreeHead{Size: 3, Root: tree.Root(), Timestamp: time.Now()}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := sth.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded SignedTreeHead
+	if err := decoded.UnmarshalCBOR(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyTreeHead(&decoded, pub); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// a signed tree head is not decoded as a tree head, and the other way around
+	var head TreeHead
+	if err := head.UnmarshalCBOR(b); err == nil {
+		t.Errorf("expected error for signed tree head")
+	}
+	b, err = sth.TreeHead.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := decoded.UnmarshalCBOR(b); err == nil {
+		t.Errorf("expected error for tree head without signature")
+	}
+}
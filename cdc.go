@@ -0,0 +1,150 @@
+package gomerkletree
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// Default chunk sizes of content-defined chunking, which are those of the FastCDC paper.
+const (
+	DefaultMinChunkSize = 2 << 10
+	DefaultAvgChunkSize = 8 << 10
+	DefaultMaxChunkSize = 64 << 10
+)
+
+// gearTable maps bytes to the random values of the gear hash. The values are the first 8 bytes of the SHA-256 of
+// the byte, so chunk boundaries are stable across versions, but differ from other FastCDC implementations.
+var gearTable = func() (t [256]uint64) {
+	for i := range t {
+		sum := sha256.Sum256([]byte{byte(i)})
+		t[i] = binary.BigEndian.Uint64(sum[:8])
+	}
+	return t
+}()
+
+// Chunker splits a stream into content-defined chunks with FastCDC. A chunk ends where the gear hash of the bytes
+// before it matches a mask, so boundaries depend only on nearby content: inserting or removing bytes only changes
+// the chunks around the edit, and the chunks after it are the same as before, just shifted.
+//
+// Normalized chunking uses a stricter mask before the average size and a looser one after it, which keeps chunk
+// sizes close to the average. Chunks are at least the minimum size, except for the last one, and at most the maximum size.
+type Chunker struct {
+	r             io.Reader
+	min, avg, max int
+	strict, loose uint64 // masks over the high bits of the hash, which depend on the last 64 bytes
+	buf           []byte
+	start, end    int
+	eof           bool
+}
+
+// NewChunker returns a chunker reading from r, with chunk sizes 0 < minSize <= avgSize <= maxSize.
+func NewChunker(r io.Reader, minSize, avgSize, maxSize int) (*Chunker, error) {
+	if minSize <= 0 || minSize > avgSize || avgSize > maxSize {
+		return nil, errors.New("invalid chunk sizes")
+	}
+	n := bits.Len(uint(avgSize)) - 1 // log2 of the average size
+	return &Chunker{
+		r:      r,
+		min:    minSize,
+		avg:    avgSize,
+		max:    maxSize,
+		strict: highBits(n + 1),
+		loose:  highBits(max(n-1, 0)),
+		buf:    make([]byte, maxSize),
+	}, nil
+}
+
+func highBits(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
+
+// Next returns the next chunk, or io.EOF at the end of the stream.
+// The chunk is only valid until the next call, like the result of bufio.Scanner.Bytes.
+func (c *Chunker) Next() ([]byte, error) {
+	if c.end-c.start < c.max && !c.eof {
+		if err := c.fill(); err != nil {
+			return nil, err
+		}
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+	n := c.cut(c.buf[c.start:c.end])
+	chunk := c.buf[c.start : c.start+n]
+	c.start += n
+	return chunk, nil
+}
+
+// fill moves the unread bytes to the front of the buffer, and reads until it is full or the stream ends.
+func (c *Chunker) fill() error {
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	for c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut returns the length of the chunk at the start of data. The bytes before the minimum size are skipped,
+// since no chunk can end there.
+func (c *Chunker) cut(data []byte) int {
+	n := min(len(data), c.max)
+	if n <= c.min {
+		return n
+	}
+	normal := min(n, c.avg)
+	var h uint64
+	i := c.min
+	for ; i < normal; i++ {
+		h = h<<1 + gearTable[data[i]]
+		if h&c.strict == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		h = h<<1 + gearTable[data[i]]
+		if h&c.loose == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// BuildFromReaderCDC splits a stream into content-defined chunks (see Chunker) and builds a merkle tree with the
+// chunks as leaves. Unlike the fixed-size chunks of BuildFromReader, an edit only changes the leaves around it, so
+// most of the tree is shared between versions of a file. Chunks are hashed while reading, so only their hashes are
+// kept in memory. Returns an error for an empty stream.
+func BuildFromReaderCDC(r io.Reader, minSize, avgSize, maxSize int, opts ...Option) (*MerkleTree, error) {
+	c, err := NewChunker(r, minSize, avgSize, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	cfg := newConfig(opts)
+
+	var hashes [][]byte
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, cfg.hashStrategy.HashLeaf(chunk))
+	}
+	if len(hashes) == 0 {
+		return nil, errors.New("no data")
+	}
+	return buildFromLeafHashes(hashes, cfg), nil
+}
@@ -0,0 +1,122 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func randomBytes(n int, seed uint64) []byte {
+	b := make([]byte, n)
+	r := rand.New(rand.NewPCG(seed, seed))
+	for i := range b {
+		b[i] = byte(r.Uint32())
+	}
+	return b
+}
+
+func cdcChunks(t *testing.T, r io.Reader, minSize, avgSize, maxSize int) [][]byte {
+	t.Helper()
+	c, err := NewChunker(r, minSize, avgSize, maxSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var chunks [][]byte
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		chunks = append(chunks, bytes.Clone(chunk))
+	}
+}
+
+func TestChunker(t *testing.T) {
+	input := randomBytes(1<<20, 1)
+	chunks := cdcChunks(t, iotest.HalfReader(bytes.NewReader(input)), 256, 1024, 4096)
+
+	if !bytes.Equal(bytes.Join(chunks, nil), input) {
+		t.Fatalf("expected chunks to make up the input")
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 4096 || len(chunk) < 256 && i != len(chunks)-1 {
+			t.Errorf("chunk %d: unexpected size %d", i, len(chunk))
+		}
+	}
+	// normalized chunking keeps the average close to the given one
+	if avg := len(input) / len(chunks); avg < 768 || avg > 1536 {
+		t.Errorf("expected an average chunk size around 1024, got %d", avg)
+	}
+
+	if again := cdcChunks(t, bytes.NewReader(input), 256, 1024, 4096); len(again) != len(chunks) {
+		t.Errorf("expected the same chunks for the same input")
+	}
+}
+
+func TestChunker_ShiftResistance(t *testing.T) {
+	input := randomBytes(1<<20, 2)
+	edited := append(append(bytes.Clone(input[:1<<19]), 'x'), input[1<<19:]...)
+
+	before := make(map[string]bool)
+	for _, chunk := range cdcChunks(t, bytes.NewReader(input), 256, 1024, 4096) {
+		before[string(chunk)] = true
+	}
+	after := cdcChunks(t, bytes.NewReader(edited), 256, 1024, 4096)
+	changed := 0
+	for _, chunk := range after {
+		if !before[string(chunk)] {
+			changed++
+		}
+	}
+	if changed == 0 || changed > 3 {
+		t.Errorf("expected an insertion to change 1 to 3 chunks, got %d of %d", changed, len(after))
+	}
+}
+
+func TestTree_BuildFromReaderCDC(t *testing.T) {
+	input := randomBytes(100_000, 3)
+	tree, err := BuildFromReaderCDC(bytes.NewReader(input), DefaultMinChunkSize, DefaultAvgChunkSize, DefaultMaxChunkSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data []Leaf
+	for _, chunk := range cdcChunks(t, bytes.NewReader(input), DefaultMinChunkSize, DefaultAvgChunkSize, DefaultMaxChunkSize) {
+		data = append(data, Chunk(chunk))
+	}
+	if expected := mustBuildMerkleTree(t, data).Root(); !bytes.Equal(tree.Root(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.Root())
+	}
+
+	proof, err := tree.ProofByIndex(len(data) - 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(data[len(data)-1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTree_BuildFromReaderCDCErrors(t *testing.T) {
+	for _, sizes := range [][3]int{{0, 8, 16}, {8, 4, 16}, {4, 16, 8}} {
+		if _, err := BuildFromReaderCDC(strings.NewReader("abc"), sizes[0], sizes[1], sizes[2]); err == nil {
+			t.Errorf("expected error for sizes %v", sizes)
+		}
+	}
+
+	if _, err := BuildFromReaderCDC(strings.NewReader(""), 4, 8, 16); err == nil {
+		t.Errorf("expected error")
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := BuildFromReaderCDC(iotest.ErrReader(readErr), 4, 8, 16); !errors.Is(err, readErr) {
+		t.Errorf("expected %v, got %v", readErr, err)
+	}
+}
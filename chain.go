@@ -0,0 +1,75 @@
+package gomerkletree
+
+import (
+	"errors"
+)
+
+// RootLeaf is a leaf holding the root of another tree, to commit to the roots of many small trees in a top-level tree.
+type RootLeaf []byte
+
+func (r RootLeaf) Bytes() []byte {
+	return r
+}
+
+// ChainedProof proves a leaf through a chain of nested trees. The first proof is for the leaf in the innermost tree,
+// every next proof is for the root of the previous tree, as a RootLeaf of the next tree.
+type ChainedProof struct {
+	proofs []*Proof
+}
+
+// ChainProofs composes proofs for nested trees into a single proof, ordered from the innermost to the outermost tree.
+func ChainProofs(proofs ...*Proof) (*ChainedProof, error) {
+	if len(proofs) == 0 {
+		return nil, errors.New("no proofs")
+	}
+	for _, p := range proofs {
+		if p == nil || p.hashStrategy == nil {
+			return nil, errors.New("no proof/hash strategy")
+		}
+	}
+	return &ChainedProof{proofs: append([]*Proof(nil), proofs...)}, nil
+}
+
+// Root returns a copy of the root of the outermost tree.
+// Compare it with a trusted root after verifying the proof.
+func (p *ChainedProof) Root() []byte {
+	return p.proofs[len(p.proofs)-1].Root()
+}
+
+// Proofs returns the proofs of the chain, from the innermost to the outermost tree.
+func (p *ChainedProof) Proofs() []*Proof {
+	return append([]*Proof(nil), p.proofs...)
+}
+
+// VerifyChainedProof checks if a chained proof is valid for a given leaf of the innermost tree,
+// i.e. that the leaf is included in the outermost tree through every tree of the chain.
+func VerifyChainedProof(x Leaf, p *ChainedProof) error {
+	if p == nil || len(p.proofs) == 0 {
+		return errors.New("no proof/hash strategy")
+	}
+	if err := VerifyProof(x, p.proofs[0]); err != nil {
+		return err
+	}
+	for i, next := range p.proofs[1:] {
+		if err := VerifyProof(RootLeaf(p.proofs[i].root), next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyChainedProofAgainstRoot checks if a chained proof is valid for a given leaf and a trusted outermost root.
+// The root stored in the outermost proof is ignored.
+func VerifyChainedProofAgainstRoot(x Leaf, p *ChainedProof, root []byte) error {
+	if p == nil || len(p.proofs) == 0 {
+		return errors.New("no proof/hash strategy")
+	}
+	last := len(p.proofs) - 1
+	if last == 0 {
+		return VerifyProofAgainstRoot(x, p.proofs[0], root)
+	}
+	if err := VerifyChainedProof(x, &ChainedProof{proofs: p.proofs[:last]}); err != nil {
+		return err
+	}
+	return VerifyProofAgainstRoot(RootLeaf(p.proofs[last-1].root), p.proofs[last], root)
+}
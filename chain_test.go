@@ -0,0 +1,91 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestProof_Chain(t *testing.T) {
+	var shards []*MerkleTree
+	var roots []Leaf
+	for s := range 3 {
+		var data []Leaf
+		for i := range 5 {
+			data = append(data, &TestLeaf{fmt.Sprintf("%d-%d", s, i)})
+		}
+		shard := mustBuildMerkleTree(t, data)
+		shards = append(shards, shard)
+		roots = append(roots, RootLeaf(shard.Root()))
+	}
+	top := mustBuildMerkleTree(t, roots)
+
+	leaf := &TestLeaf{"1-3"}
+	inner, err := shards[1].Proof(leaf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outer, err := top.ProofByIndex(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chain, err := ChainProofs(inner, outer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyChainedProof(leaf, chain); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := VerifyChainedProofAgainstRoot(leaf, chain, top.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the mismatch reports the root computed from the leaf, not the root claimed by the proof
+	err = VerifyChainedProofAgainstRoot(leaf, chain, shards[1].Root())
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRootMismatch) {
+		t.Fatalf("expected root mismatch, got %v", err)
+	}
+	if !bytes.Equal(verr.Computed, top.Root()) || !bytes.Equal(verr.Expected, shards[1].Root()) {
+		t.Errorf("expected computed root %x, got %x", top.Root(), verr.Computed)
+	}
+
+	// leaf of another shard
+	if err := VerifyChainedProof(&TestLeaf{"0-3"}, chain); err == nil {
+		t.Errorf("expected error")
+	}
+
+	// proof of the root of another shard
+	wrong, err := top.ProofByIndex(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chain, err = ChainProofs(inner, wrong)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyChainedProof(leaf, chain); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestProof_ChainErrors(t *testing.T) {
+	if _, err := ChainProofs(); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := ChainProofs(nil); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if err := VerifyChainedProof(&TestLeaf{"a"}, nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
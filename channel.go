@@ -0,0 +1,69 @@
+package gomerkletree
+
+import (
+	"context"
+	"errors"
+)
+
+// BuildFromChannel builds a merkle tree from the leaves received on a channel, finishing when the channel is closed.
+// Leaves are hashed as they arrive, so only their hashes are kept in memory.
+// Stops with the context's error when it is cancelled, and returns an error if no leaves were received.
+func BuildFromChannel(ctx context.Context, ch <-chan Leaf, opts ...Option) (*MerkleTree, error) {
+	cfg := newConfig(opts)
+
+	var hashes [][]byte
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case x, ok := <-ch:
+			if !ok {
+				if len(hashes) == 0 {
+					return nil, errors.New("no leaves")
+				}
+				p := newProgress(cfg, len(hashes))
+				p.add(len(hashes))
+				return buildFromLeafHashesCtx(ctx, hashes, cfg, p)
+			}
+			b, err := leafBytes(x)
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, cfg.hashStrategy.HashLeaf(b))
+		}
+	}
+}
+
+// RootFromChannel computes the root of the merkle tree over the leaves received on a channel,
+// finishing when the channel is closed. Like Hasher, it only keeps the frontier of the tree, using O(log n) memory.
+// The root is identical to the one BuildFromChannel would build. Duplication and sorted leaves are not supported,
+// since they need all leaves to be known up front.
+func RootFromChannel(ctx context.Context, ch <-chan Leaf, opts ...Option) ([]byte, error) {
+	cfg := newConfig(opts)
+	if cfg.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if cfg.sorted {
+		return nil, errors.New("not supported with sorted leaves")
+	}
+
+	h := NewHasherWithHashStrategy(cfg.hashStrategy)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case x, ok := <-ch:
+			if !ok {
+				if h.Count() == 0 {
+					return nil, errors.New("no leaves")
+				}
+				return h.Root(), nil
+			}
+			b, err := leafBytes(x)
+			if err != nil {
+				return nil, err
+			}
+			h.WriteLeaf(b)
+		}
+	}
+}
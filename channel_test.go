@@ -0,0 +1,67 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func sendLeaves(data []Leaf) <-chan Leaf {
+	ch := make(chan Leaf)
+	go func() {
+		defer close(ch)
+		for _, x := range data {
+			ch <- x
+		}
+	}()
+	return ch
+}
+
+func TestTree_BuildFromChannel(t *testing.T) {
+	var data []Leaf
+	for i := range 100 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	for _, opts := range [][]Option{nil, {WithDuplication()}, {WithSortedLeaves()}} {
+		tree, err := BuildFromChannel(context.Background(), sendLeaves(data), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := mustBuildMerkleTree(t, data, opts...); !bytes.Equal(tree.Root(), expected.Root()) || tree.Len() != expected.Len() {
+			t.Errorf("expected the same tree as BuildMerkleTree")
+		}
+	}
+
+	root, err := RootFromChannel(context.Background(), sendLeaves(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := mustBuildMerkleTree(t, data).Root(); !bytes.Equal(root, expected) {
+		t.Errorf("expected %x, got %x", expected, root)
+	}
+
+	if _, err := RootFromChannel(context.Background(), sendLeaves(data), WithDuplication()); err == nil {
+		t.Errorf("expected error for duplication")
+	}
+
+	if _, err := BuildFromChannel(context.Background(), sendLeaves(nil)); err == nil {
+		t.Errorf("expected error for no leaves")
+	}
+	if _, err := RootFromChannel(context.Background(), sendLeaves(nil)); err == nil {
+		t.Errorf("expected error for no leaves")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := make(chan Leaf) // never closed
+	if _, err := BuildFromChannel(ctx, ch); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if _, err := RootFromChannel(ctx, ch); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,292 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Checkpoint is the state of a log in the checkpoint format of transparency logs (C2SP tlog-checkpoint):
+//
+//	origin
+//	size
+//	root (base64)
+//
+// where the origin names the log. Checkpoints are signed by the log, and cosigned by witnesses that checked
+// the log is consistent, in the note format of the Go checksum database (golang.org/x/mod/sumdb/note).
+type Checkpoint struct {
+	Origin string
+	Size   int
+	Root   []byte
+}
+
+// Checkpoint returns the checkpoint of the tree head for the log with the given origin.
+func (h TreeHead) Checkpoint(origin string) Checkpoint {
+	return Checkpoint{
+		Origin: origin,
+		Size:   h.Size,
+		Root:   bytes.Clone(h.Root),
+	}
+}
+
+// Bytes returns the text of the checkpoint, which is the signed text of the note.
+func (c Checkpoint) Bytes() []byte {
+	b := make([]byte, 0, len(c.Origin)+base64.StdEncoding.EncodedLen(len(c.Root))+24)
+	b = append(b, c.Origin...)
+	b = append(b, '\n')
+	b = strconv.AppendInt(b, int64(c.Size), 10)
+	b = append(b, '\n')
+	b = base64.StdEncoding.AppendEncode(b, c.Root)
+	return append(b, '\n')
+}
+
+// ParseCheckpoint parses the text of a checkpoint. Extension lines after the root are not supported.
+func ParseCheckpoint(text []byte) (Checkpoint, error) {
+	lines := strings.SplitAfter(string(text), "\n")
+	if len(lines) != 4 || lines[3] != "" {
+		return Checkpoint{}, errors.New("malformed checkpoint")
+	}
+	for i := range 3 {
+		lines[i] = strings.TrimSuffix(lines[i], "\n")
+	}
+	if lines[0] == "" {
+		return Checkpoint{}, errors.New("malformed checkpoint")
+	}
+	size, err := strconv.ParseUint(lines[1], 10, 63)
+	if err != nil || strconv.FormatUint(size, 10) != lines[1] {
+		return Checkpoint{}, errors.New("invalid tree size")
+	}
+	root, err := base64.StdEncoding.Strict().DecodeString(lines[2])
+	if err != nil {
+		return Checkpoint{}, errors.New("invalid root")
+	}
+	return Checkpoint{
+		Origin: lines[0],
+		Size:   int(size),
+		Root:   root,
+	}, nil
+}
+
+// NoteSignature is a signature line of a note: the name of the signer, the hash of its key, and the signature.
+type NoteSignature struct {
+	Name      string
+	KeyHash   uint32
+	Signature []byte
+}
+
+// SignedCheckpoint is a checkpoint signed by its log and cosigned by witnesses.
+type SignedCheckpoint struct {
+	Checkpoint
+	Signatures []NoteSignature
+}
+
+// noteAlgEd25519 identifies Ed25519 keys in the key hashes of notes.
+const noteAlgEd25519 = 1
+
+// NoteKeyHash returns the hash that identifies an Ed25519 key with a name in notes:
+// the first 4 bytes of SHA-256(name | "\n" | 0x01 | key), in big endian.
+func NoteKeyHash(name string, key ed25519.PublicKey) uint32 {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{'\n', noteAlgEd25519})
+	h.Write(key)
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
+
+// validNoteName reports whether a name can be used in notes: it can't be empty, or contain spaces or pluses.
+func validNoteName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '+'
+	}) < 0
+}
+
+// SignCheckpoint signs a checkpoint with the private key of the log, under the name of the log.
+func SignCheckpoint(c Checkpoint, name string, key ed25519.PrivateKey) (*SignedCheckpoint, error) {
+	if c.Origin == "" || strings.Contains(c.Origin, "\n") {
+		return nil, errors.New("invalid origin")
+	}
+	if c.Size < 0 {
+		return nil, errors.New("invalid tree size")
+	}
+	s := &SignedCheckpoint{
+		Checkpoint: c,
+	}
+	s.Root = bytes.Clone(c.Root)
+	if err := s.Cosign(name, key); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Cosign adds the signature of a witness, replacing an earlier signature of the same key.
+// A witness should only cosign a checkpoint after verifying it is consistent with the checkpoints it cosigned before.
+func (s *SignedCheckpoint) Cosign(name string, key ed25519.PrivateKey) error {
+	if s == nil {
+		return errors.New("nil checkpoint")
+	}
+	if !validNoteName(name) {
+		return errors.New("invalid name")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return errors.New("invalid private key")
+	}
+
+	sig := NoteSignature{
+		Name:      name,
+		KeyHash:   NoteKeyHash(name, key.Public().(ed25519.PublicKey)),
+		Signature: ed25519.Sign(key, s.Bytes()),
+	}
+	for i, other := range s.Signatures {
+		if other.Name == sig.Name && other.KeyHash == sig.KeyHash {
+			s.Signatures[i] = sig
+			return nil
+		}
+	}
+	s.Signatures = append(s.Signatures, sig)
+	return nil
+}
+
+// Merge adds the signatures of another copy of the same checkpoint, e.g. as cosigned by a witness,
+// skipping signatures of keys that already signed.
+func (s *SignedCheckpoint) Merge(other *SignedCheckpoint) error {
+	if s == nil || other == nil {
+		return errors.New("nil checkpoint")
+	}
+	if !bytes.Equal(s.Bytes(), other.Bytes()) {
+		return errors.New("different checkpoints")
+	}
+	for _, sig := range other.Signatures {
+		if !slices.ContainsFunc(s.Signatures, func(x NoteSignature) bool {
+			return x.Name == sig.Name && x.KeyHash == sig.KeyHash
+		}) {
+			s.Signatures = append(s.Signatures, sig)
+		}
+	}
+	return nil
+}
+
+// MarshalText encodes the signed checkpoint as a note: the text of the checkpoint, an empty line,
+// and a line "— name base64(key hash | signature)" for every signature.
+func (s *SignedCheckpoint) MarshalText() ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("nil checkpoint")
+	}
+	b := append(s.Bytes(), '\n')
+	for _, sig := range s.Signatures {
+		if !validNoteName(sig.Name) {
+			return nil, errors.New("invalid name")
+		}
+		b = append(b, "— "...)
+		b = append(b, sig.Name...)
+		b = append(b, ' ')
+		b = base64.StdEncoding.AppendEncode(b, append(binary.BigEndian.AppendUint32(nil, sig.KeyHash), sig.Signature...))
+		b = append(b, '\n')
+	}
+	return b, nil
+}
+
+// UnmarshalText decodes a note encoded by MarshalText. The signatures are not verified.
+func (s *SignedCheckpoint) UnmarshalText(text []byte) error {
+	if s == nil {
+		return errors.New("nil checkpoint")
+	}
+	i := bytes.Index(text, []byte("\n\n"))
+	if i < 0 {
+		return errors.New("malformed note")
+	}
+	c, err := ParseCheckpoint(text[:i+1])
+	if err != nil {
+		return err
+	}
+
+	sigs := text[i+2:]
+	var signatures []NoteSignature
+	if len(sigs) == 0 || sigs[len(sigs)-1] != '\n' {
+		return errors.New("malformed note")
+	}
+	for _, line := range strings.Split(string(sigs[:len(sigs)-1]), "\n") {
+		rest, ok := strings.CutPrefix(line, "— ")
+		if !ok {
+			return errors.New("malformed note")
+		}
+		name, encoded, ok := strings.Cut(rest, " ")
+		if !ok || !validNoteName(name) {
+			return errors.New("malformed note")
+		}
+		sig, err := base64.StdEncoding.Strict().DecodeString(encoded)
+		if err != nil || len(sig) < 4 {
+			return errors.New("malformed note")
+		}
+		signatures = append(signatures, NoteSignature{
+			Name:      name,
+			KeyHash:   binary.BigEndian.Uint32(sig),
+			Signature: sig[4:],
+		})
+	}
+
+	*s = SignedCheckpoint{
+		Checkpoint: c,
+		Signatures: signatures,
+	}
+	return nil
+}
+
+// Witness is a named Ed25519 key that signs or cosigns checkpoints.
+type Witness struct {
+	Name string
+	Key  ed25519.PublicKey
+}
+
+// verifies reports whether the checkpoint has a valid signature by the witness.
+func (w Witness) verifies(s *SignedCheckpoint) bool {
+	if len(w.Key) != ed25519.PublicKeySize {
+		return false
+	}
+	hash := NoteKeyHash(w.Name, w.Key)
+	msg := s.Bytes()
+	for _, sig := range s.Signatures {
+		if sig.Name == w.Name && sig.KeyHash == hash && ed25519.Verify(w.Key, msg, sig.Signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// WitnessPolicy requires a checkpoint to be signed by its log, and cosigned by at least Threshold of the Witnesses.
+type WitnessPolicy struct {
+	Log       Witness
+	Witnesses []Witness
+	Threshold int
+}
+
+// Verify checks if a signed checkpoint satisfies the policy. Signatures by unknown keys are ignored.
+// Cosignatures count per key: a key listed under several names counts once, and the key of the log never counts.
+func (p WitnessPolicy) Verify(s *SignedCheckpoint) error {
+	if s == nil {
+		return errors.New("nil checkpoint")
+	}
+	if p.Threshold < 0 || p.Threshold > len(p.Witnesses) {
+		return errors.New("invalid threshold")
+	}
+	if !p.Log.verifies(s) {
+		return errors.New("no valid log signature")
+	}
+
+	cosigned := make(map[string]bool, len(p.Witnesses))
+	for _, w := range p.Witnesses {
+		if !bytes.Equal(w.Key, p.Log.Key) && !cosigned[string(w.Key)] && w.verifies(s) {
+			cosigned[string(w.Key)] = true
+		}
+	}
+	if len(cosigned) < p.Threshold {
+		return errors.New("not enough cosignatures")
+	}
+	return nil
+}
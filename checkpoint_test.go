@@ -0,0 +1,168 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestCheckpoint_Parse(t *testing.T) {
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}})
+	c := TreeHead{Size: 3, Root: tree.Root()}.Checkpoint("example.com/log")
+
+	text := c.Bytes()
+	expected := "example.com/log\n3\n" + base64.StdEncoding.EncodeToString(tree.Root()) + "\n"
+	if string(text) != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+
+	parsed, err := ParseCheckpoint(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.Origin != c.Origin || parsed.Size != c.Size || !bytes.Equal(parsed.Root, c.Root) {
+		t.Errorf("expected %+v, got %+v", c, parsed)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"example.com/log\n3\n",
+		"\n3\nAAAA\n",
+		"example.com/log\n03\nAAAA\n",
+		"example.com/log\n-3\nAAAA\n",
+		"example.com/log\n3\n!!!!\n",
+		"example.com/log\n3\nAAAA",
+		"example.com/log\n3\nAAAA\nextension\n",
+	} {
+		if _, err := ParseCheckpoint([]byte(invalid)); err == nil {
+			t.Errorf("%q: expected error, got nil", invalid)
+		}
+	}
+}
+
+func TestNoteKeyHash(t *testing.T) {
+	// verifier key of the tests of golang.org/x/mod/sumdb/note: PeterNeumann+c74f20a3+ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq/LDE4izWy10TW
+	key, _ := base64.StdEncoding.DecodeString("ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq/LDE4izWy10TW")
+	if hash := NoteKeyHash("PeterNeumann", key[1:]); hash != 0xc74f20a3 {
+		t.Errorf("expected c74f20a3, got %08x", hash)
+	}
+}
+
+func TestSignedCheckpoint_Cosign(t *testing.T) {
+	logPub, logKey, _ := ed25519.GenerateKey(nil)
+	c := Checkpoint{Origin: "example.com/log", Size: 5, Root: bytes.Repeat([]byte{7}, 32)}
+
+	signed, err := SignCheckpoint(c, "example.com/log", logKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policy := WitnessPolicy{
+		Log:       Witness{Name: "example.com/log", Key: logPub},
+		Threshold: 2,
+	}
+	var keys []ed25519.PrivateKey
+	for _, name := range []string{"witness-a", "witness-b", "witness-c"} {
+		pub, key, _ := ed25519.GenerateKey(nil)
+		policy.Witnesses = append(policy.Witnesses, Witness{Name: name, Key: pub})
+		keys = append(keys, key)
+	}
+
+	if err := policy.Verify(signed); err == nil {
+		t.Errorf("expected error without cosignatures")
+	}
+
+	// witnesses cosign their own copies, which are collected by merging
+	cosigned, _ := SignCheckpoint(c, "example.com/log", logKey)
+	if err := cosigned.Cosign("witness-a", keys[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := signed.Merge(cosigned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := signed.Cosign("witness-a", keys[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signed.Signatures) != 2 {
+		t.Errorf("expected 2 signatures, got %d", len(signed.Signatures))
+	}
+	if err := policy.Verify(signed); err == nil {
+		t.Errorf("expected error with 1 of 2 cosignatures")
+	}
+
+	// a signature by a key that is not a witness doesn't count
+	_, other, _ := ed25519.GenerateKey(nil)
+	signed.Cosign("witness-b", other)
+	if err := policy.Verify(signed); err == nil {
+		t.Errorf("expected error with a cosignature by an unknown key")
+	}
+
+	signed.Cosign("witness-c", keys[2])
+	if err := policy.Verify(signed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	text, err := signed.MarshalText()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(text), string(c.Bytes())+"\n— example.com/log ") {
+		t.Errorf("unexpected note %q", text)
+	}
+
+	var decoded SignedCheckpoint
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decoded.Signatures) != 4 {
+		t.Errorf("expected 4 signatures, got %d", len(decoded.Signatures))
+	}
+	if err := policy.Verify(&decoded); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	decoded.Size = 6
+	if err := policy.Verify(&decoded); err == nil {
+		t.Errorf("expected error for tampered checkpoint")
+	}
+	if err := signed.Merge(&decoded); err == nil {
+		t.Errorf("expected error merging a different checkpoint")
+	}
+
+	// the same key under another name, or the key of the log, doesn't add a cosignature
+	if err := signed.Cosign("witness-d", keys[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range []Witness{{Name: "witness-d", Key: policy.Witnesses[0].Key}, policy.Log} {
+		extra := WitnessPolicy{Log: policy.Log, Witnesses: append([]Witness{w}, policy.Witnesses...), Threshold: 3}
+		if err := extra.Verify(signed); err == nil {
+			t.Errorf("%s: expected error for a cosignature that doesn't count", w.Name)
+		}
+	}
+
+	policy.Threshold = 4
+	if err := policy.Verify(signed); err == nil {
+		t.Errorf("expected error for invalid threshold")
+	}
+
+	for _, invalid := range []string{
+		string(c.Bytes()),
+		string(c.Bytes()) + "\n",
+		string(c.Bytes()) + "\n— example.com/log AAAA\n",
+		string(c.Bytes()) + "\n- example.com/log AAAAAAAA\n",
+		string(c.Bytes()) + "\n— example.com/log AAAAAAAA",
+	} {
+		if err := decoded.UnmarshalText([]byte(invalid)); err == nil {
+			t.Errorf("%q: expected error, got nil", invalid)
+		}
+	}
+
+	if _, err := SignCheckpoint(c, "example log", logKey); err == nil {
+		t.Errorf("expected error for invalid name")
+	}
+	if _, err := SignCheckpoint(Checkpoint{Origin: "a\nb"}, "example.com/log", logKey); err == nil {
+		t.Errorf("expected error for invalid origin")
+	}
+}
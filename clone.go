@@ -0,0 +1,80 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the tree, which can be changed without affecting the original and the other way
+// around. The copy has the same options; a RootHistory given with WithRootHistory is copied, so the heads of either
+// tree are only recorded in its own history.
+func (m *MerkleTree) Clone() *MerkleTree {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.root = nil
+	c.leaves = nil
+	c.history = m.history.clone()
+	c.index = maps.Clone(m.index)
+	for h, positions := range c.index {
+		c.index[h] = slices.Clone(positions)
+	}
+	if m.root == nil {
+		return &c
+	}
+
+	c.leaves = make([]*Node, 0, len(m.leaves))
+	var clone func(n *Node) *Node
+	clone = func(n *Node) *Node {
+		copied := &Node{h: bytes.Clone(n.h)}
+		if n.left == nil {
+			c.leaves = append(c.leaves, copied)
+			return copied
+		}
+		copied.left = clone(n.left)
+		copied.right = copied.left
+		if n.right != n.left {
+			copied.right = clone(n.right)
+		}
+		copied.left.parent = copied
+		copied.right.parent = copied
+		return copied
+	}
+	c.root = clone(m.root)
+	return &c
+}
+
+// Equal reports whether two trees have the same root, number of leaves and number of nodes. It is O(1), and enough
+// for trees whose integrity was verified; DeepEqual also compares every node.
+func (m *MerkleTree) Equal(other *MerkleTree) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+	return bytes.Equal(m.RootNoCopy(), other.RootNoCopy()) && len(m.leaves) == len(other.leaves) && m.n == other.n
+}
+
+// DeepEqual reports whether two trees are Equal and have the same shape and hashes at every node, in O(n).
+func (m *MerkleTree) DeepEqual(other *MerkleTree) bool {
+	if !m.Equal(other) {
+		return false
+	}
+	if m == nil || m.root == nil || other.root == nil {
+		return m == nil || m.root == other.root
+	}
+	return nodesEqual(m.root, other.root)
+}
+
+func nodesEqual(a, b *Node) bool {
+	if !bytes.Equal(a.h, b.h) || (a.left == nil) != (b.left == nil) {
+		return false
+	}
+	if a.left == nil {
+		return true
+	}
+	if (a.right == a.left) != (b.right == b.left) || !nodesEqual(a.left, b.left) {
+		return false
+	}
+	return a.right == a.left || nodesEqual(a.right, b.right)
+}
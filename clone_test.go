@@ -0,0 +1,98 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_Clone(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithDuplication()}, {WithSortedLeaves(), WithDedup()}} {
+		data := []Leaf{&TestLeaf{"c"}, &TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"a"}, &TestLeaf{"d"}}
+		tree := mustBuildMerkleTree(t, data, opts...)
+		root := tree.Root()
+
+		clone := tree.Clone()
+		if !clone.Equal(tree) || !clone.DeepEqual(tree) || !clone.Verify() {
+			t.Fatalf("expected clone to equal tree")
+		}
+		for i := range tree.NumLeaves() {
+			expected, _ := tree.ProofByIndex(i)
+			proof, err := clone.ProofByIndex(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(proof.Root(), expected.Root()) || len(proof.Siblings()) != len(expected.Siblings()) {
+				t.Errorf("proof of %d differs", i)
+			}
+		}
+
+		// changing the clone doesn't change the tree
+		if clone.sorted {
+			continue // appends would have to keep the order
+		}
+		if err := clone.Append(&TestLeaf{"e"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := clone.Update(0, &TestLeaf{"f"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if clone.Equal(tree) {
+			t.Errorf("expected changed clone to differ")
+		}
+		if !bytes.Equal(tree.Root(), root) || !tree.Verify() || tree.FindAll(&TestLeaf{"e"}) != nil {
+			t.Errorf("tree changed with its clone")
+		}
+		if i, _ := tree.Index(&TestLeaf{"c"}); i != 0 {
+			t.Errorf("expected index=0, got %d", i)
+		}
+	}
+
+	// the clone records its heads in a copy of the history
+	history := NewRootHistory()
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}}, WithRootHistory(history))
+	clone := tree.Clone()
+	if err := clone.Append(&TestLeaf{"b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if heads := history.Heads(); len(heads) != 1 {
+		t.Errorf("expected 1 head in the history of the tree, got %d", len(heads))
+	}
+	if heads := clone.history.Heads(); len(heads) != 2 || heads[1].Size != 2 {
+		t.Errorf("expected 2 heads in the history of the clone, got %v", heads)
+	}
+
+	var nilTree *MerkleTree
+	if nilTree.Clone() != nil {
+		t.Errorf("expected nil clone")
+	}
+	if empty := (&MerkleTree{}).Clone(); empty == nil || empty.Root() != nil {
+		t.Errorf("expected empty clone")
+	}
+}
+
+func TestTree_Equal(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tree := mustBuildMerkleTree(t, data)
+	other := mustBuildMerkleTree(t, data)
+
+	if !tree.Equal(other) || !tree.DeepEqual(other) {
+		t.Errorf("expected trees to be equal")
+	}
+	if tree.Equal(mustBuildMerkleTree(t, data[:2])) || tree.Equal(nil) {
+		t.Errorf("expected trees to differ")
+	}
+
+	// a corrupt node is only found by the deep check
+	other.leaves[0].h = hashStrategy.HashLeaf([]byte("x"))
+	if !tree.Equal(other) || tree.DeepEqual(other) {
+		t.Errorf("expected only the deep check to find the corrupt node")
+	}
+
+	var nilTree *MerkleTree
+	if !nilTree.Equal(nil) || !nilTree.DeepEqual(nil) || nilTree.Equal(tree) {
+		t.Errorf("expected only nil trees to equal nil")
+	}
+	if !(&MerkleTree{}).DeepEqual(&MerkleTree{}) || (&MerkleTree{}).DeepEqual(tree) {
+		t.Errorf("expected only empty trees to equal empty trees")
+	}
+}
@@ -0,0 +1,111 @@
+package gomerkletree
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// CometBFTHashStrategy is the hash strategy of CometBFT (formerly Tendermint), which hashes with the RFC 6962 scheme
+// and splits n items into a left subtree of the largest power of two smaller than n, the shape promotion produces.
+// So BuildMerkleTreeBytes with the default options builds the tree of merkle.HashFromByteSlices, used for the
+// transactions, validator sets and headers of Cosmos SDK chains.
+type CometBFTHashStrategy = RFC6962HashStrategy
+
+// cometBFTMaxAunts is the maximum number of aunts CometBFT accepts in a proof.
+const cometBFTMaxAunts = 100
+
+// CometBFTRoot returns the root of items like CometBFT's merkle.HashFromByteSlices, which is the SHA-256 of nothing
+// for no items.
+func CometBFTRoot(items [][]byte) []byte {
+	if len(items) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+	tree, err := BuildMerkleTreeBytes(items)
+	if err != nil {
+		return nil // unreachable: byte slices are never nil leaves
+	}
+	return tree.Root()
+}
+
+// CometBFTProof is a proof in the format of CometBFT's merkle.Proof, whose JSON encoding it shares:
+// the aunts are the sibling hashes from the leaf up.
+type CometBFTProof struct {
+	Total    int64    `json:"total,string"`
+	Index    int64    `json:"index,string"`
+	LeafHash []byte   `json:"leaf_hash"`
+	Aunts    [][]byte `json:"aunts"`
+}
+
+// CometBFTProof returns the proof of the i-th leaf in the format of CometBFT. The tree must have been built with the
+// default hash strategy and promotion, like by BuildMerkleTreeBytes.
+func (m *MerkleTree) CometBFTProof(i int) (*CometBFTProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if m.duplicate {
+		return nil, errors.New("tree built with duplication")
+	}
+	switch m.hashStrategy.(type) {
+	case defaultHashStrategy, RFC6962HashStrategy:
+	default:
+		return nil, errors.New("tree not built with the RFC 6962 hash strategy")
+	}
+	p, err := m.ProofByIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return &CometBFTProof{
+		Total:    int64(len(m.leaves)),
+		Index:    int64(i),
+		LeafHash: m.LeafHash(i),
+		Aunts:    p.Siblings(),
+	}, nil
+}
+
+// Verify checks that the proof proves leaf under root, like merkle.Proof.Verify of CometBFT.
+func (p *CometBFTProof) Verify(root, leaf []byte) error {
+	if p == nil {
+		return errors.New("no proof")
+	}
+	if p.Total <= 0 {
+		return errors.New("proof total must be positive")
+	}
+	if p.Index < 0 || p.Index >= p.Total {
+		return errors.New("index out of range")
+	}
+	if p.Total > maxTreeSize {
+		return errors.New("proof total too large")
+	}
+	if len(p.Aunts) > cometBFTMaxAunts {
+		return errors.New("too many aunts")
+	}
+	hash := defaultHashStrategy{}.HashLeaf(leaf)
+	if !hashEqual(hash, p.LeafHash) {
+		return errors.New("invalid leaf hash")
+	}
+
+	left := pathDirections(int(p.Index), int(p.Total))
+	if len(left) != len(p.Aunts) {
+		return errors.New("proof not for index")
+	}
+	return verifyProof(hash, &Proof{siblings: p.Aunts, left: left}, defaultHashStrategy{}, root)
+}
+
+// Proof converts the proof into a Proof for root, which can be verified with VerifyProof.
+func (p *CometBFTProof) Proof(root []byte) (*Proof, error) {
+	if p == nil {
+		return nil, errors.New("no proof")
+	}
+	if p.Total <= 0 || p.Index < 0 || p.Index >= p.Total {
+		return nil, errors.New("index out of range")
+	}
+	if p.Total > maxTreeSize {
+		return nil, errors.New("proof total too large")
+	}
+	left := pathDirections(int(p.Index), int(p.Total))
+	if len(left) != len(p.Aunts) {
+		return nil, errors.New("proof not for index")
+	}
+	return NewProof(root, p.Aunts, left, nil), nil
+}
@@ -0,0 +1,112 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCometBFT_Root(t *testing.T) {
+	// test vectors of CometBFT's merkle.HashFromByteSlices
+	tests := []struct {
+		name  string
+		items [][]byte
+		want  string
+	}{
+		{"Empty", nil, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"EmptyItem", [][]byte{{}}, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"},
+		{"One", [][]byte{{1, 2, 3}}, "054edec1d0211f624fed0cbca9d4f9400b0e491c43742af2c5b0abebf0c990d8"},
+		{"Many", [][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}}, "f326493eceab4f2d9ffbc78c59432a0a005d6ea98392045c74df5d14a113be18"},
+	}
+
+	for _, tt := range tests {
+		if got := hex.EncodeToString(CometBFTRoot(tt.items)); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCometBFT_Proof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		items := make([][]byte, n)
+		for i := range items {
+			items[i] = []byte(fmt.Sprint(i))
+		}
+		tree, err := BuildMerkleTreeBytes(items, WithHashStrategy(CometBFTHashStrategy{}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		root := CometBFTRoot(items)
+		if !bytes.Equal(tree.Root(), root) {
+			t.Errorf("%d: expected %x, got %x", n, root, tree.Root())
+		}
+
+		for i, item := range items {
+			proof, err := tree.CometBFTProof(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// the proof survives the JSON of CometBFT
+			b, _ := json.Marshal(proof)
+			var decoded CometBFTProof
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := decoded.Verify(root, item); err != nil {
+				t.Errorf("%d/%d: unexpected error: %v", n, i, err)
+			}
+			if err := decoded.Verify(root, []byte("x")); err == nil {
+				t.Errorf("%d/%d: expected error for other leaf", n, i)
+			}
+
+			converted, err := decoded.Proof(root)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := VerifyProofBytes(item, converted); err != nil {
+				t.Errorf("%d/%d: unexpected error: %v", n, i, err)
+			}
+
+			if n > 1 {
+				decoded.Index = int64((i + 1) % n)
+				decoded.LeafHash = tree.LeafHash((i + 1) % n)
+				if err := decoded.Verify(root, items[(i+1)%n]); err == nil {
+					t.Errorf("%d/%d: expected error for other index", n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestCometBFT_ProofJSON(t *testing.T) {
+	tree, _ := BuildMerkleTreeBytes([][]byte{{1}, {2}})
+	proof, _ := tree.CometBFTProof(1)
+	b, _ := json.Marshal(proof)
+
+	var fields map[string]any
+	json.Unmarshal(b, &fields)
+	if fields["total"] != "2" || fields["index"] != "1" {
+		t.Errorf("expected total and index as strings, got %s", b)
+	}
+
+	if _, err := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}}, WithDuplication()).CometBFTProof(0); err == nil {
+		t.Errorf("expected error for duplication")
+	}
+	if _, err := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}}, WithSortedPairs()).CometBFTProof(0); err == nil {
+		t.Errorf("expected error for other hash strategy")
+	}
+	if err := (&CometBFTProof{}).Verify(tree.Root(), []byte{1}); err == nil {
+		t.Errorf("expected error for empty proof")
+	}
+	huge := &CometBFTProof{Total: math.MaxInt64, LeafHash: defaultHashStrategy{}.HashLeaf([]byte{1}), Aunts: [][]byte{tree.Root()}}
+	if err := huge.Verify(tree.Root(), []byte{1}); err == nil {
+		t.Errorf("expected error for total %d", huge.Total)
+	}
+	if _, err := huge.Proof(tree.Root()); err == nil {
+		t.Errorf("expected error for total %d", huge.Total)
+	}
+}
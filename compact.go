@@ -0,0 +1,184 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+// CompactMerkleTree is a merkle tree that stores all hashes in a single contiguous slice,
+// instead of allocating a Node per hash. Nodes are addressed arithmetically: level by level,
+// the children of the i-th node are the (2i)-th and (2i+1)-th nodes of the level below.
+// Promoted nodes are copied into the level above, which costs at most one extra hash per level.
+//
+// The tree has the same shape as MerkleTree, so it produces the same roots and proofs.
+// All hashes produced by the hash strategy must have the same size.
+type CompactMerkleTree struct {
+	hashes       []byte
+	size         int   // digest size
+	offsets      []int // index of the first node of every level, with an extra entry marking the end
+	hashStrategy HashStrategy
+}
+
+// BuildCompactMerkleTree takes a slice of leaves and builds a compact merkle tree,
+// using the default SHA-256 based hash strategy.
+// Returns an error for the same input as BuildMerkleTree.
+func BuildCompactMerkleTree(data []Leaf) (*CompactMerkleTree, error) {
+	return buildCompactMerkleTree(data, defaultHashStrategy{})
+}
+
+// BuildCompactMerkleTreeWithHashStrategy takes a slice of leaves and a hash strategy, and builds a compact merkle tree.
+// Panics if the hash strategy doesn't return digests of a fixed size.
+func BuildCompactMerkleTreeWithHashStrategy(data []Leaf, hash HashStrategy) (*CompactMerkleTree, error) {
+	return buildCompactMerkleTree(data, hash)
+}
+
+func buildCompactMerkleTree(data []Leaf, hash HashStrategy) (*CompactMerkleTree, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	hashes, err := hashLeaves(context.Background(), data, config{hashStrategy: hash}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := compactOffsets(len(data))
+	m := &CompactMerkleTree{
+		hashes:       make([]byte, offsets[len(offsets)-1]*len(hashes[0])),
+		size:         len(hashes[0]),
+		offsets:      offsets,
+		hashStrategy: hash,
+	}
+
+	for i, h := range hashes {
+		m.set(0, i, h)
+	}
+
+	for level := 1; level < len(offsets)-1; level++ {
+		below := m.levelLen(level - 1)
+		for i := range m.levelLen(level) {
+			if 2*i+1 < below {
+				m.set(level, i, hash.HashInternal(m.node(level-1, 2*i), m.node(level-1, 2*i+1)))
+			} else {
+				m.set(level, i, m.node(level-1, 2*i))
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// compactOffsets returns the index of the first node of every level of a tree with n leaves,
+// with an extra entry marking the end.
+func compactOffsets(n int) []int {
+	offsets := []int{0}
+	for ; ; n = (n + 1) / 2 {
+		offsets = append(offsets, offsets[len(offsets)-1]+n)
+		if n == 1 {
+			break
+		}
+	}
+	return offsets
+}
+
+func (m *CompactMerkleTree) levelLen(level int) int {
+	return m.offsets[level+1] - m.offsets[level]
+}
+
+func (m *CompactMerkleTree) node(level, i int) []byte {
+	start := (m.offsets[level] + i) * m.size
+	return m.hashes[start : start+m.size : start+m.size]
+}
+
+func (m *CompactMerkleTree) set(level, i int, h []byte) {
+	if len(h) != m.size {
+		panic("gomerkletree: hash strategy returned digests of different sizes")
+	}
+	copy(m.node(level, i), h)
+}
+
+// Root returns the bytes of the root.
+func (m *CompactMerkleTree) Root() []byte {
+	if m == nil {
+		return nil
+	}
+	return bytes.Clone(m.node(len(m.offsets)-2, 0))
+}
+
+// Len returns the total number of nodes in the tree (not counting promoted copies).
+func (m *CompactMerkleTree) Len() int {
+	if m == nil {
+		return -1
+	}
+	return 2*m.levelLen(0) - 1
+}
+
+// Verify verifies the integrity of the tree.
+func (m *CompactMerkleTree) Verify() bool {
+	if m == nil || m.hashStrategy == nil {
+		return false
+	}
+	for level := 1; level < len(m.offsets)-1; level++ {
+		below := m.levelLen(level - 1)
+		for i := range m.levelLen(level) {
+			expected := m.node(level-1, 2*i)
+			if 2*i+1 < below {
+				expected = m.hashStrategy.HashInternal(expected, m.node(level-1, 2*i+1))
+			}
+			if !hashEqual(m.node(level, i), expected) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Proof generates a proof for a given leaf, finding the leaf with a linear scan over the leaf hashes.
+func (m *CompactMerkleTree) Proof(x Leaf) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+
+	b, err := leafBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	hash := m.hashStrategy.HashLeaf(b)
+	for i := range m.levelLen(0) {
+		if hashEqual(hash, m.node(0, i)) {
+			return m.ProofByIndex(i)
+		}
+	}
+	return nil, errors.New("not in tree")
+}
+
+// ProofByIndex generates a proof for the i-th leaf in O(log n).
+func (m *CompactMerkleTree) ProofByIndex(i int) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= m.levelLen(0) {
+		return nil, errors.New("index out of range")
+	}
+
+	var siblings [][]byte
+	var left []bool
+
+	for level := 0; level < len(m.offsets)-2; level++ {
+		if i%2 != 0 {
+			siblings = append(siblings, m.node(level, i-1))
+			left = append(left, true) // maps to sibling hash
+		} else if i+1 < m.levelLen(level) {
+			siblings = append(siblings, m.node(level, i+1))
+			left = append(left, false) // maps to sibling hash
+		}
+		i /= 2
+	}
+
+	return &Proof{
+		root:         m.Root(),
+		siblings:     cloneHashes(siblings), // so the proof doesn't keep the hashes of the whole tree alive
+		left:         left,
+		hashStrategy: m.hashStrategy,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompactTree_Build(t *testing.T) {
+	var data []Leaf
+
+	for i := range 33 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+
+		tree, err := BuildCompactMerkleTree(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := mustBuildMerkleTree(t, data)
+
+		if !bytes.Equal(tree.Root(), expected.Root()) {
+			t.Errorf("root not correct for %d leaves", i+1)
+		}
+
+		if tree.Len() != expected.Len() {
+			t.Errorf("expected len=%d, got %d", expected.Len(), tree.Len())
+		}
+
+		if !tree.Verify() {
+			t.Errorf("couldn't verify tree of %d leaves", i+1)
+		}
+	}
+
+	if _, err := BuildCompactMerkleTree(nil); err == nil {
+		t.Errorf("expected error for empty input")
+	}
+	if _, err := BuildCompactMerkleTree([]Leaf{&TestLeaf{"a"}, nil}); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+}
+
+func TestCompactTree_Verify(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree, err := BuildCompactMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// corrupt a leaf
+	tree.hashes[0] ^= 0xff
+
+	if tree.Verify() {
+		t.Errorf("expected false, got true")
+	}
+
+	tree.hashes[0] ^= 0xff
+
+	// corrupt a promoted node
+	tree.node(1, 1)[0] ^= 0xff
+
+	if tree.Verify() {
+		t.Errorf("expected false, got true")
+	}
+}
+
+func TestCompactTree_Proof(t *testing.T) {
+	var data []Leaf
+	for i := range 13 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree, err := BuildCompactMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := mustBuildMerkleTree(t, data)
+
+	for i, x := range data {
+		proof, err := tree.Proof(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expectedProof, _ := expected.ProofByIndex(i)
+		if len(proof.siblings) != len(expectedProof.siblings) {
+			t.Fatalf("expected %d siblings, got %d", len(expectedProof.siblings), len(proof.siblings))
+		}
+
+		for j := range proof.siblings {
+			if !bytes.Equal(proof.siblings[j], expectedProof.siblings[j]) || proof.left[j] != expectedProof.left[j] {
+				t.Errorf("sibling %d of leaf %d not correct", j, i)
+			}
+		}
+
+		if err := VerifyProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// not in tree
+	if _, err := tree.Proof(&TestLeaf{"z"}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ProofByIndex(13); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
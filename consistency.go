@@ -0,0 +1,207 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ConsistencyProof proves that the tree with newSize leaves is an append-only extension of the tree with oldSize leaves.
+type ConsistencyProof struct {
+	oldSize, newSize int
+	hashes           [][]byte
+	hashStrategy     HashStrategy
+}
+
+// OldSize returns the size of the old tree.
+func (p *ConsistencyProof) OldSize() int {
+	if p == nil {
+		return 0
+	}
+	return p.oldSize
+}
+
+// NewSize returns the size of the new tree.
+func (p *ConsistencyProof) NewSize() int {
+	if p == nil {
+		return 0
+	}
+	return p.newSize
+}
+
+// Hashes returns a copy of the hashes of the proof.
+func (p *ConsistencyProof) Hashes() [][]byte {
+	if p == nil {
+		return nil
+	}
+	hashes := make([][]byte, len(p.hashes))
+	for i, h := range p.hashes {
+		hashes[i] = bytes.Clone(h)
+	}
+	return hashes
+}
+
+// NewConsistencyProof assembles a consistency proof from its contents, e.g. after receiving them over the network.
+// A nil hash strategy means the default hash strategy.
+func NewConsistencyProof(oldSize, newSize int, hashes [][]byte, hash HashStrategy) *ConsistencyProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	p := &ConsistencyProof{
+		oldSize:      oldSize,
+		newSize:      newSize,
+		hashes:       make([][]byte, len(hashes)),
+		hashStrategy: hash,
+	}
+	for i, h := range hashes {
+		p.hashes[i] = bytes.Clone(h)
+	}
+	return p
+}
+
+// ConsistencyProof generates a proof that the first newSize leaves of the tree extend the first oldSize leaves,
+// following the algorithm of RFC 6962.
+// Both sizes have to be positive and not larger than the number of leaves currently in the tree.
+func (m *MerkleTree) ConsistencyProof(oldSize, newSize int) (*ConsistencyProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if m.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if oldSize <= 0 || oldSize > newSize || newSize > len(m.leaves) {
+		return nil, errors.New("invalid tree sizes")
+	}
+
+	hashes, err := consistencyPath(oldSize, newSize, func(lo, hi int) ([]byte, error) {
+		return m.subtreeHash(lo, hi), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsistencyProof{
+		oldSize:      oldSize,
+		newSize:      newSize,
+		hashes:       cloneHashes(hashes),
+		hashStrategy: m.hashStrategy,
+	}, nil
+}
+
+// VerifyConsistency checks if a consistency proof is valid for the old and new roots,
+// following the algorithm of RFC 9162.
+// The old root should be one the verifier already trusts.
+func VerifyConsistency(oldRoot, newRoot []byte, p *ConsistencyProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	if p.oldSize <= 0 || p.oldSize > p.newSize {
+		return errors.New("invalid tree sizes")
+	}
+
+	if p.oldSize == p.newSize {
+		if len(p.hashes) != 0 {
+			return errors.New("too many hashes")
+		}
+		if !hashEqual(oldRoot, newRoot) {
+			return rootMismatch(oldRoot, newRoot)
+		}
+		return nil
+	}
+
+	hashes := p.hashes
+	// the old tree is a complete subtree of the new tree, so its root is the start of the path
+	if p.oldSize&(p.oldSize-1) == 0 {
+		hashes = append([][]byte{oldRoot}, hashes...)
+	}
+	if len(hashes) == 0 {
+		return errors.New("not enough hashes")
+	}
+
+	fn, sn := p.oldSize-1, p.newSize-1
+	for fn%2 != 0 {
+		fn /= 2
+		sn /= 2
+	}
+
+	fr, sr := hashes[0], hashes[0]
+	for _, c := range hashes[1:] {
+		if sn == 0 {
+			return errors.New("too many hashes")
+		}
+		if fn%2 != 0 || fn == sn {
+			fr = p.hashStrategy.HashInternal(c, fr)
+			sr = p.hashStrategy.HashInternal(c, sr)
+			for fn%2 == 0 && fn != 0 {
+				fn /= 2
+				sn /= 2
+			}
+		} else {
+			sr = p.hashStrategy.HashInternal(sr, c)
+		}
+		fn /= 2
+		sn /= 2
+	}
+
+	if sn != 0 {
+		return errors.New("not enough hashes")
+	}
+	if !hashEqual(fr, oldRoot) {
+		return rootMismatch(fr, oldRoot)
+	}
+	if !hashEqual(sr, newRoot) {
+		return rootMismatch(sr, newRoot)
+	}
+	return nil
+}
+
+// consistencyPath returns the hashes of a consistency proof from oldSize to newSize,
+// reading the roots of subtrees over leaves [lo, hi) with the given function.
+func consistencyPath(oldSize, newSize int, subtreeHash func(lo, hi int) ([]byte, error)) ([][]byte, error) {
+	var hashes [][]byte
+	var subproof func(old, lo, hi int, complete bool) error
+	subproof = func(old, lo, hi int, complete bool) error {
+		if old == hi-lo {
+			if complete {
+				return nil
+			}
+			hash, err := subtreeHash(lo, hi)
+			hashes = append(hashes, hash)
+			return err
+		}
+		k := split(hi - lo)
+		var err error
+		var hash []byte
+		if old <= k {
+			err = subproof(old, lo, lo+k, complete)
+			if err == nil {
+				hash, err = subtreeHash(lo+k, hi)
+			}
+		} else {
+			err = subproof(old-k, lo+k, hi, false)
+			if err == nil {
+				hash, err = subtreeHash(lo, lo+k)
+			}
+		}
+		hashes = append(hashes, hash)
+		return err
+	}
+	if err := subproof(oldSize, 0, newSize, true); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// subtreeHash returns the root of the tree over leaves [lo, hi), as if only those leaves had been used to build it.
+// Complete subtrees are read from the tree, so only the right edge of the range is rehashed.
+func (m *MerkleTree) subtreeHash(lo, hi int) []byte {
+	size := hi - lo
+	if size&(size-1) == 0 && lo%size == 0 {
+		node := m.leaves[lo]
+		for ; size > 1; size /= 2 {
+			node = node.parent
+		}
+		return node.h
+	}
+	k := split(size)
+	return m.hashStrategy.HashInternal(m.subtreeHash(lo, lo+k), m.subtreeHash(lo+k, hi))
+}
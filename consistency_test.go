@@ -0,0 +1,132 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTree_ConsistencyProof(t *testing.T) {
+	data := rfc6962Data(t)
+	tree, err := BuildRFC6962MerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// test vectors from the Certificate Transparency reference implementation
+	tests := []struct {
+		oldSize, newSize int
+		hashes           []string
+	}{
+		{1, 1, nil},
+		{1, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{6, 8, []string{
+			"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 5, []string{
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	}
+
+	for _, test := range tests {
+		proof, err := tree.ConsistencyProof(test.oldSize, test.newSize)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(proof.hashes) != len(test.hashes) {
+			t.Fatalf("expected %d hashes, got %d", len(test.hashes), len(proof.hashes))
+		}
+
+		for i, hash := range test.hashes {
+			if !bytes.Equal(proof.hashes[i], mustDecodeHex(t, hash)) {
+				t.Errorf("hash %d of proof %d -> %d not correct", i, test.oldSize, test.newSize)
+			}
+		}
+	}
+
+	// invalid sizes
+	if _, err := tree.ConsistencyProof(0, 8); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ConsistencyProof(5, 4); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ConsistencyProof(1, 9); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestConsistencyProof_Verify(t *testing.T) {
+	var data []Leaf
+	for i := range 20 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for newSize := 1; newSize <= len(data); newSize++ {
+		newRoot := mustBuildMerkleTree(t, data[:newSize]).Root()
+
+		for oldSize := 1; oldSize <= newSize; oldSize++ {
+			oldRoot := mustBuildMerkleTree(t, data[:oldSize]).Root()
+
+			proof, err := tree.ConsistencyProof(oldSize, newSize)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := VerifyConsistency(oldRoot, newRoot, proof); err != nil {
+				t.Errorf("unexpected error for %d -> %d: %v", oldSize, newSize, err)
+			}
+
+			// roots of a different tree
+			if oldSize < newSize {
+				if err := VerifyConsistency(newRoot, oldRoot, proof); err == nil {
+					t.Errorf("expected err for %d -> %d, got nil", oldSize, newSize)
+				}
+			}
+		}
+	}
+
+	oldRoot := mustBuildMerkleTree(t, data[:3]).Root()
+	proof, _ := tree.ConsistencyProof(3, 20)
+
+	// tampered old leaf
+	tampered := append([]Leaf{&TestLeaf{"z"}}, data[1:]...)
+	err := VerifyConsistency(oldRoot, mustBuildMerkleTree(t, tampered).Root(), proof)
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRootMismatch) {
+		t.Errorf("expected root mismatch, got %v", err)
+	}
+
+	// missing hashes
+	proof.hashes = proof.hashes[1:]
+	if err := VerifyConsistency(oldRoot, tree.Root(), proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// missing hashing strategy
+	proof.hashStrategy = nil
+	err = VerifyConsistency(oldRoot, tree.Root(), proof)
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	if err.Error() != "no proof/hash strategy" {
+		t.Errorf("expected missing proof/strategy, got %s", err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package gomerkletree
+
+import (
+	"context"
+	"errors"
+)
+
+// Number of leaves hashed, or nodes verified, between checks for cancellation.
+const ctxCheckInterval = 1024
+
+// BuildMerkleTreeCtx builds a merkle tree like BuildMerkleTree, but stops with the context's error
+// when it is cancelled. Cancellation is checked between batches of leaves and between levels.
+func BuildMerkleTreeCtx(ctx context.Context, data []Leaf, opts ...Option) (*MerkleTree, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	cfg := newConfig(opts)
+	p := newProgress(cfg, len(data))
+	hashes, err := hashLeaves(ctx, data, cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	return buildFromLeafHashesCtx(ctx, hashes, cfg, p)
+}
+
+// VerifyCtx verifies the integrity of the tree like Verify, but stops with the context's error when it is cancelled.
+func (m *MerkleTree) VerifyCtx(ctx context.Context) error {
+	if m == nil || m.root == nil || m.hashStrategy == nil {
+		return errors.New("unable to verify tree")
+	}
+	var visited int
+	ok, err := m.root.verifyCtx(ctx, m.hashStrategy, &visited)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("unable to verify tree")
+	}
+	return nil
+}
+
+func (n *Node) verifyCtx(ctx context.Context, hasher HashStrategy, visited *int) (bool, error) {
+	if *visited++; *visited%ctxCheckInterval == 0 {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+	}
+	if n.left == nil || n.right == nil {
+		return n.left == nil && n.right == nil, nil
+	}
+	if !hashEqual(n.h, hasher.HashInternal(n.left.h, n.right.h)) {
+		return false, nil
+	}
+	if ok, err := n.left.verifyCtx(ctx, hasher, visited); !ok || err != nil {
+		return ok, err
+	}
+	if n.right == n.left {
+		return true, nil
+	}
+	return n.right.verifyCtx(ctx, hasher, visited)
+}
+
+// ProofCtx generates a proof for a given leaf like Proof, but stops with the context's error when it is cancelled
+// while verifying the tree.
+func (m *MerkleTree) ProofCtx(ctx context.Context, x Leaf) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return nil, err
+	}
+	i := m.leafIndex(hash)
+	if i < 0 {
+		return nil, errors.New("not in tree")
+	}
+	return m.ProofByIndexCtx(ctx, i)
+}
+
+// ProofByIndexCtx generates a proof for the i-th leaf like ProofByIndex, but stops with the context's error
+// when it is cancelled while verifying the tree.
+func (m *MerkleTree) ProofByIndexCtx(ctx context.Context, i int) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.leaves) {
+		return nil, errors.New("index out of range")
+	}
+	if !m.trusted {
+		if err := m.VerifyCtx(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return m.proof(m.leaves[i]), nil
+}
@@ -0,0 +1,84 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTree_BuildCtx(t *testing.T) {
+	var data []Leaf
+	for i := range 3000 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	tree, err := BuildMerkleTreeCtx(context.Background(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := mustBuildMerkleTree(t, data); !bytes.Equal(tree.Root(), expected.Root()) || tree.Len() != expected.Len() {
+		t.Errorf("expected the same tree as BuildMerkleTree")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := BuildMerkleTreeCtx(ctx, data); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestTree_ProofCtx(t *testing.T) {
+	var data []Leaf
+	for i := range 3000 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	tree := mustBuildMerkleTree(t, data, WithDuplication())
+
+	proof, err := tree.ProofCtx(context.Background(), data[1234])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(data[1234], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := tree.ProofCtx(context.Background(), &TestLeaf{"x"}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := tree.ProofByIndexCtx(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestTree_VerifyCtx(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	if err := tree.VerifyCtx(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tree.leaves[2].h = hashStrategy.HashLeaf([]byte("x"))
+	if err := tree.VerifyCtx(context.Background()); err == nil {
+		t.Errorf("expected error")
+	}
+
+	var nilTree *MerkleTree
+	if err := nilTree.VerifyCtx(context.Background()); err == nil {
+		t.Errorf("expected error")
+	}
+}
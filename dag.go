@@ -0,0 +1,269 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+// ErrObjectNotFound is returned by an ObjectStore when it has no object with the requested key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore stores the encoded objects of a DAG by their key, e.g. in a database, or on a remote peer to fetch
+// objects from. It doesn't need to be trusted: the DAG verifies every object it gets against its key.
+type ObjectStore interface {
+	Get(key []byte) ([]byte, error)
+	Put(key, object []byte) error
+}
+
+// MemoryObjectStore is an ObjectStore that keeps all objects in memory. It is safe for concurrent use.
+type MemoryObjectStore struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewMemoryObjectStore returns an empty in-memory object store.
+func NewMemoryObjectStore() *MemoryObjectStore {
+	return &MemoryObjectStore{
+		objects: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryObjectStore) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[string(key)]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return object, nil
+}
+
+func (s *MemoryObjectStore) Put(key, object []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[string(key)] = bytes.Clone(object)
+	return nil
+}
+
+// Len returns the number of objects in the store.
+func (s *MemoryObjectStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.objects)
+}
+
+// Object is a node of a DAG: a leaf with data, or an internal node referencing its children by their keys.
+type Object struct {
+	Data        []byte
+	Left, Right []byte
+}
+
+// IsLeaf reports whether the object is a leaf.
+func (o *Object) IsLeaf() bool {
+	return o.Left == nil
+}
+
+// Tags of encoded objects.
+const (
+	objectLeaf = iota
+	objectNode
+)
+
+// encode encodes the object as
+//
+//	0 | data
+//	1 | left length (uvarint) | left | right
+func (o *Object) encode() []byte {
+	if o.IsLeaf() {
+		return append([]byte{objectLeaf}, o.Data...)
+	}
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+len(o.Left)+len(o.Right))
+	b = append(b, objectNode)
+	b = binary.AppendUvarint(b, uint64(len(o.Left)))
+	b = append(b, o.Left...)
+	return append(b, o.Right...)
+}
+
+func decodeObject(b []byte) (*Object, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty object")
+	}
+	switch b[0] {
+	case objectLeaf:
+		return &Object{Data: bytes.Clone(b[1:])}, nil
+	case objectNode:
+		n, k := binary.Uvarint(b[1:])
+		if k <= 0 || n == 0 || n >= uint64(len(b)-1-k) {
+			return nil, errors.New("invalid object")
+		}
+		children := bytes.Clone(b[1+k:])
+		return &Object{Left: children[:n:n], Right: children[n:]}, nil
+	}
+	return nil, errors.New("unknown object type")
+}
+
+// DAG is a content-addressed merkle DAG on top of an object store. The key of an object is its hash in a merkle tree:
+// the leaf hash of the data of a leaf, and the hash of the keys of the children of an internal node. So the key of
+// the root of a tree stored with PutLeaves is the root BuildMerkleTree builds, and any subtree can be fetched and
+// verified on its own, like the objects of git or IPFS.
+type DAG struct {
+	store        ObjectStore
+	hashStrategy HashStrategy
+	duplicate    bool
+	reorder      bool // sorted or deduplicated leaves, which PutLeaves doesn't support
+}
+
+// NewDAG returns a DAG on top of an object store. Options can change the hash strategy, and WithDuplication changes
+// the trees PutLeaves stores. PutLeaves rejects sorted and deduplicated leaves.
+func NewDAG(store ObjectStore, opts ...Option) *DAG {
+	cfg := newConfig(opts)
+	return &DAG{
+		store:        store,
+		hashStrategy: cfg.hashStrategy,
+		duplicate:    cfg.duplicate,
+		reorder:      cfg.sorted || cfg.dedup,
+	}
+}
+
+// key returns the key of an object.
+func (d *DAG) key(o *Object) []byte {
+	if o.IsLeaf() {
+		return d.hashStrategy.HashLeaf(o.Data)
+	}
+	return d.hashStrategy.HashInternal(o.Left, o.Right)
+}
+
+// PutLeaf stores a leaf with the given data and returns its key.
+func (d *DAG) PutLeaf(data []byte) ([]byte, error) {
+	return d.put(&Object{Data: data})
+}
+
+// PutNode stores an internal node with the children with the given keys, which must be in the DAG, and returns its key.
+func (d *DAG) PutNode(left, right []byte) ([]byte, error) {
+	for _, child := range [][]byte{left, right} {
+		if _, err := d.store.Get(child); err != nil {
+			return nil, err
+		}
+	}
+	return d.put(&Object{Left: left, Right: right})
+}
+
+func (d *DAG) put(o *Object) ([]byte, error) {
+	if !o.IsLeaf() && (len(o.Left) == 0 || len(o.Right) == 0) {
+		return nil, errors.New("invalid child key")
+	}
+	key := d.key(o)
+	if err := d.store.Put(key, o.encode()); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PutLeaves stores the leaves and the internal nodes of the merkle tree over them, and returns the key of its root,
+// which is the root BuildMerkleTree builds with the same hash strategy and duplication. Returns an error for no leaves,
+// and if the DAG was created with sorted or deduplicated leaves.
+func (d *DAG) PutLeaves(data []Leaf) ([]byte, error) {
+	if d.reorder {
+		return nil, errors.New("not supported with sorted or deduplicated leaves")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	level := make([][]byte, len(data))
+	for i, x := range data {
+		b, err := leafBytes(x)
+		if err != nil {
+			return nil, err
+		}
+		if level[i], err = d.PutLeaf(b); err != nil {
+			return nil, err
+		}
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			switch {
+			case i+1 < len(level):
+				right = level[i+1]
+			case !d.duplicate:
+				next = append(next, level[i]) // promoted
+				continue
+			}
+			key, err := d.put(&Object{Left: level[i], Right: right})
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, key)
+		}
+		level = next
+	}
+	return level[0], nil
+}
+
+// Get returns the object with the given key, verifying that the key is its hash.
+func (d *DAG) Get(key []byte) (*Object, error) {
+	b, err := d.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	o, err := decodeObject(b)
+	if err != nil {
+		return nil, err
+	}
+	if !hashEqual(d.key(o), key) {
+		return nil, errors.New("object does not match its key")
+	}
+	return o, nil
+}
+
+// Has reports whether the object with the given key is in the DAG, without verifying it.
+func (d *DAG) Has(key []byte) (bool, error) {
+	_, err := d.store.Get(key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Walk calls fn with the data of the leaves below the object with the given key, from left to right,
+// verifying every object on the way. It stops at the first error of fn.
+func (d *DAG) Walk(key []byte, fn func(data []byte) error) error {
+	o, err := d.Get(key)
+	if err != nil {
+		return err
+	}
+	if o.IsLeaf() {
+		return fn(o.Data)
+	}
+	if err := d.Walk(o.Left, fn); err != nil {
+		return err
+	}
+	return d.Walk(o.Right, fn)
+}
+
+// Fetch copies the objects below the object with the given key from another store, e.g. one backed by a remote
+// peer, verifying every object against its key. Like git, subtrees whose root is already in the DAG are assumed to be
+// complete, and are not fetched again. Any subtree can be fetched on its own, by its key.
+func (d *DAG) Fetch(key []byte, from ObjectStore) error {
+	if ok, err := d.Has(key); ok || err != nil {
+		return err
+	}
+	remote := &DAG{store: from, hashStrategy: d.hashStrategy}
+	o, err := remote.Get(key)
+	if err != nil {
+		return err
+	}
+	if !o.IsLeaf() {
+		if err := d.Fetch(o.Left, from); err != nil {
+			return err
+		}
+		if err := d.Fetch(o.Right, from); err != nil {
+			return err
+		}
+	}
+	// children first, so an object is only in the DAG once its subtree is
+	return d.store.Put(key, o.encode())
+}
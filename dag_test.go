@@ -0,0 +1,179 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func dagLeaves(n int) []Leaf {
+	data := make([]Leaf, n)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprintf("leaf %d", i))
+	}
+	return data
+}
+
+func TestDAG_PutLeaves(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 8, 13} {
+		data := dagLeaves(n)
+		d := NewDAG(NewMemoryObjectStore())
+		root, err := d.PutLeaves(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := mustBuildMerkleTree(t, data).Root(); !bytes.Equal(root, expected) {
+			t.Errorf("%d leaves: expected %x, got %x", n, expected, root)
+		}
+
+		var walked []Leaf
+		err = d.Walk(root, func(b []byte) error {
+			walked = append(walked, BytesLeaf(b))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(walked) != n || !bytes.Equal(walked[n-1].Bytes(), data[n-1].Bytes()) {
+			t.Errorf("%d leaves: expected the leaves in order, got %d", n, len(walked))
+		}
+	}
+}
+
+func TestDAG_PutLeavesDuplication(t *testing.T) {
+	for _, n := range []int{3, 5, 13} {
+		data := dagLeaves(n)
+		root, err := NewDAG(NewMemoryObjectStore(), WithDuplication()).PutLeaves(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := mustBuildMerkleTree(t, data, WithDuplication()).Root(); !bytes.Equal(root, expected) {
+			t.Errorf("%d leaves: expected %x, got %x", n, expected, root)
+		}
+	}
+
+	if _, err := NewDAG(NewMemoryObjectStore(), WithSortedLeaves()).PutLeaves(dagLeaves(3)); err == nil {
+		t.Errorf("expected error for sorted leaves")
+	}
+}
+
+func TestDAG_PutNode(t *testing.T) {
+	d := NewDAG(NewMemoryObjectStore(), WithHashStrategy(hashStrategy))
+	a, err := d.PutLeaf([]byte("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := d.PutLeaf([]byte("b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root, err := d.PutNode(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}}, WithHashStrategy(hashStrategy)).Root()
+	if !bytes.Equal(root, expected) {
+		t.Errorf("expected %x, got %x", expected, root)
+	}
+
+	o, err := d.Get(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.IsLeaf() || !bytes.Equal(o.Left, a) || !bytes.Equal(o.Right, b) {
+		t.Errorf("expected a node of a and b")
+	}
+
+	if _, err := d.PutNode(a, []byte("missing")); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("expected %v, got %v", ErrObjectNotFound, err)
+	}
+	if _, err := d.Get([]byte("missing")); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("expected %v, got %v", ErrObjectNotFound, err)
+	}
+	if _, err := d.PutLeaves(nil); err == nil {
+		t.Errorf("expected error for no leaves")
+	}
+}
+
+func TestDAG_Fetch(t *testing.T) {
+	remote := NewMemoryObjectStore()
+	r := NewDAG(remote)
+	root, err := r.PutLeaves(dagLeaves(8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	top, err := r.Get(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// fetch only the left half
+	local := NewMemoryObjectStore()
+	d := NewDAG(local)
+	if err := d.Fetch(top.Left, remote); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if local.Len() != 7 {
+		t.Errorf("expected 7 objects, got %d", local.Len())
+	}
+	if ok, _ := d.Has(root); ok {
+		t.Errorf("expected the root not to be fetched")
+	}
+	count := 0
+	if err := d.Walk(top.Left, func([]byte) error { count++; return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 leaves, got %d", count)
+	}
+
+	// the rest, skipping the half that is already there
+	if err := d.Fetch(root, remote); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if local.Len() != remote.Len() {
+		t.Errorf("expected %d objects, got %d", remote.Len(), local.Len())
+	}
+}
+
+func TestDAG_FetchTampered(t *testing.T) {
+	remote := NewMemoryObjectStore()
+	r := NewDAG(remote)
+	root, err := r.PutLeaves(dagLeaves(4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	leaf := defaultHashStrategy{}.HashLeaf(dagLeaves(4)[3].Bytes())
+	if err := remote.Put(leaf, []byte{objectLeaf, 'x'}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	local := NewMemoryObjectStore()
+	if err := NewDAG(local).Fetch(root, remote); err == nil {
+		t.Errorf("expected error for tampered object")
+	}
+	if _, err := r.Get(leaf); err == nil {
+		t.Errorf("expected error for tampered object")
+	}
+	if ok, _ := NewDAG(local).Has(root); ok {
+		t.Errorf("expected the root not to be stored")
+	}
+}
+
+func TestDecodeObject(t *testing.T) {
+	o := &Object{Left: []byte("left"), Right: []byte("right!")}
+	decoded, err := decodeObject(o.encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(decoded.Left, o.Left) || !bytes.Equal(decoded.Right, o.Right) {
+		t.Errorf("expected %v, got %v", o, decoded)
+	}
+
+	for _, b := range [][]byte{nil, {2}, {objectNode}, {objectNode, 0, 'a'}, {objectNode, 2, 'a', 'b'}} {
+		if _, err := decodeObject(b); err == nil {
+			t.Errorf("expected error for %x", b)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gomerkletree
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Number of bytes of each hash shown in diagrams.
+const diagramHashBytes = 4
+
+// DOT writes a Graphviz diagram of the tree to w, labelling each node with its truncated hex hash.
+// With duplication, padded nodes are drawn with two edges to the same child.
+func (m *MerkleTree) DOT(w io.Writer) error {
+	dw := &diagramWriter{w: w}
+	dw.printf("digraph merkletree {\n")
+	dw.printf("\tnode [shape=box, fontname=monospace];\n")
+	m.diagram(
+		func(id int, n *Node) {
+			shape := ""
+			if n.left == nil {
+				shape = ", style=rounded"
+			}
+			dw.printf("\tn%d [label=\"%s\"%s];\n", id, diagramLabel(n), shape)
+		},
+		func(parent, child int) {
+			dw.printf("\tn%d -> n%d;\n", parent, child)
+		},
+	)
+	dw.printf("}\n")
+	return dw.err
+}
+
+// Mermaid writes a Mermaid flowchart of the tree to w, labelling each node with its truncated hex hash.
+// With duplication, padded nodes are drawn with two edges to the same child.
+func (m *MerkleTree) Mermaid(w io.Writer) error {
+	dw := &diagramWriter{w: w}
+	dw.printf("graph TD\n")
+	m.diagram(
+		func(id int, n *Node) {
+			if n.left == nil {
+				dw.printf("\tn%d([%s])\n", id, diagramLabel(n))
+			} else {
+				dw.printf("\tn%d[%s]\n", id, diagramLabel(n))
+			}
+		},
+		func(parent, child int) {
+			dw.printf("\tn%d --> n%d\n", parent, child)
+		},
+	)
+	return dw.err
+}
+
+// diagram calls node for every node in depth-first order, followed by edge for every parent-child edge.
+func (m *MerkleTree) diagram(node func(id int, n *Node), edge func(parent, child int)) {
+	ids := make(map[*Node]int)
+	var edges [][2]*Node
+	m.Walk(func(n *Node) bool {
+		ids[n] = len(ids)
+		node(ids[n], n)
+		if n.left != nil {
+			edges = append(edges, [2]*Node{n, n.left}, [2]*Node{n, n.right})
+		}
+		return true
+	})
+	for _, e := range edges {
+		edge(ids[e[0]], ids[e[1]])
+	}
+}
+
+func diagramLabel(n *Node) string {
+	h := n.h
+	if len(h) > diagramHashBytes {
+		return hex.EncodeToString(h[:diagramHashBytes]) + "..."
+	}
+	return hex.EncodeToString(h)
+}
+
+// diagramWriter keeps the first write error so diagrams can be written without checking every line.
+type diagramWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (d *diagramWriter) printf(format string, args ...any) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format, args...)
+}
@@ -0,0 +1,86 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTree_DOT(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	var buf bytes.Buffer
+	if err := tree.DOT(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "digraph merkletree {") || !strings.HasSuffix(out, "}\n") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	if n := strings.Count(out, "->"); n != tree.Len()-1 {
+		t.Errorf("expected %d edges, got %d", tree.Len()-1, n)
+	}
+
+	root := hex.EncodeToString(tree.Root()[:diagramHashBytes])
+	if !strings.Contains(out, "n0 [label=\""+root+"...\"];") {
+		t.Errorf("expected root label %s", root)
+	}
+}
+
+func TestTree_Mermaid(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data, WithDuplication())
+
+	var buf bytes.Buffer
+	if err := tree.Mermaid(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "graph TD\n") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	// the padded node has two edges to the same child
+	if n := strings.Count(out, "-->"); n != 6 {
+		t.Errorf("expected 6 edges, got %d", n)
+	}
+
+	if n := strings.Count(out, "(["); n != 3 {
+		t.Errorf("expected 3 leaves, got %d", n)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestTree_DOTWriteError(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	if err := tree.DOT(failingWriter{}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if err := tree.Mermaid(failingWriter{}); err == nil {
+		t.Errorf("expected error")
+	}
+}
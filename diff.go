@@ -0,0 +1,59 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"math/bits"
+)
+
+// Diff returns the indices of the leaves that differ between two trees, in ascending order.
+// Leaves that only exist in the larger tree count as different.
+// Both trees are walked top-down at once, skipping subtrees with identical hashes,
+// so finding k differences takes O(k log n) instead of comparing every leaf.
+func Diff(a, b *MerkleTree) []int {
+	na, nb := a.NumLeaves(), b.NumLeaves()
+	common := min(na, nb)
+
+	var diff []int
+	switch {
+	case common == 0:
+	case a.duplicate != b.duplicate || (a.duplicate && na != nb):
+		// the trees have different shapes, compare the leaves one by one
+		for i := range common {
+			if !bytes.Equal(a.leaves[i].h, b.leaves[i].h) {
+				diff = append(diff, i)
+			}
+		}
+	case a.duplicate:
+		width := 1 << bits.Len(uint(na-1))
+		diffNodes(a.root, b.root, 0, width, &diff)
+	default:
+		// with promotion, every aligned power of two span of leaves is a subtree in both trees
+		for lo := 0; lo < common; {
+			width := 1 << (bits.Len(uint(common-lo)) - 1)
+			x, _ := a.subtree(lo, lo+width)
+			y, _ := b.subtree(lo, lo+width)
+			diffNodes(x, y, lo, width, &diff)
+			lo += width
+		}
+	}
+
+	for i := common; i < max(na, nb); i++ {
+		diff = append(diff, i)
+	}
+	return diff
+}
+
+// diffNodes collects the differing leaves below two nodes with the same shape, spanning width leaves from lo.
+func diffNodes(x, y *Node, lo, width int, diff *[]int) {
+	if bytes.Equal(x.h, y.h) {
+		return
+	}
+	if x.left == nil {
+		*diff = append(*diff, lo)
+		return
+	}
+	diffNodes(x.left, y.left, lo, width/2, diff)
+	if x.right != x.left {
+		diffNodes(x.right, y.right, lo+width/2, width/2, diff)
+	}
+}
@@ -0,0 +1,87 @@
+package gomerkletree
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func diffData(n int) []Leaf {
+	var data []Leaf
+	for i := range n {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+	return data
+}
+
+func TestDiff(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithDuplication()}} {
+		for n := 1; n <= 17; n++ {
+			data := diffData(n)
+			a := mustBuildMerkleTree(t, data, opts...)
+
+			if diff := Diff(a, mustBuildMerkleTree(t, data, opts...)); len(diff) != 0 {
+				t.Errorf("n=%d: expected no differences, got %v", n, diff)
+			}
+
+			for i := 0; i < n; i += 3 {
+				changed := slices.Clone(data)
+				changed[i] = &TestLeaf{"x"}
+				if i+1 < n {
+					changed[i+1] = &TestLeaf{"y"}
+				}
+
+				expected := []int{i}
+				if i+1 < n {
+					expected = append(expected, i+1)
+				}
+
+				if diff := Diff(a, mustBuildMerkleTree(t, changed, opts...)); !slices.Equal(diff, expected) {
+					t.Errorf("n=%d: expected %v, got %v", n, expected, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestDiff_DifferentSizes(t *testing.T) {
+	data := diffData(11)
+	a := mustBuildMerkleTree(t, data[:6])
+	b := mustBuildMerkleTree(t, data)
+
+	if diff := Diff(a, b); !slices.Equal(diff, []int{6, 7, 8, 9, 10}) {
+		t.Errorf("expected [6 7 8 9 10], got %v", diff)
+	}
+
+	changed := slices.Clone(data)
+	changed[5] = &TestLeaf{"x"}
+	b = mustBuildMerkleTree(t, changed)
+
+	if diff := Diff(b, a); !slices.Equal(diff, []int{5, 6, 7, 8, 9, 10}) {
+		t.Errorf("expected [5 6 7 8 9 10], got %v", diff)
+	}
+
+	// different shapes
+	dup := mustBuildMerkleTree(t, data[:6], WithDuplication())
+	if diff := Diff(dup, b); !slices.Equal(diff, []int{5, 6, 7, 8, 9, 10}) {
+		t.Errorf("expected [5 6 7 8 9 10], got %v", diff)
+	}
+
+	if diff := Diff(nil, a); !slices.Equal(diff, []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("expected [0 1 2 3 4 5], got %v", diff)
+	}
+}
+
+func TestDiff_AfterUpdate(t *testing.T) {
+	data := diffData(9)
+	a := mustBuildMerkleTree(t, data, WithDuplication())
+	b := mustBuildMerkleTree(t, data, WithDuplication())
+
+	if err := b.Update(8, &TestLeaf{"x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := Diff(a, b); !slices.Equal(diff, []int{8}) {
+		t.Errorf("expected [8], got %v", diff)
+	}
+}
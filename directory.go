@@ -0,0 +1,168 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// FileEntry is a leaf for a file of a directory, committing to its path and the SHA-256 digest of its contents.
+// It is encoded as path length (uvarint) | path | digest.
+type FileEntry struct {
+	Path   string `json:"path"`
+	Digest []byte `json:"digest"`
+}
+
+func (f FileEntry) Bytes() []byte {
+	b := make([]byte, 0, binary.MaxVarintLen64+len(f.Path)+len(f.Digest))
+	b = binary.AppendUvarint(b, uint64(len(f.Path)))
+	b = append(b, f.Path...)
+	return append(b, f.Digest...)
+}
+
+// Manifest lists the files of a directory, sorted by path. Its root can be stored to later verify the directory.
+type Manifest struct {
+	Files []FileEntry `json:"files"`
+}
+
+// HashDirectory walks a directory and hashes every regular file into a manifest.
+// Paths are slash separated and relative to the root of fsys, e.g. os.DirFS(dir). Other files, like symlinks, are skipped.
+func HashDirectory(fsys fs.FS) (*Manifest, error) {
+	m := &Manifest{}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		digest, err := hashFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, FileEntry{Path: path, Digest: digest})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// WalkDir visits files in lexical order per directory, which differs from sorting by full path
+	slices.SortFunc(m.Files, func(a, b FileEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return m, nil
+}
+
+func hashFile(fsys fs.FS, path string) ([]byte, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Tree builds the merkle tree over the files of the manifest in O(n). Returns an error for an empty manifest.
+func (m *Manifest) Tree() (*MerkleTree, error) {
+	data := make([]Leaf, len(m.Files))
+	for i, f := range m.Files {
+		data[i] = f
+	}
+	return BuildMerkleTree(data)
+}
+
+// Root returns the root of the tree over the files of the manifest, or nil for an empty manifest.
+// It hashes every file entry in O(n), but doesn't keep the tree in memory.
+func (m *Manifest) Root() []byte {
+	h := NewHasher()
+	for _, f := range m.Files {
+		h.WriteLeaf(f.Bytes())
+	}
+	return h.Root()
+}
+
+// Proof generates a proof for the file with the given path. It builds the tree in O(n) for every call;
+// to generate many proofs, build the Tree once and use its ProofByIndex.
+func (m *Manifest) Proof(path string) (*Proof, error) {
+	i, ok := slices.BinarySearchFunc(m.Files, path, func(f FileEntry, path string) int {
+		return strings.Compare(f.Path, path)
+	})
+	if !ok {
+		return nil, errors.New("not in tree")
+	}
+	tree, err := m.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return tree.ProofByIndex(i)
+}
+
+// ChangeKind describes how a file changed.
+type ChangeKind int
+
+const (
+	FileAdded ChangeKind = iota
+	FileRemoved
+	FileModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case FileAdded:
+		return "added"
+	case FileRemoved:
+		return "removed"
+	case FileModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// FileChange is a file that differs between a manifest and a directory.
+type FileChange struct {
+	Path string
+	Kind ChangeKind
+}
+
+// VerifyDirectory checks a directory against a manifest whose root the verifier trusts,
+// and returns the files that were added, removed or modified since, sorted by path.
+// Returns an error if the manifest doesn't match the root.
+func VerifyDirectory(fsys fs.FS, m *Manifest, root []byte) ([]FileChange, error) {
+	if m == nil {
+		return nil, errors.New("nil manifest")
+	}
+	if !hashEqual(m.Root(), root) {
+		return nil, rootMismatch(m.Root(), root)
+	}
+
+	current, err := HashDirectory(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	old, cur := m.Files, current.Files
+	for len(old) > 0 || len(cur) > 0 {
+		switch {
+		case len(cur) == 0 || (len(old) > 0 && old[0].Path < cur[0].Path):
+			changes = append(changes, FileChange{old[0].Path, FileRemoved})
+			old = old[1:]
+		case len(old) == 0 || cur[0].Path < old[0].Path:
+			changes = append(changes, FileChange{cur[0].Path, FileAdded})
+			cur = cur[1:]
+		default:
+			if !bytes.Equal(old[0].Digest, cur[0].Digest) {
+				changes = append(changes, FileChange{cur[0].Path, FileModified})
+			}
+			old, cur = old[1:], cur[1:]
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,111 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func testDirectory() fstest.MapFS {
+	return fstest.MapFS{
+		"a.txt":       {Data: []byte("a")},
+		"a/b.txt":     {Data: []byte("b")},
+		"a/c/d.txt":   {Data: []byte("d")},
+		"z.txt":       {Data: []byte("z")},
+		"empty":       {Mode: fs.ModeDir},
+		"link":        {Data: []byte("z.txt"), Mode: fs.ModeSymlink},
+		"a/c/e/f.txt": {Data: []byte("f")},
+	}
+}
+
+func TestHashDirectory(t *testing.T) {
+	m, err := HashDirectory(testDirectory())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var paths []string
+	for _, f := range m.Files {
+		paths = append(paths, f.Path)
+	}
+
+	expected := []string{"a.txt", "a/b.txt", "a/c/d.txt", "a/c/e/f.txt", "z.txt"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+
+	digest := sha256.Sum256([]byte("b"))
+	if !slices.Equal(m.Files[1].Digest, digest[:]) {
+		t.Errorf("digest not correct")
+	}
+
+	proof, err := m.Proof("a/c/d.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(m.Files[2], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := m.Proof("missing.txt"); err == nil {
+		t.Errorf("expected error")
+	}
+
+	tree, err := m.Tree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(tree.Root(), m.Root()) || !bytes.Equal(tree.Root(), proof.Root()) {
+		t.Errorf("expected the root of the tree")
+	}
+
+	empty := &Manifest{}
+	if _, err := empty.Tree(); err == nil {
+		t.Errorf("expected error for empty manifest")
+	}
+	if empty.Root() != nil {
+		t.Errorf("expected nil root for empty manifest")
+	}
+}
+
+func TestVerifyDirectory(t *testing.T) {
+	dir := testDirectory()
+	m, err := HashDirectory(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := m.Root()
+
+	changes, err := VerifyDirectory(dir, m, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+
+	dir["a/b.txt"] = &fstest.MapFile{Data: []byte("changed")}
+	dir["new.txt"] = &fstest.MapFile{Data: []byte("new")}
+	delete(dir, "z.txt")
+
+	changes, err = VerifyDirectory(dir, m, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []FileChange{{"a/b.txt", FileModified}, {"new.txt", FileAdded}, {"z.txt", FileRemoved}}
+	if !slices.Equal(changes, expected) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+
+	// a tampered manifest doesn't match the stored root
+	m.Files[1].Digest = sha256.New().Sum(nil)
+	if _, err := VerifyDirectory(dir, m, root); err == nil {
+		t.Errorf("expected error")
+	}
+}
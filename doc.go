@@ -5,6 +5,9 @@
 // This library was implemented as a learning exercise into binary tree creation, Merkle trees,
 // roots and proofs, so it is not hardened for production, and unlike standard implementations
 // does not use duplication (which means standard proof verification methods likely won't work).
+// Promotion does however produce the same tree shape as RFC 6962 (Certificate Transparency),
+// so with the default hash strategy roots and proofs match those of CT logs (see BuildRFC6962MerkleTree).
+// Trees can also be built with duplication instead (see WithDuplication).
 //
 // Building the Merkle tree is O(n) (with n = #leaves; the total number of nodes is ~2n-1).
 // Proof size and verification are O(log n).
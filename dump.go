@@ -0,0 +1,91 @@
+package gomerkletree
+
+import (
+	"io"
+	"strings"
+)
+
+// Dump writes the tree to w, one node per line with its truncated hex hash, indented by level from the root down.
+// Leaves are numbered, and with duplication the padded copy of a node is marked as duplicate.
+func (m *MerkleTree) Dump(w io.Writer) error {
+	dw := &diagramWriter{w: w}
+	if m == nil || m.root == nil {
+		dw.printf("empty tree\n")
+		return dw.err
+	}
+	dw.printf("tree with %d leaves and %d nodes\n", len(m.leaves), m.n)
+	leaf := 0
+	dumpNode(dw, m.root, 1, &leaf)
+	return dw.err
+}
+
+// String returns the dump of the tree, see Dump.
+func (m *MerkleTree) String() string {
+	var b strings.Builder
+	m.Dump(&b)
+	return b.String()
+}
+
+// Dump writes the subtree under the node to w, like MerkleTree.Dump but without numbering the leaves.
+func (n *Node) Dump(w io.Writer) error {
+	dw := &diagramWriter{w: w}
+	if n == nil {
+		dw.printf("nil node\n")
+		return dw.err
+	}
+	dumpNode(dw, n, 0, nil)
+	return dw.err
+}
+
+// String returns the dump of the subtree under the node, see Dump.
+func (n *Node) String() string {
+	var b strings.Builder
+	n.Dump(&b)
+	return b.String()
+}
+
+// dumpNode writes n and its descendants at the given depth, numbering leaves from *leaf if it isn't nil.
+func dumpNode(dw *diagramWriter, n *Node, depth int, leaf *int) {
+	indent := strings.Repeat("  ", depth)
+	if n.left == nil {
+		if leaf != nil {
+			dw.printf("%sleaf %d %s\n", indent, *leaf, diagramLabel(n))
+			*leaf++
+		} else {
+			dw.printf("%sleaf %s\n", indent, diagramLabel(n))
+		}
+		return
+	}
+	dw.printf("%snode %s\n", indent, diagramLabel(n))
+	dumpNode(dw, n.left, depth+1, leaf)
+	if n.right == n.left {
+		dw.printf("%s  duplicate %s\n", indent, diagramLabel(n.right))
+	} else {
+		dumpNode(dw, n.right, depth+1, leaf)
+	}
+}
+
+// Dump writes the proof to w: its root, and its siblings from the leaf up with the side they are on.
+func (p *Proof) Dump(w io.Writer) error {
+	dw := &diagramWriter{w: w}
+	if p == nil {
+		dw.printf("nil proof\n")
+		return dw.err
+	}
+	dw.printf("proof with %d siblings for root %s\n", len(p.siblings), diagramLabel(&Node{h: p.root}))
+	for i, sibling := range p.siblings {
+		side := "right"
+		if i < len(p.left) && p.left[i] {
+			side = "left"
+		}
+		dw.printf("  %d %-5s %s\n", i, side, diagramLabel(&Node{h: sibling}))
+	}
+	return dw.err
+}
+
+// String returns the dump of the proof, see Dump.
+func (p *Proof) String() string {
+	var b strings.Builder
+	p.Dump(&b)
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package gomerkletree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTree_Dump(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tree := mustBuildMerkleTree(t, data)
+
+	label := func(i int) string { return diagramLabel(tree.leaves[i]) }
+	expected := "tree with 3 leaves and 5 nodes\n" +
+		"  node " + diagramLabel(tree.root) + "\n" +
+		"    node " + diagramLabel(tree.root.left) + "\n" +
+		"      leaf 0 " + label(0) + "\n" +
+		"      leaf 1 " + label(1) + "\n" +
+		"    leaf 2 " + label(2) + "\n"
+	if s := tree.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+	if s := fmt.Sprint(tree.root.left); s != "node "+diagramLabel(tree.root.left)+"\n  leaf "+label(0)+"\n  leaf "+label(1)+"\n" {
+		t.Errorf("unexpected node dump %q", s)
+	}
+
+	duplicated := mustBuildMerkleTree(t, data, WithDuplication())
+	expected = "tree with 3 leaves and 6 nodes\n" +
+		"  node " + diagramLabel(duplicated.root) + "\n" +
+		"    node " + diagramLabel(duplicated.root.left) + "\n" +
+		"      leaf 0 " + label(0) + "\n" +
+		"      leaf 1 " + label(1) + "\n" +
+		"    node " + diagramLabel(duplicated.root.right) + "\n" +
+		"      leaf 2 " + label(2) + "\n" +
+		"      duplicate " + label(2) + "\n"
+	if s := duplicated.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	var nilTree *MerkleTree
+	var nilNode *Node
+	if nilTree.String() != "empty tree\n" || nilNode.String() != "nil node\n" {
+		t.Errorf("unexpected dumps of nil")
+	}
+
+	if err := tree.Dump(failingWriter{}); err == nil {
+		t.Errorf("expected write error")
+	}
+}
+
+func TestProof_Dump(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.ProofByIndex(1)
+
+	expected := "proof with 2 siblings for root " + diagramLabel(tree.root) + "\n" +
+		"  0 left  " + diagramLabel(tree.leaves[0]) + "\n" +
+		"  1 right " + diagramLabel(tree.leaves[2]) + "\n"
+	if s := proof.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	var nilProof *Proof
+	if nilProof.String() != "nil proof\n" {
+		t.Errorf("unexpected dump of nil proof")
+	}
+}
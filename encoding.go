@@ -0,0 +1,1190 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/bits"
+)
+
+const (
+	proofEncodingVersion       = 1
+	treeEncodingVersion        = 1
+	rangeProofEncodingVersion  = 1
+	sumProofEncodingVersion    = 1
+	sparseProofEncodingVersion = 1
+	mapProofEncodingVersion    = 1
+	karyProofEncodingVersion   = 1
+)
+
+const (
+	treeFlagDuplicate = 1 << iota
+	treeFlagSorted
+	treeFlagSortedPairs
+	treeFlagDedup
+)
+
+// MarshalBinary encodes the proof as
+// version (1 byte) | root length (uvarint) | root | sibling count (uvarint) | (sibling length (uvarint) | sibling)... | direction bits,
+// where the direction bits are packed least significant bit first, with a set bit meaning the sibling is a left child.
+// The hash strategy is not part of the encoding.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if len(p.siblings) != len(p.left) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	b := []byte{proofEncodingVersion}
+	b = appendBytes(b, p.root)
+	b = appendHashes(b, p.siblings)
+	return appendDirections(b, p.left), nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != proofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	siblings := d.hashes()
+	left := d.directions(len(siblings))
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+
+	*p = Proof{
+		root:         root,
+		siblings:     siblings,
+		left:         left,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type proofJSON struct {
+	Root       string   `json:"root"`
+	Siblings   []string `json:"siblings"`
+	Directions []string `json:"directions"`
+}
+
+const (
+	directionLeft  = "left"
+	directionRight = "right"
+)
+
+// MarshalJSON encodes the proof as {"root": "...", "siblings": [...], "directions": [...]},
+// with hex-encoded hashes and every direction being either "left" or "right" (the side the sibling is on).
+// The hash strategy is not part of the encoding.
+func (p *Proof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	if len(p.siblings) != len(p.left) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	return json.Marshal(proofJSON{
+		Root:       hex.EncodeToString(p.root),
+		Siblings:   hexHashes(p.siblings),
+		Directions: directionStrings(p.left),
+	})
+}
+
+// UnmarshalJSON decodes a proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v proofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.Siblings) != len(v.Directions) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	root, err := hex.DecodeString(v.Root)
+	if err != nil {
+		return err
+	}
+	siblings, err := parseHexHashes(v.Siblings)
+	if err != nil {
+		return err
+	}
+	left, err := parseDirections(v.Directions)
+	if err != nil {
+		return err
+	}
+
+	*p = Proof{
+		root:         root,
+		siblings:     siblings,
+		left:         left,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type consistencyProofJSON struct {
+	OldSize int      `json:"oldSize"`
+	NewSize int      `json:"newSize"`
+	Hashes  []string `json:"hashes"`
+}
+
+// MarshalJSON encodes the consistency proof as {"oldSize": ..., "newSize": ..., "hashes": [...]}, with hex-encoded hashes.
+// The hash strategy is not part of the encoding.
+func (p *ConsistencyProof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(consistencyProofJSON{
+		OldSize: p.oldSize,
+		NewSize: p.newSize,
+		Hashes:  hexHashes(p.hashes),
+	})
+}
+
+// UnmarshalJSON decodes a consistency proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *ConsistencyProof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v consistencyProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.OldSize <= 0 || v.OldSize > v.NewSize {
+		return errors.New("invalid tree sizes")
+	}
+
+	hashes, err := parseHexHashes(v.Hashes)
+	if err != nil {
+		return err
+	}
+
+	*p = ConsistencyProof{
+		oldSize:      v.OldSize,
+		newSize:      v.NewSize,
+		hashes:       hashes,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// MarshalBinary encodes the range proof as
+// version (1 byte) | root length (uvarint) | root | size (uvarint) | start (uvarint) | end (uvarint) |
+// hash count (uvarint) | (hash length (uvarint) | hash)....
+// The hash strategy is not part of the encoding.
+func (p *RangeProof) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+
+	b := []byte{rangeProofEncodingVersion}
+	b = appendBytes(b, p.root)
+	b = binary.AppendUvarint(b, uint64(p.size))
+	b = binary.AppendUvarint(b, uint64(p.start))
+	b = binary.AppendUvarint(b, uint64(p.end))
+	return appendHashes(b, p.hashes), nil
+}
+
+// UnmarshalBinary decodes a range proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *RangeProof) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != rangeProofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	size := d.size()
+	start := d.size()
+	end := d.size()
+	hashes := d.hashes()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+	if size > maxTreeSize {
+		return errors.New("invalid tree size")
+	}
+	if start >= end || end > size {
+		return errors.New("index out of range")
+	}
+
+	*p = RangeProof{
+		root:         root,
+		size:         size,
+		start:        start,
+		end:          end,
+		hashes:       hashes,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type rangeProofJSON struct {
+	Root   string   `json:"root"`
+	Size   int      `json:"size"`
+	Start  int      `json:"start"`
+	End    int      `json:"end"`
+	Hashes []string `json:"hashes"`
+}
+
+// MarshalJSON encodes the range proof as {"root": "...", "size": ..., "start": ..., "end": ..., "hashes": [...]},
+// with hex-encoded hashes. The hash strategy is not part of the encoding.
+func (p *RangeProof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(rangeProofJSON{
+		Root:   hex.EncodeToString(p.root),
+		Size:   p.size,
+		Start:  p.start,
+		End:    p.end,
+		Hashes: hexHashes(p.hashes),
+	})
+}
+
+// UnmarshalJSON decodes a range proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *RangeProof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v rangeProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Size > maxTreeSize {
+		return errors.New("invalid tree size")
+	}
+	if v.Start < 0 || v.Start >= v.End || v.End > v.Size {
+		return errors.New("index out of range")
+	}
+	root, err := hex.DecodeString(v.Root)
+	if err != nil {
+		return err
+	}
+	hashes, err := parseHexHashes(v.Hashes)
+	if err != nil {
+		return err
+	}
+
+	*p = RangeProof{
+		root:         root,
+		size:         v.Size,
+		start:        v.Start,
+		end:          v.End,
+		hashes:       hashes,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// MarshalBinary encodes the sum proof as
+// version (1 byte) | root length (uvarint) | root | sum (8 bytes) | sibling count (uvarint) |
+// (sibling length (uvarint) | sibling | sibling sum (8 bytes))... | direction bits,
+// with the sums in big endian and the direction bits packed like in Proof.MarshalBinary.
+// The hash strategy is not part of the encoding.
+func (p *SumProof) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if len(p.siblings) != len(p.left) || len(p.sums) != len(p.left) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	b := []byte{sumProofEncodingVersion}
+	b = appendBytes(b, p.root)
+	b = binary.BigEndian.AppendUint64(b, p.sum)
+	b = binary.AppendUvarint(b, uint64(len(p.siblings)))
+	for i, sibling := range p.siblings {
+		b = appendBytes(b, sibling)
+		b = binary.BigEndian.AppendUint64(b, p.sums[i])
+	}
+	return appendDirections(b, p.left), nil
+}
+
+// UnmarshalBinary decodes a sum proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *SumProof) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != sumProofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	sum := d.uint64()
+	n := d.length()
+	siblings := make([][]byte, 0, n)
+	sums := make([]uint64, 0, n)
+	for range n {
+		siblings = append(siblings, d.bytes())
+		sums = append(sums, d.uint64())
+	}
+	left := d.directions(n)
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+
+	*p = SumProof{
+		root:         root,
+		sum:          sum,
+		siblings:     siblings,
+		sums:         sums,
+		left:         left,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type sumProofJSON struct {
+	Root       string   `json:"root"`
+	Sum        uint64   `json:"sum"`
+	Siblings   []string `json:"siblings"`
+	Sums       []uint64 `json:"sums"`
+	Directions []string `json:"directions"`
+}
+
+// MarshalJSON encodes the sum proof as {"root": "...", "sum": ..., "siblings": [...], "sums": [...], "directions": [...]},
+// with hex-encoded hashes and directions like in Proof.MarshalJSON. The hash strategy is not part of the encoding.
+func (p *SumProof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	if len(p.siblings) != len(p.left) || len(p.sums) != len(p.left) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+	return json.Marshal(sumProofJSON{
+		Root:       hex.EncodeToString(p.root),
+		Sum:        p.sum,
+		Siblings:   hexHashes(p.siblings),
+		Sums:       p.sums,
+		Directions: directionStrings(p.left),
+	})
+}
+
+// UnmarshalJSON decodes a sum proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *SumProof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v sumProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.Siblings) != len(v.Directions) || len(v.Sums) != len(v.Directions) {
+		return errors.New("proof lengths mismatch")
+	}
+	root, err := hex.DecodeString(v.Root)
+	if err != nil {
+		return err
+	}
+	siblings, err := parseHexHashes(v.Siblings)
+	if err != nil {
+		return err
+	}
+	left, err := parseDirections(v.Directions)
+	if err != nil {
+		return err
+	}
+
+	*p = SumProof{
+		root:         root,
+		sum:          v.Sum,
+		siblings:     siblings,
+		sums:         v.Sums,
+		left:         left,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// MarshalBinary encodes the sparse proof as
+// version (1 byte) | root length (uvarint) | root | bitmap (SparseKeySize bytes) | sibling count (uvarint) |
+// (sibling length (uvarint) | sibling)....
+// The hash strategy is not part of the encoding.
+func (p *SparseProof) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if len(p.bitmap) != SparseKeySize {
+		return nil, errors.New("invalid bitmap")
+	}
+
+	b := []byte{sparseProofEncodingVersion}
+	b = appendBytes(b, p.root)
+	b = append(b, p.bitmap...)
+	return appendHashes(b, p.siblings), nil
+}
+
+// UnmarshalBinary decodes a sparse proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *SparseProof) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != sparseProofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	bitmap := bytes.Clone(d.next(SparseKeySize))
+	siblings := d.hashes()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+	if sparseBitmapCount(bitmap) != len(siblings) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	*p = SparseProof{
+		root:         root,
+		bitmap:       bitmap,
+		siblings:     siblings,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type sparseProofJSON struct {
+	Root     string   `json:"root"`
+	Bitmap   string   `json:"bitmap"`
+	Siblings []string `json:"siblings"`
+}
+
+// MarshalJSON encodes the sparse proof as {"root": "...", "bitmap": "...", "siblings": [...]}, with the hashes and the
+// bitmap hex-encoded. The hash strategy is not part of the encoding.
+func (p *SparseProof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(sparseProofJSON{
+		Root:     hex.EncodeToString(p.root),
+		Bitmap:   hex.EncodeToString(p.bitmap),
+		Siblings: hexHashes(p.siblings),
+	})
+}
+
+// UnmarshalJSON decodes a sparse proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *SparseProof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v sparseProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(v.Root)
+	if err != nil {
+		return err
+	}
+	bitmap, err := hex.DecodeString(v.Bitmap)
+	if err != nil {
+		return err
+	}
+	if len(bitmap) != SparseKeySize {
+		return errors.New("invalid bitmap")
+	}
+	siblings, err := parseHexHashes(v.Siblings)
+	if err != nil {
+		return err
+	}
+	if sparseBitmapCount(bitmap) != len(siblings) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	*p = SparseProof{
+		root:         root,
+		bitmap:       bitmap,
+		siblings:     siblings,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// MarshalBinary encodes the map proof as
+// version (1 byte) | root length (uvarint) | root | size (uvarint) | index (uvarint) | found (1 byte) |
+// entry count (uvarint) | (key length (uvarint) | key | value length (uvarint) | value | sibling count (uvarint) |
+// (sibling length (uvarint) | sibling)... | direction bits)...,
+// with the inclusion proof of every entry encoded without its root, which is the root of the map proof.
+// The hash strategy is not part of the encoding.
+func (p *MapProof) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if len(p.entries) != len(p.proofs) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	b := []byte{mapProofEncodingVersion}
+	b = appendBytes(b, p.root)
+	b = binary.AppendUvarint(b, uint64(p.size))
+	b = binary.AppendUvarint(b, uint64(p.index))
+	if p.found {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = binary.AppendUvarint(b, uint64(len(p.entries)))
+	for i, e := range p.entries {
+		proof := p.proofs[i]
+		if proof == nil || len(proof.siblings) != len(proof.left) {
+			return nil, errors.New("invalid proof")
+		}
+		b = appendBytes(b, []byte(e.Key))
+		b = appendBytes(b, e.Value)
+		b = appendHashes(b, proof.siblings)
+		b = appendDirections(b, proof.left)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a map proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *MapProof) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != mapProofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	size := d.size()
+	index := d.size()
+	found := d.byte()
+	if d.err == nil && found > 1 {
+		return errors.New("invalid found flag")
+	}
+	n := d.length()
+	entries := make([]MapEntry, 0, n)
+	proofs := make([]*Proof, 0, n)
+	for range n {
+		key := d.bytes()
+		value := d.bytes()
+		siblings := d.hashes()
+		left := d.directions(len(siblings))
+		if d.err != nil {
+			return d.err
+		}
+		entries = append(entries, MapEntry{Key: string(key), Value: value})
+		proofs = append(proofs, &Proof{root: root, siblings: siblings, left: left, hashStrategy: defaultHashStrategy{}})
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+
+	*p = MapProof{
+		root:         root,
+		size:         size,
+		index:        index,
+		found:        found == 1,
+		entries:      entries,
+		proofs:       proofs,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type mapProofJSON struct {
+	Root    string              `json:"root"`
+	Size    int                 `json:"size"`
+	Index   int                 `json:"index"`
+	Found   bool                `json:"found"`
+	Entries []mapProofEntryJSON `json:"entries"`
+}
+
+type mapProofEntryJSON struct {
+	Key        string   `json:"key"`
+	Value      string   `json:"value"`
+	Siblings   []string `json:"siblings"`
+	Directions []string `json:"directions"`
+}
+
+// MarshalJSON encodes the map proof as
+// {"root": "...", "size": ..., "index": ..., "found": ..., "entries": [{"key": "...", "value": "...", "siblings": [...], "directions": [...]}, ...]},
+// with hex-encoded values and hashes, and the inclusion proof of every entry like in Proof.MarshalJSON, without its root.
+// The hash strategy is not part of the encoding.
+func (p *MapProof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	if len(p.entries) != len(p.proofs) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	v := mapProofJSON{
+		Root:    hex.EncodeToString(p.root),
+		Size:    p.size,
+		Index:   p.index,
+		Found:   p.found,
+		Entries: make([]mapProofEntryJSON, len(p.entries)),
+	}
+	for i, e := range p.entries {
+		proof := p.proofs[i]
+		if proof == nil || len(proof.siblings) != len(proof.left) {
+			return nil, errors.New("invalid proof")
+		}
+		v.Entries[i] = mapProofEntryJSON{
+			Key:        e.Key,
+			Value:      hex.EncodeToString(e.Value),
+			Siblings:   hexHashes(proof.siblings),
+			Directions: directionStrings(proof.left),
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a map proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *MapProof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v mapProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(v.Root)
+	if err != nil {
+		return err
+	}
+	entries := make([]MapEntry, len(v.Entries))
+	proofs := make([]*Proof, len(v.Entries))
+	for i, e := range v.Entries {
+		if len(e.Siblings) != len(e.Directions) {
+			return errors.New("proof lengths mismatch")
+		}
+		value, err := hex.DecodeString(e.Value)
+		if err != nil {
+			return err
+		}
+		siblings, err := parseHexHashes(e.Siblings)
+		if err != nil {
+			return err
+		}
+		left, err := parseDirections(e.Directions)
+		if err != nil {
+			return err
+		}
+		entries[i] = MapEntry{Key: e.Key, Value: value}
+		proofs[i] = &Proof{root: root, siblings: siblings, left: left, hashStrategy: defaultHashStrategy{}}
+	}
+
+	*p = MapProof{
+		root:         root,
+		size:         v.Size,
+		index:        v.Index,
+		found:        v.Found,
+		entries:      entries,
+		proofs:       proofs,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// MarshalBinary encodes the k-ary proof as
+// version (1 byte) | root length (uvarint) | root | level count (uvarint) |
+// (position (uvarint) | sibling count (uvarint) | (sibling length (uvarint) | sibling)...)....
+// The hash strategy is not part of the encoding.
+func (p *KaryProof) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if len(p.siblings) != len(p.positions) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	b := []byte{karyProofEncodingVersion}
+	b = appendBytes(b, p.root)
+	b = binary.AppendUvarint(b, uint64(len(p.siblings)))
+	for i, siblings := range p.siblings {
+		if p.positions[i] < 0 {
+			return nil, errors.New("invalid position")
+		}
+		b = binary.AppendUvarint(b, uint64(p.positions[i]))
+		b = appendHashes(b, siblings)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a k-ary proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *KaryProof) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != karyProofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	n := d.length()
+	siblings := make([][][]byte, 0, n)
+	positions := make([]int, 0, n)
+	for range n {
+		positions = append(positions, d.size())
+		siblings = append(siblings, d.hashes())
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+
+	*p = KaryProof{
+		root:         root,
+		siblings:     siblings,
+		positions:    positions,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+type karyProofJSON struct {
+	Root   string               `json:"root"`
+	Levels []karyProofLevelJSON `json:"levels"`
+}
+
+type karyProofLevelJSON struct {
+	Position int      `json:"position"`
+	Siblings []string `json:"siblings"`
+}
+
+// MarshalJSON encodes the k-ary proof as {"root": "...", "levels": [{"position": ..., "siblings": [...]}, ...]},
+// with hex-encoded hashes and the levels from the leaf up. The hash strategy is not part of the encoding.
+func (p *KaryProof) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	if len(p.siblings) != len(p.positions) {
+		return nil, errors.New("proof lengths mismatch")
+	}
+
+	v := karyProofJSON{
+		Root:   hex.EncodeToString(p.root),
+		Levels: make([]karyProofLevelJSON, len(p.siblings)),
+	}
+	for i, siblings := range p.siblings {
+		v.Levels[i] = karyProofLevelJSON{Position: p.positions[i], Siblings: hexHashes(siblings)}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a k-ary proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (p *KaryProof) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+
+	var v karyProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(v.Root)
+	if err != nil {
+		return err
+	}
+	siblings := make([][][]byte, len(v.Levels))
+	positions := make([]int, len(v.Levels))
+	for i, level := range v.Levels {
+		if siblings[i], err = parseHexHashes(level.Siblings); err != nil {
+			return err
+		}
+		positions[i] = level.Position
+	}
+
+	*p = KaryProof{
+		root:         root,
+		siblings:     siblings,
+		positions:    positions,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
+
+// sparseBitmapCount returns the number of siblings included according to the bitmap of a sparse proof.
+func sparseBitmapCount(bitmap []byte) int {
+	n := 0
+	for _, b := range bitmap {
+		n += bits.OnesCount8(b)
+	}
+	return n
+}
+
+// MarshalBinary encodes the tree, including all internal hashes, so it can be restored without rehashing as
+// version (1 byte) | flags (1 byte) | leaf count (uvarint) | (hash length (uvarint) | hash)...,
+// where the hashes are ordered like they are built: first the leaves, then the new nodes of every level.
+// The hash strategy is not part of the encoding, besides whether pairs are sorted.
+func (m *MerkleTree) MarshalBinary() ([]byte, error) {
+	if m == nil || m.root == nil {
+		return nil, errors.New("nil tree")
+	}
+
+	var flags byte
+	if m.duplicate {
+		flags |= treeFlagDuplicate
+	}
+	if m.sorted {
+		flags |= treeFlagSorted
+	}
+	if m.dedup {
+		flags |= treeFlagDedup
+	}
+	if _, ok := m.hashStrategy.(SortedPairHashStrategy); ok {
+		flags |= treeFlagSortedPairs
+	}
+
+	b := []byte{treeEncodingVersion, flags}
+	b = binary.AppendUvarint(b, uint64(len(m.leaves)))
+
+	level := m.leaves
+	for _, l := range level {
+		b = appendBytes(b, l.h)
+	}
+	for len(level) > 1 {
+		next := make([]*Node, 0, (len(level)+1)/2)
+		for i := range len(level) / 2 {
+			next = append(next, level[2*i].parent)
+		}
+		if last := level[len(level)-1]; len(level)%2 != 0 && m.duplicate {
+			next = append(next, last.parent)
+		} else if len(level)%2 != 0 {
+			next = append(next, last)
+		}
+
+		for i := range len(level) / 2 {
+			b = appendBytes(b, next[i].h)
+		}
+		if len(level)%2 != 0 && m.duplicate {
+			b = appendBytes(b, next[len(next)-1].h)
+		}
+		level = next
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a tree encoded by MarshalBinary, using the default hash strategy.
+// The hashes are not recomputed; use Verify to check the integrity of the decoded tree.
+func (m *MerkleTree) UnmarshalBinary(data []byte) error {
+	if m == nil {
+		return errors.New("nil tree")
+	}
+	decoded, err := DecodeMerkleTree(data)
+	if err != nil {
+		return err
+	}
+	*m = *decoded
+	return nil
+}
+
+// DecodeMerkleTree decodes a tree encoded by MarshalBinary, using the hash strategy set by the options.
+// Options that change the shape of the tree are ignored, the encoding determines the shape.
+// The hashes are not recomputed; use Verify to check the integrity of the decoded tree.
+func DecodeMerkleTree(data []byte, opts ...Option) (*MerkleTree, error) {
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != treeEncodingVersion {
+		return nil, errors.New("unsupported encoding version")
+	}
+	flags := d.byte()
+	n := d.length()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if n == 0 {
+		return nil, errors.New("no leaves")
+	}
+
+	cfg := newConfig(opts)
+	cfg.duplicate = flags&treeFlagDuplicate != 0
+	cfg.sorted = flags&treeFlagSorted != 0
+	cfg.dedup = flags&treeFlagDedup != 0
+	if _, ok := cfg.hashStrategy.(SortedPairHashStrategy); flags&treeFlagSortedPairs != 0 && !ok {
+		cfg.hashStrategy = SortedPairHashStrategy{cfg.hashStrategy}
+	}
+
+	leaves := make([]*Node, n)
+	index := make(map[string][]int, n)
+	for i := range leaves {
+		leaves[i] = &Node{
+			h: d.bytes(),
+		}
+		index[string(leaves[i].h)] = append(index[string(leaves[i].h)], i)
+	}
+
+	level := leaves
+	for len(level) > 1 && d.err == nil {
+		next := make([]*Node, 0, (len(level)+1)/2)
+		for i := range len(level) / 2 {
+			next = append(next, link(level[2*i], level[2*i+1], d.bytes()))
+		}
+		if last := level[len(level)-1]; len(level)%2 != 0 && cfg.duplicate {
+			next = append(next, link(last, last, d.bytes()))
+		} else if len(level)%2 != 0 {
+			next = append(next, last)
+		}
+		level = next
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.b) != 0 {
+		return nil, errors.New("trailing data")
+	}
+
+	return &MerkleTree{
+		root:         level[0],
+		n:            nodeCount(n, cfg.duplicate),
+		leaves:       leaves,
+		index:        index,
+		hashStrategy: cfg.hashStrategy,
+		duplicate:    cfg.duplicate,
+		sorted:       cfg.sorted,
+		dedup:        cfg.dedup,
+		trusted:      cfg.trusted,
+		constantTime: cfg.constantTime,
+		history:      cfg.history,
+	}, nil
+}
+
+func appendBytes(b, x []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(x)))
+	return append(b, x...)
+}
+
+// appendHashes appends a count followed by the length-prefixed hashes, which decoder.hashes reads.
+func appendHashes(b []byte, hashes [][]byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(hashes)))
+	for _, h := range hashes {
+		b = appendBytes(b, h)
+	}
+	return b
+}
+
+// appendDirections appends the directions as bits, packed least significant bit first, with a set bit meaning
+// the sibling is a left child.
+func appendDirections(b []byte, left []bool) []byte {
+	bits := make([]byte, (len(left)+7)/8)
+	for i, isLeft := range left {
+		if isLeft {
+			bits[i/8] |= 1 << (i % 8)
+		}
+	}
+	return append(b, bits...)
+}
+
+func directionStrings(left []bool) []string {
+	s := make([]string, len(left))
+	for i, isLeft := range left {
+		if isLeft {
+			s[i] = directionLeft
+		} else {
+			s[i] = directionRight
+		}
+	}
+	return s
+}
+
+func parseDirections(s []string) ([]bool, error) {
+	left := make([]bool, len(s))
+	for i, direction := range s {
+		switch direction {
+		case directionLeft:
+			left[i] = true
+		case directionRight:
+		default:
+			return nil, errors.New("invalid direction")
+		}
+	}
+	return left, nil
+}
+
+func hexHashes(hashes [][]byte) []string {
+	s := make([]string, len(hashes))
+	for i, h := range hashes {
+		s[i] = hex.EncodeToString(h)
+	}
+	return s
+}
+
+func parseHexHashes(s []string) ([][]byte, error) {
+	hashes := make([][]byte, len(s))
+	for i, h := range s {
+		var err error
+		if hashes[i], err = hex.DecodeString(h); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// decoder reads length-prefixed values from a byte slice, remembering the first error it encounters.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.b) {
+		d.err = errors.New("unexpected end of data")
+		return nil
+	}
+	x := d.b[:n:n]
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *decoder) byte() byte {
+	x := d.next(1)
+	if x == nil {
+		return 0
+	}
+	return x[0]
+}
+
+// length reads a uvarint that has to fit in the remaining data, so corrupt input can't cause huge allocations.
+func (d *decoder) length() int {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errors.New("invalid length")
+		return 0
+	}
+	d.b = d.b[n:]
+	if x > uint64(len(d.b)) {
+		d.err = errors.New("unexpected end of data")
+		return 0
+	}
+	return int(x)
+}
+
+// directions reads n directions packed by appendDirections.
+func (d *decoder) directions(n int) []bool {
+	bits := d.next((n + 7) / 8)
+	if d.err != nil {
+		return nil
+	}
+	left := make([]bool, n)
+	for i := range left {
+		left[i] = bits[i/8]&(1<<(i%8)) != 0
+	}
+	return left
+}
+
+// uvarint reads a uvarint that isn't bounded by the remaining data, like the size of a tree.
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errors.New("invalid uvarint")
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+// size reads a uvarint that has to fit in an int, like the size of a tree or an index of a leaf.
+func (d *decoder) size() int {
+	x := d.uvarint()
+	if d.err == nil && x > math.MaxInt {
+		d.err = errors.New("size out of range")
+		return 0
+	}
+	return int(x)
+}
+
+// index reads a uvarint that has to be below n, like an index into a slice of length n.
+func (d *decoder) index(n int) int {
+	x := d.uvarint()
+	if d.err == nil && x >= uint64(n) {
+		d.err = errors.New("index out of range")
+		return 0
+	}
+	return int(x)
+}
+
+// hashes reads a count followed by that many length-prefixed hashes.
+func (d *decoder) hashes() [][]byte {
+	n := d.length()
+	hashes := make([][]byte, 0, n)
+	for range n {
+		hashes = append(hashes, d.bytes())
+	}
+	return hashes
+}
+
+func (d *decoder) bytes() []byte {
+	return append([]byte(nil), d.next(d.length())...)
+}
+
+func (d *decoder) uint16() int {
+	x := d.next(2)
+	if x == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(x))
+}
+
+func (d *decoder) uint64() uint64 {
+	x := d.next(8)
+	if x == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(x)
+}
@@ -0,0 +1,318 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestProof_MarshalBinary(t *testing.T) {
+	var data []Leaf
+	for i := range 11 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for _, x := range data {
+		proof, err := tree.Proof(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		b, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var decoded Proof
+		if err := decoded.UnmarshalBinary(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(proof.root, decoded.root) {
+			t.Errorf("root not correct")
+		}
+
+		if len(proof.siblings) != len(decoded.siblings) || len(proof.left) != len(decoded.left) {
+			t.Fatalf("expected %d siblings, got %d", len(proof.siblings), len(decoded.siblings))
+		}
+
+		for i := range proof.siblings {
+			if !bytes.Equal(proof.siblings[i], decoded.siblings[i]) {
+				t.Errorf("sibling %d not correct", i)
+			}
+			if proof.left[i] != decoded.left[i] {
+				t.Errorf("direction %d not correct", i)
+			}
+		}
+
+		if err := VerifyProof(x, &decoded); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestProof_UnmarshalBinary(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.Proof(data[1])
+	b, _ := proof.MarshalBinary()
+
+	var decoded Proof
+
+	// truncated
+	for i := range b {
+		if err := decoded.UnmarshalBinary(b[:i]); err == nil {
+			t.Errorf("expected err for %d bytes, got nil", i)
+		}
+	}
+
+	// trailing data
+	if err := decoded.UnmarshalBinary(append(b, 0)); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// unknown version
+	b[0] = 0xff
+	err := decoded.UnmarshalBinary(b)
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	if err.Error() != "unsupported encoding version" {
+		t.Errorf("expected unsupported version, got %s", err.Error())
+	}
+
+	// absurd sibling count
+	if err := decoded.UnmarshalBinary([]byte{proofEncodingVersion, 0, 0xff, 0xff, 0xff, 0xff, 0x0f}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestProof_MarshalJSON(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.Proof(data[1])
+
+	b, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	leftLeft := hashStrategy.HashLeaf(data[0].Bytes())
+	right := hashStrategy.HashLeaf(data[2].Bytes())
+	expected := `{"root":"` + hex.EncodeToString(tree.Root()) + `",` +
+		`"siblings":["` + hex.EncodeToString(leftLeft) + `","` + hex.EncodeToString(right) + `"],` +
+		`"directions":["left","right"]}`
+
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, string(b))
+	}
+
+	var decoded Proof
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(data[1], &decoded); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(data[0], &decoded); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestProof_UnmarshalJSON(t *testing.T) {
+	tests := []string{
+		`{"root":"zz","siblings":[],"directions":[]}`,
+		`{"root":"00","siblings":["zz"],"directions":["left"]}`,
+		`{"root":"00","siblings":["00"],"directions":["up"]}`,
+		`{"root":"00","siblings":["00"],"directions":[]}`,
+		`[]`,
+	}
+
+	for _, test := range tests {
+		var decoded Proof
+		if err := json.Unmarshal([]byte(test), &decoded); err == nil {
+			t.Errorf("expected err for %s, got nil", test)
+		}
+	}
+}
+
+func TestConsistencyProof_MarshalJSON(t *testing.T) {
+	data := rfc6962Data(t)
+	tree, err := BuildRFC6962MerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	proof, err := tree.ConsistencyProof(2, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"oldSize":2,"newSize":5,"hashes":[` +
+		`"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",` +
+		`"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b"]}`
+
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, string(b))
+	}
+
+	var decoded ConsistencyProof
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oldTree, err := BuildRFC6962MerkleTree(data[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	newTree, err := BuildRFC6962MerkleTree(data[:5])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyConsistency(oldTree.Root(), newTree.Root(), &decoded); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConsistencyProof_UnmarshalJSON(t *testing.T) {
+	tests := []string{
+		`{"oldSize":0,"newSize":5,"hashes":[]}`,
+		`{"oldSize":6,"newSize":5,"hashes":[]}`,
+		`{"oldSize":2,"newSize":5,"hashes":["zz"]}`,
+		`[]`,
+	}
+
+	for _, test := range tests {
+		var decoded ConsistencyProof
+		if err := json.Unmarshal([]byte(test), &decoded); err == nil {
+			t.Errorf("expected err for %s, got nil", test)
+		}
+	}
+}
+
+func TestTree_MarshalBinary(t *testing.T) {
+	var data []Leaf
+	for i := range 13 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	for _, opts := range [][]Option{nil, {WithDuplication()}, {WithSortedLeaves(), WithSortedPairs()}} {
+		tree := mustBuildMerkleTree(t, data, opts...)
+
+		b, err := tree.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var decoded MerkleTree
+		if err := decoded.UnmarshalBinary(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(tree.Root(), decoded.Root()) {
+			t.Errorf("root not correct")
+		}
+
+		if tree.Len() != decoded.Len() {
+			t.Errorf("expected len=%d, got %d", tree.Len(), decoded.Len())
+		}
+
+		if !decoded.Verify() {
+			t.Fatalf("couldn't verify decoded tree")
+		}
+
+		for _, x := range data {
+			proof, err := decoded.Proof(x)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := VerifyProofAgainstRoot(x, proof, tree.Root()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		// decoded trees keep working
+		if decoded.sorted {
+			continue
+		}
+
+		if err := decoded.Append(&TestLeaf{"z"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(decoded.Root(), mustBuildMerkleTree(t, append(data, &TestLeaf{"z"}), opts...).Root()) {
+			t.Errorf("root not correct after append")
+		}
+	}
+}
+
+func TestTree_UnmarshalBinary(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data, WithHashStrategy(Keccak256HashStrategy{}))
+	b, _ := tree.MarshalBinary()
+
+	// custom hash strategies have to be given when decoding
+	decoded, err := DecodeMerkleTree(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if decoded.Verify() {
+		t.Errorf("expected false, got true")
+	}
+
+	decoded, err = DecodeMerkleTree(b, WithHashStrategy(Keccak256HashStrategy{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !decoded.Verify() {
+		t.Errorf("couldn't verify decoded tree")
+	}
+
+	// truncated
+	for i := range b {
+		if _, err := DecodeMerkleTree(b[:i]); err == nil {
+			t.Errorf("expected err for %d bytes, got nil", i)
+		}
+	}
+
+	// trailing data
+	if _, err := DecodeMerkleTree(append(b, 0)); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// unknown version
+	b[0] = 0xff
+	if _, err := DecodeMerkleTree(b); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	var nilTree *MerkleTree
+	if _, err := nilTree.MarshalBinary(); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
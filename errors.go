@@ -0,0 +1,50 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrRootMismatch is matched (with errors.Is) by every VerificationError.
+var ErrRootMismatch = errors.New("root does not match")
+
+// VerificationError is returned when a proof doesn't lead to the expected root.
+// It holds the root computed from the proof and the expected root, to debug proofs that were produced by
+// other implementations. Proofs only carry siblings, so where the computation went wrong can't be told.
+type VerificationError struct {
+	Computed []byte
+	Expected []byte
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("%v: computed %x, expected %x", ErrRootMismatch, e.Computed, e.Expected)
+}
+
+// Unwrap returns ErrRootMismatch.
+func (e *VerificationError) Unwrap() error {
+	return ErrRootMismatch
+}
+
+// CorruptNodeError is returned by VerifyDetailed for the first node (in pre-order) whose hash doesn't match its children.
+// Depth is counted from the root, and the node covers the leaves with indices in [Start, End).
+// Computed is nil if the node has only one child.
+type CorruptNodeError struct {
+	Depth      int
+	Start, End int
+	Computed   []byte
+	Stored     []byte
+}
+
+func (e *CorruptNodeError) Error() string {
+	return fmt.Sprintf("corrupt node at depth %d covering leaves [%d, %d): computed %x, stored %x",
+		e.Depth, e.Start, e.End, e.Computed, e.Stored)
+}
+
+// rootMismatch returns a VerificationError for a computed root.
+func rootMismatch(computed, expected []byte) error {
+	return &VerificationError{
+		Computed: bytes.Clone(computed),
+		Expected: bytes.Clone(expected),
+	}
+}
@@ -0,0 +1,93 @@
+package gomerkletree
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// Keccak256HashStrategy hashes with Keccak-256 following OpenZeppelin's conventions:
+// leaves are hashed twice, keccak256(keccak256(leaf)), to prevent them from being mistaken for internal nodes,
+// and internal nodes are hashed as keccak256(left || right) without a prefix.
+// Combine it with WithSortedPairs to produce proofs that OpenZeppelin's `MerkleProof.verify` accepts.
+type Keccak256HashStrategy struct{}
+
+func (h Keccak256HashStrategy) HashLeaf(l []byte) []byte {
+	return hashing.HashKeccak256(hashing.HashKeccak256(l))
+}
+
+func (h Keccak256HashStrategy) HashInternal(l, r []byte) []byte {
+	bytes := append(append([]byte{}, l...), r...)
+	return hashing.HashKeccak256(bytes)
+}
+
+// AirdropClaim is a leaf of an airdrop tree, granting an amount of tokens to an account.
+type AirdropClaim struct {
+	Account [20]byte
+	Amount  *big.Int
+}
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Bytes returns the claim encoded as abi.encode(address, uint256).
+// Amounts outside of the uint256 range are encoded as zero; BuildAirdropTree rejects them.
+func (c AirdropClaim) Bytes() []byte {
+	b := make([]byte, 64)
+	copy(b[12:32], c.Account[:])
+	if c.Amount != nil && c.Amount.Sign() >= 0 && c.Amount.Cmp(maxUint256) <= 0 {
+		c.Amount.FillBytes(b[32:])
+	}
+	return b
+}
+
+// ParseAddress parses a hex-encoded Ethereum address, with or without 0x prefix.
+func ParseAddress(s string) ([20]byte, error) {
+	var address [20]byte
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return address, err
+	}
+	if len(b) != len(address) {
+		return address, errors.New("invalid address length")
+	}
+	copy(address[:], b)
+	return address, nil
+}
+
+// BuildAirdropTree builds a merkle tree over the claims, whose proofs can be verified by a Solidity contract with
+//
+//	bytes32 leaf = keccak256(bytes.concat(keccak256(abi.encode(account, amount))));
+//	require(MerkleProof.verify(proof, root, leaf));
+//
+// Use (*Proof).SolidityProof to get the proof in the format the contract expects.
+func BuildAirdropTree(claims []AirdropClaim) (*MerkleTree, error) {
+	if len(claims) == 0 {
+		return nil, errors.New("no claims")
+	}
+
+	data := make([]Leaf, len(claims))
+	for i, c := range claims {
+		if c.Amount == nil || c.Amount.Sign() < 0 || c.Amount.Cmp(maxUint256) > 0 {
+			return nil, errors.New("invalid amount")
+		}
+		data[i] = c
+	}
+	return BuildMerkleTree(data, WithHashStrategy(Keccak256HashStrategy{}), WithSortedPairs())
+}
+
+// SolidityProof returns the siblings of the proof as 0x-prefixed hex strings, i.e. a Solidity bytes32[] proof.
+func (p *Proof) SolidityProof() []string {
+	if p == nil {
+		return nil
+	}
+	proof := make([]string, len(p.siblings))
+	for i, sibling := range p.siblings {
+		proof[i] = "0x" + hex.EncodeToString(sibling)
+	}
+	return proof
+}
@@ -0,0 +1,98 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func TestKeccak256HashStrategy(t *testing.T) {
+	empty := mustDecodeHex(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")
+
+	if !bytes.Equal(hashing.HashKeccak256(nil), empty) {
+		t.Errorf("keccak256 not correct")
+	}
+
+	h := Keccak256HashStrategy{}
+	if !bytes.Equal(h.HashLeaf(nil), hashing.HashKeccak256(empty)) {
+		t.Errorf("leaf hash not correct")
+	}
+
+	if !bytes.Equal(h.HashInternal([]byte("a"), []byte("b")), hashing.HashKeccak256([]byte("ab"))) {
+		t.Errorf("internal hash not correct")
+	}
+}
+
+func TestAirdropClaim_Bytes(t *testing.T) {
+	account, err := ParseAddress("0x1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claim := AirdropClaim{account, big.NewInt(5000)}
+	expected := strings.Repeat("00", 12) + strings.Repeat("11", 20) + strings.Repeat("00", 30) + "1388"
+
+	if got := hex.EncodeToString(claim.Bytes()); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	if _, err := ParseAddress("0x1111"); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := ParseAddress("zz"); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestAirdrop_Proof(t *testing.T) {
+	var claims []AirdropClaim
+	for i := range 5 {
+		var account [20]byte
+		account[19] = byte(i + 1)
+		claims = append(claims, AirdropClaim{account, big.NewInt(int64(100 * (i + 1)))})
+	}
+
+	tree, err := BuildAirdropTree(claims)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, c := range claims {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// verify the way MerkleProof.verify does
+		computed := Keccak256HashStrategy{}.HashLeaf(c.Bytes())
+		for _, sibling := range proof.SolidityProof() {
+			b := mustDecodeHex(t, strings.TrimPrefix(sibling, "0x"))
+			if bytes.Compare(computed, b) < 0 {
+				computed = hashing.HashKeccak256(append(append([]byte{}, computed...), b...))
+			} else {
+				computed = hashing.HashKeccak256(append(append([]byte{}, b...), computed...))
+			}
+		}
+
+		if !bytes.Equal(computed, tree.Root()) {
+			t.Errorf("proof of claim %d not correct", i)
+		}
+	}
+
+	if _, err := BuildAirdropTree([]AirdropClaim{{Amount: big.NewInt(-1)}}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := BuildAirdropTree([]AirdropClaim{{Amount: new(big.Int).Lsh(big.NewInt(1), 256)}}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := BuildAirdropTree(nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
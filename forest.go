@@ -0,0 +1,124 @@
+package gomerkletree
+
+import (
+	"errors"
+	"sync"
+)
+
+// Forest is a set of shard trees whose roots are committed as RootLeaf leaves in a top-level tree,
+// whose root is the super-root of the forest. Shards can be built in parallel, or on different machines.
+type Forest struct {
+	shards []*MerkleTree
+	top    *MerkleTree
+}
+
+// BuildForest builds a shard tree for every partition of the leaves in parallel,
+// and combines their roots into a super-root. The options apply to the shards and to the top-level tree.
+func BuildForest(partitions [][]Leaf, opts ...Option) (*Forest, error) {
+	for _, data := range partitions {
+		if len(data) == 0 {
+			return nil, errors.New("no leaves")
+		}
+	}
+
+	shards := make([]*MerkleTree, len(partitions))
+	errs := make([]error, len(partitions))
+	var wg sync.WaitGroup
+	for i, data := range partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shards[i], errs[i] = BuildMerkleTree(data, opts...)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return NewForest(shards, opts...)
+}
+
+// NewForest combines shard trees that were built before, e.g. on different machines, into a forest.
+// The options apply to the top-level tree.
+func NewForest(shards []*MerkleTree, opts ...Option) (*Forest, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("no shards")
+	}
+
+	roots := make([]Leaf, len(shards))
+	for i, shard := range shards {
+		if shard == nil {
+			return nil, errors.New("nil tree")
+		}
+		roots[i] = RootLeaf(shard.Root())
+	}
+
+	top, err := BuildMerkleTree(roots, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Forest{
+		shards: append([]*MerkleTree(nil), shards...),
+		top:    top,
+	}, nil
+}
+
+// Root returns the super-root of the forest.
+func (f *Forest) Root() []byte {
+	if f == nil {
+		return nil
+	}
+	return f.top.Root()
+}
+
+// NumShards returns the number of shards in the forest.
+func (f *Forest) NumShards() int {
+	if f == nil {
+		return 0
+	}
+	return len(f.shards)
+}
+
+// Shard returns the tree of the i-th shard, or nil if the index is out of range.
+func (f *Forest) Shard(i int) *MerkleTree {
+	if f == nil || i < 0 || i >= len(f.shards) {
+		return nil
+	}
+	return f.shards[i]
+}
+
+// Proof generates a proof for a given leaf of a shard up to the super-root.
+func (f *Forest) Proof(shard int, x Leaf) (*ChainedProof, error) {
+	tree := f.Shard(shard)
+	if tree == nil {
+		return nil, errors.New("index out of range")
+	}
+	inner, err := tree.Proof(x)
+	if err != nil {
+		return nil, err
+	}
+	return f.chain(tree, inner)
+}
+
+// ProofByIndex generates a proof for the i-th leaf of a shard up to the super-root.
+func (f *Forest) ProofByIndex(shard, i int) (*ChainedProof, error) {
+	tree := f.Shard(shard)
+	if tree == nil {
+		return nil, errors.New("index out of range")
+	}
+	inner, err := tree.ProofByIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return f.chain(tree, inner)
+}
+
+func (f *Forest) chain(tree *MerkleTree, inner *Proof) (*ChainedProof, error) {
+	// look up the root instead of using the shard index, the top-level tree may be sorted
+	outer, err := f.top.Proof(RootLeaf(tree.Root()))
+	if err != nil {
+		return nil, err
+	}
+	return ChainProofs(inner, outer)
+}
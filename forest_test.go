@@ -0,0 +1,111 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestForest_Build(t *testing.T) {
+	var partitions [][]Leaf
+	for s := range 4 {
+		var data []Leaf
+		for i := range s + 3 {
+			data = append(data, &TestLeaf{fmt.Sprintf("%d-%d", s, i)})
+		}
+		partitions = append(partitions, data)
+	}
+
+	forest, err := BuildForest(partitions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if forest.NumShards() != 4 {
+		t.Errorf("expected 4 shards, got %d", forest.NumShards())
+	}
+
+	var roots []Leaf
+	for _, data := range partitions {
+		roots = append(roots, RootLeaf(mustBuildMerkleTree(t, data).Root()))
+	}
+	if expected := mustBuildMerkleTree(t, roots).Root(); !bytes.Equal(forest.Root(), expected) {
+		t.Errorf("expected %x, got %x", expected, forest.Root())
+	}
+
+	for s, data := range partitions {
+		for i, x := range data {
+			proof, err := forest.ProofByIndex(s, i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := VerifyChainedProofAgainstRoot(x, proof, forest.Root()); err != nil {
+				t.Errorf("shard %d leaf %d: unexpected error: %v", s, i, err)
+			}
+		}
+	}
+
+	proof, err := forest.Proof(2, &TestLeaf{"2-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyChainedProof(&TestLeaf{"2-1"}, proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := forest.Proof(1, &TestLeaf{"2-1"}); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestForest_SortedTopLevel(t *testing.T) {
+	var partitions [][]Leaf
+	for s := range 5 {
+		partitions = append(partitions, []Leaf{&TestLeaf{fmt.Sprint(s)}})
+	}
+
+	forest, err := BuildForest(partitions, WithSortedLeaves())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for s, data := range partitions {
+		proof, err := forest.Proof(s, data[0])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := VerifyChainedProofAgainstRoot(data[0], proof, forest.Root()); err != nil {
+			t.Errorf("shard %d: unexpected error: %v", s, err)
+		}
+	}
+}
+
+func TestForest_Errors(t *testing.T) {
+	if _, err := BuildForest(nil); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := BuildForest([][]Leaf{{&TestLeaf{"a"}}, {}}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := NewForest([]*MerkleTree{nil}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	forest, err := BuildForest([][]Leaf{{&TestLeaf{"a"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if forest.Shard(1) != nil {
+		t.Errorf("expected nil shard")
+	}
+
+	if _, err := forest.ProofByIndex(1, 0); err == nil {
+		t.Errorf("expected error")
+	}
+}
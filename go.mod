@@ -1,3 +1,14 @@
 module github.com/jeltjongsma/go-merkletree
 
-go 1.22.3
+go 1.23.0
+
+require (
+	github.com/zeebo/blake3 v0.2.4
+	golang.org/x/crypto v0.33.0
+	golang.org/x/mod v0.22.0
+)
+
+require (
+	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
@@ -0,0 +1,22 @@
+module github.com/jeltjongsma/go-merkletree/grpcapi
+
+go 1.23.0
+
+require (
+	github.com/jeltjongsma/go-merkletree v0.1.0
+	google.golang.org/grpc v1.71.0
+	google.golang.org/protobuf v1.36.9
+)
+
+require (
+	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
+	github.com/zeebo/blake3 v0.2.4 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
+)
+
+replace github.com/jeltjongsma/go-merkletree => ../
@@ -0,0 +1,140 @@
+// Package grpcapi implements the MerkleLogService defined in merkletreepb/merkletree.proto,
+// and converts proofs to and from their protobuf messages.
+//
+// The generated code is updated with `buf generate`, using protoc-gen-go and protoc-gen-go-grpc.
+package grpcapi
+
+import (
+	"context"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/grpcapi/merkletreepb"
+	"github.com/jeltjongsma/go-merkletree/httpapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Log is the tree served by the service, see httpapi.FromTree, httpapi.FromSyncTree and httpapi.FromStoredTree.
+type Log = httpapi.Log
+
+// Server implements merkletreepb.MerkleLogServiceServer on top of a log.
+type Server struct {
+	merkletreepb.UnimplementedMerkleLogServiceServer
+	log Log
+}
+
+// NewServer returns a server for the log, to register with merkletreepb.RegisterMerkleLogServiceServer.
+func NewServer(log Log) *Server {
+	return &Server{log: log}
+}
+
+func (s *Server) GetRoot(ctx context.Context, req *merkletreepb.GetRootRequest) (*merkletreepb.GetRootResponse, error) {
+	size, root, err := s.log.Head()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &merkletreepb.GetRootResponse{Size: uint64(size), Root: root}, nil
+}
+
+func (s *Server) GetProof(ctx context.Context, req *merkletreepb.GetProofRequest) (*merkletreepb.GetProofResponse, error) {
+	size, _, err := s.log.Head()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if req.GetIndex() >= uint64(size) {
+		return nil, status.Error(codes.OutOfRange, "index out of range")
+	}
+
+	proof, err := s.log.ProofByIndex(int(req.GetIndex()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &merkletreepb.GetProofResponse{Index: req.GetIndex(), Proof: ProofToProto(proof)}, nil
+}
+
+func (s *Server) GetConsistencyProof(ctx context.Context, req *merkletreepb.GetConsistencyProofRequest) (*merkletreepb.GetConsistencyProofResponse, error) {
+	size, _, err := s.log.Head()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	oldSize, newSize := req.GetOldSize(), req.GetNewSize()
+	if oldSize == 0 || oldSize > newSize || newSize > uint64(size) {
+		return nil, status.Error(codes.InvalidArgument, "invalid tree sizes")
+	}
+
+	proof, err := s.log.ConsistencyProof(int(oldSize), int(newSize))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &merkletreepb.GetConsistencyProofResponse{Proof: ConsistencyProofToProto(proof)}, nil
+}
+
+// ProofToProto converts a proof to its protobuf message.
+func ProofToProto(p *gomerkletree.Proof) *merkletreepb.Proof {
+	if p == nil {
+		return nil
+	}
+	return &merkletreepb.Proof{
+		Root:     p.Root(),
+		Siblings: p.Siblings(),
+		Left:     p.Directions(),
+	}
+}
+
+// ProofFromProto converts a protobuf message to a proof, using the given hash strategy (nil means the default).
+func ProofFromProto(m *merkletreepb.Proof, hash gomerkletree.HashStrategy) *gomerkletree.Proof {
+	if m == nil {
+		return nil
+	}
+	return gomerkletree.NewProof(m.GetRoot(), m.GetSiblings(), m.GetLeft(), hash)
+}
+
+// MultiProofToProto converts a multiproof to its protobuf message.
+func MultiProofToProto(p *gomerkletree.MultiProof) *merkletreepb.MultiProof {
+	if p == nil {
+		return nil
+	}
+	indices := make([]uint64, len(p.Indices()))
+	for i, index := range p.Indices() {
+		indices[i] = uint64(index)
+	}
+	return &merkletreepb.MultiProof{
+		Root:    p.Root(),
+		Size:    uint64(p.Size()),
+		Indices: indices,
+		Hashes:  p.Hashes(),
+	}
+}
+
+// MultiProofFromProto converts a protobuf message to a multiproof, using the given hash strategy (nil means the default).
+func MultiProofFromProto(m *merkletreepb.MultiProof, hash gomerkletree.HashStrategy) *gomerkletree.MultiProof {
+	if m == nil {
+		return nil
+	}
+	indices := make([]int, len(m.GetIndices()))
+	for i, index := range m.GetIndices() {
+		indices[i] = int(index)
+	}
+	return gomerkletree.NewMultiProof(m.GetRoot(), int(m.GetSize()), indices, m.GetHashes(), hash)
+}
+
+// ConsistencyProofToProto converts a consistency proof to its protobuf message.
+func ConsistencyProofToProto(p *gomerkletree.ConsistencyProof) *merkletreepb.ConsistencyProof {
+	if p == nil {
+		return nil
+	}
+	return &merkletreepb.ConsistencyProof{
+		OldSize: uint64(p.OldSize()),
+		NewSize: uint64(p.NewSize()),
+		Hashes:  p.Hashes(),
+	}
+}
+
+// ConsistencyProofFromProto converts a protobuf message to a consistency proof,
+// using the given hash strategy (nil means the default).
+func ConsistencyProofFromProto(m *merkletreepb.ConsistencyProof, hash gomerkletree.HashStrategy) *gomerkletree.ConsistencyProof {
+	if m == nil {
+		return nil
+	}
+	return gomerkletree.NewConsistencyProof(int(m.GetOldSize()), int(m.GetNewSize()), m.GetHashes(), hash)
+}
@@ -0,0 +1,137 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/grpcapi/merkletreepb"
+	"github.com/jeltjongsma/go-merkletree/httpapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type testLeaf string
+
+func (l testLeaf) Bytes() []byte {
+	return []byte(l)
+}
+
+func testData(n int) []gomerkletree.Leaf {
+	var data []gomerkletree.Leaf
+	for i := range n {
+		data = append(data, testLeaf(fmt.Sprint(i)))
+	}
+	return data
+}
+
+func newClient(t *testing.T, log Log) merkletreepb.MerkleLogServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	merkletreepb.RegisterMerkleLogServiceServer(srv, NewServer(log))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return merkletreepb.NewMerkleLogServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	data := testData(8)
+	tree := mustBuildMerkleTree(t, data)
+	client := newClient(t, httpapi.FromTree(tree))
+	ctx := context.Background()
+
+	root, err := client.GetRoot(ctx, &merkletreepb.GetRootRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if root.GetSize() != 8 || !bytes.Equal(root.GetRoot(), tree.Root()) {
+		t.Errorf("unexpected response: %v", root)
+	}
+
+	proof, err := client.GetProof(ctx, &merkletreepb.GetProofRequest{Index: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := gomerkletree.VerifyProof(data[5], ProofFromProto(proof.GetProof(), nil)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	consistency, err := client.GetConsistencyProof(ctx, &merkletreepb.GetConsistencyProofRequest{OldSize: 3, NewSize: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oldRoot := mustBuildMerkleTree(t, data[:3]).Root()
+	if err := gomerkletree.VerifyConsistency(oldRoot, tree.Root(), ConsistencyProofFromProto(consistency.GetProof(), nil)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	client := newClient(t, httpapi.FromTree(mustBuildMerkleTree(t, testData(4))))
+	ctx := context.Background()
+
+	_, err := client.GetProof(ctx, &merkletreepb.GetProofRequest{Index: 4})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("expected OutOfRange, got %v", err)
+	}
+
+	_, err = client.GetConsistencyProof(ctx, &merkletreepb.GetConsistencyProofRequest{OldSize: 3, NewSize: 5})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestMultiProofProto(t *testing.T) {
+	data := testData(7)
+	tree := mustBuildMerkleTree(t, data)
+
+	proof, err := tree.MultiProof([]gomerkletree.Leaf{data[4], data[1]})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := proto.Marshal(MultiProofToProto(proof))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var m merkletreepb.MultiProof
+	if err := proto.Unmarshal(b, &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := gomerkletree.VerifyMultiProof([]gomerkletree.Leaf{data[4], data[1]}, MultiProofFromProto(&m, nil)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func mustBuildMerkleTree(t testing.TB, data []gomerkletree.Leaf) *gomerkletree.MerkleTree {
+	t.Helper()
+	tree, err := gomerkletree.BuildMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tree
+}
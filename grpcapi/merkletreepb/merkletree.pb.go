@@ -0,0 +1,600 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: merkletreepb/merkletree.proto
+
+package merkletreepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Proof proves the inclusion of a single leaf.
+type Proof struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Root  []byte                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// Sibling hashes, ordered from the leaf up to the root.
+	Siblings [][]byte `protobuf:"bytes,2,rep,name=siblings,proto3" json:"siblings,omitempty"`
+	// For every sibling, whether it is a left child.
+	Left          []bool `protobuf:"varint,3,rep,packed,name=left,proto3" json:"left,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Proof) Reset() {
+	*x = Proof{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Proof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proof) ProtoMessage() {}
+
+func (x *Proof) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proof.ProtoReflect.Descriptor instead.
+func (*Proof) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Proof) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *Proof) GetSiblings() [][]byte {
+	if x != nil {
+		return x.Siblings
+	}
+	return nil
+}
+
+func (x *Proof) GetLeft() []bool {
+	if x != nil {
+		return x.Left
+	}
+	return nil
+}
+
+// MultiProof proves the inclusion of several leaves at once.
+type MultiProof struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Root  []byte                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// Number of leaves in the tree.
+	Size uint64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// Positions of the proven leaves, in the order they were given.
+	Indices []uint64 `protobuf:"varint,3,rep,packed,name=indices,proto3" json:"indices,omitempty"`
+	// Hashes needed to recompute the root, in depth-first order.
+	Hashes        [][]byte `protobuf:"bytes,4,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiProof) Reset() {
+	*x = MultiProof{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiProof) ProtoMessage() {}
+
+func (x *MultiProof) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiProof.ProtoReflect.Descriptor instead.
+func (*MultiProof) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MultiProof) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *MultiProof) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *MultiProof) GetIndices() []uint64 {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
+func (x *MultiProof) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+// ConsistencyProof proves that the tree with new_size leaves extends the tree with old_size leaves (RFC 6962).
+type ConsistencyProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldSize       uint64                 `protobuf:"varint,1,opt,name=old_size,json=oldSize,proto3" json:"old_size,omitempty"`
+	NewSize       uint64                 `protobuf:"varint,2,opt,name=new_size,json=newSize,proto3" json:"new_size,omitempty"`
+	Hashes        [][]byte               `protobuf:"bytes,3,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsistencyProof) Reset() {
+	*x = ConsistencyProof{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsistencyProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsistencyProof) ProtoMessage() {}
+
+func (x *ConsistencyProof) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsistencyProof.ProtoReflect.Descriptor instead.
+func (*ConsistencyProof) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConsistencyProof) GetOldSize() uint64 {
+	if x != nil {
+		return x.OldSize
+	}
+	return 0
+}
+
+func (x *ConsistencyProof) GetNewSize() uint64 {
+	if x != nil {
+		return x.NewSize
+	}
+	return 0
+}
+
+func (x *ConsistencyProof) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+type GetRootRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRootRequest) Reset() {
+	*x = GetRootRequest{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRootRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRootRequest) ProtoMessage() {}
+
+func (x *GetRootRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRootRequest.ProtoReflect.Descriptor instead.
+func (*GetRootRequest) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{3}
+}
+
+type GetRootResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          uint64                 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Root          []byte                 `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRootResponse) Reset() {
+	*x = GetRootResponse{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRootResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRootResponse) ProtoMessage() {}
+
+func (x *GetRootResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRootResponse.ProtoReflect.Descriptor instead.
+func (*GetRootResponse) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRootResponse) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *GetRootResponse) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+type GetProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofRequest) Reset() {
+	*x = GetProofRequest{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofRequest) ProtoMessage() {}
+
+func (x *GetProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofRequest.ProtoReflect.Descriptor instead.
+func (*GetProofRequest) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetProofRequest) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type GetProofResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Proof         *Proof                 `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofResponse) Reset() {
+	*x = GetProofResponse{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofResponse) ProtoMessage() {}
+
+func (x *GetProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofResponse.ProtoReflect.Descriptor instead.
+func (*GetProofResponse) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetProofResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetProofResponse) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type GetConsistencyProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldSize       uint64                 `protobuf:"varint,1,opt,name=old_size,json=oldSize,proto3" json:"old_size,omitempty"`
+	NewSize       uint64                 `protobuf:"varint,2,opt,name=new_size,json=newSize,proto3" json:"new_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsistencyProofRequest) Reset() {
+	*x = GetConsistencyProofRequest{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsistencyProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsistencyProofRequest) ProtoMessage() {}
+
+func (x *GetConsistencyProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsistencyProofRequest.ProtoReflect.Descriptor instead.
+func (*GetConsistencyProofRequest) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetConsistencyProofRequest) GetOldSize() uint64 {
+	if x != nil {
+		return x.OldSize
+	}
+	return 0
+}
+
+func (x *GetConsistencyProofRequest) GetNewSize() uint64 {
+	if x != nil {
+		return x.NewSize
+	}
+	return 0
+}
+
+type GetConsistencyProofResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proof         *ConsistencyProof      `protobuf:"bytes,1,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsistencyProofResponse) Reset() {
+	*x = GetConsistencyProofResponse{}
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsistencyProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsistencyProofResponse) ProtoMessage() {}
+
+func (x *GetConsistencyProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merkletreepb_merkletree_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsistencyProofResponse.ProtoReflect.Descriptor instead.
+func (*GetConsistencyProofResponse) Descriptor() ([]byte, []int) {
+	return file_merkletreepb_merkletree_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetConsistencyProofResponse) GetProof() *ConsistencyProof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+var File_merkletreepb_merkletree_proto protoreflect.FileDescriptor
+
+const file_merkletreepb_merkletree_proto_rawDesc = "" +
+	"\n" +
+	"\x1dmerkletreepb/merkletree.proto\x12\rmerkletree.v1\"K\n" +
+	"\x05Proof\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\fR\x04root\x12\x1a\n" +
+	"\bsiblings\x18\x02 \x03(\fR\bsiblings\x12\x12\n" +
+	"\x04left\x18\x03 \x03(\bR\x04left\"f\n" +
+	"\n" +
+	"MultiProof\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\fR\x04root\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\x12\x18\n" +
+	"\aindices\x18\x03 \x03(\x04R\aindices\x12\x16\n" +
+	"\x06hashes\x18\x04 \x03(\fR\x06hashes\"`\n" +
+	"\x10ConsistencyProof\x12\x19\n" +
+	"\bold_size\x18\x01 \x01(\x04R\aoldSize\x12\x19\n" +
+	"\bnew_size\x18\x02 \x01(\x04R\anewSize\x12\x16\n" +
+	"\x06hashes\x18\x03 \x03(\fR\x06hashes\"\x10\n" +
+	"\x0eGetRootRequest\"9\n" +
+	"\x0fGetRootResponse\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x04R\x04size\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\fR\x04root\"'\n" +
+	"\x0fGetProofRequest\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"T\n" +
+	"\x10GetProofResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12*\n" +
+	"\x05proof\x18\x02 \x01(\v2\x14.merkletree.v1.ProofR\x05proof\"R\n" +
+	"\x1aGetConsistencyProofRequest\x12\x19\n" +
+	"\bold_size\x18\x01 \x01(\x04R\aoldSize\x12\x19\n" +
+	"\bnew_size\x18\x02 \x01(\x04R\anewSize\"T\n" +
+	"\x1bGetConsistencyProofResponse\x125\n" +
+	"\x05proof\x18\x01 \x01(\v2\x1f.merkletree.v1.ConsistencyProofR\x05proof2\x97\x02\n" +
+	"\x10MerkleLogService\x12H\n" +
+	"\aGetRoot\x12\x1d.merkletree.v1.GetRootRequest\x1a\x1e.merkletree.v1.GetRootResponse\x12K\n" +
+	"\bGetProof\x12\x1e.merkletree.v1.GetProofRequest\x1a\x1f.merkletree.v1.GetProofResponse\x12l\n" +
+	"\x13GetConsistencyProof\x12).merkletree.v1.GetConsistencyProofRequest\x1a*.merkletree.v1.GetConsistencyProofResponseB;Z9github.com/jeltjongsma/go-merkletree/grpcapi/merkletreepbb\x06proto3"
+
+var (
+	file_merkletreepb_merkletree_proto_rawDescOnce sync.Once
+	file_merkletreepb_merkletree_proto_rawDescData []byte
+)
+
+func file_merkletreepb_merkletree_proto_rawDescGZIP() []byte {
+	file_merkletreepb_merkletree_proto_rawDescOnce.Do(func() {
+		file_merkletreepb_merkletree_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_merkletreepb_merkletree_proto_rawDesc), len(file_merkletreepb_merkletree_proto_rawDesc)))
+	})
+	return file_merkletreepb_merkletree_proto_rawDescData
+}
+
+var file_merkletreepb_merkletree_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_merkletreepb_merkletree_proto_goTypes = []any{
+	(*Proof)(nil),                       // 0: merkletree.v1.Proof
+	(*MultiProof)(nil),                  // 1: merkletree.v1.MultiProof
+	(*ConsistencyProof)(nil),            // 2: merkletree.v1.ConsistencyProof
+	(*GetRootRequest)(nil),              // 3: merkletree.v1.GetRootRequest
+	(*GetRootResponse)(nil),             // 4: merkletree.v1.GetRootResponse
+	(*GetProofRequest)(nil),             // 5: merkletree.v1.GetProofRequest
+	(*GetProofResponse)(nil),            // 6: merkletree.v1.GetProofResponse
+	(*GetConsistencyProofRequest)(nil),  // 7: merkletree.v1.GetConsistencyProofRequest
+	(*GetConsistencyProofResponse)(nil), // 8: merkletree.v1.GetConsistencyProofResponse
+}
+var file_merkletreepb_merkletree_proto_depIdxs = []int32{
+	0, // 0: merkletree.v1.GetProofResponse.proof:type_name -> merkletree.v1.Proof
+	2, // 1: merkletree.v1.GetConsistencyProofResponse.proof:type_name -> merkletree.v1.ConsistencyProof
+	3, // 2: merkletree.v1.MerkleLogService.GetRoot:input_type -> merkletree.v1.GetRootRequest
+	5, // 3: merkletree.v1.MerkleLogService.GetProof:input_type -> merkletree.v1.GetProofRequest
+	7, // 4: merkletree.v1.MerkleLogService.GetConsistencyProof:input_type -> merkletree.v1.GetConsistencyProofRequest
+	4, // 5: merkletree.v1.MerkleLogService.GetRoot:output_type -> merkletree.v1.GetRootResponse
+	6, // 6: merkletree.v1.MerkleLogService.GetProof:output_type -> merkletree.v1.GetProofResponse
+	8, // 7: merkletree.v1.MerkleLogService.GetConsistencyProof:output_type -> merkletree.v1.GetConsistencyProofResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_merkletreepb_merkletree_proto_init() }
+func file_merkletreepb_merkletree_proto_init() {
+	if File_merkletreepb_merkletree_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_merkletreepb_merkletree_proto_rawDesc), len(file_merkletreepb_merkletree_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_merkletreepb_merkletree_proto_goTypes,
+		DependencyIndexes: file_merkletreepb_merkletree_proto_depIdxs,
+		MessageInfos:      file_merkletreepb_merkletree_proto_msgTypes,
+	}.Build()
+	File_merkletreepb_merkletree_proto = out.File
+	file_merkletreepb_merkletree_proto_goTypes = nil
+	file_merkletreepb_merkletree_proto_depIdxs = nil
+}
@@ -0,0 +1,64 @@
+package gomerkletree
+
+import "bytes"
+
+// Hasher computes the root of a merkle tree from a stream of leaves, using O(log n) memory.
+// It only keeps the frontier of the tree: the root of at most one perfect subtree per level.
+// The resulting root is identical to the one BuildMerkleTree would build from all leaves.
+type Hasher struct {
+	frontier     [][]byte // frontier[i] is the root of a perfect subtree of 2^i leaves, or nil
+	n            int
+	hashStrategy HashStrategy
+}
+
+// NewHasher returns a Hasher that uses the default SHA-256 based hash strategy.
+func NewHasher() *Hasher {
+	return NewHasherWithHashStrategy(defaultHashStrategy{})
+}
+
+// NewHasherWithHashStrategy returns a Hasher that uses the given hash strategy.
+func NewHasherWithHashStrategy(hash HashStrategy) *Hasher {
+	return &Hasher{
+		hashStrategy: hash,
+	}
+}
+
+// WriteLeaf adds the next leaf to the tree.
+func (h *Hasher) WriteLeaf(b []byte) {
+	h.writeHash(h.hashStrategy.HashLeaf(b))
+}
+
+func (h *Hasher) writeHash(hash []byte) {
+	level := 0
+	for ; level < len(h.frontier) && h.frontier[level] != nil; level++ {
+		hash = h.hashStrategy.HashInternal(h.frontier[level], hash)
+		h.frontier[level] = nil
+	}
+	if level == len(h.frontier) {
+		h.frontier = append(h.frontier, nil)
+	}
+	h.frontier[level] = hash
+	h.n++
+}
+
+// Root returns a copy of the root of the tree over all leaves written so far, or nil if there are none.
+// More leaves can be written after calling Root.
+func (h *Hasher) Root() []byte {
+	var root []byte
+	for _, hash := range h.frontier {
+		if hash == nil {
+			continue
+		}
+		if root == nil {
+			root = hash
+		} else {
+			root = h.hashStrategy.HashInternal(hash, root)
+		}
+	}
+	return bytes.Clone(root) // with a single perfect subtree, root is still part of the frontier
+}
+
+// Count returns the number of leaves written so far.
+func (h *Hasher) Count() int {
+	return h.n
+}
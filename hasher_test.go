@@ -0,0 +1,50 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHasher_Root(t *testing.T) {
+	h := NewHasher()
+
+	if h.Root() != nil {
+		t.Errorf("expected nil root")
+	}
+
+	var data []Leaf
+	for i := range 33 {
+		x := &TestLeaf{string(rune('a' + i))}
+		data = append(data, x)
+
+		h.WriteLeaf(x.Bytes())
+
+		expected := mustBuildMerkleTree(t, data)
+		if !bytes.Equal(h.Root(), expected.Root()) {
+			t.Errorf("root not correct after %d leaves", i+1)
+		}
+
+		if h.Count() != i+1 {
+			t.Errorf("expected count=%d, got %d", i+1, h.Count())
+		}
+	}
+
+	if len(h.frontier) != 6 {
+		t.Errorf("expected frontier of 6 levels, got %d", len(h.frontier))
+	}
+}
+
+func TestHasher_RootCopy(t *testing.T) {
+	h := NewHasher()
+	h.WriteLeaf([]byte("a"))
+	h.WriteLeaf([]byte("b"))
+	expected := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}).Root()
+
+	// a single perfect subtree, whose root must not be shared with the frontier
+	h.Root()[0] ^= 0xff
+
+	h.WriteLeaf([]byte("c"))
+	if !bytes.Equal(h.Root(), expected) {
+		t.Errorf("modifying the root changed the hasher")
+	}
+}
@@ -0,0 +1,96 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func TestTree_HashStrategies(t *testing.T) {
+	strategies := map[string]HashStrategy{
+		"SHA3":       hashing.SHA3Strategy{},
+		"SHA512_256": hashing.SHA512_256Strategy{},
+		"Blake2b":    hashing.Blake2bStrategy{},
+		"Blake3":     hashing.Blake3Strategy{},
+		"Default":    defaultHashStrategy{},
+	}
+
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	for name, s := range strategies {
+		if _, ok := s.(AppendHasher); !ok {
+			t.Errorf("%s: expected AppendHasher", name)
+		}
+
+		tree := mustBuildMerkleTree(t, data, WithHashStrategy(s))
+		if !tree.Verify() {
+			t.Fatalf("%s: expected tree to verify", name)
+		}
+
+		proof, err := tree.Proof(data[2])
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if err := VerifyProof(data[2], proof); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+
+	// the default strategy is prefixed SHA-256, like any SHA-256 strategy of pkg/hashing
+	expected := mustBuildMerkleTree(t, data)
+	for name, s := range map[string]HashStrategy{
+		"Digest": hashing.NewStrategy(sha256.New),
+		"Pooled": hashing.NewPooledStrategy(sha256.New),
+	} {
+		if tree := mustBuildMerkleTree(t, data, WithHashStrategy(s)); !bytes.Equal(tree.Root(), expected.Root()) {
+			t.Errorf("%s: expected %x, got %x", name, expected.Root(), tree.Root())
+		}
+	}
+}
+
+func TestTree_HMACStrategy(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	key := []byte("key")
+	tree := mustBuildMerkleTree(t, data, WithHashStrategy(hashing.NewHMACStrategy(key, sha256.New)))
+
+	proof, err := tree.Proof(data[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProofWithStrategy(data[2], proof, hashing.NewHMACStrategy(key, sha256.New), tree.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProofWithStrategy(data[2], proof, hashing.NewHMACStrategy([]byte("other"), sha256.New), tree.Root()); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func BenchmarkDefaultHashStrategy(b *testing.B) {
+	leaf := bytes.Repeat([]byte{1}, 64)
+	node := bytes.Repeat([]byte{2}, 32)
+
+	b.Run("Leaf", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			hashStrategy.HashLeaf(leaf)
+		}
+	})
+	b.Run("Internal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			hashStrategy.HashInternal(node, node)
+		}
+	})
+}
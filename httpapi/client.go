@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/internal/jsonhttp"
+)
+
+// Client reads the root and proofs of a log served by NewHandler.
+type Client struct {
+	base string
+	http *http.Client
+}
+
+// NewClient returns a client for the handler served at baseURL, sending requests with c (http.DefaultClient if nil).
+func NewClient(baseURL string, c *http.Client) *Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	return &Client{
+		base: strings.TrimSuffix(baseURL, "/"),
+		http: c,
+	}
+}
+
+// Head returns the current size and root of the log.
+func (c *Client) Head(ctx context.Context) (int, []byte, error) {
+	var res rootResponse
+	if err := c.get(ctx, "/root", &res); err != nil {
+		return 0, nil, err
+	}
+	root, err := hex.DecodeString(res.Root)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.Size, root, nil
+}
+
+// SignedHead returns the latest signed tree head of a SignedLog. The signature is not verified.
+func (c *Client) SignedHead(ctx context.Context) (*gomerkletree.SignedTreeHead, error) {
+	var res headResponse
+	if err := c.get(ctx, "/head", &res); err != nil {
+		return nil, err
+	}
+	root, err := hex.DecodeString(res.Root)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(res.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &gomerkletree.SignedTreeHead{
+		TreeHead: gomerkletree.TreeHead{
+			Size:      res.Size,
+			Root:      root,
+			Timestamp: time.UnixMilli(res.Timestamp).UTC(),
+		},
+		Signature: signature,
+	}, nil
+}
+
+// ProofByIndex returns the proof of the i-th leaf against the current root.
+func (c *Client) ProofByIndex(ctx context.Context, i int) (*gomerkletree.Proof, error) {
+	return c.proof(ctx, "/proof/"+strconv.Itoa(i))
+}
+
+// InclusionProof returns the proof of the i-th leaf against the tree head with the given size, from a SignedLog.
+func (c *Client) InclusionProof(ctx context.Context, i, size int) (*gomerkletree.Proof, error) {
+	return c.proof(ctx, "/proof/"+strconv.Itoa(i)+"?size="+strconv.Itoa(size))
+}
+
+func (c *Client) proof(ctx context.Context, path string) (*gomerkletree.Proof, error) {
+	var res proofResponse
+	if err := c.get(ctx, path, &res); err != nil {
+		return nil, err
+	}
+	if res.Proof == nil {
+		return nil, errors.New("no proof in response")
+	}
+	return res.Proof, nil
+}
+
+// ConsistencyProof returns the proof that the tree with newSize leaves extends the tree with oldSize leaves.
+func (c *Client) ConsistencyProof(ctx context.Context, oldSize, newSize int) (*gomerkletree.ConsistencyProof, error) {
+	query := url.Values{}
+	query.Set("old", strconv.Itoa(oldSize))
+	query.Set("new", strconv.Itoa(newSize))
+
+	var proof gomerkletree.ConsistencyProof
+	if err := c.get(ctx, "/consistency?"+query.Encode(), &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// LeafHash returns the hash of the i-th leaf of a SignedLog.
+func (c *Client) LeafHash(ctx context.Context, i int) ([]byte, error) {
+	var res leafResponse
+	if err := c.get(ctx, "/leaf/"+strconv.Itoa(i), &res); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(res.Hash)
+}
+
+// get decodes the JSON response of a GET request into v, or returns the error of the response.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return jsonhttp.ResponseError(res)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, jsonhttp.MaxResponseSize)).Decode(v)
+}
@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/log"
+)
+
+func TestClient_Tree(t *testing.T) {
+	data := testData(7)
+	tree := mustBuildMerkleTree(t, data)
+	server := httptest.NewServer(NewHandler(FromTree(tree)))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := NewClient(server.URL, nil)
+
+	size, root, err := c.Head(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size != 7 || !bytes.Equal(root, tree.Root()) {
+		t.Errorf("expected size 7 and root %x, got size %d and root %x", tree.Root(), size, root)
+	}
+
+	proof, err := c.ProofByIndex(ctx, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := gomerkletree.VerifyProofAgainstRoot(data[2], proof, root); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	consistency, err := c.ConsistencyProof(ctx, 3, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oldRoot := mustBuildMerkleTree(t, data[:3]).Root()
+	if err := gomerkletree.VerifyConsistency(oldRoot, root, consistency); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := c.ProofByIndex(ctx, 7); err == nil || err.Error() != "index out of range" {
+		t.Errorf("expected index out of range, got %v", err)
+	}
+
+	// a tree has no signed tree heads
+	if _, err := c.SignedHead(ctx); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := c.InclusionProof(ctx, 2, 5); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestClient_SignedLog(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	l, err := log.New(gomerkletree.NewMemoryStore(), key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := testData(6)
+	for _, x := range data[:4] {
+		l.Add(x)
+	}
+	oldHead, _ := l.Integrate()
+	for _, x := range data[4:] {
+		l.Add(x)
+	}
+	l.Integrate()
+
+	server := httptest.NewServer(NewHandler(l))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := NewClient(server.URL+"/", nil)
+
+	head, err := c.SignedHead(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := gomerkletree.VerifyTreeHead(head, l.PublicKey()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if head.Size != 6 {
+		t.Errorf("expected size 6, got %d", head.Size)
+	}
+
+	proof, err := c.InclusionProof(ctx, 1, oldHead.Size)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := log.VerifyInclusion(l.PublicKey(), oldHead, data[1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	hash, err := c.LeafHash(ctx, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := mustBuildMerkleTree(t, data).LeafHash(5); !bytes.Equal(hash, expected) {
+		t.Errorf("expected %x, got %x", expected, hash)
+	}
+
+	if _, err := c.InclusionProof(ctx, 1, 7); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := c.LeafHash(ctx, 6); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestClient_LargeResponse(t *testing.T) {
+	// a body with a hash longer than any client reads
+	body := `{"size":1,"root":"` + strings.Repeat("00", 1<<20) + `"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	if _, _, err := NewClient(server.URL, nil).Head(context.Background()); err == nil {
+		t.Errorf("expected error for oversized response")
+	}
+}
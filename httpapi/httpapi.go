@@ -0,0 +1,257 @@
+// Package httpapi serves the root, inclusion proofs and consistency proofs of a merkle tree over HTTP, as JSON.
+//
+//	GET /root                        {"size": 8, "root": "5dc9..."}
+//	GET /proof/{index}               {"index": 3, "proof": {"root": "...", "siblings": [...], "directions": [...]}}
+//	GET /consistency?old=2&new=5     {"oldSize": 2, "newSize": 5, "hashes": [...]}
+//
+// For a SignedLog the handler also serves signed tree heads, leaf hashes, and proofs against older tree heads:
+//
+//	GET /head                        {"size": 8, "root": "5dc9...", "timestamp": 1700000000000, "signature": "..."}
+//	GET /leaf/{index}                {"index": 3, "hash": "..."}
+//	GET /proof/{index}?size=5        {"index": 3, "proof": {...}}
+//
+// Errors are returned as {"error": "..."}.
+package httpapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/internal/jsonhttp"
+)
+
+// Log is the tree served by the handler.
+type Log interface {
+	// Head returns the current number of leaves and the root of the tree with that many leaves.
+	Head() (size int, root []byte, err error)
+	ProofByIndex(i int) (*gomerkletree.Proof, error)
+	ConsistencyProof(oldSize, newSize int) (*gomerkletree.ConsistencyProof, error)
+}
+
+// SignedLog is a log with signed tree heads, like log.Log. Head returns the size and root of its latest signed tree head.
+type SignedLog interface {
+	Log
+	SignedHead() *gomerkletree.SignedTreeHead
+	// InclusionProof generates a proof for the i-th leaf against the tree head with the given size.
+	InclusionProof(i, size int) (*gomerkletree.Proof, error)
+	LeafHash(i int) ([]byte, error)
+}
+
+// FromTree serves a MerkleTree. The tree must not change while it is served, use FromSyncTree instead.
+func FromTree(m *gomerkletree.MerkleTree) Log {
+	return tree{m}
+}
+
+type tree struct {
+	m *gomerkletree.MerkleTree
+}
+
+func (t tree) Head() (int, []byte, error) {
+	return t.m.NumLeaves(), t.m.Root(), nil
+}
+
+func (t tree) ProofByIndex(i int) (*gomerkletree.Proof, error) {
+	return t.m.ProofByIndex(i)
+}
+
+func (t tree) ConsistencyProof(oldSize, newSize int) (*gomerkletree.ConsistencyProof, error) {
+	return t.m.ConsistencyProof(oldSize, newSize)
+}
+
+// FromSyncTree serves a SyncTree, which can be appended to while it is served.
+func FromSyncTree(s *gomerkletree.SyncTree) Log {
+	return syncTree{s}
+}
+
+type syncTree struct {
+	s *gomerkletree.SyncTree
+}
+
+func (t syncTree) Head() (int, []byte, error) {
+	// a snapshot reads the size and root at once
+	snapshot := t.s.Snapshot()
+	return snapshot.Size(), snapshot.Root(), nil
+}
+
+func (t syncTree) ProofByIndex(i int) (*gomerkletree.Proof, error) {
+	return t.s.ProofByIndex(i)
+}
+
+func (t syncTree) ConsistencyProof(oldSize, newSize int) (*gomerkletree.ConsistencyProof, error) {
+	return t.s.ConsistencyProof(oldSize, newSize)
+}
+
+// FromStoredTree serves a StoredTree. The tree must not change while it is served.
+func FromStoredTree(s *gomerkletree.StoredTree) Log {
+	return storedTree{s}
+}
+
+type storedTree struct {
+	*gomerkletree.StoredTree
+}
+
+func (t storedTree) Head() (int, []byte, error) {
+	if t.Size() == 0 {
+		return 0, nil, nil
+	}
+	root, err := t.Root()
+	return t.Size(), root, err
+}
+
+type rootResponse struct {
+	Size int    `json:"size"`
+	Root string `json:"root"`
+}
+
+type proofResponse struct {
+	Index int                 `json:"index"`
+	Proof *gomerkletree.Proof `json:"proof"`
+}
+
+type headResponse struct {
+	Size      int    `json:"size"`
+	Root      string `json:"root"`
+	Timestamp int64  `json:"timestamp"` // milliseconds since the Unix epoch
+	Signature string `json:"signature"`
+}
+
+type leafResponse struct {
+	Index int    `json:"index"`
+	Hash  string `json:"hash"`
+}
+
+// NewHandler returns a handler serving the root and proofs of the log.
+func NewHandler(log Log) http.Handler {
+	h := &handler{log: log}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /root", h.root)
+	mux.HandleFunc("GET /proof/{index}", h.proof)
+	mux.HandleFunc("GET /consistency", h.consistency)
+	if _, ok := log.(SignedLog); ok {
+		mux.HandleFunc("GET /head", h.head)
+		mux.HandleFunc("GET /leaf/{index}", h.leaf)
+	}
+	return mux
+}
+
+type handler struct {
+	log Log
+}
+
+func (h *handler) root(w http.ResponseWriter, r *http.Request) {
+	size, root, err := h.log.Head()
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	jsonhttp.WriteJSON(w, http.StatusOK, rootResponse{Size: size, Root: hex.EncodeToString(root)})
+}
+
+func (h *handler) proof(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid index"))
+		return
+	}
+	size, _, err := h.log.Head()
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var signed SignedLog
+	if query := r.URL.Query().Get("size"); query != "" {
+		var ok bool
+		if signed, ok = h.log.(SignedLog); !ok {
+			jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("proofs against older tree heads not supported"))
+			return
+		}
+		headSize := size
+		if size, err = strconv.Atoi(query); err != nil || size <= 0 || size > headSize {
+			jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid tree size"))
+			return
+		}
+	}
+	if index < 0 || index >= size {
+		jsonhttp.WriteError(w, http.StatusNotFound, errors.New("index out of range"))
+		return
+	}
+
+	var proof *gomerkletree.Proof
+	if signed != nil {
+		proof, err = signed.InclusionProof(index, size)
+	} else {
+		proof, err = h.log.ProofByIndex(index)
+	}
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	jsonhttp.WriteJSON(w, http.StatusOK, proofResponse{Index: index, Proof: proof})
+}
+
+func (h *handler) consistency(w http.ResponseWriter, r *http.Request) {
+	oldSize, err := strconv.Atoi(r.URL.Query().Get("old"))
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid old size"))
+		return
+	}
+	newSize, err := strconv.Atoi(r.URL.Query().Get("new"))
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid new size"))
+		return
+	}
+	size, _, err := h.log.Head()
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if oldSize <= 0 || oldSize > newSize || newSize > size {
+		jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid tree sizes"))
+		return
+	}
+
+	proof, err := h.log.ConsistencyProof(oldSize, newSize)
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	jsonhttp.WriteJSON(w, http.StatusOK, proof)
+}
+
+func (h *handler) head(w http.ResponseWriter, r *http.Request) {
+	head := h.log.(SignedLog).SignedHead()
+	jsonhttp.WriteJSON(w, http.StatusOK, headResponse{
+		Size:      head.Size,
+		Root:      hex.EncodeToString(head.Root),
+		Timestamp: head.Timestamp.UnixMilli(),
+		Signature: hex.EncodeToString(head.Signature),
+	})
+}
+
+func (h *handler) leaf(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusBadRequest, errors.New("invalid index"))
+		return
+	}
+	size, _, err := h.log.Head()
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if index < 0 || index >= size {
+		jsonhttp.WriteError(w, http.StatusNotFound, errors.New("index out of range"))
+		return
+	}
+
+	hash, err := h.log.(SignedLog).LeafHash(index)
+	if err != nil {
+		jsonhttp.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	jsonhttp.WriteJSON(w, http.StatusOK, leafResponse{Index: index, Hash: hex.EncodeToString(hash)})
+}
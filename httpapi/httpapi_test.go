@@ -0,0 +1,156 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/internal/jsonhttp"
+)
+
+type testLeaf string
+
+func (l testLeaf) Bytes() []byte {
+	return []byte(l)
+}
+
+func testData(n int) []gomerkletree.Leaf {
+	var data []gomerkletree.Leaf
+	for i := range n {
+		data = append(data, testLeaf(fmt.Sprint(i)))
+	}
+	return data
+}
+
+func get(t *testing.T, h http.Handler, path string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec.Code
+}
+
+func TestHandler_Root(t *testing.T) {
+	tree := mustBuildMerkleTree(t, testData(5))
+	h := NewHandler(FromTree(tree))
+
+	var res rootResponse
+	if code := get(t, h, "/root", &res); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+
+	if res.Size != 5 || res.Root != hex.EncodeToString(tree.Root()) {
+		t.Errorf("unexpected response: %+v", res)
+	}
+}
+
+func TestHandler_Proof(t *testing.T) {
+	data := testData(5)
+	h := NewHandler(FromTree(mustBuildMerkleTree(t, data)))
+
+	var res proofResponse
+	if code := get(t, h, "/proof/3", &res); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+
+	if res.Index != 3 {
+		t.Errorf("expected index 3, got %d", res.Index)
+	}
+
+	if err := gomerkletree.VerifyProof(data[3], res.Proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var errRes jsonhttp.ErrorResponse
+	for path, status := range map[string]int{"/proof/5": http.StatusNotFound, "/proof/-1": http.StatusNotFound, "/proof/x": http.StatusBadRequest} {
+		if code := get(t, h, path, &errRes); code != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, code)
+		}
+		if errRes.Error == "" {
+			t.Errorf("%s: expected error message", path)
+		}
+	}
+}
+
+func TestHandler_Consistency(t *testing.T) {
+	data := testData(8)
+	s := gomerkletree.NewSyncTree(nil)
+	for _, x := range data[:3] {
+		if err := s.Append(x); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	h := NewHandler(FromSyncTree(s))
+
+	var old rootResponse
+	get(t, h, "/root", &old)
+
+	for _, x := range data[3:] {
+		if err := s.Append(x); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var res gomerkletree.ConsistencyProof
+	if code := get(t, h, "/consistency?old=3&new=8", &res); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+
+	oldRoot, _ := hex.DecodeString(old.Root)
+	if err := gomerkletree.VerifyConsistency(oldRoot, s.Root(), &res); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var errRes jsonhttp.ErrorResponse
+	for _, path := range []string{"/consistency?old=3&new=9", "/consistency?old=0&new=8", "/consistency?old=x&new=8", "/consistency?old=3"} {
+		if code := get(t, h, path, &errRes); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", path, code)
+		}
+	}
+}
+
+func TestHandler_StoredTree(t *testing.T) {
+	data := testData(6)
+	stored, err := gomerkletree.NewStoredTree(gomerkletree.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := NewHandler(FromStoredTree(stored))
+
+	var res rootResponse
+	if code := get(t, h, "/root", &res); code != http.StatusOK || res.Size != 0 {
+		t.Errorf("unexpected response %d: %+v", code, res)
+	}
+
+	if err := stored.AppendBatch(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	get(t, h, "/root", &res)
+	expected := mustBuildMerkleTree(t, data).Root()
+	if root, _ := hex.DecodeString(res.Root); !bytes.Equal(root, expected) {
+		t.Errorf("expected %x, got %s", expected, res.Root)
+	}
+
+	var proof proofResponse
+	get(t, h, "/proof/4", &proof)
+	if err := gomerkletree.VerifyProof(data[4], proof.Proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func mustBuildMerkleTree(t testing.TB, data []gomerkletree.Leaf) *gomerkletree.MerkleTree {
+	t.Helper()
+	tree, err := gomerkletree.BuildMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tree
+}
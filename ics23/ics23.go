@@ -0,0 +1,363 @@
+// Package ics23 converts proofs of merkle trees into the ICS-23 format used by the Cosmos SDK and IBC, and verifies
+// ICS-23 existence proofs, so commitments produced with this library can be verified on other chains, and proofs
+// from other chains can be verified here.
+//
+// ICS-23 commits to key-value pairs. A tree of Entry leaves, built with the default options, is a tree of the
+// TendermintSpec: leaves are hashed as SHA-256(0x00 || varint(len(key)) || key || varint(32) || SHA-256(value)) and
+// internal nodes as SHA-256(0x01 || left || right), like the simple merkle maps of CometBFT. The types mirror the
+// messages of cosmos.ics23.v1 and are encoded in the same protobuf wire format; only existence proofs are supported.
+package ics23
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+// HashOp is a hash function of ICS-23.
+type HashOp int32
+
+const (
+	NoHash     HashOp = 0
+	SHA256     HashOp = 1
+	SHA512     HashOp = 2
+	Keccak256  HashOp = 3
+	RIPEMD160  HashOp = 4
+	Bitcoin    HashOp = 5
+	SHA512_256 HashOp = 6
+	Blake2b512 HashOp = 7
+	Blake2s256 HashOp = 8
+	Blake3     HashOp = 9
+)
+
+// LengthOp is the length prefix ICS-23 puts before keys and values of leaves.
+type LengthOp int32
+
+const (
+	NoPrefix       LengthOp = 0
+	VarProto       LengthOp = 1
+	VarRLP         LengthOp = 2
+	Fixed32Big     LengthOp = 3
+	Fixed32Little  LengthOp = 4
+	Fixed64Big     LengthOp = 5
+	Fixed64Little  LengthOp = 6
+	Require32Bytes LengthOp = 7
+	Require64Bytes LengthOp = 8
+)
+
+// LeafOp hashes a leaf as Hash(Prefix || Length(PrehashKey(key)) || Length(PrehashValue(value))).
+type LeafOp struct {
+	Hash         HashOp
+	PrehashKey   HashOp
+	PrehashValue HashOp
+	Length       LengthOp
+	Prefix       []byte
+}
+
+// InnerOp hashes an internal node as Hash(Prefix || child || Suffix), where the prefix and suffix hold the siblings.
+type InnerOp struct {
+	Hash   HashOp
+	Prefix []byte
+	Suffix []byte
+}
+
+// ExistenceProof proves that a key has a value, with the operations from the leaf up to the root.
+type ExistenceProof struct {
+	Key   []byte
+	Value []byte
+	Leaf  *LeafOp
+	Path  []*InnerOp
+}
+
+// CommitmentProof wraps an existence proof, like the CommitmentProof of ICS-23 that IBC sends.
+type CommitmentProof struct {
+	Exist *ExistenceProof
+}
+
+// InnerSpec describes the internal nodes of a tree.
+type InnerSpec struct {
+	ChildOrder      []int32
+	ChildSize       int32
+	MinPrefixLength int32
+	MaxPrefixLength int32
+	EmptyChild      []byte
+	Hash            HashOp
+}
+
+// ProofSpec describes the proofs of a tree, which existence proofs must match to be verified.
+type ProofSpec struct {
+	LeafSpec  *LeafOp
+	InnerSpec *InnerSpec
+	MaxDepth  int32
+	MinDepth  int32
+}
+
+// TendermintSpec is the spec of the simple merkle maps of CometBFT (Tendermint), and of trees of Entry leaves.
+var TendermintSpec = &ProofSpec{
+	LeafSpec: &LeafOp{
+		Hash:         SHA256,
+		PrehashKey:   NoHash,
+		PrehashValue: SHA256,
+		Length:       VarProto,
+		Prefix:       []byte{0},
+	},
+	InnerSpec: &InnerSpec{
+		ChildOrder:      []int32{0, 1},
+		ChildSize:       32,
+		MinPrefixLength: 1,
+		MaxPrefixLength: 1,
+		Hash:            SHA256,
+	},
+}
+
+// Entry is a key-value pair as a leaf of the TendermintSpec, encoded as
+// varint(len(key)) || key || varint(32) || SHA-256(value).
+type Entry struct {
+	Key   []byte
+	Value []byte
+}
+
+// Bytes returns the encoded entry. The default hash strategy prefixes it with 0x00, like the leaf op of the spec.
+func (e Entry) Bytes() []byte {
+	spec := *TendermintSpec.LeafSpec
+	spec.Prefix = nil
+	b, _ := leafData(&spec, e.Key, e.Value) // can't fail for the ops of the spec
+	return b
+}
+
+// ConvertProof converts the proof of the Entry with the key and value, in a tree of Entry leaves built with the
+// default options, into an existence proof of the TendermintSpec.
+func ConvertProof(p *gomerkletree.Proof, key, value []byte) (*ExistenceProof, error) {
+	if p == nil {
+		return nil, errors.New("no proof")
+	}
+	leaf := *TendermintSpec.LeafSpec
+	e := &ExistenceProof{
+		Key:   bytes.Clone(key),
+		Value: bytes.Clone(value),
+		Leaf:  &leaf,
+	}
+	left := p.Directions()
+	for i, sibling := range p.Siblings() {
+		if len(sibling) != sha256.Size {
+			return nil, errors.New("invalid sibling length")
+		}
+		if left[i] {
+			e.Path = append(e.Path, &InnerOp{Hash: SHA256, Prefix: append([]byte{1}, sibling...)})
+		} else {
+			e.Path = append(e.Path, &InnerOp{Hash: SHA256, Prefix: []byte{1}, Suffix: sibling})
+		}
+	}
+
+	root, err := e.Calculate()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, p.Root()) {
+		return nil, errors.New("proof not of the TendermintSpec")
+	}
+	return e, nil
+}
+
+// Proof converts an existence proof of the TendermintSpec back into a proof for root, which can be verified with
+// gomerkletree.VerifyProof and the Entry of the key and value.
+func (e *ExistenceProof) Proof(root []byte) (*gomerkletree.Proof, error) {
+	if err := e.CheckAgainstSpec(TendermintSpec); err != nil {
+		return nil, err
+	}
+	siblings := make([][]byte, len(e.Path))
+	left := make([]bool, len(e.Path))
+	for i, op := range e.Path {
+		switch {
+		case len(op.Prefix) == 1 && len(op.Suffix) == sha256.Size:
+			siblings[i] = op.Suffix
+		case len(op.Prefix) == 1+sha256.Size && len(op.Suffix) == 0:
+			siblings[i] = op.Prefix[1:]
+			left[i] = true
+		default:
+			return nil, errors.New("inner op not of the TendermintSpec")
+		}
+	}
+	return gomerkletree.NewProof(root, siblings, left, nil), nil
+}
+
+// Calculate returns the root the proof leads to.
+func (e *ExistenceProof) Calculate() ([]byte, error) {
+	if e == nil || e.Leaf == nil {
+		return nil, errors.New("existence proof needs a leaf op")
+	}
+	h, err := e.Leaf.Apply(e.Key, e.Value)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range e.Path {
+		if op == nil {
+			return nil, errors.New("nil inner op")
+		}
+		if h, err = op.Apply(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Verify checks that the proof matches the spec, proves the key and value, and leads to root.
+func (e *ExistenceProof) Verify(spec *ProofSpec, root, key, value []byte) error {
+	if err := e.CheckAgainstSpec(spec); err != nil {
+		return err
+	}
+	if !bytes.Equal(key, e.Key) {
+		return errors.New("proof not for key")
+	}
+	if !bytes.Equal(value, e.Value) {
+		return errors.New("proof not for value")
+	}
+	calculated, err := e.Calculate()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(calculated, root) {
+		return errors.New("calculated root does not match")
+	}
+	return nil
+}
+
+// CheckAgainstSpec checks that the operations of the proof are the ones of the spec, so a proof can't pass off an
+// internal node as a leaf or the other way around.
+func (e *ExistenceProof) CheckAgainstSpec(spec *ProofSpec) error {
+	if e == nil || e.Leaf == nil {
+		return errors.New("existence proof needs a leaf op")
+	}
+	if spec == nil || spec.LeafSpec == nil || spec.InnerSpec == nil {
+		return errors.New("incomplete spec")
+	}
+	leaf := spec.LeafSpec
+	if e.Leaf.Hash != leaf.Hash || e.Leaf.PrehashKey != leaf.PrehashKey || e.Leaf.PrehashValue != leaf.PrehashValue ||
+		e.Leaf.Length != leaf.Length || !bytes.HasPrefix(e.Leaf.Prefix, leaf.Prefix) {
+		return errors.New("leaf op does not match spec")
+	}
+	if spec.MinDepth > 0 && len(e.Path) < int(spec.MinDepth) {
+		return errors.New("proof too short")
+	}
+	if spec.MaxDepth > 0 && len(e.Path) > int(spec.MaxDepth) {
+		return errors.New("proof too long")
+	}
+
+	inner := spec.InnerSpec
+	maxPrefix := int(inner.MaxPrefixLength) + (len(inner.ChildOrder)-1)*int(inner.ChildSize)
+	for _, op := range e.Path {
+		switch {
+		case op == nil:
+			return errors.New("nil inner op")
+		case op.Hash != inner.Hash:
+			return errors.New("inner op hash does not match spec")
+		case bytes.HasPrefix(op.Prefix, leaf.Prefix):
+			return errors.New("inner op prefix starts with leaf prefix")
+		case len(op.Prefix) < int(inner.MinPrefixLength) || len(op.Prefix) > maxPrefix:
+			return errors.New("invalid inner op prefix length")
+		case inner.ChildSize <= 0 || len(op.Suffix)%int(inner.ChildSize) != 0:
+			return errors.New("invalid inner op suffix length")
+		}
+	}
+	return nil
+}
+
+// VerifyMembership reports whether the commitment proof proves that key has value under root, like the function
+// of the same name of ICS-23.
+func VerifyMembership(spec *ProofSpec, root []byte, proof *CommitmentProof, key, value []byte) bool {
+	if proof == nil || proof.Exist == nil {
+		return false
+	}
+	return proof.Exist.Verify(spec, root, key, value) == nil
+}
+
+// Apply returns the hash of the leaf with the key and value.
+func (op *LeafOp) Apply(key, value []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("leaf op needs key")
+	}
+	if len(value) == 0 {
+		return nil, errors.New("leaf op needs value")
+	}
+	data, err := leafData(op, key, value)
+	if err != nil {
+		return nil, err
+	}
+	return doHash(op.Hash, data)
+}
+
+// leafData returns the data of a leaf that is hashed, without the leaf hash itself.
+func leafData(op *LeafOp, key, value []byte) ([]byte, error) {
+	k, err := prepare(op.PrehashKey, op.Length, key)
+	if err != nil {
+		return nil, err
+	}
+	v, err := prepare(op.PrehashValue, op.Length, value)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(bytes.Clone(op.Prefix), k...), v...), nil
+}
+
+// Apply returns the hash of the parent of child.
+func (op *InnerOp) Apply(child []byte) ([]byte, error) {
+	if len(child) == 0 {
+		return nil, errors.New("inner op needs child value")
+	}
+	data := append(append(bytes.Clone(op.Prefix), child...), op.Suffix...)
+	return doHash(op.Hash, data)
+}
+
+// prepare hashes b with the prehash op and prefixes it with its length.
+func prepare(prehash HashOp, length LengthOp, b []byte) ([]byte, error) {
+	b, err := doHash(prehash, b)
+	if err != nil {
+		return nil, err
+	}
+	switch length {
+	case NoPrefix:
+		return b, nil
+	case VarProto:
+		return append(binary.AppendUvarint(nil, uint64(len(b))), b...), nil
+	case Fixed32Big:
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...), nil
+	case Fixed32Little:
+		return append(binary.LittleEndian.AppendUint32(nil, uint32(len(b))), b...), nil
+	case Fixed64Big:
+		return append(binary.BigEndian.AppendUint64(nil, uint64(len(b))), b...), nil
+	case Fixed64Little:
+		return append(binary.LittleEndian.AppendUint64(nil, uint64(len(b))), b...), nil
+	case Require32Bytes:
+		if len(b) != 32 {
+			return nil, errors.New("data is not 32 bytes")
+		}
+		return b, nil
+	case Require64Bytes:
+		if len(b) != 64 {
+			return nil, errors.New("data is not 64 bytes")
+		}
+		return b, nil
+	}
+	return nil, errors.New("unsupported length op")
+}
+
+func doHash(op HashOp, b []byte) ([]byte, error) {
+	switch op {
+	case NoHash:
+		return b, nil
+	case SHA256:
+		h := sha256.Sum256(b)
+		return h[:], nil
+	case SHA512:
+		h := sha512.Sum512(b)
+		return h[:], nil
+	case SHA512_256:
+		h := sha512.Sum512_256(b)
+		return h[:], nil
+	}
+	return nil, errors.New("unsupported hash op")
+}
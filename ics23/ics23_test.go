@@ -0,0 +1,225 @@
+package ics23
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func buildEntryTree(t *testing.T, n int, opts ...gomerkletree.Option) (*gomerkletree.MerkleTree, []Entry) {
+	t.Helper()
+	entries := make([]Entry, n)
+	data := make([]gomerkletree.Leaf, n)
+	for i := range entries {
+		entries[i] = Entry{Key: []byte(fmt.Sprint("key", i)), Value: []byte(fmt.Sprint("value", i))}
+		data[i] = entries[i]
+	}
+	tree, err := gomerkletree.BuildMerkleTree(data, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tree, entries
+}
+
+func TestEntry_Bytes(t *testing.T) {
+	e := Entry{Key: []byte("foo"), Value: []byte("bar")}
+	v := sha256.Sum256([]byte("bar"))
+	want := append([]byte{3, 'f', 'o', 'o', 32}, v[:]...)
+	if !bytes.Equal(e.Bytes(), want) {
+		t.Errorf("expected %x, got %x", want, e.Bytes())
+	}
+
+	// the leaf op of the spec hashes the same bytes as the default hash strategy
+	h, err := TendermintSpec.LeafSpec.Apply(e.Key, e.Value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := sha256.Sum256(append([]byte{0}, e.Bytes()...))
+	if !bytes.Equal(h, d[:]) {
+		t.Errorf("expected %x, got %x", d, h)
+	}
+}
+
+func TestConvertProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		tree, entries := buildEntryTree(t, n)
+		for i, e := range entries {
+			p, err := tree.ProofByIndex(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			exist, err := ConvertProof(p, e.Key, e.Value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := exist.Verify(TendermintSpec, tree.Root(), e.Key, e.Value); err != nil {
+				t.Errorf("%d/%d: expected valid proof, got %v", i, n, err)
+			}
+			if !VerifyMembership(TendermintSpec, tree.Root(), &CommitmentProof{Exist: exist}, e.Key, e.Value) {
+				t.Errorf("%d/%d: expected membership", i, n)
+			}
+			if VerifyMembership(TendermintSpec, tree.Root(), &CommitmentProof{Exist: exist}, e.Key, []byte("other")) {
+				t.Errorf("%d/%d: expected no membership for other value", i, n)
+			}
+		}
+	}
+}
+
+func TestConvertProof_Errors(t *testing.T) {
+	if _, err := ConvertProof(nil, []byte("k"), []byte("v")); err == nil {
+		t.Errorf("expected error for nil proof")
+	}
+
+	tree, entries := buildEntryTree(t, 4)
+	p, err := tree.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ConvertProof(p, entries[1].Key, entries[1].Value); err == nil {
+		t.Errorf("expected error for other entry")
+	}
+
+	tree, entries = buildEntryTree(t, 4, gomerkletree.WithHashStrategy(hashing.SHA3Strategy{}))
+	p, err = tree.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ConvertProof(p, entries[0].Key, entries[0].Value); err == nil {
+		t.Errorf("expected error for other hash strategy")
+	}
+}
+
+func TestExistenceProof_Proof(t *testing.T) {
+	tree, entries := buildEntryTree(t, 7)
+	for i, e := range entries {
+		p, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		exist, err := ConvertProof(p, e.Key, e.Value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		back, err := exist.Proof(tree.Root())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := gomerkletree.VerifyProof(e, back); err != nil {
+			t.Errorf("%d: expected valid proof, got %v", i, err)
+		}
+	}
+}
+
+func TestExistenceProof_Verify(t *testing.T) {
+	tree, entries := buildEntryTree(t, 5)
+	p, err := tree.ProofByIndex(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := entries[2]
+
+	tests := []struct {
+		name   string
+		change func(p *ExistenceProof)
+	}{
+		{"Key", func(p *ExistenceProof) { p.Key = []byte("other") }},
+		{"NoLeaf", func(p *ExistenceProof) { p.Leaf = nil }},
+		{"LeafHash", func(p *ExistenceProof) { p.Leaf.Hash = SHA512 }},
+		{"LeafPrefix", func(p *ExistenceProof) { p.Leaf.Prefix = []byte{1} }},
+		{"InnerHash", func(p *ExistenceProof) { p.Path[0].Hash = SHA512 }},
+		{"InnerLeafPrefix", func(p *ExistenceProof) { p.Path[0].Prefix = []byte{0} }},
+		{"InnerPrefixLength", func(p *ExistenceProof) { p.Path[0].Prefix = make([]byte, 34) }},
+		{"InnerSuffixLength", func(p *ExistenceProof) { p.Path[0].Suffix = []byte{1} }},
+		{"Sibling", func(p *ExistenceProof) { p.Path[1].Prefix[1] ^= 1 }},
+		{"TooShort", func(p *ExistenceProof) { p.Path = p.Path[:1] }},
+	}
+
+	for _, tt := range tests {
+		exist, err := ConvertProof(p, e.Key, e.Value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tt.change(exist)
+		if err := exist.Verify(TendermintSpec, tree.Root(), e.Key, e.Value); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	exist, err := ConvertProof(p, e.Key, e.Value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec := *TendermintSpec
+	spec.MaxDepth = 2
+	if err := exist.Verify(&spec, tree.Root(), e.Key, e.Value); err == nil {
+		t.Errorf("expected error for proof deeper than max depth")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	op := &InnerOp{Hash: SHA256, Prefix: []byte{1}}
+	if got := hex.EncodeToString(op.appendProto(nil)); got != "0801120101" {
+		t.Errorf("expected 0801120101, got %s", got)
+	}
+	if got := hex.EncodeToString(TendermintSpec.LeafSpec.appendProto(nil)); got != "0801180120012a0100" {
+		t.Errorf("expected 0801180120012a0100, got %s", got)
+	}
+
+	tree, entries := buildEntryTree(t, 6)
+	p, err := tree.ProofByIndex(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exist, err := ConvertProof(p, entries[5].Key, entries[5].Value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := (&CommitmentProof{Exist: exist}).Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded CommitmentProof
+	if err := decoded.Unmarshal(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Exist, exist) {
+		t.Errorf("expected %+v, got %+v", exist, decoded.Exist)
+	}
+	if !VerifyMembership(TendermintSpec, tree.Root(), &decoded, entries[5].Key, entries[5].Value) {
+		t.Errorf("expected membership of decoded proof")
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		b    string
+	}{
+		{"Truncated", "0a05"},
+		{"Tag", "80"},
+		{"FieldZero", "0200"},
+		{"NonExist", "1200"},
+		{"WireType", "08"},
+		{"LeafWireType", "0a041a020a00"},
+	}
+	for _, tt := range tests {
+		b, _ := hex.DecodeString(tt.b)
+		var p CommitmentProof
+		if err := p.Unmarshal(b); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	// unknown fields are skipped
+	var p CommitmentProof
+	if err := p.Unmarshal([]byte{0x0a, 0x02, 0x28, 0x01}); err != nil || p.Exist == nil {
+		t.Errorf("expected unknown field to be skipped, got %v", err)
+	}
+}
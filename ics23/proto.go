@@ -0,0 +1,223 @@
+package ics23
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// The wire types of protobuf that the messages of ICS-23 use.
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireBytes  = 2
+	wireI32    = 5
+)
+
+// Marshal encodes the proof as a cosmos.ics23.v1.CommitmentProof.
+func (p *CommitmentProof) Marshal() ([]byte, error) {
+	if p == nil || p.Exist == nil {
+		return nil, errors.New("commitment proof needs an existence proof")
+	}
+	return appendMessage(nil, 1, p.Exist.appendProto(nil)), nil
+}
+
+// Unmarshal decodes a cosmos.ics23.v1.CommitmentProof. Proofs other than existence proofs are rejected.
+func (p *CommitmentProof) Unmarshal(b []byte) error {
+	*p = CommitmentProof{}
+	return decode(b, func(field int, wire int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			if wire != wireBytes {
+				return errors.New("invalid wire type")
+			}
+			p.Exist = new(ExistenceProof)
+			return p.Exist.Unmarshal(data)
+		case 2, 3, 4:
+			return errors.New("unsupported commitment proof")
+		}
+		return nil
+	})
+}
+
+// Marshal encodes the proof as a cosmos.ics23.v1.ExistenceProof.
+func (e *ExistenceProof) Marshal() ([]byte, error) {
+	if e == nil {
+		return nil, errors.New("nil existence proof")
+	}
+	return e.appendProto(nil), nil
+}
+
+func (e *ExistenceProof) appendProto(b []byte) []byte {
+	b = appendBytes(b, 1, e.Key)
+	b = appendBytes(b, 2, e.Value)
+	if e.Leaf != nil {
+		b = appendMessage(b, 3, e.Leaf.appendProto(nil))
+	}
+	for _, op := range e.Path {
+		if op != nil {
+			b = appendMessage(b, 4, op.appendProto(nil))
+		}
+	}
+	return b
+}
+
+// Unmarshal decodes a cosmos.ics23.v1.ExistenceProof.
+func (e *ExistenceProof) Unmarshal(b []byte) error {
+	*e = ExistenceProof{}
+	return decode(b, func(field int, wire int, v uint64, data []byte) error {
+		if field < 1 || field > 4 {
+			return nil
+		}
+		if wire != wireBytes {
+			return errors.New("invalid wire type")
+		}
+		switch field {
+		case 1:
+			e.Key = data
+		case 2:
+			e.Value = data
+		case 3:
+			e.Leaf = new(LeafOp)
+			return e.Leaf.unmarshal(data)
+		case 4:
+			op := new(InnerOp)
+			if err := op.unmarshal(data); err != nil {
+				return err
+			}
+			e.Path = append(e.Path, op)
+		}
+		return nil
+	})
+}
+
+func (op *LeafOp) appendProto(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(op.Hash))
+	b = appendVarint(b, 2, uint64(op.PrehashKey))
+	b = appendVarint(b, 3, uint64(op.PrehashValue))
+	b = appendVarint(b, 4, uint64(op.Length))
+	return appendBytes(b, 5, op.Prefix)
+}
+
+func (op *LeafOp) unmarshal(b []byte) error {
+	return decode(b, func(field int, wire int, v uint64, data []byte) error {
+		if field < 1 || field > 5 {
+			return nil
+		}
+		if (field == 5) != (wire == wireBytes) || (field != 5 && wire != wireVarint) {
+			return errors.New("invalid wire type")
+		}
+		switch field {
+		case 1:
+			op.Hash = HashOp(v)
+		case 2:
+			op.PrehashKey = HashOp(v)
+		case 3:
+			op.PrehashValue = HashOp(v)
+		case 4:
+			op.Length = LengthOp(v)
+		case 5:
+			op.Prefix = data
+		}
+		return nil
+	})
+}
+
+func (op *InnerOp) appendProto(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(op.Hash))
+	b = appendBytes(b, 2, op.Prefix)
+	return appendBytes(b, 3, op.Suffix)
+}
+
+func (op *InnerOp) unmarshal(b []byte) error {
+	return decode(b, func(field int, wire int, v uint64, data []byte) error {
+		if field < 1 || field > 3 {
+			return nil
+		}
+		if (field == 1) != (wire == wireVarint) || (field != 1 && wire != wireBytes) {
+			return errors.New("invalid wire type")
+		}
+		switch field {
+		case 1:
+			op.Hash = HashOp(v)
+		case 2:
+			op.Prefix = data
+		case 3:
+			op.Suffix = data
+		}
+		return nil
+	})
+}
+
+// appendVarint appends a varint field, which proto3 leaves out when it is zero.
+func appendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendBytes appends a bytes field, which proto3 leaves out when it is empty.
+func appendBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessage(b, field, v)
+}
+
+// appendMessage appends a length-delimited field, also when it is empty, as an empty message is still set.
+func appendMessage(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// decode calls fn for every field of the message in b, with the value of varint fields and the data of
+// length-delimited fields. Fixed size fields are skipped, as ICS-23 doesn't use them.
+func decode(b []byte, fn func(field int, wire int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("invalid tag")
+		}
+		b = b[n:]
+		field, wire := int(tag>>3), int(tag&7)
+		if field == 0 || tag>>3 > 1<<29-1 {
+			return errors.New("invalid field number")
+		}
+
+		var v uint64
+		var data []byte
+		switch wire {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errors.New("invalid varint")
+			}
+			b = b[n:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return errors.New("invalid length")
+			}
+			data = bytes.Clone(b[n : n+int(l)]) // so the decoded proof doesn't share memory with b
+			b = b[n+int(l):]
+		case wireI64:
+			if len(b) < 8 {
+				return errors.New("unexpected end of message")
+			}
+			b = b[8:]
+		case wireI32:
+			if len(b) < 4 {
+				return errors.New("unexpected end of message")
+			}
+			b = b[4:]
+		default:
+			return errors.New("unsupported wire type")
+		}
+		if err := fn(field, wire, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Package ingest commits to a stream of records, like a Kafka partition, with a transparency log.
+//
+// A Consumer reads records from a Source and appends them to a log.Log as they arrive. Every interval, and whenever
+// enough records are pending, it integrates them and emits the new signed tree head. Inclusion proofs are looked up
+// by the offset of a record, so consumers of the stream can check that the record they read is the one committed to.
+package ingest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/log"
+)
+
+// DefaultBatchSize is the number of pending records that triggers an integration between intervals.
+const DefaultBatchSize = 1000
+
+// Record is a record of a stream. It is a leaf of the log committing to its offset, key and value, encoded as
+//
+//	offset (8 bytes, big endian) | key length (uvarint) | key | value
+type Record struct {
+	Offset int64
+	Key    []byte
+	Value  []byte
+}
+
+func (r Record) Bytes() []byte {
+	b := make([]byte, 0, 8+binary.MaxVarintLen64+len(r.Key)+len(r.Value))
+	b = binary.BigEndian.AppendUint64(b, uint64(r.Offset))
+	b = binary.AppendUvarint(b, uint64(len(r.Key)))
+	b = append(b, r.Key...)
+	return append(b, r.Value...)
+}
+
+// Source is the stream that is consumed, e.g. a partition read with a Kafka client.
+type Source interface {
+	// Next blocks until the next record is available. It returns io.EOF at the end of a finite stream,
+	// and the error of the context when it is done.
+	Next(ctx context.Context) (Record, error)
+}
+
+// Committer is implemented by sources that track the progress of the consumer, like the committed offsets of a
+// Kafka consumer group. Commit is called with the offset of the last record of every signed tree head, so
+// records are only committed once they are in the log.
+type Committer interface {
+	Commit(ctx context.Context, offset int64) error
+}
+
+// Option configures a Consumer.
+type Option func(*Consumer)
+
+// WithBatchSize integrates as soon as n records are pending, instead of waiting for the next interval.
+func WithBatchSize(n int) Option {
+	return func(c *Consumer) {
+		c.batchSize = max(n, 1)
+	}
+}
+
+// Consumer appends the records of a source to a log. It is safe for concurrent use.
+type Consumer struct {
+	src       Source
+	log       *log.Log
+	emit      func(*gomerkletree.SignedTreeHead)
+	batchSize int
+
+	integrating sync.Mutex // orders the commits and heads of concurrent integrations
+	mu          sync.RWMutex
+	base        int     // size of the log before the first record
+	offsets     []int64 // offsets of the records by index in the log minus base, ascending
+	integrated  int     // number of offsets in the latest signed tree head
+}
+
+// New returns a consumer appending the records of src to l. Signed tree heads are emitted to emit, which can be nil.
+// The consumer must be the only writer of the log. Entries already in it, e.g. from an earlier run on the same store,
+// are kept, but can't be looked up by offset.
+func New(src Source, l *log.Log, emit func(*gomerkletree.SignedTreeHead), opts ...Option) *Consumer {
+	size, _, _ := l.Head() // never fails
+	c := &Consumer{
+		src:       src,
+		log:       l,
+		emit:      emit,
+		batchSize: DefaultBatchSize,
+		base:      size + l.Pending(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run consumes records until the source ends or fails, or the context is done, integrating them every interval
+// and whenever a batch is full. Records that were consumed are integrated before Run returns.
+// Returns nil at the end of the source, the error of the context, or the first error of the source or the log.
+func (c *Consumer) Run(ctx context.Context, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var tickErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.Integrate(ctx); err != nil {
+					tickErr = err
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := c.consume(ctx)
+	cancel()
+	wg.Wait()
+	if tickErr != nil {
+		return tickErr
+	}
+	if c.log.Pending() > 0 {
+		if _, ierr := c.Integrate(context.WithoutCancel(ctx)); ierr != nil {
+			return ierr
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func (c *Consumer) consume(ctx context.Context) error {
+	for {
+		r, err := c.src.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if !c.add(r) {
+			continue
+		}
+		if c.log.Pending() >= c.batchSize {
+			if _, err := c.Integrate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// add sequences a record in the log. Records at or before the last offset are skipped, since sources that deliver
+// at least once may repeat records.
+func (c *Consumer) add(r Record) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n := len(c.offsets); n > 0 && r.Offset <= c.offsets[n-1] {
+		return false
+	}
+	c.log.Add(r)
+	c.offsets = append(c.offsets, r.Offset)
+	return true
+}
+
+// Integrate integrates the pending records into the log, commits their offsets if the source is a Committer,
+// and emits the new signed tree head.
+func (c *Consumer) Integrate(ctx context.Context) (*gomerkletree.SignedTreeHead, error) {
+	c.integrating.Lock()
+	defer c.integrating.Unlock()
+
+	c.mu.Lock()
+	head, err := c.log.Integrate()
+	if err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.integrated = head.Size - c.base
+	last := int64(-1)
+	if c.integrated > 0 {
+		last = c.offsets[c.integrated-1]
+	}
+	c.mu.Unlock()
+
+	if committer, ok := c.src.(Committer); ok && last >= 0 {
+		if err := committer.Commit(ctx, last); err != nil {
+			return nil, err
+		}
+	}
+	if c.emit != nil {
+		c.emit(head)
+	}
+	return head, nil
+}
+
+// Index returns the index in the log of the record with the given offset, if it is integrated.
+func (c *Consumer) Index(offset int64) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.index(offset)
+}
+
+func (c *Consumer) index(offset int64) (int, error) {
+	integrated := c.offsets[:c.integrated]
+	i := sort.Search(len(integrated), func(i int) bool { return integrated[i] >= offset })
+	if i == len(integrated) || integrated[i] != offset {
+		return -1, errors.New("offset not in log")
+	}
+	return c.base + i, nil
+}
+
+// InclusionProof generates a proof for the record with the given offset, and returns it with the signed tree head
+// it was generated against. The record can then be verified with log.VerifyInclusion.
+func (c *Consumer) InclusionProof(offset int64) (*gomerkletree.Proof, *gomerkletree.SignedTreeHead, error) {
+	// the lock keeps Integrate from signing a new head between the proof and the head
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	i, err := c.index(offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	head := c.log.SignedHead()
+	p, err := c.log.InclusionProof(i, head.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, head, nil
+}
+
+// SignedHead returns the latest signed tree head of the log.
+func (c *Consumer) SignedHead() *gomerkletree.SignedTreeHead {
+	return c.log.SignedHead()
+}
@@ -0,0 +1,173 @@
+package ingest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/log"
+)
+
+// testSource delivers the records sent on a channel, ending with io.EOF when it is closed.
+type testSource struct {
+	records chan Record
+
+	mu        sync.Mutex
+	committed []int64
+}
+
+func (s *testSource) Next(ctx context.Context) (Record, error) {
+	select {
+	case <-ctx.Done():
+		return Record{}, ctx.Err()
+	case r, ok := <-s.records:
+		if !ok {
+			return Record{}, io.EOF
+		}
+		return r, nil
+	}
+}
+
+func (s *testSource) Commit(_ context.Context, offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.committed = append(s.committed, offset)
+	return nil
+}
+
+func newLog(t *testing.T) (*log.Log, ed25519.PublicKey) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l, err := log.New(gomerkletree.NewMemoryStore(), key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return l, l.PublicKey()
+}
+
+func record(offset int64) Record {
+	return Record{Offset: offset, Key: []byte("key"), Value: []byte(fmt.Sprintf("value %d", offset))}
+}
+
+func TestConsumer_Run(t *testing.T) {
+	l, pub := newLog(t)
+	src := &testSource{records: make(chan Record, 16)}
+	var heads []*gomerkletree.SignedTreeHead
+	c := New(src, l, func(h *gomerkletree.SignedTreeHead) { heads = append(heads, h) }, WithBatchSize(4))
+
+	// offsets with gaps, like a compacted topic, and a redelivered record
+	offsets := []int64{0, 1, 3, 4, 4, 7, 8, 9, 12}
+	for _, o := range offsets {
+		src.records <- record(o)
+	}
+	close(src.records)
+
+	if err := c.Run(context.Background(), time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size := c.SignedHead().Size; size != 8 {
+		t.Fatalf("expected 8 records, got %d", size)
+	}
+	// two full batches, leaving nothing to integrate at the end of the source
+	if len(heads) != 2 || heads[0].Size != 4 || heads[1].Size != 8 {
+		t.Errorf("unexpected heads")
+	}
+	if len(src.committed) != 2 || src.committed[0] != 4 || src.committed[1] != 12 {
+		t.Errorf("unexpected commits %v", src.committed)
+	}
+
+	for i, o := range []int64{0, 1, 3, 4, 7, 8, 9, 12} {
+		index, err := c.Index(o)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if index != i {
+			t.Errorf("expected index %d for offset %d, got %d", i, o, index)
+		}
+		p, head, err := c.InclusionProof(o)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := log.VerifyInclusion(pub, head, record(o), p); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := log.VerifyInclusion(pub, head, record(o+1), p); err == nil {
+			t.Errorf("expected error for another record")
+		}
+	}
+	if _, _, err := c.InclusionProof(2); err == nil {
+		t.Errorf("expected error for missing offset")
+	}
+}
+
+func TestConsumer_Interval(t *testing.T) {
+	l, pub := newLog(t)
+	src := &testSource{records: make(chan Record)}
+	heads := make(chan *gomerkletree.SignedTreeHead, 16)
+	c := New(src, l, func(h *gomerkletree.SignedTreeHead) { heads <- h })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Run(ctx, 10*time.Millisecond) }()
+
+	src.records <- record(5)
+	for head := range heads {
+		if err := gomerkletree.VerifyTreeHead(head, pub); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if head.Size == 1 {
+			break
+		}
+	}
+	if _, _, err := c.InclusionProof(5); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// a record consumed before cancelling is integrated before Run returns
+	src.records <- record(6)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if _, err := c.Index(6); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConsumer_ExistingLog(t *testing.T) {
+	l, pub := newLog(t)
+	l.Add(record(100))
+	if _, err := l.Integrate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	src := &testSource{records: make(chan Record, 1)}
+	c := New(src, l, nil)
+	src.records <- record(0)
+	close(src.records)
+	if err := c.Run(context.Background(), time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if i, err := c.Index(0); err != nil || i != 1 {
+		t.Errorf("expected index 1, got %d (%v)", i, err)
+	}
+	if _, err := c.Index(100); err == nil {
+		t.Errorf("expected error for an entry of an earlier run")
+	}
+	p, head, err := c.InclusionProof(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := log.VerifyInclusion(pub, head, record(0), p); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
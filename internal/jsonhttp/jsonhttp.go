@@ -0,0 +1,45 @@
+// Package jsonhttp holds the JSON responses and errors shared by the HTTP servers and clients of the module.
+package jsonhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxResponseSize is the most bytes a client reads from the body of a response.
+// The servers a client talks to may be adversarial, so it doesn't trust them to send a small body.
+const MaxResponseSize = 1 << 20
+
+// ErrorResponse is the body of an error response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// WriteJSON writes v as the body of a response with the given status.
+// If v can't be encoded, the response is an internal server error instead.
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		b, _ = json.Marshal(ErrorResponse{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(b, '\n'))
+}
+
+// WriteError writes err as the body of an error response with the given status.
+func WriteError(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// ResponseError returns the error in the body of a response that isn't 200 OK, or its status if the body has none.
+func ResponseError(res *http.Response) error {
+	var errRes ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, MaxResponseSize)).Decode(&errRes); err != nil || errRes.Error == "" {
+		return errors.New(res.Status)
+	}
+	return errors.New(errRes.Error)
+}
@@ -0,0 +1,303 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// KaryHashStrategy is implemented by hash strategies that can hash more than two children into a single node.
+// Hash strategies that don't implement it hash the children of k-ary trees by folding HashInternal from left to right.
+type KaryHashStrategy interface {
+	HashStrategy
+	HashChildren(children [][]byte) []byte
+}
+
+// HashChildren hashes any number of children as SHA-256(0x01 || c_1 || ... || c_k),
+// which is the same as HashInternal for two children.
+func (h defaultHashStrategy) HashChildren(children [][]byte) []byte {
+	bytes := []byte{0x01}
+	for _, c := range children {
+		bytes = append(bytes, c...)
+	}
+	return hashing.HashSHA256(bytes)
+}
+
+func hashChildren(hash HashStrategy, children [][]byte) []byte {
+	if k, ok := hash.(KaryHashStrategy); ok {
+		return k.HashChildren(children)
+	}
+	h := children[0]
+	for _, c := range children[1:] {
+		h = hash.HashInternal(h, c)
+	}
+	return h
+}
+
+// KaryMerkleTree is a merkle tree where every internal node has up to k children instead of two.
+// Wider trees have fewer levels, so they need fewer hashes and fewer reads from storage to build and verify,
+// at the cost of larger proofs (up to k-1 siblings per level).
+// Like MerkleTree, a node without siblings is promoted to the level above.
+type KaryMerkleTree struct {
+	levels       [][][]byte // the leaf hashes, followed by every level above, up to the root
+	arity        int
+	hashStrategy HashStrategy
+}
+
+// KaryProof is the proof for a leaf of a k-ary tree: for every level, the other children of the node on the path,
+// and the position of the node among them.
+type KaryProof struct {
+	root         []byte
+	siblings     [][][]byte
+	positions    []int
+	hashStrategy HashStrategy
+}
+
+// NewKaryProof assembles a k-ary proof from its contents, e.g. after receiving them over the network: for every level,
+// the other children of the node on the path, and the position of the node among them.
+// A nil hash strategy means the default hash strategy.
+func NewKaryProof(root []byte, siblings [][][]byte, positions []int, hash HashStrategy) *KaryProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	p := &KaryProof{
+		root:         bytes.Clone(root),
+		siblings:     make([][][]byte, len(siblings)),
+		positions:    slices.Clone(positions),
+		hashStrategy: hash,
+	}
+	for i, level := range siblings {
+		p.siblings[i] = cloneHashes(level)
+	}
+	return p
+}
+
+// Root returns a copy of the root the proof was generated for.
+func (p *KaryProof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Siblings returns a copy of the other children of the node on the path, for every level from the leaf up.
+func (p *KaryProof) Siblings() [][][]byte {
+	if p == nil {
+		return nil
+	}
+	siblings := make([][][]byte, len(p.siblings))
+	for i, level := range p.siblings {
+		siblings[i] = cloneHashes(level)
+	}
+	return siblings
+}
+
+// Positions returns a copy of the position of the node on the path among its siblings, for every level from the leaf up.
+func (p *KaryProof) Positions() []int {
+	if p == nil {
+		return nil
+	}
+	return slices.Clone(p.positions)
+}
+
+// BuildKaryMerkleTree takes a slice of leaves and builds a merkle tree in which every internal node has up to
+// arity children. With an arity of two, it builds the same tree as BuildMerkleTree.
+// Duplication and sorted pairs are not supported.
+func BuildKaryMerkleTree(data []Leaf, arity int, opts ...Option) (*KaryMerkleTree, error) {
+	cfg := newConfig(opts)
+	if arity < 2 {
+		return nil, errors.New("invalid arity")
+	}
+	if cfg.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if cfg.sortPairs {
+		return nil, errors.New("not supported with sorted pairs")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+
+	level := make([][]byte, len(data))
+	for i, x := range data {
+		b, err := leafBytes(x)
+		if err != nil {
+			return nil, err
+		}
+		level[i] = cfg.hashStrategy.HashLeaf(b)
+	}
+	if cfg.sorted {
+		slices.SortFunc(level, bytes.Compare)
+	}
+
+	m := &KaryMerkleTree{
+		levels:       [][][]byte{level},
+		arity:        arity,
+		hashStrategy: cfg.hashStrategy,
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+arity-1)/arity)
+		for i := 0; i < len(level); i += arity {
+			children := level[i:min(i+arity, len(level))]
+			if len(children) == 1 {
+				next = append(next, children[0])
+			} else {
+				next = append(next, hashChildren(m.hashStrategy, children))
+			}
+		}
+		m.levels = append(m.levels, next)
+		level = next
+	}
+
+	return m, nil
+}
+
+// Root returns the bytes of the root.
+func (m *KaryMerkleTree) Root() []byte {
+	if m == nil {
+		return nil
+	}
+	return bytes.Clone(m.levels[len(m.levels)-1][0])
+}
+
+// Arity returns the maximum number of children of an internal node.
+func (m *KaryMerkleTree) Arity() int {
+	if m == nil {
+		return 0
+	}
+	return m.arity
+}
+
+// NumLeaves returns the number of leaves in the tree.
+func (m *KaryMerkleTree) NumLeaves() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.levels[0])
+}
+
+// Depth returns the number of levels above the leaves.
+func (m *KaryMerkleTree) Depth() int {
+	if m == nil {
+		return -1
+	}
+	return len(m.levels) - 1
+}
+
+// Verify verifies the integrity of the tree.
+func (m *KaryMerkleTree) Verify() bool {
+	if m == nil || m.hashStrategy == nil {
+		return false
+	}
+	for l := 1; l < len(m.levels); l++ {
+		below := m.levels[l-1]
+		for i, h := range m.levels[l] {
+			children := below[i*m.arity : min((i+1)*m.arity, len(below))]
+			expected := children[0]
+			if len(children) > 1 {
+				expected = hashChildren(m.hashStrategy, children)
+			}
+			if !hashEqual(h, expected) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Proof generates a proof for a given leaf, finding the leaf with a linear scan over the leaf hashes.
+func (m *KaryMerkleTree) Proof(x Leaf) (*KaryProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+
+	b, err := leafBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	hash := m.hashStrategy.HashLeaf(b)
+	for i, h := range m.levels[0] {
+		if hashEqual(hash, h) {
+			return m.ProofByIndex(i)
+		}
+	}
+	return nil, errors.New("not in tree")
+}
+
+// ProofByIndex generates a proof for the i-th leaf.
+func (m *KaryMerkleTree) ProofByIndex(i int) (*KaryProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.levels[0]) {
+		return nil, errors.New("index out of range")
+	}
+
+	p := &KaryProof{
+		root:         m.Root(),
+		hashStrategy: m.hashStrategy,
+	}
+	for _, level := range m.levels[:len(m.levels)-1] {
+		start := i / m.arity * m.arity
+		var siblings [][]byte
+		for j := start; j < min(start+m.arity, len(level)); j++ {
+			if j != i {
+				siblings = append(siblings, bytes.Clone(level[j]))
+			}
+		}
+		p.siblings = append(p.siblings, siblings)
+		p.positions = append(p.positions, i-start)
+		i /= m.arity
+	}
+	return p, nil
+}
+
+// VerifyKaryProof checks if a proof is valid for a given leaf.
+func VerifyKaryProof(x Leaf, p *KaryProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyKaryProof(x, p, p.root)
+}
+
+// VerifyKaryProofAgainstRoot checks if a proof is valid for a given leaf under a root the verifier already trusts.
+// The root stored in the proof is ignored.
+func VerifyKaryProofAgainstRoot(x Leaf, p *KaryProof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyKaryProof(x, p, root)
+}
+
+func verifyKaryProof(x Leaf, p *KaryProof, root []byte) error {
+	if len(p.siblings) != len(p.positions) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	b, err := leafBytes(x)
+	if err != nil {
+		return err
+	}
+	hash := p.hashStrategy.HashLeaf(b)
+	for i, siblings := range p.siblings {
+		pos := p.positions[i]
+		if pos < 0 || pos > len(siblings) {
+			return errors.New("invalid position")
+		}
+		if len(siblings) == 0 {
+			continue // promoted
+		}
+		children := make([][]byte, 0, len(siblings)+1)
+		children = append(children, siblings[:pos]...)
+		children = append(children, hash)
+		children = append(children, siblings[pos:]...)
+		hash = hashChildren(p.hashStrategy, children)
+	}
+
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestKaryTree_Binary(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		var data []Leaf
+		for i := range n {
+			data = append(data, &TestLeaf{fmt.Sprint(i)})
+		}
+
+		kary, err := BuildKaryMerkleTree(data, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if tree := mustBuildMerkleTree(t, data); !bytes.Equal(kary.Root(), tree.Root()) {
+			t.Errorf("n=%d: expected %x, got %x", n, tree.Root(), kary.Root())
+		}
+	}
+}
+
+func TestKaryTree_Root(t *testing.T) {
+	var data []Leaf
+	for _, s := range []string{"a", "b", "c", "d", "e", "f"} {
+		data = append(data, &TestLeaf{s})
+	}
+
+	tree, err := BuildKaryMerkleTree(data, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h := func(i int) []byte {
+		return hashStrategy.HashLeaf(data[i].Bytes())
+	}
+	left := hashStrategy.HashChildren([][]byte{h(0), h(1), h(2), h(3)})
+	right := hashStrategy.HashChildren([][]byte{h(4), h(5)})
+	expected := hashStrategy.HashInternal(left, right)
+
+	if !bytes.Equal(tree.Root(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.Root())
+	}
+
+	if tree.Depth() != 2 {
+		t.Errorf("expected depth 2, got %d", tree.Depth())
+	}
+
+	if !tree.Verify() {
+		t.Errorf("expected tree to verify")
+	}
+
+	tree.levels[1][0] = right
+	if tree.Verify() {
+		t.Errorf("expected tampered tree not to verify")
+	}
+}
+
+func TestKaryTree_Proof(t *testing.T) {
+	for _, arity := range []int{2, 3, 4, 16} {
+		for n := 1; n <= 40; n++ {
+			var data []Leaf
+			for i := range n {
+				data = append(data, &TestLeaf{fmt.Sprint(i)})
+			}
+
+			tree, err := BuildKaryMerkleTree(data, arity)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for i, x := range data {
+				proof, err := tree.ProofByIndex(i)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if err := VerifyKaryProof(x, proof); err != nil {
+					t.Errorf("arity=%d n=%d i=%d: unexpected error: %v", arity, n, i, err)
+				}
+
+				if n > 1 {
+					if err := VerifyKaryProof(&TestLeaf{"x"}, proof); err == nil {
+						t.Errorf("arity=%d n=%d i=%d: expected error", arity, n, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestKaryTree_Errors(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+
+	if _, err := BuildKaryMerkleTree(data, 1); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := BuildKaryMerkleTree(data, 4, WithDuplication()); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := BuildKaryMerkleTree(nil, 4); err == nil {
+		t.Errorf("expected error")
+	}
+
+	for _, x := range []Leaf{nil, nilLeaf{}} {
+		if _, err := BuildKaryMerkleTree([]Leaf{data[0], x}, 4); err == nil {
+			t.Errorf("expected error for invalid leaf %v", x)
+		}
+	}
+
+	tree, err := BuildKaryMerkleTree(data, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := tree.ProofByIndex(1); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := tree.Proof(&TestLeaf{"b"}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if err := VerifyKaryProof(data[0], nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestKaryTree_CustomHashStrategy(t *testing.T) {
+	var data []Leaf
+	for _, s := range []string{"a", "b", "c"} {
+		data = append(data, &TestLeaf{s})
+	}
+
+	// without HashChildren, children are folded with HashInternal
+	h := RFC6962HashStrategy{}
+	tree, err := BuildKaryMerkleTree(data, 3, WithHashStrategy(h))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, b, c := h.HashLeaf(data[0].Bytes()), h.HashLeaf(data[1].Bytes()), h.HashLeaf(data[2].Bytes())
+	if expected := h.HashInternal(h.HashInternal(a, b), c); !bytes.Equal(tree.Root(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.Root())
+	}
+
+	proof, err := tree.ProofByIndex(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyKaryProof(data[2], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestKaryProof_Encoding(t *testing.T) {
+	var data []Leaf
+	for i := range 11 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+	tree, err := BuildKaryMerkleTree(data, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, x := range data {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fromBinary KaryProof
+		if err := fromBinary.UnmarshalBinary(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		j, err := json.Marshal(proof)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fromJSON KaryProof
+		if err := json.Unmarshal(j, &fromJSON); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assembled := NewKaryProof(nil, proof.Siblings(), proof.Positions(), nil)
+
+		for _, p := range []*KaryProof{&fromBinary, &fromJSON, assembled} {
+			if err := VerifyKaryProofAgainstRoot(x, p, tree.Root()); err != nil {
+				t.Errorf("leaf %d: unexpected error: %v", i, err)
+			}
+			if err := VerifyKaryProofAgainstRoot(data[(i+1)%len(data)], p, tree.Root()); err == nil {
+				t.Errorf("leaf %d: expected error for another leaf", i)
+			}
+		}
+
+		if err := fromBinary.UnmarshalBinary(b[:len(b)-1]); err == nil {
+			t.Errorf("leaf %d: expected error for truncated data", i)
+		}
+	}
+}
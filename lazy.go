@@ -0,0 +1,105 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+)
+
+// LazyMerkleTree is a merkle tree that only hashes its leaves when it is built, and computes internal nodes when a
+// root or proof needs them. Computed hashes are kept, so every node is hashed at most once: the first root or proof
+// hashes the rest of the tree, later proofs are O(log n), and a tree whose root is never requested costs no more than
+// hashing its leaves. This suits workloads that build many trees but need roots or proofs of only a few of them.
+//
+// The tree has the same shape as MerkleTree, so it produces the same roots and proofs.
+// It is not safe for concurrent use, as reading the root or a proof stores the hashes it computes.
+type LazyMerkleTree struct {
+	levels [][][]byte // levels[0] holds the leaf hashes; nil hashes of the levels above aren't computed yet
+	levelShape
+}
+
+// BuildLazyMerkleTree takes a slice of leaves and builds a lazy merkle tree, hashing only the leaves.
+// It accepts the options of BuildMerkleTree, except that progress and root history aren't reported.
+func BuildLazyMerkleTree(data []Leaf, opts ...Option) (*LazyMerkleTree, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	cfg := newConfig(opts)
+	hashes, err := hashLeaves(context.Background(), data, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.sorted {
+		slices.SortFunc(hashes, bytes.Compare)
+	}
+	if cfg.dedup {
+		hashes = dedupHashes(hashes, cfg.sorted)
+	}
+
+	m := &LazyMerkleTree{
+		levels:     [][][]byte{hashes},
+		levelShape: newLevelShape(len(hashes), cfg),
+	}
+	for _, n := range m.sizes[1:] {
+		m.levels = append(m.levels, make([][]byte, n))
+	}
+	return m, nil
+}
+
+// node returns the i-th hash of a level, computing it and the hashes below it that weren't computed yet.
+func (m *LazyMerkleTree) node(level, i int) []byte {
+	if h := m.levels[level][i]; h != nil {
+		return h
+	}
+	h := m.hashNode(m.node, level, i)
+	m.levels[level][i] = h
+	return h
+}
+
+// Root returns a copy of the root, computing the internal nodes that weren't computed yet.
+func (m *LazyMerkleTree) Root() []byte {
+	if m == nil {
+		return nil
+	}
+	return bytes.Clone(m.node(len(m.levels)-1, 0))
+}
+
+// NumLeaves returns the number of leaves.
+func (m *LazyMerkleTree) NumLeaves() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.levels[0])
+}
+
+// LeafHash returns a copy of the hash of the i-th leaf, or nil if i is out of range.
+func (m *LazyMerkleTree) LeafHash(i int) []byte {
+	if m == nil || i < 0 || i >= len(m.levels[0]) {
+		return nil
+	}
+	return bytes.Clone(m.levels[0][i])
+}
+
+// Proof generates a proof for a given leaf, finding the leaf with a linear scan over the leaf hashes.
+func (m *LazyMerkleTree) Proof(x Leaf) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	i, err := m.find(m.node, x)
+	if err != nil {
+		return nil, err
+	}
+	return m.ProofByIndex(i)
+}
+
+// ProofByIndex generates a proof for the i-th leaf, computing the sibling hashes that weren't computed yet.
+func (m *LazyMerkleTree) ProofByIndex(i int) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.levels[0]) {
+		return nil, errors.New("index out of range")
+	}
+	return m.proof(m.node, i, m.node(len(m.levels)-1, 0)), nil
+}
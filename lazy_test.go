@@ -0,0 +1,129 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// countingHashStrategy is the default hash strategy, counting the internal nodes it hashes.
+type countingHashStrategy struct {
+	defaultHashStrategy
+	internal *int
+}
+
+func (h countingHashStrategy) HashInternal(l, r []byte) []byte {
+	*h.internal++
+	return h.defaultHashStrategy.HashInternal(l, r)
+}
+
+func TestLazyMerkleTree_SameAsMerkleTree(t *testing.T) {
+	options := []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"Duplication", []Option{WithDuplication()}},
+		{"SortedDedup", []Option{WithSortedLeaves(), WithDedup()}},
+	}
+
+	for _, o := range options {
+		for _, n := range []int{1, 2, 3, 5, 8, 13, 100} {
+			data := make([]Leaf, n)
+			for i := range data {
+				data[i] = BytesLeaf(fmt.Sprint(i % 50))
+			}
+
+			expected := mustBuildMerkleTree(t, data, o.opts...)
+			tree, err := BuildLazyMerkleTree(data, o.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tree.NumLeaves() != expected.NumLeaves() {
+				t.Fatalf("%s/%d: expected %d leaves, got %d", o.name, n, expected.NumLeaves(), tree.NumLeaves())
+			}
+
+			for i := range tree.NumLeaves() {
+				proof, err := tree.ProofByIndex(i)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				expectedProof, _ := expected.ProofByIndex(i)
+				if !bytes.Equal(proof.Root(), expectedProof.Root()) || len(proof.Siblings()) != len(expectedProof.Siblings()) {
+					t.Errorf("%s/%d: proof of %d differs", o.name, n, i)
+				}
+				for j, sibling := range proof.Siblings() {
+					if !bytes.Equal(sibling, expectedProof.Siblings()[j]) || proof.Directions()[j] != expectedProof.Directions()[j] {
+						t.Errorf("%s/%d: sibling %d of proof of %d differs", o.name, n, j, i)
+					}
+				}
+				if err := VerifyProofFromHash(tree.LeafHash(i), proof); err != nil {
+					t.Errorf("%s/%d: unexpected error: %v", o.name, n, err)
+				}
+			}
+			if !bytes.Equal(tree.Root(), expected.Root()) {
+				t.Errorf("%s/%d: expected %x, got %x", o.name, n, expected.Root(), tree.Root())
+			}
+		}
+	}
+}
+
+func TestLazyMerkleTree_Lazy(t *testing.T) {
+	data := make([]Leaf, 100)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+
+	var internal int
+	tree, err := BuildLazyMerkleTree(data, WithHashStrategy(countingHashStrategy{internal: &internal}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if internal != 0 {
+		t.Errorf("expected no internal hashes, got %d", internal)
+	}
+
+	// the first root hashes every internal node once, later roots and proofs hash nothing
+	tree.Root()
+	if internal != 99 {
+		t.Errorf("expected 99 internal hashes, got %d", internal)
+	}
+	if _, err := tree.Proof(data[42]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tree.Root()
+	if internal != 99 {
+		t.Errorf("expected 99 internal hashes, got %d", internal)
+	}
+}
+
+func TestLazyMerkleTree_Errors(t *testing.T) {
+	if _, err := BuildLazyMerkleTree(nil); err == nil {
+		t.Errorf("expected error for no leaves")
+	}
+	if _, err := BuildLazyMerkleTree([]Leaf{&TestLeaf{"a"}, nil}); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+
+	tree, err := BuildLazyMerkleTree([]Leaf{&TestLeaf{"a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tree.ProofByIndex(1); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+	if _, err := tree.Proof(&TestLeaf{"b"}); err == nil {
+		t.Errorf("expected error for leaf not in tree")
+	}
+	if tree.LeafHash(1) != nil {
+		t.Errorf("expected nil leaf hash")
+	}
+
+	var nilTree *LazyMerkleTree
+	if nilTree.Root() != nil || nilTree.NumLeaves() != 0 {
+		t.Errorf("expected nil root and no leaves")
+	}
+	if _, err := nilTree.ProofByIndex(0); err == nil {
+		t.Errorf("expected error for nil tree")
+	}
+}
@@ -0,0 +1,108 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+)
+
+// LeanMerkleTree is a merkle tree that keeps only its leaf hashes and root: n hashes instead of the 2n-1 hashes and
+// nodes of a MerkleTree. A proof rehashes the subtrees of its siblings, O(n) hashes per proof, which suits read-mostly
+// servers where proofs are requested rarely.
+//
+// The tree has the same shape as MerkleTree, so it produces the same roots and proofs.
+type LeanMerkleTree struct {
+	leaves [][]byte
+	root   []byte
+	levelShape
+}
+
+// BuildLeanMerkleTree takes a slice of leaves and builds a lean merkle tree, computing the root without keeping the
+// internal nodes. It accepts the options of BuildMerkleTree, except that progress and root history aren't reported.
+func BuildLeanMerkleTree(data []Leaf, opts ...Option) (*LeanMerkleTree, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	cfg := newConfig(opts)
+	hashes, err := hashLeaves(context.Background(), data, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.sorted {
+		slices.SortFunc(hashes, bytes.Compare)
+	}
+	if cfg.dedup {
+		hashes = dedupHashes(hashes, cfg.sorted)
+	}
+
+	m := &LeanMerkleTree{
+		leaves:     slices.Clip(hashes),
+		levelShape: newLevelShape(len(hashes), cfg),
+	}
+	m.root = m.node(len(m.sizes)-1, 0)
+	return m, nil
+}
+
+// node computes the i-th hash of a level from the leaves below it, in O(2^level) hashes and O(level) memory.
+func (m *LeanMerkleTree) node(level, i int) []byte {
+	if level == 0 {
+		return m.leaves[i]
+	}
+	return m.hashNode(m.node, level, i)
+}
+
+// Root returns a copy of the root.
+func (m *LeanMerkleTree) Root() []byte {
+	if m == nil {
+		return nil
+	}
+	return bytes.Clone(m.root)
+}
+
+// NumLeaves returns the number of leaves.
+func (m *LeanMerkleTree) NumLeaves() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.leaves)
+}
+
+// LeafHash returns a copy of the hash of the i-th leaf, or nil if i is out of range.
+func (m *LeanMerkleTree) LeafHash(i int) []byte {
+	if m == nil || i < 0 || i >= len(m.leaves) {
+		return nil
+	}
+	return bytes.Clone(m.leaves[i])
+}
+
+// Verify verifies that the leaf hashes still hash to the root, in O(n).
+func (m *LeanMerkleTree) Verify() bool {
+	if m == nil || m.hashStrategy == nil {
+		return false
+	}
+	return hashEqual(m.node(len(m.sizes)-1, 0), m.root)
+}
+
+// Proof generates a proof for a given leaf, finding the leaf with a linear scan over the leaf hashes.
+func (m *LeanMerkleTree) Proof(x Leaf) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	i, err := m.find(m.node, x)
+	if err != nil {
+		return nil, err
+	}
+	return m.ProofByIndex(i)
+}
+
+// ProofByIndex generates a proof for the i-th leaf, rehashing the subtrees of its siblings in O(n).
+func (m *LeanMerkleTree) ProofByIndex(i int) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.leaves) {
+		return nil, errors.New("index out of range")
+	}
+	return m.proof(m.node, i, m.root), nil
+}
@@ -0,0 +1,94 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestLeanMerkleTree_SameAsMerkleTree(t *testing.T) {
+	options := []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"Duplication", []Option{WithDuplication()}},
+		{"SortedDedup", []Option{WithSortedLeaves(), WithDedup()}},
+	}
+
+	for _, o := range options {
+		for _, n := range []int{1, 2, 3, 5, 8, 13, 100} {
+			data := make([]Leaf, n)
+			for i := range data {
+				data[i] = BytesLeaf(fmt.Sprint(i % 50))
+			}
+
+			expected := mustBuildMerkleTree(t, data, o.opts...)
+			tree, err := BuildLeanMerkleTree(data, o.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(tree.Root(), expected.Root()) {
+				t.Errorf("%s/%d: expected %x, got %x", o.name, n, expected.Root(), tree.Root())
+			}
+			if !tree.Verify() {
+				t.Errorf("%s/%d: couldn't verify tree", o.name, n)
+			}
+
+			for i := range tree.NumLeaves() {
+				proof, err := tree.ProofByIndex(i)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				expectedProof, _ := expected.ProofByIndex(i)
+				if len(proof.Siblings()) != len(expectedProof.Siblings()) {
+					t.Fatalf("%s/%d: expected %d siblings, got %d", o.name, n, len(expectedProof.Siblings()), len(proof.Siblings()))
+				}
+				for j, sibling := range proof.Siblings() {
+					if !bytes.Equal(sibling, expectedProof.Siblings()[j]) || proof.Directions()[j] != expectedProof.Directions()[j] {
+						t.Errorf("%s/%d: sibling %d of proof of %d differs", o.name, n, j, i)
+					}
+				}
+				if err := VerifyProofFromHash(tree.LeafHash(i), proof); err != nil {
+					t.Errorf("%s/%d: unexpected error: %v", o.name, n, err)
+				}
+			}
+		}
+	}
+}
+
+func TestLeanMerkleTree_Proof(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tree, err := BuildLeanMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	proof, err := tree.Proof(data[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(data[1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := tree.Proof(&TestLeaf{"d"}); err == nil {
+		t.Errorf("expected error for leaf not in tree")
+	}
+	if _, err := tree.ProofByIndex(3); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+
+	// a corrupted leaf hash no longer hashes to the root
+	tree.leaves[0] = hashStrategy.HashLeaf([]byte("x"))
+	if tree.Verify() {
+		t.Errorf("expected tree to not verify")
+	}
+
+	if _, err := BuildLeanMerkleTree(nil); err == nil {
+		t.Errorf("expected error for no leaves")
+	}
+	var nilTree *LeanMerkleTree
+	if nilTree.Root() != nil || nilTree.Verify() {
+		t.Errorf("expected nil root and no verification")
+	}
+}
@@ -0,0 +1,84 @@
+package gomerkletree
+
+import "errors"
+
+// levelShape is the shape of a tree whose hashes are addressed by level, for trees that don't keep a Node per hash:
+// the children of the i-th node of a level are the (2i)-th and (2i+1)-th nodes of the level below.
+// The trees differ in how they obtain the hash of a node, which they pass to its methods as a nodeFunc.
+type levelShape struct {
+	sizes        []int // number of nodes of every level, from the leaves up to the root
+	hashStrategy HashStrategy
+	duplicate    bool
+}
+
+// nodeFunc returns the i-th hash of a level.
+type nodeFunc func(level, i int) []byte
+
+// newLevelShape returns the shape of a tree over the given number of leaves.
+func newLevelShape(leaves int, cfg config) levelShape {
+	sizes := []int{leaves}
+	for n := leaves; n > 1; {
+		n = (n + 1) / 2
+		sizes = append(sizes, n)
+	}
+	return levelShape{
+		sizes:        sizes,
+		hashStrategy: cfg.hashStrategy,
+		duplicate:    cfg.duplicate,
+	}
+}
+
+// hashNode computes the i-th hash of a level above the leaves from its children.
+func (s levelShape) hashNode(node nodeFunc, level, i int) []byte {
+	left := node(level-1, 2*i)
+	switch {
+	case 2*i+1 < s.sizes[level-1]:
+		return s.hashStrategy.HashInternal(left, node(level-1, 2*i+1))
+	case s.duplicate:
+		return s.hashStrategy.HashInternal(left, left)
+	default:
+		return left // promoted
+	}
+}
+
+// find returns the index of a leaf with a linear scan over the leaf hashes.
+func (s levelShape) find(node nodeFunc, x Leaf) (int, error) {
+	b, err := leafBytes(x)
+	if err != nil {
+		return -1, err
+	}
+	hash := s.hashStrategy.HashLeaf(b)
+	for i := range s.sizes[0] {
+		if hashEqual(hash, node(0, i)) {
+			return i, nil
+		}
+	}
+	return -1, errors.New("not in tree")
+}
+
+// proof generates a proof for the i-th leaf, which must be in range, against the given root.
+func (s levelShape) proof(node nodeFunc, i int, root []byte) *Proof {
+	var siblings [][]byte
+	var left []bool
+
+	for level := 0; level < len(s.sizes)-1; level++ {
+		if i%2 != 0 {
+			siblings = append(siblings, node(level, i-1))
+			left = append(left, true) // maps to sibling hash
+		} else if i+1 < s.sizes[level] {
+			siblings = append(siblings, node(level, i+1))
+			left = append(left, false) // maps to sibling hash
+		} else if s.duplicate {
+			siblings = append(siblings, node(level, i))
+			left = append(left, false) // paired with itself
+		}
+		i /= 2
+	}
+
+	return &Proof{
+		root:         root,
+		siblings:     siblings,
+		left:         left,
+		hashStrategy: s.hashStrategy,
+	}
+}
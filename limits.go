@@ -0,0 +1,281 @@
+package gomerkletree
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// Limits bounds the proofs accepted from untrusted clients, so a server verifying them can't be made to spend
+// unbounded memory or time on a single proof. A zero field means no limit.
+// The Unmarshal methods cover the proofs of this package; encodings of other formats, like ProofBundle, RFC 9162
+// TransItems and SignedProof, have no limit-checked decoders.
+type Limits struct {
+	// MaxDepth bounds the depth of the tree a proof is for: the number of levels of a Proof, SumProof or KaryProof,
+	// and the tree sizes of multiproofs, consistency, range and map proofs (at most 2^MaxDepth leaves).
+	MaxDepth int
+	// MaxSiblings bounds the number of hashes of a proof, and the number of leaves a multiproof or range proof proves.
+	MaxSiblings int
+	// MaxSize bounds the size in bytes of an encoded proof, checked before it is decoded.
+	MaxSize int
+}
+
+// DefaultLimits accepts proofs for trees of up to 2^64 leaves, with up to 4096 hashes in up to 1 MiB.
+var DefaultLimits = Limits{
+	MaxDepth:    MaxProofDepth,
+	MaxSiblings: 4096,
+	MaxSize:     1 << 20,
+}
+
+// CheckProof returns an error if the proof exceeds the limits.
+func (l Limits) CheckProof(p *Proof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkDepth(len(p.siblings)); err != nil {
+		return err
+	}
+	return l.checkSiblings(len(p.siblings))
+}
+
+// CheckMultiProof returns an error if the multiproof exceeds the limits.
+func (l Limits) CheckMultiProof(p *MultiProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkSize(p.size); err != nil {
+		return err
+	}
+	if err := l.checkSiblings(len(p.indices)); err != nil {
+		return err
+	}
+	return l.checkSiblings(len(p.hashes))
+}
+
+// CheckConsistencyProof returns an error if the consistency proof exceeds the limits.
+func (l Limits) CheckConsistencyProof(p *ConsistencyProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkSize(p.newSize); err != nil {
+		return err
+	}
+	return l.checkSiblings(len(p.hashes))
+}
+
+// CheckRangeProof returns an error if the range proof exceeds the limits.
+func (l Limits) CheckRangeProof(p *RangeProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkSize(p.size); err != nil {
+		return err
+	}
+	if err := l.checkSiblings(p.end - p.start); err != nil {
+		return err
+	}
+	return l.checkSiblings(len(p.hashes))
+}
+
+// CheckSumProof returns an error if the sum proof exceeds the limits.
+func (l Limits) CheckSumProof(p *SumProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkDepth(len(p.siblings)); err != nil {
+		return err
+	}
+	return l.checkSiblings(len(p.siblings))
+}
+
+// CheckSparseProof returns an error if the sparse proof exceeds the limits. Sparse trees always have a depth of 256,
+// so only the number of siblings is checked.
+func (l Limits) CheckSparseProof(p *SparseProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	return l.checkSiblings(len(p.siblings))
+}
+
+// CheckMapProof returns an error if the map proof, or any of the inclusion proofs of its entries, exceeds the limits.
+func (l Limits) CheckMapProof(p *MapProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkSize(p.size); err != nil {
+		return err
+	}
+	if err := l.checkSiblings(len(p.entries)); err != nil {
+		return err
+	}
+	for _, proof := range p.proofs {
+		if proof == nil {
+			continue
+		}
+		if err := l.CheckProof(proof); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckKaryProof returns an error if the k-ary proof exceeds the limits.
+func (l Limits) CheckKaryProof(p *KaryProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	if err := l.checkDepth(len(p.siblings)); err != nil {
+		return err
+	}
+	n := 0
+	for _, siblings := range p.siblings {
+		n += len(siblings)
+	}
+	return l.checkSiblings(n)
+}
+
+// VerifyProof checks if a proof is valid for a leaf under a root the verifier already trusts, like
+// VerifyProofAgainstRoot, after checking that the proof is within the limits.
+func (l Limits) VerifyProof(x Leaf, p *Proof, root []byte) error {
+	if err := l.CheckProof(p); err != nil {
+		return err
+	}
+	return VerifyProofAgainstRoot(x, p, root)
+}
+
+// VerifyMultiProof checks if a multiproof is valid for the leaves under a root the verifier already trusts, like
+// VerifyMultiProofAgainstRoot, after checking that the proof is within the limits.
+func (l Limits) VerifyMultiProof(leaves []Leaf, p *MultiProof, root []byte) error {
+	if err := l.CheckMultiProof(p); err != nil {
+		return err
+	}
+	return VerifyMultiProofAgainstRoot(leaves, p, root)
+}
+
+// VerifyConsistency checks if a consistency proof is valid for the roots, like VerifyConsistency, after checking
+// that the proof is within the limits.
+func (l Limits) VerifyConsistency(oldRoot, newRoot []byte, p *ConsistencyProof) error {
+	if err := l.CheckConsistencyProof(p); err != nil {
+		return err
+	}
+	return VerifyConsistency(oldRoot, newRoot, p)
+}
+
+// UnmarshalProof decodes a proof encoded by MarshalBinary, rejecting encodings and proofs that exceed the limits.
+func (l Limits) UnmarshalProof(data []byte) (*Proof, error) {
+	return decodeLimited(l, data, (*Proof).UnmarshalBinary, l.CheckProof)
+}
+
+// UnmarshalProofJSON decodes a proof encoded by MarshalJSON, rejecting encodings and proofs that exceed the limits.
+func (l Limits) UnmarshalProofJSON(data []byte) (*Proof, error) {
+	return decodeLimited(l, data, (*Proof).UnmarshalJSON, l.CheckProof)
+}
+
+// UnmarshalConsistencyProofJSON decodes a consistency proof encoded by MarshalJSON, rejecting encodings and proofs
+// that exceed the limits.
+func (l Limits) UnmarshalConsistencyProofJSON(data []byte) (*ConsistencyProof, error) {
+	return decodeLimited(l, data, (*ConsistencyProof).UnmarshalJSON, l.CheckConsistencyProof)
+}
+
+// UnmarshalRangeProof decodes a range proof encoded by MarshalBinary, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalRangeProof(data []byte) (*RangeProof, error) {
+	return decodeLimited(l, data, (*RangeProof).UnmarshalBinary, l.CheckRangeProof)
+}
+
+// UnmarshalRangeProofJSON decodes a range proof encoded by MarshalJSON, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalRangeProofJSON(data []byte) (*RangeProof, error) {
+	return decodeLimited(l, data, (*RangeProof).UnmarshalJSON, l.CheckRangeProof)
+}
+
+// UnmarshalSumProof decodes a sum proof encoded by MarshalBinary, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalSumProof(data []byte) (*SumProof, error) {
+	return decodeLimited(l, data, (*SumProof).UnmarshalBinary, l.CheckSumProof)
+}
+
+// UnmarshalSumProofJSON decodes a sum proof encoded by MarshalJSON, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalSumProofJSON(data []byte) (*SumProof, error) {
+	return decodeLimited(l, data, (*SumProof).UnmarshalJSON, l.CheckSumProof)
+}
+
+// UnmarshalSparseProof decodes a sparse proof encoded by MarshalBinary, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalSparseProof(data []byte) (*SparseProof, error) {
+	return decodeLimited(l, data, (*SparseProof).UnmarshalBinary, l.CheckSparseProof)
+}
+
+// UnmarshalSparseProofJSON decodes a sparse proof encoded by MarshalJSON, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalSparseProofJSON(data []byte) (*SparseProof, error) {
+	return decodeLimited(l, data, (*SparseProof).UnmarshalJSON, l.CheckSparseProof)
+}
+
+// UnmarshalMapProof decodes a map proof encoded by MarshalBinary, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalMapProof(data []byte) (*MapProof, error) {
+	return decodeLimited(l, data, (*MapProof).UnmarshalBinary, l.CheckMapProof)
+}
+
+// UnmarshalMapProofJSON decodes a map proof encoded by MarshalJSON, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalMapProofJSON(data []byte) (*MapProof, error) {
+	return decodeLimited(l, data, (*MapProof).UnmarshalJSON, l.CheckMapProof)
+}
+
+// UnmarshalKaryProof decodes a k-ary proof encoded by MarshalBinary, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalKaryProof(data []byte) (*KaryProof, error) {
+	return decodeLimited(l, data, (*KaryProof).UnmarshalBinary, l.CheckKaryProof)
+}
+
+// UnmarshalKaryProofJSON decodes a k-ary proof encoded by MarshalJSON, rejecting encodings and proofs that exceed
+// the limits.
+func (l Limits) UnmarshalKaryProofJSON(data []byte) (*KaryProof, error) {
+	return decodeLimited(l, data, (*KaryProof).UnmarshalJSON, l.CheckKaryProof)
+}
+
+// decodeLimited checks the size of an encoding, decodes it and checks the decoded proof.
+func decodeLimited[T any](l Limits, data []byte, unmarshal func(*T, []byte) error, check func(*T) error) (*T, error) {
+	if err := l.checkEncoded(data); err != nil {
+		return nil, err
+	}
+	p := new(T)
+	if err := unmarshal(p, data); err != nil {
+		return nil, err
+	}
+	if err := check(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (l Limits) checkEncoded(data []byte) error {
+	if l.MaxSize > 0 && len(data) > l.MaxSize {
+		return errors.New("encoded proof too large")
+	}
+	return nil
+}
+
+func (l Limits) checkDepth(depth int) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return errors.New("proof too deep")
+	}
+	return nil
+}
+
+// checkSize checks the number of leaves of the tree a proof is for against the maximum depth.
+func (l Limits) checkSize(size int) error {
+	if size > 1 {
+		return l.checkDepth(bits.Len(uint(size - 1)))
+	}
+	return nil
+}
+
+func (l Limits) checkSiblings(n int) error {
+	if l.MaxSiblings > 0 && n > l.MaxSiblings {
+		return errors.New("too many hashes in proof")
+	}
+	return nil
+}
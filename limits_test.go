@@ -0,0 +1,237 @@
+package gomerkletree
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLimits_Proof(t *testing.T) {
+	data := make([]Leaf, 16)
+	for i := range data {
+		data[i] = &TestLeaf{string(rune('a' + i))}
+	}
+	tree := mustBuildMerkleTree(t, data)
+	p, err := tree.ProofByIndex(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := DefaultLimits.VerifyProof(data[3], p, tree.Root()); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+	if err := (Limits{}).VerifyProof(data[3], p, tree.Root()); err != nil {
+		t.Errorf("expected valid proof without limits, got %v", err)
+	}
+	if err := (Limits{MaxDepth: 3}).VerifyProof(data[3], p, tree.Root()); err == nil {
+		t.Errorf("expected error for proof deeper than max depth")
+	}
+	if err := (Limits{MaxSiblings: 3}).CheckProof(p); err == nil {
+		t.Errorf("expected error for too many siblings")
+	}
+	if err := DefaultLimits.CheckProof(nil); err == nil {
+		t.Errorf("expected error for nil proof")
+	}
+}
+
+func TestLimits_UnmarshalProof(t *testing.T) {
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}})
+	p, err := tree.ProofByIndex(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	j, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, unmarshal := range map[string]func(Limits) (*Proof, error){
+		"Binary": func(l Limits) (*Proof, error) { return l.UnmarshalProof(b) },
+		"JSON":   func(l Limits) (*Proof, error) { return l.UnmarshalProofJSON(j) },
+	} {
+		decoded, err := unmarshal(DefaultLimits)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if err := VerifyProof(&TestLeaf{"b"}, decoded); err != nil {
+			t.Errorf("%s: expected valid proof, got %v", name, err)
+		}
+		if _, err := unmarshal(Limits{MaxSize: 10}); err == nil {
+			t.Errorf("%s: expected error for encoding larger than max size", name)
+		}
+		if _, err := unmarshal(Limits{MaxDepth: 1}); err == nil {
+			t.Errorf("%s: expected error for proof deeper than max depth", name)
+		}
+	}
+}
+
+func TestLimits_MultiProof(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}, &TestLeaf{"e"}}
+	tree := mustBuildMerkleTree(t, data)
+	leaves := []Leaf{data[0], data[3]}
+	p, err := tree.MultiProof(leaves)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := DefaultLimits.VerifyMultiProof(leaves, p, tree.Root()); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+	if err := DefaultLimits.VerifyMultiProof(leaves, p, tree.Root()[1:]); err == nil {
+		t.Errorf("expected error for another root")
+	}
+	if err := (Limits{MaxSiblings: 1}).VerifyMultiProof(leaves, p, tree.Root()); err == nil {
+		t.Errorf("expected error for too many hashes")
+	}
+	if err := (Limits{MaxDepth: 2}).VerifyMultiProof(leaves, p, tree.Root()); err == nil {
+		t.Errorf("expected error for tree larger than max depth")
+	}
+	huge := NewMultiProof(tree.Root(), 1<<40, nil, nil, nil)
+	if err := DefaultLimits.CheckMultiProof(huge); err != nil {
+		t.Errorf("expected tree of 2^40 leaves within default limits, got %v", err)
+	}
+}
+
+func TestLimits_ConsistencyProof(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}, &TestLeaf{"e"}}
+	tree := mustBuildMerkleTree(t, data)
+	oldTree := mustBuildMerkleTree(t, data[:3])
+	p, err := tree.ConsistencyProof(3, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := DefaultLimits.VerifyConsistency(oldTree.Root(), tree.Root(), p); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+	if err := (Limits{MaxSiblings: 1}).VerifyConsistency(oldTree.Root(), tree.Root(), p); err == nil {
+		t.Errorf("expected error for too many hashes")
+	}
+
+	j, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := DefaultLimits.UnmarshalConsistencyProofJSON(j); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := (Limits{MaxDepth: 2}).UnmarshalConsistencyProofJSON(j); err == nil {
+		t.Errorf("expected error for tree larger than max depth")
+	}
+}
+
+func TestLimits_Unmarshal(t *testing.T) {
+	data := make([]Leaf, 16)
+	sumData := make([]SumLeaf, 16)
+	entries := make(map[string][]byte)
+	sparse := NewSparseMerkleTree()
+	for i := range data {
+		data[i] = &TestLeaf{string(rune('a' + i))}
+		sumData[i] = testSumLeaf{string(rune('a' + i)), uint64(i)}
+		entries[string(rune('a'+i))] = []byte{byte(i)}
+		if err := sparse.Update(sparseKey(i), []byte{byte(i)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	tree := mustBuildMerkleTree(t, data)
+	sumTree, err := BuildSumMerkleTree(sumData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	merkleMap, err := BuildMerkleMap(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	karyTree, err := BuildKaryMerkleTree(data, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rangeProof, err := tree.RangeProof(3, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sumProof, err := sumTree.ProofByIndex(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sparseProof, err := sparse.Proof(sparseKey(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mapProof, err := merkleMap.Proof("d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	karyProof, err := karyTree.ProofByIndex(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type proof interface {
+		MarshalBinary() ([]byte, error)
+		MarshalJSON() ([]byte, error)
+	}
+	for _, tt := range []struct {
+		name                     string
+		proof                    proof
+		unmarshal, unmarshalJSON func(Limits, []byte) error
+	}{
+		{
+			"range", rangeProof,
+			func(l Limits, b []byte) error { _, err := l.UnmarshalRangeProof(b); return err },
+			func(l Limits, b []byte) error { _, err := l.UnmarshalRangeProofJSON(b); return err },
+		},
+		{
+			"sum", sumProof,
+			func(l Limits, b []byte) error { _, err := l.UnmarshalSumProof(b); return err },
+			func(l Limits, b []byte) error { _, err := l.UnmarshalSumProofJSON(b); return err },
+		},
+		{
+			"sparse", sparseProof,
+			func(l Limits, b []byte) error { _, err := l.UnmarshalSparseProof(b); return err },
+			func(l Limits, b []byte) error { _, err := l.UnmarshalSparseProofJSON(b); return err },
+		},
+		{
+			"map", mapProof,
+			func(l Limits, b []byte) error { _, err := l.UnmarshalMapProof(b); return err },
+			func(l Limits, b []byte) error { _, err := l.UnmarshalMapProofJSON(b); return err },
+		},
+		{
+			"kary", karyProof,
+			func(l Limits, b []byte) error { _, err := l.UnmarshalKaryProof(b); return err },
+			func(l Limits, b []byte) error { _, err := l.UnmarshalKaryProofJSON(b); return err },
+		},
+	} {
+		b, err := tt.proof.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		j, err := tt.proof.MarshalJSON()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		for encoding, unmarshal := range map[string]func(Limits) error{
+			"Binary": func(l Limits) error { return tt.unmarshal(l, b) },
+			"JSON":   func(l Limits) error { return tt.unmarshalJSON(l, j) },
+		} {
+			if err := unmarshal(DefaultLimits); err != nil {
+				t.Errorf("%s %s: unexpected error: %v", tt.name, encoding, err)
+			}
+			if err := unmarshal(Limits{MaxSize: 10}); err == nil {
+				t.Errorf("%s %s: expected error for encoding larger than max size", tt.name, encoding)
+			}
+			if err := unmarshal(Limits{MaxSiblings: 1}); err == nil {
+				t.Errorf("%s %s: expected error for too many hashes", tt.name, encoding)
+			}
+		}
+	}
+
+	huge := NewRangeProof(tree.Root(), 1<<40, 0, 1, nil, nil)
+	if err := (Limits{MaxDepth: 32}).CheckRangeProof(huge); err == nil {
+		t.Errorf("expected error for tree larger than max depth")
+	}
+}
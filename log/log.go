@@ -0,0 +1,200 @@
+// Package log runs a small transparency log on top of a stored merkle tree.
+//
+// Entries are sequenced when they are added: they get the index they will have in the tree.
+// Integrate appends all sequenced entries to the tree in one batch, and signs the new tree head with the key of the log.
+// Clients verify inclusion and consistency proofs against signed tree heads with VerifyInclusion and VerifyConsistency.
+//
+// A Log has the methods of httpapi.SignedLog, so it can be served by httpapi.NewHandler, including its signed tree heads,
+// and by grpcapi.NewServer.
+package log
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"sync"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+// Log is an append-only log of entries with signed tree heads. It is safe for concurrent use.
+type Log struct {
+	mu      sync.RWMutex
+	tree    *gomerkletree.StoredTree
+	key     ed25519.PrivateKey
+	pending []gomerkletree.Leaf
+	head    *gomerkletree.SignedTreeHead
+}
+
+// New opens the log kept in the store, which can be empty, and signs its current head with the private key.
+// Options can change the hash strategy, like for gomerkletree.NewStoredTree.
+func New(store gomerkletree.NodeStore, key ed25519.PrivateKey, opts ...gomerkletree.Option) (*Log, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key")
+	}
+	tree, err := gomerkletree.NewStoredTree(store, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		tree: tree,
+		key:  key,
+	}
+	if err := l.sign(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// PublicKey returns the public key that verifies the signed tree heads of the log.
+func (l *Log) PublicKey() ed25519.PublicKey {
+	return l.key.Public().(ed25519.PublicKey)
+}
+
+// Add sequences an entry, returning the index it will have in the log.
+// The entry is part of the log after the next call to Integrate.
+func (l *Log) Add(x gomerkletree.Leaf) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(l.pending, x)
+	return l.tree.Size() + len(l.pending) - 1
+}
+
+// Pending returns the number of sequenced entries that are not integrated yet.
+func (l *Log) Pending() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.pending)
+}
+
+// Integrate appends all sequenced entries to the tree and returns the new signed tree head.
+// Without sequenced entries, the current head is signed again with a new timestamp.
+// If appending fails, the entries stay sequenced and Integrate can be retried.
+func (l *Log) Integrate() (*gomerkletree.SignedTreeHead, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending) > 0 {
+		if err := l.tree.AppendBatch(l.pending); err != nil {
+			return nil, err
+		}
+		l.pending = nil
+	}
+	if err := l.sign(); err != nil {
+		return nil, err
+	}
+	return l.signedHead(), nil
+}
+
+// sign signs the current head of the tree. The caller must hold the write lock.
+func (l *Log) sign() error {
+	root, err := l.tree.Root()
+	if err != nil {
+		return err
+	}
+	head, err := gomerkletree.SignTreeHead(gomerkletree.TreeHead{
+		Size:      l.tree.Size(),
+		Root:      root,
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+	}, l.key)
+	if err != nil {
+		return err
+	}
+	l.head = head
+	return nil
+}
+
+// SignedHead returns the latest signed tree head.
+func (l *Log) SignedHead() *gomerkletree.SignedTreeHead {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.signedHead()
+}
+
+func (l *Log) signedHead() *gomerkletree.SignedTreeHead {
+	head := *l.head
+	head.Root = bytes.Clone(l.head.Root)
+	head.Signature = bytes.Clone(l.head.Signature)
+	return &head
+}
+
+// Checkpoint returns the latest signed tree head as a checkpoint of the log with the given origin, signed with
+// the key of the log under the origin as name. Its text (see SignedCheckpoint.MarshalText) is a note of
+// golang.org/x/mod/sumdb/note, which witnesses can cosign.
+func (l *Log) Checkpoint(origin string) (*gomerkletree.SignedCheckpoint, error) {
+	head := l.SignedHead()
+	return gomerkletree.SignCheckpoint(head.Checkpoint(origin), origin, l.key)
+}
+
+// Head returns the size and root of the latest signed tree head.
+func (l *Log) Head() (int, []byte, error) {
+	head := l.SignedHead()
+	return head.Size, head.Root, nil
+}
+
+// ProofByIndex generates an inclusion proof for the i-th entry against the latest signed tree head.
+func (l *Log) ProofByIndex(i int) (*gomerkletree.Proof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.tree.ProofByIndexAtSize(i, l.head.Size)
+}
+
+// InclusionProof generates an inclusion proof for the i-th entry against the tree head with the given size.
+func (l *Log) InclusionProof(i, size int) (*gomerkletree.Proof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if size > l.head.Size {
+		return nil, errors.New("invalid tree size")
+	}
+	return l.tree.ProofByIndexAtSize(i, size)
+}
+
+// LeafHash returns the hash of the i-th entry of the latest signed tree head.
+func (l *Log) LeafHash(i int) ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if i < 0 || i >= l.head.Size {
+		return nil, errors.New("index out of range")
+	}
+	return l.tree.LeafHash(i)
+}
+
+// ConsistencyProof generates a proof that the tree head with newSize entries extends the one with oldSize entries.
+func (l *Log) ConsistencyProof(oldSize, newSize int) (*gomerkletree.ConsistencyProof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if newSize > l.head.Size {
+		return nil, errors.New("invalid tree sizes")
+	}
+	return l.tree.ConsistencyProof(oldSize, newSize)
+}
+
+// VerifyInclusion checks if a signed tree head is signed by the log, and if the proof includes the entry in its tree.
+func VerifyInclusion(key ed25519.PublicKey, head *gomerkletree.SignedTreeHead, x gomerkletree.Leaf, p *gomerkletree.Proof) error {
+	if err := gomerkletree.VerifyTreeHead(head, key); err != nil {
+		return err
+	}
+	return gomerkletree.VerifyProofAgainstRoot(x, p, head.Root)
+}
+
+// VerifyConsistency checks if both signed tree heads are signed by the log, and if the proof shows that
+// the tree of the new head extends the tree of the old head. Any tree extends the empty tree, which needs no proof.
+func VerifyConsistency(key ed25519.PublicKey, oldHead, newHead *gomerkletree.SignedTreeHead, p *gomerkletree.ConsistencyProof) error {
+	if err := gomerkletree.VerifyTreeHead(oldHead, key); err != nil {
+		return err
+	}
+	if err := gomerkletree.VerifyTreeHead(newHead, key); err != nil {
+		return err
+	}
+	if oldHead.Size > newHead.Size {
+		return errors.New("invalid tree sizes")
+	}
+	if oldHead.Size == 0 {
+		return nil
+	}
+	if p.OldSize() != oldHead.Size || p.NewSize() != newHead.Size {
+		return errors.New("proof does not match tree heads")
+	}
+	return gomerkletree.VerifyConsistency(oldHead.Root, newHead.Root, p)
+}
@@ -0,0 +1,197 @@
+package log
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+	"github.com/jeltjongsma/go-merkletree/httpapi"
+)
+
+type entry string
+
+func (e entry) Bytes() []byte {
+	return []byte(e)
+}
+
+var _ httpapi.SignedLog = (*Log)(nil)
+
+func newLog(t *testing.T) (*Log, ed25519.PublicKey) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l, err := New(gomerkletree.NewMemoryStore(), key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return l, l.PublicKey()
+}
+
+func TestLog_Integrate(t *testing.T) {
+	l, pub := newLog(t)
+
+	empty := l.SignedHead()
+	if empty.Size != 0 {
+		t.Errorf("expected size 0, got %d", empty.Size)
+	}
+	if err := gomerkletree.VerifyTreeHead(empty, pub); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var data []gomerkletree.Leaf
+	for i := range 5 {
+		data = append(data, entry(fmt.Sprint(i)))
+		if index := l.Add(data[i]); index != i {
+			t.Errorf("expected index %d, got %d", i, index)
+		}
+	}
+	if l.Pending() != 5 {
+		t.Errorf("expected 5 pending entries, got %d", l.Pending())
+	}
+
+	// sequenced entries are not part of the head yet
+	if size, _, _ := l.Head(); size != 0 {
+		t.Errorf("expected size 0, got %d", size)
+	}
+	if _, err := l.ProofByIndex(0); err == nil {
+		t.Errorf("expected error for entry that is not integrated")
+	}
+
+	head, err := l.Integrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, _ := gomerkletree.BuildMerkleTree(data)
+	if head.Size != 5 || !bytes.Equal(head.Root, expected.Root()) {
+		t.Errorf("expected head of size 5 with root %x, got size %d with root %x", expected.Root(), head.Size, head.Root)
+	}
+	if l.Pending() != 0 {
+		t.Errorf("expected no pending entries, got %d", l.Pending())
+	}
+
+	for i, x := range data {
+		proof, err := l.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyInclusion(pub, head, x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	l.Add(entry("5"))
+	l.Add(entry("6"))
+	newHead, err := l.Integrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	consistency, err := l.ConsistencyProof(head.Size, newHead.Size)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyConsistency(pub, head, newHead, consistency); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := VerifyConsistency(pub, empty, newHead, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// proofs against the older head
+	proof, err := l.InclusionProof(2, head.Size)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyInclusion(pub, head, data[2], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := VerifyInclusion(pub, newHead, data[2], proof); err == nil {
+		t.Errorf("expected error for proof against another head")
+	}
+}
+
+func TestLog_Verify(t *testing.T) {
+	l, pub := newLog(t)
+	l.Add(entry("a"))
+	l.Add(entry("b"))
+	head, err := l.Integrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proof, _ := l.ProofByIndex(1)
+
+	other, _ := newLog(t)
+	if err := VerifyInclusion(other.PublicKey(), head, entry("b"), proof); err == nil {
+		t.Errorf("expected error for head signed by another log")
+	}
+
+	forged := *head
+	forged.Root = bytes.Repeat([]byte{1}, 32)
+	if err := VerifyInclusion(pub, &forged, entry("b"), proof); err == nil {
+		t.Errorf("expected error for forged head")
+	}
+
+	l.Add(entry("c"))
+	newHead, _ := l.Integrate()
+	consistency, _ := l.ConsistencyProof(1, newHead.Size)
+	if err := VerifyConsistency(pub, head, newHead, consistency); err == nil {
+		t.Errorf("expected error for proof of other sizes")
+	}
+	if err := VerifyConsistency(pub, newHead, head, consistency); err == nil {
+		t.Errorf("expected error for heads in the wrong order")
+	}
+
+	if _, err := New(gomerkletree.NewMemoryStore(), nil); err == nil {
+		t.Errorf("expected error for invalid key")
+	}
+}
+
+func TestLog_Reopen(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	store := gomerkletree.NewMemoryStore()
+
+	l, err := New(store, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Add(entry("a"))
+	l.Add(entry("b"))
+	head, err := l.Integrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := New(store, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size, root, _ := reopened.Head(); size != head.Size || !bytes.Equal(root, head.Root) {
+		t.Errorf("expected the head of the stored log")
+	}
+	if index := reopened.Add(entry("c")); index != 2 {
+		t.Errorf("expected index 2, got %d", index)
+	}
+}
+
+func TestLog_Checkpoint(t *testing.T) {
+	l, pub := newLog(t)
+	l.Add(entry("a"))
+	head, _ := l.Integrate()
+
+	c, err := l.Checkpoint("example.com/log")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Origin != "example.com/log" || c.Size != head.Size || !bytes.Equal(c.Root, head.Root) {
+		t.Errorf("expected checkpoint of the signed tree head, got %+v", c)
+	}
+
+	policy := gomerkletree.WitnessPolicy{Log: gomerkletree.Witness{Name: "example.com/log", Key: pub}}
+	if err := policy.Verify(c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package gomerkletree
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	mappedFileMagic   = "GMTC"
+	mappedFileVersion = 1
+	mappedHeaderSize  = len(mappedFileMagic) + 1 + 4 + 8
+)
+
+// WriteTo writes the tree as a flat file that OpenMappedMerkleTree can map into memory:
+// magic ("GMTC") | version (1 byte) | digest size (uint32) | leaf count (uint64) | hashes,
+// with integers in big endian and the hashes laid out level by level, like they are in memory.
+func (m *CompactMerkleTree) WriteTo(w io.Writer) (int64, error) {
+	if m == nil {
+		return 0, errors.New("nil tree")
+	}
+
+	header := append([]byte(mappedFileMagic), mappedFileVersion)
+	header = binary.BigEndian.AppendUint32(header, uint32(m.size))
+	header = binary.BigEndian.AppendUint64(header, uint64(m.levelLen(0)))
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	k, err := w.Write(m.hashes)
+	return int64(n + k), err
+}
+
+// MappedMerkleTree is a CompactMerkleTree whose hashes are memory-mapped from a file written by WriteTo,
+// so opening it is O(1) and only the pages that are read are loaded, even for trees far larger than RAM.
+// The file must not be modified while it is mapped.
+type MappedMerkleTree struct {
+	*CompactMerkleTree
+	data []byte
+}
+
+// OpenMappedMerkleTree maps a file written by CompactMerkleTree.WriteTo into memory.
+// Options can set the hash strategy the file was written with; other options are ignored.
+// On platforms without mmap, the file is read into memory instead.
+func OpenMappedMerkleTree(path string, opts ...Option) (*MappedMerkleTree, error) {
+	data, err := mmapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := parseMappedFile(data, newConfig(opts).hashStrategy)
+	if err != nil {
+		munmapFile(data)
+		return nil, err
+	}
+	return &MappedMerkleTree{
+		CompactMerkleTree: tree,
+		data:              data,
+	}, nil
+}
+
+// Close unmaps the file. The tree can't be used afterwards.
+func (m *MappedMerkleTree) Close() error {
+	if m == nil || m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	m.CompactMerkleTree = nil
+	return munmapFile(data)
+}
+
+func parseMappedFile(data []byte, hash HashStrategy) (*CompactMerkleTree, error) {
+	if len(data) < mappedHeaderSize || string(data[:len(mappedFileMagic)]) != mappedFileMagic {
+		return nil, errors.New("not a merkle tree file")
+	}
+	header := data[len(mappedFileMagic):mappedHeaderSize]
+	if header[0] != mappedFileVersion {
+		return nil, errors.New("unsupported encoding version")
+	}
+	size := binary.BigEndian.Uint32(header[1:5])
+	n := binary.BigEndian.Uint64(header[5:13])
+	if size == 0 || n == 0 || n > uint64(len(data)) {
+		return nil, errors.New("invalid header")
+	}
+
+	offsets := compactOffsets(int(n))
+	if uint64(len(data)-mappedHeaderSize) != uint64(offsets[len(offsets)-1])*uint64(size) {
+		return nil, errors.New("unexpected file size")
+	}
+
+	return &CompactMerkleTree{
+		hashes:       data[mappedHeaderSize:],
+		size:         int(size),
+		offsets:      offsets,
+		hashStrategy: hash,
+	}, nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package gomerkletree
+
+import "os"
+
+func mmapFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func munmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,99 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedTree_Open(t *testing.T) {
+	var data []Leaf
+	for i := range 21 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree, err := BuildCompactMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "tree")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tree.WriteTo(f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.Close()
+
+	mapped, err := OpenMappedMerkleTree(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer mapped.Close()
+
+	if !bytes.Equal(mapped.Root(), tree.Root()) {
+		t.Errorf("root not correct")
+	}
+
+	if mapped.Len() != tree.Len() {
+		t.Errorf("expected len=%d, got %d", tree.Len(), mapped.Len())
+	}
+
+	if !mapped.Verify() {
+		t.Errorf("couldn't verify mapped tree")
+	}
+
+	for i, x := range data {
+		proof, err := mapped.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyProofAgainstRoot(x, proof, tree.Root()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if err := mapped.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMappedTree_Invalid(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree, err := BuildCompactMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b bytes.Buffer
+	tree.WriteTo(&b)
+	valid := b.Bytes()
+
+	tests := map[string][]byte{
+		"empty":     {},
+		"magic":     append([]byte("XXXX"), valid[4:]...),
+		"version":   append(append([]byte("GMTC"), 0xff), valid[5:]...),
+		"truncated": valid[:len(valid)-1],
+		"trailing":  append(bytes.Clone(valid), 0),
+	}
+
+	dir := t.TempDir()
+	for name, contents := range tests {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, contents, 0o644)
+
+		if _, err := OpenMappedMerkleTree(path); err == nil {
+			t.Errorf("expected err for %s, got nil", name)
+		}
+	}
+
+	if _, err := OpenMappedMerkleTree(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
@@ -0,0 +1,322 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MapEntry is a leaf for a key of a MerkleMap, committing to the key and its value.
+// It is encoded as key length (uvarint) | key | value.
+type MapEntry struct {
+	Key   string
+	Value []byte
+}
+
+func (e MapEntry) Bytes() []byte {
+	b := make([]byte, 0, binary.MaxVarintLen64+len(e.Key)+len(e.Value))
+	b = binary.AppendUvarint(b, uint64(len(e.Key)))
+	b = append(b, e.Key...)
+	return append(b, e.Value...)
+}
+
+// MerkleMap commits to a map from keys to values, with a merkle tree over its entries sorted by key.
+// Since the entries are sorted, the map can prove both the value of a key, and that a key is absent.
+type MerkleMap struct {
+	entries []MapEntry
+	tree    *MerkleTree
+}
+
+// MapProof proves the value of a key of a MerkleMap, with the inclusion proof of its entry.
+// For an absent key it proves the entries before and after the key instead, which have to be adjacent.
+// At the edges of the map only one of them exists.
+type MapProof struct {
+	root         []byte
+	size         int
+	index        int  // index of the entry of the key, or of the first entry after the absent key
+	found        bool // whether the key is in the map
+	entries      []MapEntry
+	proofs       []*Proof
+	hashStrategy HashStrategy
+}
+
+// NewMapProof assembles a map proof from its contents, e.g. after receiving them over the network: the number of
+// entries of the map, the index of the entry of the key (or of the first entry after it), whether the key was found,
+// and the entries with their inclusion proofs. The roots of the inclusion proofs are ignored.
+// A nil hash strategy means the default hash strategy.
+func NewMapProof(root []byte, size, index int, found bool, entries []MapEntry, proofs []*Proof, hash HashStrategy) *MapProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	p := &MapProof{
+		root:         bytes.Clone(root),
+		size:         size,
+		index:        index,
+		found:        found,
+		entries:      cloneMapEntries(entries),
+		proofs:       make([]*Proof, len(proofs)),
+		hashStrategy: hash,
+	}
+	for i, proof := range proofs {
+		if proof != nil {
+			p.proofs[i] = NewProof(root, proof.siblings, proof.left, hash)
+		}
+	}
+	return p
+}
+
+// Root returns a copy of the root the proof was generated for.
+func (p *MapProof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Size returns the number of entries of the map the proof was generated for.
+func (p *MapProof) Size() int {
+	if p == nil {
+		return 0
+	}
+	return p.size
+}
+
+// Index returns the index of the entry of the key, or of the first entry after the key if it is absent.
+func (p *MapProof) Index() int {
+	if p == nil {
+		return 0
+	}
+	return p.index
+}
+
+// Found returns whether the proof is for the value of a key, rather than for its absence.
+func (p *MapProof) Found() bool {
+	return p != nil && p.found
+}
+
+// Entries returns a copy of the proven entries: the entry of the key, or the entries before and after an absent key.
+func (p *MapProof) Entries() []MapEntry {
+	if p == nil {
+		return nil
+	}
+	return cloneMapEntries(p.entries)
+}
+
+// Proofs returns copies of the inclusion proofs of the entries.
+func (p *MapProof) Proofs() []*Proof {
+	if p == nil {
+		return nil
+	}
+	proofs := make([]*Proof, len(p.proofs))
+	for i, proof := range p.proofs {
+		if proof != nil {
+			proofs[i] = NewProof(proof.root, proof.siblings, proof.left, proof.hashStrategy)
+		}
+	}
+	return proofs
+}
+
+func cloneMapEntries(entries []MapEntry) []MapEntry {
+	if entries == nil {
+		return nil
+	}
+	clone := make([]MapEntry, len(entries))
+	for i, e := range entries {
+		clone[i] = MapEntry{Key: e.Key, Value: bytes.Clone(e.Value)}
+	}
+	return clone
+}
+
+// BuildMerkleMap sorts the keys of a map and builds a merkle tree over its entries.
+// The map is copied, so later changes don't affect the MerkleMap. Returns an error for an empty map.
+// Duplication and sorted leaves are not supported, since entries are sorted by key.
+func BuildMerkleMap(m map[string][]byte, opts ...Option) (*MerkleMap, error) {
+	cfg := newConfig(opts)
+	if cfg.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if cfg.sorted {
+		return nil, errors.New("not supported with sorted leaves")
+	}
+
+	keys := slices.Sorted(maps.Keys(m))
+	entries := make([]MapEntry, len(keys))
+	data := make([]Leaf, len(keys))
+	for i, key := range keys {
+		value := m[key]
+		if value == nil {
+			value = []byte{}
+		}
+		entries[i] = MapEntry{Key: key, Value: bytes.Clone(value)}
+		data[i] = entries[i]
+	}
+
+	// the tree is never exposed, so it doesn't need to be verified before proofs
+	tree, err := BuildMerkleTree(data, append(slices.Clip(opts), WithTrusted())...)
+	if err != nil {
+		return nil, err
+	}
+	return &MerkleMap{
+		entries: entries,
+		tree:    tree,
+	}, nil
+}
+
+// Root returns the bytes of the root.
+func (m *MerkleMap) Root() []byte {
+	if m == nil {
+		return nil
+	}
+	return m.tree.Root()
+}
+
+// Len returns the number of keys in the map.
+func (m *MerkleMap) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.entries)
+}
+
+// Get returns a copy of the value of a key, and whether the key is in the map.
+func (m *MerkleMap) Get(key string) ([]byte, bool) {
+	if m == nil {
+		return nil, false
+	}
+	i, found := m.search(key)
+	if !found {
+		return nil, false
+	}
+	return bytes.Clone(m.entries[i].Value), true
+}
+
+// Proof generates a proof for a key: of its value if the key is in the map, or of its absence otherwise.
+func (m *MerkleMap) Proof(key string) (*MapProof, error) {
+	if m == nil {
+		return nil, errors.New("nil map")
+	}
+
+	index, found := m.search(key)
+	p := &MapProof{
+		root:         m.tree.Root(),
+		size:         len(m.entries),
+		index:        index,
+		found:        found,
+		hashStrategy: m.tree.hashStrategy,
+	}
+
+	indices := []int{index}
+	if !found {
+		indices = nil
+		if index > 0 {
+			indices = append(indices, index-1)
+		}
+		if index < len(m.entries) {
+			indices = append(indices, index)
+		}
+	}
+	for _, i := range indices {
+		proof, err := m.tree.ProofByIndex(i)
+		if err != nil {
+			return nil, err
+		}
+		p.entries = append(p.entries, m.entries[i])
+		p.proofs = append(p.proofs, proof)
+	}
+	return p, nil
+}
+
+// search returns the index of the entry of the key, or of the first entry after it if the key is absent.
+func (m *MerkleMap) search(key string) (int, bool) {
+	i := sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].Key >= key
+	})
+	return i, i < len(m.entries) && m.entries[i].Key == key
+}
+
+// VerifyMapProof checks if a proof is valid for a key and its value, or for the absence of a key if value is nil.
+func VerifyMapProof(key string, value []byte, p *MapProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyMapProof(key, value, p, p.root)
+}
+
+// VerifyMapProofAgainstRoot checks if a proof is valid for a key and its value like VerifyMapProof, under a root the
+// verifier already trusts. The root stored in the proof is ignored.
+func VerifyMapProofAgainstRoot(key string, value []byte, p *MapProof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyMapProof(key, value, p, root)
+}
+
+func verifyMapProof(key string, value []byte, p *MapProof, root []byte) error {
+	if p.size <= 0 || p.index < 0 || p.index > p.size || (p.found && p.index == p.size) {
+		return errors.New("index out of range")
+	}
+	if len(p.entries) != len(p.proofs) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	if value != nil {
+		if !p.found {
+			return errors.New("key not in map")
+		}
+		if len(p.entries) != 1 {
+			return errors.New("invalid number of entries")
+		}
+		if e := p.entries[0]; e.Key != key || !bytes.Equal(e.Value, value) {
+			return errors.New("entry does not match")
+		}
+		return verifyMapEntry(p.entries[0], p.proofs[0], p.index, p, root)
+	}
+
+	if p.found {
+		return errors.New("key in map")
+	}
+	entries, proofs := p.entries, p.proofs
+	var adjacent int
+	if p.index > 0 {
+		adjacent++
+	}
+	if p.index < p.size {
+		adjacent++
+	}
+	if len(entries) != adjacent {
+		return errors.New("missing adjacent entry")
+	}
+	if p.index > 0 {
+		if strings.Compare(entries[0].Key, key) >= 0 {
+			return errors.New("key not bracketed")
+		}
+		if err := verifyMapEntry(entries[0], proofs[0], p.index-1, p, root); err != nil {
+			return err
+		}
+		entries, proofs = entries[1:], proofs[1:]
+	}
+	if p.index < p.size {
+		if strings.Compare(key, entries[0].Key) >= 0 {
+			return errors.New("key not bracketed")
+		}
+		if err := verifyMapEntry(entries[0], proofs[0], p.index, p, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyMapEntry verifies the inclusion proof of an entry, and that its path is the path of the entry at the given index.
+func verifyMapEntry(e MapEntry, proof *Proof, index int, p *MapProof, root []byte) error {
+	if proof == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	if !slices.Equal(proof.left, pathDirections(index, p.size)) {
+		return errors.New("entry not at index")
+	}
+	return verifyProof(p.hashStrategy.HashLeaf(e.Bytes()), proof, p.hashStrategy, root)
+}
@@ -0,0 +1,155 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestMerkleMap_Proof(t *testing.T) {
+	values := map[string][]byte{
+		"b": []byte("2"),
+		"d": []byte("4"),
+		"f": []byte("6"),
+		"h": {},
+	}
+
+	m, err := BuildMerkleMap(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the same map always has the same root
+	again, _ := BuildMerkleMap(map[string][]byte{"h": {}, "f": []byte("6"), "d": []byte("4"), "b": []byte("2")})
+	if !bytes.Equal(m.Root(), again.Root()) {
+		t.Errorf("expected the same root")
+	}
+
+	// the map is copied
+	values["b"][0] = 'x'
+	if value, ok := m.Get("b"); !ok || !bytes.Equal(value, []byte("2")) {
+		t.Errorf("expected 2, got %q", value)
+	}
+
+	for key, value := range map[string][]byte{"b": []byte("2"), "d": []byte("4"), "f": []byte("6"), "h": {}} {
+		proof, err := m.Proof(key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyMapProof(key, value, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyMapProof(key, []byte("wrong"), proof); err == nil {
+			t.Errorf("expected error for wrong value")
+		}
+		if err := VerifyMapProof(key, nil, proof); err == nil {
+			t.Errorf("expected error for absence of a key in the map")
+		}
+	}
+
+	// absent keys before, between and after the keys of the map
+	for _, key := range []string{"a", "c", "e", "g", "i"} {
+		if _, ok := m.Get(key); ok {
+			t.Errorf("expected %s to be absent", key)
+		}
+		proof, err := m.Proof(key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyMapProof(key, nil, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyMapProof(key, []byte("1"), proof); err == nil {
+			t.Errorf("expected error for a value of an absent key")
+		}
+	}
+
+	// a proof of absence of one key doesn't prove the absence of a key outside its bracket
+	proof, _ := m.Proof("c")
+	if err := VerifyMapProof("e", nil, proof); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	// entries that aren't adjacent don't prove absence
+	proof.entries[1], proof.proofs[1] = MapEntry{"f", []byte("6")}, mustMapEntryProof(t, m, "f")
+	if err := VerifyMapProof("c", nil, proof); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func mustMapEntryProof(t *testing.T, m *MerkleMap, key string) *Proof {
+	t.Helper()
+	proof, err := m.Proof(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return proof.proofs[0]
+}
+
+func TestMerkleMap_Build(t *testing.T) {
+	if _, err := BuildMerkleMap(nil); err == nil {
+		t.Errorf("expected error for empty map")
+	}
+	if _, err := BuildMerkleMap(map[string][]byte{"a": nil}, WithSortedLeaves()); err == nil {
+		t.Errorf("expected error for sorted leaves")
+	}
+
+	m, err := BuildMerkleMap(map[string][]byte{"a": nil})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, ok := m.Get("a"); !ok || value == nil {
+		t.Errorf("expected an empty value, got %v", value)
+	}
+	if m.Len() != 1 {
+		t.Errorf("expected 1 key, got %d", m.Len())
+	}
+}
+
+func TestMapProof_Encoding(t *testing.T) {
+	m, err := BuildMerkleMap(map[string][]byte{"b": []byte("2"), "d": []byte("4"), "f": []byte("6"), "h": {}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// values, including an empty one, and absent keys in the middle and at both edges
+	for _, kv := range []struct {
+		key   string
+		value []byte
+	}{{"d", []byte("4")}, {"h", []byte{}}, {"c", nil}, {"a", nil}, {"z", nil}} {
+		proof, err := m.Proof(kv.key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fromBinary MapProof
+		if err := fromBinary.UnmarshalBinary(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		j, err := json.Marshal(proof)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fromJSON MapProof
+		if err := json.Unmarshal(j, &fromJSON); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assembled := NewMapProof(m.Root(), proof.Size(), proof.Index(), proof.Found(), proof.Entries(), proof.Proofs(), nil)
+
+		for _, p := range []*MapProof{&fromBinary, &fromJSON, assembled} {
+			if err := VerifyMapProofAgainstRoot(kv.key, kv.value, p, m.Root()); err != nil {
+				t.Errorf("%q: unexpected error: %v", kv.key, err)
+			}
+			if err := VerifyMapProofAgainstRoot(kv.key, kv.value, p, m.Root()[1:]); err == nil {
+				t.Errorf("%q: expected error for another root", kv.key)
+			}
+		}
+
+		if err := fromBinary.UnmarshalBinary(b[:len(b)-1]); err == nil {
+			t.Errorf("%q: expected error for truncated data", kv.key)
+		}
+	}
+}
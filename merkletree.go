@@ -2,9 +2,14 @@ package gomerkletree
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
-
-	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+	"hash"
+	"iter"
+	"slices"
+	"sync"
 )
 
 type Node struct {
@@ -13,10 +18,43 @@ type Node struct {
 	parent      *Node
 }
 
+// Hash returns a copy of the node's hash.
+func (n *Node) Hash() []byte {
+	if n == nil {
+		return nil
+	}
+	return bytes.Clone(n.h)
+}
+
+// Left returns the left child of the node, or nil for leaves.
+func (n *Node) Left() *Node {
+	if n == nil {
+		return nil
+	}
+	return n.left
+}
+
+// Right returns the right child of the node, or nil for leaves.
+// With duplication, the right child can be the same node as the left child.
+func (n *Node) Right() *Node {
+	if n == nil {
+		return nil
+	}
+	return n.right
+}
+
+// Parent returns the parent of the node in the most recent version of the tree, or nil for the root.
+func (n *Node) Parent() *Node {
+	if n == nil {
+		return nil
+	}
+	return n.parent
+}
+
 func (n *Node) verify(hasher HashStrategy) bool {
 	if n.left != nil && n.right != nil {
 		hash := hasher.HashInternal(n.left.h, n.right.h)
-		return bytes.Equal(n.h, hash) && n.left.verify(hasher) && n.right.verify(hasher)
+		return hashEqual(n.h, hash) && n.left.verify(hasher) && (n.right == n.left || n.right.verify(hasher))
 	} else if n.left == nil && n.right == nil {
 		return true
 	} else {
@@ -28,6 +66,22 @@ func (n *Node) isLeft() bool {
 	return n.parent != nil && n.parent.left == n
 }
 
+func newParent(left, right *Node, hash HashStrategy) *Node {
+	return link(left, right, hash.HashInternal(left.h, right.h))
+}
+
+// link creates the parent of two nodes with an already computed hash.
+func link(left, right *Node, h []byte) *Node {
+	parent := &Node{
+		h:     h,
+		left:  left,
+		right: right,
+	}
+	left.parent = parent
+	right.parent = parent
+	return parent
+}
+
 // Leaf is the interface data needs to implement to turn it into a merkle tree.
 type Leaf interface {
 	Bytes() []byte
@@ -42,14 +96,46 @@ type HashStrategy interface {
 type defaultHashStrategy struct{}
 
 func (h defaultHashStrategy) HashLeaf(l []byte) []byte {
-	bytes := append([]byte{0x00}, l...)
-	return hashing.HashSHA256(bytes)
+	return sha256Prefixed(0x00, l, nil)
 }
 
 func (h defaultHashStrategy) HashInternal(l, r []byte) []byte {
-	bytes := append([]byte{0x01}, l...)
-	bytes = append(bytes, r...)
-	return hashing.HashSHA256(bytes)
+	return sha256Prefixed(0x01, l, r)
+}
+
+// maxStackInput bounds the input that is hashed from a buffer on the stack instead of a digest:
+// large enough for an internal node with children of up to 64 bytes.
+const maxStackInput = 1 + 2*64
+
+// sha256Prefixed returns SHA-256(prefix || l || r).
+func sha256Prefixed(prefix byte, l, r []byte) []byte {
+	return appendSHA256Prefixed(nil, prefix, l, r)
+}
+
+// appendSHA256Prefixed appends SHA-256(prefix || l || r) to dst, which may overlap l and r. Small inputs are copied
+// into a buffer on the stack and hashed with sha256.Sum256, so nothing is allocated if dst has room for the hash;
+// larger inputs are written into a pooled digest without copying.
+func appendSHA256Prefixed(dst []byte, prefix byte, l, r []byte) []byte {
+	if n := 1 + len(l) + len(r); n <= maxStackInput {
+		var buf [maxStackInput]byte
+		buf[0] = prefix
+		copy(buf[1:], l)
+		copy(buf[1+len(l):], r)
+		sum := sha256.Sum256(buf[:n])
+		return append(dst, sum[:]...)
+	}
+	d := sha256Pool.Get().(hash.Hash)
+	defer sha256Pool.Put(d)
+	d.Reset()
+	d.Write([]byte{prefix})
+	d.Write(l)
+	d.Write(r)
+	return d.Sum(dst)
+}
+
+// sha256Pool reuses the digests of sha256Prefixed between calls and goroutines.
+var sha256Pool = sync.Pool{
+	New: func() any { return sha256.New() },
 }
 
 type Proof struct {
@@ -59,72 +145,512 @@ type Proof struct {
 	hashStrategy HashStrategy
 }
 
+// Root returns a copy of the root the proof was generated for.
+func (p *Proof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Siblings returns a copy of the sibling hashes, ordered from the leaf up to the root.
+func (p *Proof) Siblings() [][]byte {
+	if p == nil {
+		return nil
+	}
+	siblings := make([][]byte, len(p.siblings))
+	for i, sibling := range p.siblings {
+		siblings[i] = bytes.Clone(sibling)
+	}
+	return siblings
+}
+
+// Directions returns, for every sibling, whether it is a left child (true) or a right child (false).
+func (p *Proof) Directions() []bool {
+	if p == nil {
+		return nil
+	}
+	return slices.Clone(p.left)
+}
+
+// MatchesIndex reports whether the directions of the proof are those of the proof of the i-th leaf of a tree of size
+// leaves built with promotion, so a verifier can check that a proof from an untrusted log is for the leaf it asked for.
+func (p *Proof) MatchesIndex(i, size int) bool {
+	if p == nil || i < 0 || i >= size || size > maxTreeSize {
+		return false
+	}
+	return slices.Equal(p.left, pathDirections(i, size))
+}
+
+// NewProof assembles a proof from its contents, e.g. after receiving them over the network.
+// A nil hash strategy means the default hash strategy.
+func NewProof(root []byte, siblings [][]byte, directions []bool, hash HashStrategy) *Proof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	p := &Proof{
+		root:         bytes.Clone(root),
+		siblings:     make([][]byte, len(siblings)),
+		left:         slices.Clone(directions),
+		hashStrategy: hash,
+	}
+	for i, sibling := range siblings {
+		p.siblings[i] = bytes.Clone(sibling)
+	}
+	return p
+}
+
 type MerkleTree struct {
 	root         *Node
 	n            int
 	leaves       []*Node
+	index        map[string][]int // leaf hash -> positions of the leaves, in ascending order
 	hashStrategy HashStrategy
+	duplicate    bool
+	sorted       bool
+	dedup        bool
+	trusted      bool // skip verification before proofs, see WithTrusted
+	constantTime bool // look up leaves without the index, see WithConstantTime
+	history      *RootHistory
 }
 
 // BuildMerkleTree takes a slice of leaves and builds a merkle tree.
 // This function will use the default SHA-256 based hash strategy,
 // where leaves and internal nodes are prepended with 0x00 and 0x01, respectively.
 // On odd input the function relies on promotion, where the last node is carried up unchanged.
-func BuildMerkleTree(data []Leaf) *MerkleTree {
-	return buildMerkleTree(data, defaultHashStrategy{})
+// Options can change the hash strategy and padding behaviour.
+// Returns an error for empty input, nil leaves, and leaves whose Bytes returns nil.
+func BuildMerkleTree(data []Leaf, opts ...Option) (*MerkleTree, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	cfg := newConfig(opts)
+	p := newProgress(cfg, len(data))
+	hashes, err := hashLeaves(context.Background(), data, cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := buildFromLeafHashesCtx(context.Background(), hashes, cfg, p) // can't be cancelled
+	return m, nil
 }
 
 // BuildMerkleTreeWithHashStrategy takes a slice of leaves and a hash strategy, and builds a merkle tree.
 // On odd input the function relies on promotion, where the last node is carried up unchanged.
-func BuildMerkleTreeWithHashStrategy(data []Leaf, hash HashStrategy) *MerkleTree {
-	return buildMerkleTree(data, hash)
+// Returns an error for the same input as BuildMerkleTree.
+func BuildMerkleTreeWithHashStrategy(data []Leaf, hash HashStrategy) (*MerkleTree, error) {
+	return BuildMerkleTree(data, WithHashStrategy(hash))
 }
 
-func buildMerkleTree(data []Leaf, hash HashStrategy) *MerkleTree {
-	if len(data) == 0 {
-		return nil
+// BuildMerkleTreeFromHashes takes a slice of leaf hashes and builds a merkle tree, without hashing the leaves again.
+// The hashes should have been computed with the hash strategy's HashLeaf for proofs of the leaves to verify.
+// Returns an error for empty input and nil hashes.
+func BuildMerkleTreeFromHashes(hashes [][]byte, opts ...Option) (*MerkleTree, error) {
+	return buildMerkleTreeFromHashes(hashes, newConfig(opts))
+}
+
+// BuildMerkleTreeFromHashesWithHashStrategy takes a slice of leaf hashes and a hash strategy, and builds a merkle tree,
+// without hashing the leaves again.
+func BuildMerkleTreeFromHashesWithHashStrategy(hashes [][]byte, hash HashStrategy) (*MerkleTree, error) {
+	return buildMerkleTreeFromHashes(hashes, newConfig([]Option{WithHashStrategy(hash)}))
+}
+
+// hashLeaves hashes the leaves, rejecting nil leaves and leaves whose Bytes returns nil.
+// Cancellation is checked between batches of leaves.
+func hashLeaves(ctx context.Context, data []Leaf, cfg config, p *progress) ([][]byte, error) {
+	if cfg.workers > 1 {
+		return hashLeavesParallel(ctx, data, cfg.hashStrategy, cfg.workers, p)
+	}
+	hashes := make([][]byte, len(data))
+	if err := hashLeafRange(ctx, data, hashes, cfg.hashStrategy, p); err != nil {
+		return nil, err
 	}
-	level := make([]*Node, len(data))
+	return hashes, nil
+}
+
+// hashLeafRange hashes data into hashes like hashLeaves.
+func hashLeafRange(ctx context.Context, data []Leaf, hashes [][]byte, hash HashStrategy, p *progress) error {
 	for i, x := range data {
-		level[i] = &Node{
-			h: hash.HashLeaf(x.Bytes()),
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		b, err := leafBytes(x)
+		if err != nil {
+			return err
+		}
+		hashes[i] = hash.HashLeaf(b)
+		p.add(1)
+	}
+	return nil
+}
+
+// leafBytes returns the bytes of a leaf, rejecting nil leaves and leaves whose Bytes returns nil.
+func leafBytes(x Leaf) ([]byte, error) {
+	if x == nil {
+		return nil, errors.New("nil leaf")
+	}
+	b := x.Bytes()
+	if b == nil {
+		return nil, errors.New("nil leaf bytes")
+	}
+	return b, nil
+}
+
+// hashLeaf hashes a leaf with the hash strategy of the tree, rejecting the same leaves as BuildMerkleTree.
+func (m *MerkleTree) hashLeaf(x Leaf) ([]byte, error) {
+	b, err := leafBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	return m.hashStrategy.HashLeaf(b), nil
+}
+
+func buildMerkleTreeFromHashes(hashes [][]byte, cfg config) (*MerkleTree, error) {
+	if len(hashes) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	copied := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		if h == nil {
+			return nil, errors.New("nil leaf hash")
+		}
+		copied[i] = bytes.Clone(h)
+	}
+	return buildFromLeafHashes(copied, cfg), nil
+}
+
+func buildFromLeafHashes(hashes [][]byte, cfg config) *MerkleTree {
+	p := newProgress(cfg, len(hashes))
+	p.add(len(hashes))
+	m, _ := buildFromLeafHashesCtx(context.Background(), hashes, cfg, p) // can't be cancelled
+	return m
+}
+
+// dedupHashes removes repeated hashes, keeping the first occurrence.
+func dedupHashes(hashes [][]byte, sorted bool) [][]byte {
+	if sorted {
+		return slices.CompactFunc(hashes, bytes.Equal)
+	}
+	seen := make(map[string]bool, len(hashes))
+	return slices.DeleteFunc(hashes, func(h []byte) bool {
+		if seen[string(h)] {
+			return true
+		}
+		seen[string(h)] = true
+		return false
+	})
+}
+
+// buildFromLeafHashesCtx builds the tree level by level, checking for cancellation before every level.
+// Progress is reported for every new node.
+func buildFromLeafHashesCtx(ctx context.Context, hashes [][]byte, cfg config, p *progress) (*MerkleTree, error) {
+	hash := cfg.hashStrategy
+	if cfg.sorted {
+		slices.SortFunc(hashes, bytes.Compare)
+	}
+	if cfg.dedup {
+		hashes = dedupHashes(hashes, cfg.sorted)
+		if p != nil {
+			p.setTotal(p.done + nodeCount(len(hashes), cfg.duplicate) - len(hashes))
+		}
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	var level []*Node
+	if cfg.workers > 1 {
+		level = leafNodes(hashes)
+	} else {
+		level = make([]*Node, len(hashes))
+		for i, h := range hashes {
+			level[i] = &Node{
+				h: h,
+			}
 		}
 	}
 
 	var leaves []*Node
 	leaves = append(leaves, level...)
 
+	index := make(map[string][]int, len(leaves))
+	for i, l := range leaves {
+		index[string(l.h)] = append(index[string(l.h)], i)
+	}
+
 	n := len(level)
 	for len(level) > 1 {
-		next := make([]*Node, 0, (len(level)+1)/2)
-		for i := range len(level) / 2 {
-			parent := &Node{
-				h:     hash.HashInternal(level[2*i].h, level[2*i+1].h),
-				left:  level[2*i],
-				right: level[2*i+1],
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var next []*Node
+		if pairs := len(level) / 2; cfg.workers > 1 && pairs >= 2*minParallelChunk {
+			next = parentsParallel(level, hash, cfg.workers)
+			n += pairs
+			p.add(pairs)
+		} else {
+			next = make([]*Node, 0, (len(level)+1)/2)
+			for i := range pairs {
+				next = append(next, newParent(level[2*i], level[2*i+1], hash))
+				n++
+				p.add(1)
 			}
-			parent.left.parent = parent
-			parent.right.parent = parent
-			next = append(next, parent)
-			n++
 		}
-		if len(level)%2 != 0 {
-			next = append(next, level[len(level)-1])
+		if last := level[len(level)-1]; len(level)%2 != 0 && cfg.duplicate {
+			next = append(next, newParent(last, last, hash))
+			n++
+			p.add(1)
+		} else if len(level)%2 != 0 {
+			next = append(next, last)
 		}
 
 		level = next
 	}
 
+	cfg.history.record(len(leaves), level[0].h)
 	return &MerkleTree{
 		root:         level[0],
 		n:            n,
 		leaves:       leaves,
+		index:        index,
 		hashStrategy: hash,
+		duplicate:    cfg.duplicate,
+		sorted:       cfg.sorted,
+		dedup:        cfg.dedup,
+		trusted:      cfg.trusted,
+		constantTime: cfg.constantTime,
+		history:      cfg.history,
+	}, nil
+}
+
+// Append adds a leaf to the end of the tree in O(log n), rehashing only the right edge of the tree.
+// The resulting tree is identical to the one BuildMerkleTree would build from all leaves.
+// Appending to an empty (zero value) tree uses the default hash strategy.
+func (m *MerkleTree) Append(x Leaf) error {
+	if m == nil {
+		return errors.New("nil tree")
+	}
+	if m.hashStrategy == nil {
+		m.hashStrategy = defaultHashStrategy{}
+	}
+
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return err
+	}
+	leaf := &Node{
+		h: hash,
+	}
+	if m.sorted && len(m.leaves) > 0 && bytes.Compare(leaf.h, m.leaves[len(m.leaves)-1].h) < 0 {
+		return errors.New("leaf out of order")
+	}
+	if m.dedup && len(m.index[string(leaf.h)]) > 0 {
+		return errors.New("leaf already in tree")
+	}
+
+	if m.duplicate && len(m.leaves) > 0 {
+		m.root = m.appendDuplicated(leaf)
+	} else {
+		m.root = m.appendPromoted(leaf)
+	}
+
+	if m.index == nil {
+		m.index = make(map[string][]int)
+	}
+	m.index[string(leaf.h)] = append(m.index[string(leaf.h)], len(m.leaves))
+
+	m.leaves = append(m.leaves, leaf)
+	m.n = nodeCount(len(m.leaves), m.duplicate)
+	m.history.record(len(m.leaves), m.root.h)
+	return nil
+}
+
+// appendPromoted returns the new root after adding a leaf to a tree built with promotion.
+func (m *MerkleTree) appendPromoted(leaf *Node) *Node {
+	// merge the new leaf with every perfect subtree of the same size, like a binary increment
+	peaks := m.peaks()
+	carry := leaf
+	for size := len(m.leaves); size%2 != 0; size /= 2 {
+		carry = newParent(peaks[len(peaks)-1], carry, m.hashStrategy)
+		peaks = peaks[:len(peaks)-1]
+	}
+	peaks = append(peaks, carry)
+
+	// promotion folds the perfect subtrees together from the right
+	root := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		root = newParent(peaks[i], root, m.hashStrategy)
+	}
+	return root
+}
+
+// appendDuplicated returns the new root after adding a leaf to a non-empty tree built with duplication.
+// Only the ancestors of the new leaf change; their left children are either on the path of the previous last leaf,
+// or the left child of the node they replace.
+func (m *MerkleTree) appendDuplicated(leaf *Node) *Node {
+	n := len(m.leaves)
+
+	var path []*Node // ancestors of the previous last leaf, by level
+	for node := m.leaves[n-1]; node != nil; node = node.parent {
+		path = append(path, node)
+	}
+
+	node := leaf
+	for level := 1; n>>(level-1) > 0; level++ {
+		p := n >> (level - 1) // position of node in the level below
+		if p%2 == 0 {
+			node = newParent(node, node, m.hashStrategy)
+		} else if (n-1)>>(level-1) == p-1 {
+			node = newParent(path[level-1], node, m.hashStrategy)
+		} else {
+			node = newParent(path[level].left, node, m.hashStrategy)
+		}
+	}
+	return node
+}
+
+// nodeCount returns the total number of nodes in a tree with the given number of leaves.
+// With promotion every internal node has two distinct children, so there are always 2n-1 nodes.
+func nodeCount(leaves int, duplicate bool) int {
+	if !duplicate {
+		return 2*leaves - 1
+	}
+	n := leaves
+	for size := leaves; size > 1; size = (size + 1) / 2 {
+		n += (size + 1) / 2
+	}
+	return n
+}
+
+// Update replaces the leaf at the given index and rehashes only the path to the root in O(log n).
+// Nodes on the path are replaced rather than modified.
+func (m *MerkleTree) Update(index int, x Leaf) error {
+	if m == nil {
+		return errors.New("nil tree")
+	}
+	if index < 0 || index >= len(m.leaves) {
+		return errors.New("index out of range")
+	}
+
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return err
+	}
+	if m.sorted && (index > 0 && bytes.Compare(hash, m.leaves[index-1].h) < 0 ||
+		index < len(m.leaves)-1 && bytes.Compare(hash, m.leaves[index+1].h) > 0) {
+		return errors.New("leaf out of order")
+	}
+	if positions := m.index[string(hash)]; m.dedup && len(positions) > 0 && positions[0] != index {
+		return errors.New("leaf already in tree")
+	}
+
+	// copy the path instead of rehashing it in place, so snapshots sharing the old nodes are unaffected
+	old := m.leaves[index]
+	m.removeIndex(old.h, index)
+	node := &Node{
+		h: hash,
+	}
+	m.leaves[index] = node
+	m.insertIndex(node.h, index)
+	for old.parent != nil {
+		parent := old.parent
+		switch {
+		case parent.left == parent.right:
+			node = newParent(node, node, m.hashStrategy)
+		case parent.left == old:
+			node = newParent(node, parent.right, m.hashStrategy)
+		default:
+			node = newParent(parent.left, node, m.hashStrategy)
+		}
+		old = parent
+	}
+	m.root = node
+	m.history.record(len(m.leaves), m.root.h)
+	return nil
+}
+
+// peaks returns the roots of the perfect subtrees that make up the tree, from left to right.
+// Because of promotion, the left child of every node on the right edge is a perfect subtree
+// covering the largest power of two smaller than the number of leaves below that node.
+func (m *MerkleTree) peaks() []*Node {
+	var peaks []*Node
+	node, size := m.root, len(m.leaves)
+	for node != nil && size > 0 {
+		if size&(size-1) == 0 {
+			peaks = append(peaks, node)
+			break
+		}
+		peaks = append(peaks, node.left)
+		node, size = node.right, size-split(size)
+	}
+	return peaks
+}
+
+// maxTreeSize is the largest tree size accepted from proofs, which can come from untrusted peers.
+const maxTreeSize = 1 << 62
+
+// split returns the number of leaves in the left subtree of a node covering size leaves,
+// which is the largest power of two smaller than size.
+func split(size int) int {
+	k := 1
+	for k < size-k { // not k*2 < size, which overflows for sizes from 2^62, e.g. of untrusted proofs
+		k *= 2
+	}
+	return k
+}
+
+// leafIndex returns the index of the first leaf with the given hash, or -1 if there is none.
+func (m *MerkleTree) leafIndex(hash []byte) int {
+	if m.constantTime {
+		return m.leafIndexConstantTime(hash)
+	}
+	positions := m.index[string(hash)]
+	if len(positions) == 0 {
+		return -1
+	}
+	return positions[0]
+}
+
+// leafIndexConstantTime finds the first leaf with the hash like leafIndex, comparing the hash with every leaf
+// without branching on the result, so the time taken doesn't depend on the hash or where the leaf is.
+func (m *MerkleTree) leafIndexConstantTime(hash []byte) int {
+	found := -1
+	for i := len(m.leaves) - 1; i >= 0; i-- {
+		found = subtle.ConstantTimeSelect(subtle.ConstantTimeCompare(m.leaves[i].h, hash), i, found)
 	}
+	return found
+}
+
+func (m *MerkleTree) insertIndex(hash []byte, i int) {
+	positions := m.index[string(hash)]
+	j, _ := slices.BinarySearch(positions, i)
+	m.index[string(hash)] = slices.Insert(positions, j, i)
 }
 
-// Root returns the bytes of the root.
+func (m *MerkleTree) removeIndex(hash []byte, i int) {
+	positions := m.index[string(hash)]
+	if j, ok := slices.BinarySearch(positions, i); ok {
+		positions = slices.Delete(positions, j, j+1)
+	}
+	if len(positions) == 0 {
+		delete(m.index, string(hash))
+	} else {
+		m.index[string(hash)] = positions
+	}
+}
+
+// Root returns a copy of the bytes of the root.
+//
+// The tree owns its hashes: every hash it returns (roots, leaf hashes, the contents of proofs) is a copy the caller
+// may modify, and byte slices passed to it are copied or only read. Hashes inside the tree are never modified in
+// place, so proofs and clones can't be changed by later appends or updates either.
 func (m *MerkleTree) Root() []byte {
+	return bytes.Clone(m.RootNoCopy())
+}
+
+// RootNoCopy returns the bytes of the root without copying them, for hot paths that only read the root.
+// The returned slice is owned by the tree and must not be modified.
+func (m *MerkleTree) RootNoCopy() []byte {
 	if m == nil || m.root == nil {
 		return nil
 	}
@@ -139,25 +665,129 @@ func (m *MerkleTree) Len() int {
 	return m.n
 }
 
+// Walk visits the nodes of the tree depth-first, parents before their children and left before right.
+// If fn returns false, the children of that node are skipped. Duplicated nodes are visited once.
+func (m *MerkleTree) Walk(fn func(*Node) bool) {
+	if m == nil || m.root == nil {
+		return
+	}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if !fn(n) || n.left == nil {
+			return
+		}
+		walk(n.left)
+		if n.right != n.left {
+			walk(n.right)
+		}
+	}
+	walk(m.root)
+}
+
+// NumLeaves returns the number of leaves in the tree.
+func (m *MerkleTree) NumLeaves() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.leaves)
+}
+
+// LeafHash returns a copy of the hash of the i-th leaf, or nil if i is out of range.
+func (m *MerkleTree) LeafHash(i int) []byte {
+	if m == nil || i < 0 || i >= len(m.leaves) {
+		return nil
+	}
+	return bytes.Clone(m.leaves[i].h)
+}
+
+// Leaves iterates over the index and a copy of the hash of every leaf, in order.
+func (m *MerkleTree) Leaves() iter.Seq2[int, []byte] {
+	return func(yield func(int, []byte) bool) {
+		if m == nil {
+			return
+		}
+		for i, l := range m.leaves {
+			if !yield(i, bytes.Clone(l.h)) {
+				return
+			}
+		}
+	}
+}
+
 // Verify verifies the integrity of the tree.
 func (m *MerkleTree) Verify() bool {
 	return m != nil && m.root != nil && m.hashStrategy != nil && m.root.verify(m.hashStrategy)
 }
 
-// VerifyExists verifies a leaf's existence in the tree in O(n) and returns its node (if found).
-func (m *MerkleTree) VerifyExists(x Leaf) (*Node, error) {
-	inLeaves := false
-	hash := m.hashStrategy.HashLeaf(x.Bytes())
-	var node *Node
-	for _, l := range m.leaves {
-		if bytes.Equal(hash, l.h) {
-			inLeaves = true
-			node = l
+// VerifyDetailed verifies the integrity of the tree like Verify, but returns a *CorruptNodeError locating the first node
+// whose hash doesn't match its children, so corrupted regions of persisted trees can be found and repaired.
+func (m *MerkleTree) VerifyDetailed() error {
+	if m == nil || m.root == nil || m.hashStrategy == nil {
+		return errors.New("unable to verify tree")
+	}
+	var start int
+	return m.root.verifyDetailed(m.hashStrategy, 0, &start)
+}
+
+// verifyDetailed verifies the subtree in pre-order. start counts the leaves visited before the node.
+func (n *Node) verifyDetailed(hasher HashStrategy, depth int, start *int) error {
+	if n.left == nil && n.right == nil {
+		*start++
+		return nil
+	}
+	var computed []byte
+	if n.left != nil && n.right != nil {
+		computed = hasher.HashInternal(n.left.h, n.right.h)
+		if hashEqual(n.h, computed) {
+			if err := n.left.verifyDetailed(hasher, depth+1, start); err != nil || n.right == n.left {
+				return err
+			}
+			return n.right.verifyDetailed(hasher, depth+1, start)
 		}
 	}
-	if !inLeaves {
-		return node, errors.New("not in tree")
+	return &CorruptNodeError{
+		Depth:    depth,
+		Start:    *start,
+		End:      *start + n.countLeaves(),
+		Computed: computed,
+		Stored:   bytes.Clone(n.h),
 	}
+}
+
+// countLeaves returns the number of distinct leaves below the node.
+func (n *Node) countLeaves() int {
+	if n == nil {
+		return 0
+	}
+	if n.left == nil && n.right == nil {
+		return 1
+	}
+	if n.right == n.left {
+		return n.left.countLeaves()
+	}
+	return n.left.countLeaves() + n.right.countLeaves()
+}
+
+// verifiedForProof verifies the integrity of the tree before a proof is generated, unless the tree was built WithTrusted.
+func (m *MerkleTree) verifiedForProof() bool {
+	return m.trusted || m.Verify()
+}
+
+// VerifyExists looks up a leaf in O(1), verifies the integrity of the tree in O(n), and returns the leaf's node (if found).
+// If the leaf occurs multiple times, the node of its first occurrence is returned.
+func (m *MerkleTree) VerifyExists(x Leaf) (*Node, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return nil, err
+	}
+	i := m.leafIndex(hash)
+	if i < 0 {
+		return nil, errors.New("not in tree")
+	}
+	node := m.leaves[i]
 
 	if !m.Verify() {
 		return node, errors.New("unable to verify tree")
@@ -169,15 +799,150 @@ func (m *MerkleTree) VerifyExists(x Leaf) (*Node, error) {
 // Proof generates a proof for a given leaf.
 // Returns `Proof` object that contains the root, necessary siblings for the proof,
 // and whether the sibling is a left or right child.
+// The integrity of the whole tree is verified first in O(n), unless the tree was built WithTrusted.
+// If the leaf occurs multiple times, the proof is for its first occurrence; use FindAll and ProofAt for the others.
 func (m *MerkleTree) Proof(x Leaf) (proof *Proof, err error) {
 	if m == nil {
 		return nil, errors.New("nil tree")
 	}
-	node, err := m.VerifyExists(x)
+	hash, err := m.hashLeaf(x)
 	if err != nil {
 		return nil, err
 	}
+	i := m.leafIndex(hash)
+	if i < 0 {
+		return nil, errors.New("not in tree")
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	return m.proof(m.leaves[i]), nil
+}
+
+// Index returns the position of a leaf in the tree in O(1). If the leaf occurs multiple times, the position of its
+// first occurrence is returned; use FindAll for the others.
+func (m *MerkleTree) Index(x Leaf) (int, error) {
+	if m == nil {
+		return -1, errors.New("nil tree")
+	}
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return -1, err
+	}
+	i := m.leafIndex(hash)
+	if i < 0 {
+		return -1, errors.New("not in tree")
+	}
+	return i, nil
+}
+
+// FindAll returns the indices of all occurrences of a leaf in ascending order, or nil if it isn't in the tree.
+func (m *MerkleTree) FindAll(x Leaf) []int {
+	if m == nil {
+		return nil
+	}
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return nil
+	}
+	return slices.Clone(m.index[string(hash)])
+}
+
+// ProofAt generates a proof for a leaf at the given index, which disambiguates between occurrences of duplicate leaves.
+// Returns an error if the leaf isn't at that index.
+func (m *MerkleTree) ProofAt(x Leaf, i int) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.leaves) {
+		return nil, errors.New("index out of range")
+	}
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return nil, err
+	}
+	if !hashEqual(m.leaves[i].h, hash) {
+		return nil, errors.New("not at index")
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
 
+	return m.proof(m.leaves[i]), nil
+}
+
+// ProofByIndex generates a proof for the i-th leaf, without requiring the leaf itself.
+func (m *MerkleTree) ProofByIndex(i int) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.leaves) {
+		return nil, errors.New("index out of range")
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	return m.proof(m.leaves[i]), nil
+}
+
+// AllProofs generates the proofs of all leaves, in the order of the leaves, verifying the tree only once.
+// The proofs are collected in a single traversal in O(n log n), instead of calling ProofByIndex n times in O(n^2).
+func (m *MerkleTree) AllProofs() ([]*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	root := m.Root()
+	proofs := make([]*Proof, 0, len(m.leaves))
+
+	// siblings and left hold the path from the root down to the current node
+	var siblings [][]byte
+	var left []bool
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n.left == nil {
+			p := &Proof{
+				root:         root,
+				siblings:     make([][]byte, len(siblings)),
+				left:         make([]bool, len(left)),
+				hashStrategy: m.hashStrategy,
+			}
+			for i, sibling := range cloneHashes(siblings) {
+				p.siblings[len(siblings)-1-i] = sibling
+				p.left[len(left)-1-i] = left[i]
+			}
+			proofs = append(proofs, p)
+			return
+		}
+		siblings, left = append(siblings, n.right.h), append(left, false)
+		walk(n.left)
+		siblings, left = siblings[:len(siblings)-1], left[:len(left)-1]
+		if n.right == n.left {
+			return
+		}
+		siblings, left = append(siblings, n.left.h), append(left, true)
+		walk(n.right)
+		siblings, left = siblings[:len(siblings)-1], left[:len(left)-1]
+	}
+	walk(m.root)
+
+	return proofs, nil
+}
+
+func (m *MerkleTree) proof(node *Node) *Proof {
+	return nodeProof(node, m.root.h, m.hashStrategy)
+}
+
+// nodeProof collects the proof of a node by following its parents up to the root.
+func nodeProof(node *Node, root []byte, hash HashStrategy) *Proof {
 	var siblings [][]byte
 	var left []bool
 
@@ -193,11 +958,26 @@ func (m *MerkleTree) Proof(x Leaf) (proof *Proof, err error) {
 	}
 
 	return &Proof{
-		root:         m.Root(),
-		siblings:     siblings,
+		root:         bytes.Clone(root),
+		siblings:     cloneHashes(siblings),
 		left:         left,
-		hashStrategy: m.hashStrategy,
-	}, nil
+		hashStrategy: hash,
+	}
+}
+
+// cloneHashes copies hashes into a single buffer, so proofs don't share memory with the nodes they were read from.
+func cloneHashes(hashes [][]byte) [][]byte {
+	n := 0
+	for _, h := range hashes {
+		n += len(h)
+	}
+	buf := make([]byte, 0, n)
+	clones := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		buf = append(buf, h...)
+		clones[i] = buf[len(buf)-len(h) : len(buf) : len(buf)]
+	}
+	return clones
 }
 
 // VerifyProof checks if a proof is valid for a given leaf.
@@ -205,21 +985,129 @@ func VerifyProof(x Leaf, p *Proof) error {
 	if p == nil || p.hashStrategy == nil {
 		return errors.New("no proof/hash strategy")
 	}
+	b, err := leafBytes(x)
+	if err != nil {
+		return err
+	}
+	return verifyLeafProof(b, p, p.hashStrategy, p.root)
+}
+
+// VerifyProofAgainstRoot checks if a proof is valid for a given leaf under a root the verifier already trusts.
+// Unlike VerifyProof, the root stored in the proof (which came from the prover) is ignored.
+func VerifyProofAgainstRoot(x Leaf, p *Proof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	b, err := leafBytes(x)
+	if err != nil {
+		return err
+	}
+	return verifyLeafProof(b, p, p.hashStrategy, root)
+}
+
+// Verify checks if the proof is valid for a given leaf, like VerifyProof.
+func (p *Proof) Verify(x Leaf) error {
+	return VerifyProof(x, p)
+}
+
+// VerifyProofWithStrategy checks if a proof is valid for a given leaf under the expected root, using the given hash strategy.
+// The root and hash strategy stored in the proof are ignored, so proofs that were decoded
+// (and therefore can't carry their hash strategy) can be verified against a trusted root.
+func VerifyProofWithStrategy(x Leaf, p *Proof, hash HashStrategy, root []byte) error {
+	if p == nil || hash == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	b, err := leafBytes(x)
+	if err != nil {
+		return err
+	}
+	return verifyLeafProof(b, p, hash, root)
+}
+
+// VerifyProofFromHash checks if a proof is valid for a leaf of which only the hash is known, so light clients can verify
+// inclusion without the data of the leaf. The hash must be the HashLeaf of the proof's hash strategy over the leaf:
+// an internal node hash would verify against a shorter proof.
+func VerifyProofFromHash(leafHash []byte, p *Proof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return VerifyProofFromHashAgainstRoot(leafHash, p, p.root)
+}
+
+// VerifyProofFromHashAgainstRoot checks if a proof is valid for a leaf hash under a root the verifier already trusts,
+// like VerifyProofFromHash, ignoring the root stored in the proof.
+func VerifyProofFromHashAgainstRoot(leafHash []byte, p *Proof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	if leafHash == nil {
+		return errors.New("nil leaf hash")
+	}
+	return verifyProof(leafHash, p, p.hashStrategy, root)
+}
+
+// MaxProofDepth is the maximum number of siblings VerifyProofStrict accepts, enough for trees of up to 2^64 leaves.
+const MaxProofDepth = 64
+
+// VerifyProofStrict checks if a proof is valid for a given leaf under a root the verifier already trusts,
+// like VerifyProofAgainstRoot, but first rejects malformed proofs: proofs with more than MaxProofDepth siblings,
+// nil siblings, and siblings whose length differs from the digest size of the hash strategy.
+// Use it for proofs that come from untrusted peers.
+func VerifyProofStrict(x Leaf, p *Proof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	b, err := leafBytes(x)
+	if err != nil {
+		return err
+	}
+	hash := p.hashStrategy.HashLeaf(b)
+	if err := validateProof(p, len(hash)); err != nil {
+		return err
+	}
+	return verifyProof(hash, p, p.hashStrategy, root)
+}
+
+// validateProof checks the shape of a proof before any hashing is done over it.
+func validateProof(p *Proof, size int) error {
 	if len(p.siblings) != len(p.left) {
 		return errors.New("proof lengths mismatch")
 	}
-	hash := p.hashStrategy.HashLeaf(x.Bytes())
+	if len(p.siblings) > MaxProofDepth {
+		return errors.New("proof too deep")
+	}
+	for _, sibling := range p.siblings {
+		if sibling == nil {
+			return errors.New("nil sibling")
+		}
+		if len(sibling) != size {
+			return errors.New("invalid sibling length")
+		}
+	}
+	return nil
+}
+
+// verifyProof checks if a proof is valid for a given leaf hash under the given root.
+func verifyProof(hash []byte, p *Proof, strategy HashStrategy, root []byte) error {
+	if len(p.siblings) != len(p.left) {
+		return errors.New("proof lengths mismatch")
+	}
+	if s, ok := strategy.(AppendHasher); ok {
+		scratch := scratchPool.Get().(*[]byte)
+		defer scratchPool.Put(scratch)
+		return verifyProofAppend(hash, p, s, root, scratch)
+	}
 
 	for i, isLeft := range p.left {
 		if isLeft {
-			hash = p.hashStrategy.HashInternal(p.siblings[i], hash)
+			hash = strategy.HashInternal(p.siblings[i], hash)
 		} else {
-			hash = p.hashStrategy.HashInternal(hash, p.siblings[i])
+			hash = strategy.HashInternal(hash, p.siblings[i])
 		}
 	}
 
-	if !bytes.Equal(hash, p.root) {
-		return errors.New("root does not match")
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root)
 	}
 	return nil
 }
@@ -2,6 +2,11 @@ package gomerkletree
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"testing"
 )
 
@@ -100,7 +105,7 @@ func TestTree_Build_Basic(t *testing.T) {
 	data = append(data, &TestLeaf{"a"})
 	data = append(data, &TestLeaf{"b"})
 
-	tree := BuildMerkleTree(data)
+	tree := mustBuildMerkleTree(t, data)
 
 	// check tree properties
 	if tree.Len() != 3 {
@@ -147,7 +152,7 @@ func TestTree_Build_Uneven(t *testing.T) {
 	data = append(data, &TestLeaf{"b"})
 	data = append(data, &TestLeaf{"c"})
 
-	tree := BuildMerkleTree(data)
+	tree := mustBuildMerkleTree(t, data)
 
 	// check tree properties
 	if tree.Len() != 5 {
@@ -205,7 +210,7 @@ func TestTree_VerifyExists(t *testing.T) {
 	data = append(data, &TestLeaf{"b"})
 	data = append(data, &TestLeaf{"c"})
 
-	tree := BuildMerkleTree(data)
+	tree := mustBuildMerkleTree(t, data)
 
 	if _, err := tree.VerifyExists(data[0]); err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -224,13 +229,44 @@ func TestTree_VerifyExists(t *testing.T) {
 	}
 }
 
+func TestTree_VerifyDetailed(t *testing.T) {
+	var data []Leaf
+	for _, x := range []string{"a", "b", "c", "d", "e"} {
+		data = append(data, &TestLeaf{x})
+	}
+
+	for _, opts := range [][]Option{nil, {WithDuplication()}} {
+		tree := mustBuildMerkleTree(t, data, opts...)
+		if err := tree.VerifyDetailed(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tree.leaves[2].h = []byte("corrupted")
+
+		var cerr *CorruptNodeError
+		if err := tree.VerifyDetailed(); !errors.As(err, &cerr) {
+			t.Fatalf("expected corrupt node error, got %v", err)
+		}
+		if cerr.Depth != 2 || cerr.Start != 2 || cerr.End != 4 {
+			t.Errorf("expected node at depth 2 covering [2, 4), got depth %d covering [%d, %d)", cerr.Depth, cerr.Start, cerr.End)
+		}
+		if !bytes.Equal(cerr.Stored, tree.leaves[2].parent.h) {
+			t.Errorf("expected stored hash %x, got %x", tree.leaves[2].parent.h, cerr.Stored)
+		}
+	}
+
+	if err := (*MerkleTree)(nil).VerifyDetailed(); err == nil {
+		t.Errorf("expected error for nil tree")
+	}
+}
+
 func TestTree_Proof(t *testing.T) {
 	var data []Leaf
 	data = append(data, &TestLeaf{"a"})
 	data = append(data, &TestLeaf{"b"})
 	data = append(data, &TestLeaf{"c"})
 
-	tree := BuildMerkleTree(data)
+	tree := mustBuildMerkleTree(t, data)
 
 	// not in tree
 	_, err := tree.Proof(&TestLeaf{"d"})
@@ -316,7 +352,7 @@ func TestProof_Verify(t *testing.T) {
 	data = append(data, &TestLeaf{"b"})
 	data = append(data, &TestLeaf{"c"})
 
-	tree := BuildMerkleTree(data)
+	tree := mustBuildMerkleTree(t, data)
 
 	proof, _ := tree.Proof(data[0])
 
@@ -331,8 +367,12 @@ func TestProof_Verify(t *testing.T) {
 		t.Errorf("expected err, got nil")
 	}
 
-	if err.Error() != "root does not match" {
-		t.Errorf("expected root does not match, got %s", err.Error())
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRootMismatch) {
+		t.Fatalf("expected root mismatch, got %v", err)
+	}
+	if !bytes.Equal(verr.Expected, proof.Root()) || bytes.Equal(verr.Computed, verr.Expected) {
+		t.Errorf("expected computed root and root of the proof, got %x and %x", verr.Computed, verr.Expected)
 	}
 
 	// mismatch lengths
@@ -368,3 +408,1084 @@ func TestProof_Verify(t *testing.T) {
 		t.Errorf("expected missing proof/strategy, got %s", err.Error())
 	}
 }
+
+func TestTree_Append(t *testing.T) {
+	var data []Leaf
+	tree := &MerkleTree{}
+
+	for i := range 33 {
+		x := &TestLeaf{string(rune('a' + i))}
+		data = append(data, x)
+
+		if err := tree.Append(x); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := mustBuildMerkleTree(t, data)
+		if !bytes.Equal(tree.Root(), expected.Root()) {
+			t.Fatalf("root not correct after %d appends", i+1)
+		}
+
+		if tree.Len() != expected.Len() {
+			t.Errorf("expected len=%d, got %d", expected.Len(), tree.Len())
+		}
+
+		if !tree.Verify() {
+			t.Fatalf("couldn't verify tree after %d appends", i+1)
+		}
+	}
+
+	// proofs still work on appended leaves
+	for _, x := range data {
+		proof, err := tree.Proof(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	var nilTree *MerkleTree
+	if err := nilTree.Append(data[0]); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_Update(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+	data = append(data, &TestLeaf{"d"})
+	data = append(data, &TestLeaf{"e"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for i := range data {
+		data[i] = &TestLeaf{data[i].(*TestLeaf).x + "*"}
+		if err := tree.Update(i, data[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := mustBuildMerkleTree(t, data)
+		if !bytes.Equal(tree.Root(), expected.Root()) {
+			t.Errorf("root not correct after updating leaf %d", i)
+		}
+
+		if !tree.Verify() {
+			t.Fatalf("couldn't verify tree after updating leaf %d", i)
+		}
+	}
+
+	if _, err := tree.VerifyExists(&TestLeaf{"a"}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if err := tree.Update(-1, data[0]); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if err := tree.Update(len(data), data[0]); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_AllProofs(t *testing.T) {
+	var data []Leaf
+	for i := range 13 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	for _, opts := range [][]Option{nil, {WithDuplication()}} {
+		tree := mustBuildMerkleTree(t, data, opts...)
+		proofs, err := tree.AllProofs()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(proofs) != len(data) {
+			t.Fatalf("expected %d proofs, got %d", len(data), len(proofs))
+		}
+
+		for i, proof := range proofs {
+			expected, _ := tree.ProofByIndex(i)
+			if !slices.EqualFunc(proof.Siblings(), expected.Siblings(), bytes.Equal) || !slices.Equal(proof.Directions(), expected.Directions()) {
+				t.Errorf("proof %d differs from ProofByIndex", i)
+			}
+			if err := VerifyProof(data[i], proof); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	if _, err := (*MerkleTree)(nil).AllProofs(); err == nil {
+		t.Errorf("expected error for nil tree")
+	}
+}
+
+func TestTree_WithTrusted(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data, WithTrusted())
+	proof, err := tree.Proof(data[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(data[2], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// corruption goes unnoticed while generating proofs, but the proofs don't verify
+	tree.leaves[0].parent.h = []byte("corrupted")
+	proof, err = tree.Proof(data[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(data[2], proof); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	untrusted := mustBuildMerkleTree(t, data)
+	untrusted.leaves[0].parent.h = []byte("corrupted")
+	if _, err := untrusted.Proof(data[2]); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestTree_FindAll(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"c"})
+	data = append(data, &TestLeaf{"a"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	indices := tree.FindAll(&TestLeaf{"a"})
+	if !slices.Equal(indices, []int{0, 2, 4}) {
+		t.Fatalf("expected [0 2 4], got %v", indices)
+	}
+	if indices := tree.FindAll(&TestLeaf{"d"}); indices != nil {
+		t.Errorf("expected nil, got %v", indices)
+	}
+
+	// the indices are a copy
+	indices[0] = 3
+	if indices := tree.FindAll(&TestLeaf{"a"}); indices[0] != 0 {
+		t.Errorf("expected index 0, got %d", indices[0])
+	}
+
+	for _, i := range []int{0, 2, 4} {
+		proof, err := tree.ProofAt(&TestLeaf{"a"}, i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected, _ := tree.ProofByIndex(i)
+		if !slices.EqualFunc(proof.Siblings(), expected.Siblings(), bytes.Equal) {
+			t.Errorf("expected proof of index %d", i)
+		}
+		if err := VerifyProof(&TestLeaf{"a"}, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// the first occurrence is proven by Proof
+	proof, _ := tree.Proof(&TestLeaf{"a"})
+	if expected, _ := tree.ProofAt(&TestLeaf{"a"}, 0); !slices.EqualFunc(proof.Siblings(), expected.Siblings(), bytes.Equal) {
+		t.Errorf("expected proof of the first occurrence")
+	}
+
+	if _, err := tree.ProofAt(&TestLeaf{"a"}, 1); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := tree.ProofAt(&TestLeaf{"a"}, 5); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestTree_ProofByIndex(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for i, x := range data {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected, _ := tree.Proof(x)
+		if len(proof.siblings) != len(expected.siblings) {
+			t.Fatalf("expected %d siblings, got %d", len(expected.siblings), len(proof.siblings))
+		}
+
+		for j := range proof.siblings {
+			if !bytes.Equal(proof.siblings[j], expected.siblings[j]) || proof.left[j] != expected.left[j] {
+				t.Errorf("sibling %d of leaf %d not correct", j, i)
+			}
+		}
+
+		if err := VerifyProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := tree.ProofByIndex(-1); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ProofByIndex(3); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_Index(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"a"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	// duplicates resolve to the first occurrence
+	if i := tree.leafIndex(hashStrategy.HashLeaf([]byte("a"))); i != 0 {
+		t.Errorf("expected index=0, got %d", i)
+	}
+
+	if i := tree.leafIndex(hashStrategy.HashLeaf([]byte("c"))); i != -1 {
+		t.Errorf("expected index=-1, got %d", i)
+	}
+
+	// index follows updates
+	tree.Update(0, &TestLeaf{"c"})
+
+	if i := tree.leafIndex(hashStrategy.HashLeaf([]byte("a"))); i != 2 {
+		t.Errorf("expected index=2, got %d", i)
+	}
+
+	if i := tree.leafIndex(hashStrategy.HashLeaf([]byte("c"))); i != 0 {
+		t.Errorf("expected index=0, got %d", i)
+	}
+
+	tree.Update(2, &TestLeaf{"b"})
+
+	if i := tree.leafIndex(hashStrategy.HashLeaf([]byte("a"))); i != -1 {
+		t.Errorf("expected index=-1, got %d", i)
+	}
+
+	if len(tree.index[string(hashStrategy.HashLeaf([]byte("b")))]) != 2 {
+		t.Errorf("expected 2 positions for b")
+	}
+
+	// index follows appends
+	tree.Append(&TestLeaf{"a"})
+
+	if i := tree.leafIndex(hashStrategy.HashLeaf([]byte("a"))); i != 3 {
+		t.Errorf("expected index=3, got %d", i)
+	}
+
+	// Index looks up leaves
+	if i, err := tree.Index(&TestLeaf{"b"}); err != nil || i != 1 {
+		t.Errorf("expected index=1, got %d (%v)", i, err)
+	}
+	if _, err := tree.Index(&TestLeaf{"d"}); err == nil {
+		t.Errorf("expected error for leaf not in tree")
+	}
+	if _, err := tree.Index(nil); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+	if i, err := NewSyncTree(tree).Index(&TestLeaf{"a"}); err != nil || i != 3 {
+		t.Errorf("expected index=3, got %d (%v)", i, err)
+	}
+}
+
+func TestTree_BuildFromHashes(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	var hashes [][]byte
+	for _, x := range data {
+		hashes = append(hashes, hashStrategy.HashLeaf(x.Bytes()))
+	}
+
+	tree, err := BuildMerkleTreeFromHashes(hashes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := mustBuildMerkleTree(t, data)
+
+	if !bytes.Equal(tree.Root(), expected.Root()) {
+		t.Errorf("root not correct")
+	}
+
+	if tree.Len() != expected.Len() {
+		t.Errorf("expected len=%d, got %d", expected.Len(), tree.Len())
+	}
+
+	// input is copied
+	hashes[0][0] ^= 0xff
+
+	if !tree.Verify() || !bytes.Equal(tree.Root(), expected.Root()) {
+		t.Errorf("tree changed with input")
+	}
+
+	proof, err := tree.Proof(data[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(data[1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := BuildMerkleTreeFromHashes(nil); err == nil {
+		t.Errorf("expected error for empty input")
+	}
+	if _, err := BuildMerkleTreeFromHashes([][]byte{hashes[0], nil}); err == nil {
+		t.Errorf("expected error for nil hash")
+	}
+}
+
+func TestTree_WithDedup(t *testing.T) {
+	a, b, c := &TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}
+
+	set := mustBuildMerkleTree(t, []Leaf{c, a, b, a, c}, WithSortedLeaves(), WithDedup())
+	if expected := mustBuildMerkleTree(t, []Leaf{b, c, a}, WithSortedLeaves()); !bytes.Equal(set.Root(), expected.Root()) {
+		t.Errorf("expected the root of the sorted set")
+	}
+
+	// without sorting, the first occurrences are kept in order
+	tree := mustBuildMerkleTree(t, []Leaf{a, b, a, c, b}, WithDedup())
+	if expected := mustBuildMerkleTree(t, []Leaf{a, b, c}); !bytes.Equal(tree.Root(), expected.Root()) {
+		t.Errorf("expected the root of the first occurrences")
+	}
+	if tree.NumLeaves() != 3 {
+		t.Errorf("expected 3 leaves, got %d", tree.NumLeaves())
+	}
+
+	if err := tree.Append(a); err == nil {
+		t.Errorf("expected error for duplicate leaf")
+	}
+	if err := tree.Update(2, b); err == nil {
+		t.Errorf("expected error for duplicate leaf")
+	}
+	if err := tree.Update(2, c); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	encoded, _ := tree.MarshalBinary()
+	decoded, err := DecodeMerkleTree(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := decoded.Append(a); err == nil {
+		t.Errorf("expected error for duplicate leaf after decoding")
+	}
+
+	var done, total int
+	mustBuildMerkleTree(t, []Leaf{a, a, a, b}, WithDedup(), WithProgress(func(d, t int) {
+		done, total = d, t
+	}))
+	if done != total {
+		t.Errorf("expected done == total, got %d and %d", done, total)
+	}
+}
+
+func TestTree_Build_Duplication(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data, WithDuplication())
+
+	// check tree properties
+	if tree.Len() != 6 {
+		t.Errorf("expected len=6, got %d", tree.Len())
+	}
+
+	if !tree.Verify() {
+		t.Fatalf("couldn't verify tree")
+	}
+
+	// check node properties
+	leftLeft := hashStrategy.HashLeaf(data[0].Bytes())
+	leftRight := hashStrategy.HashLeaf(data[1].Bytes())
+	left := hashStrategy.HashInternal(leftLeft, leftRight)
+	rightLeft := hashStrategy.HashLeaf(data[2].Bytes())
+	right := hashStrategy.HashInternal(rightLeft, rightLeft)
+
+	root := hashStrategy.HashInternal(left, right)
+
+	if !bytes.Equal(tree.Root(), root) {
+		t.Errorf("root not correct")
+	}
+
+	if !bytes.Equal(tree.leaves[2].parent.h, right) {
+		t.Errorf("right parent not correct")
+	}
+
+	// the duplicated node is its own sibling
+	proof, err := tree.Proof(data[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(rightLeft, proof.siblings[0]) {
+		t.Errorf("first sibling not correct")
+	}
+	if proof.left[0] {
+		t.Errorf("expected false, got true (right child)")
+	}
+
+	if err := VerifyProof(data[2], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// not supported with duplication
+	if _, err := tree.MultiProof(data); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ConsistencyProof(1, 2); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_Append_Duplication(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+
+	tree := mustBuildMerkleTree(t, data, WithDuplication())
+
+	for i := range 33 {
+		x := &TestLeaf{string(rune('b' + i))}
+		data = append(data, x)
+
+		if err := tree.Append(x); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := mustBuildMerkleTree(t, data, WithDuplication())
+		if !bytes.Equal(tree.Root(), expected.Root()) {
+			t.Fatalf("root not correct after %d appends", i+1)
+		}
+
+		if tree.Len() != expected.Len() {
+			t.Errorf("expected len=%d, got %d", expected.Len(), tree.Len())
+		}
+
+		if !tree.Verify() {
+			t.Fatalf("couldn't verify tree after %d appends", i+1)
+		}
+	}
+
+	for i, x := range data {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// updates rehash duplicated nodes as well
+	data[len(data)-1] = &TestLeaf{"z"}
+	if err := tree.Update(len(data)-1, data[len(data)-1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(tree.Root(), mustBuildMerkleTree(t, data, WithDuplication()).Root()) {
+		t.Errorf("root not correct after update")
+	}
+}
+
+func TestProof_Accessors(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.Proof(data[1])
+
+	if !bytes.Equal(proof.Root(), tree.Root()) {
+		t.Errorf("root not correct")
+	}
+
+	siblings := proof.Siblings()
+	if len(siblings) != 2 {
+		t.Fatalf("expected 2 siblings, got %d", len(siblings))
+	}
+
+	if !bytes.Equal(siblings[0], hashStrategy.HashLeaf(data[0].Bytes())) {
+		t.Errorf("first sibling not correct")
+	}
+
+	directions := proof.Directions()
+	if len(directions) != 2 || !directions[0] || directions[1] {
+		t.Errorf("expected [true false], got %v", directions)
+	}
+
+	// modifying the results doesn't affect the proof
+	proof.Root()[0] ^= 0xff
+	siblings[0][0] ^= 0xff
+	directions[0] = false
+
+	if err := VerifyProof(data[1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var nilProof *Proof
+	if nilProof.Root() != nil || nilProof.Siblings() != nil || nilProof.Directions() != nil {
+		t.Errorf("expected nil results for nil proof")
+	}
+}
+
+func TestProof_New(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.Proof(data[2])
+
+	rebuilt := NewProof(proof.Root(), proof.Siblings(), proof.Directions(), nil)
+	if err := VerifyProof(data[2], rebuilt); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	multi, err := tree.MultiProof(data[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rebuiltMulti := NewMultiProof(multi.Root(), multi.Size(), multi.Indices(), multi.Hashes(), nil)
+	if err := VerifyMultiProof(data[:2], rebuiltMulti); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	consistency, err := tree.ConsistencyProof(1, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rebuiltConsistency := NewConsistencyProof(consistency.OldSize(), consistency.NewSize(), consistency.Hashes(), nil)
+	if err := VerifyConsistency(mustBuildMerkleTree(t, data[:1]).Root(), tree.Root(), rebuiltConsistency); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProof_VerifyStrict(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.Proof(data[2])
+
+	if err := VerifyProofStrict(data[2], proof, tree.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	siblings := proof.Siblings()
+	short := append([][]byte{siblings[0][:31]}, siblings[1:]...)
+	deep := make([][]byte, MaxProofDepth+1)
+	for i := range deep {
+		deep[i] = siblings[0]
+	}
+
+	for name, p := range map[string]*Proof{
+		"nil sibling":     NewProof(proof.Root(), [][]byte{nil}, []bool{true}, nil),
+		"short sibling":   NewProof(proof.Root(), short, proof.Directions(), nil),
+		"too deep":        NewProof(proof.Root(), deep, make([]bool, len(deep)), nil),
+		"length mismatch": NewProof(proof.Root(), siblings, nil, nil),
+	} {
+		if err := VerifyProofStrict(data[2], p, tree.Root()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	for _, x := range []Leaf{nil, nilLeaf{}} {
+		if err := VerifyProofStrict(x, proof, tree.Root()); err == nil {
+			t.Errorf("expected error for invalid leaf %v", x)
+		}
+	}
+}
+
+func TestProof_VerifyWithStrategy(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data, WithSortedPairs())
+	proof, _ := tree.Proof(data[2])
+
+	if err := proof.Verify(data[2]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := proof.Verify(data[0]); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// decoded proofs lose their hash strategy
+	b, _ := proof.MarshalBinary()
+	var decoded Proof
+	decoded.UnmarshalBinary(b)
+
+	strategy := SortedPairHashStrategy{defaultHashStrategy{}}
+	if err := VerifyProofWithStrategy(data[2], &decoded, strategy, tree.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the root in the proof is ignored
+	decoded.root = []byte("a")
+	if err := VerifyProofWithStrategy(data[2], &decoded, strategy, tree.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := VerifyProofWithStrategy(data[2], &decoded, strategy, []byte("a"))
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRootMismatch) {
+		t.Errorf("expected root mismatch, got %v", err)
+	}
+
+	if err := VerifyProofWithStrategy(data[2], &decoded, nil, tree.Root()); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestProof_VerifyAgainstRoot(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+	proof, _ := tree.Proof(data[0])
+
+	if err := VerifyProofAgainstRoot(data[0], proof, tree.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// a prover can't substitute its own root
+	forged := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"x"}, data[1], data[2]})
+	forgedProof, _ := forged.Proof(&TestLeaf{"x"})
+
+	if err := VerifyProof(&TestLeaf{"x"}, forgedProof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := VerifyProofAgainstRoot(&TestLeaf{"x"}, forgedProof, tree.Root())
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRootMismatch) {
+		t.Errorf("expected root mismatch, got %v", err)
+	}
+
+	if err := VerifyProofAgainstRoot(data[0], nil, tree.Root()); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestProof_VerifyFromHash(t *testing.T) {
+	var data []Leaf
+	for _, x := range []string{"a", "b", "c", "d", "e"} {
+		data = append(data, &TestLeaf{x})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+	for i := range data {
+		proof, _ := tree.ProofByIndex(i)
+		if err := VerifyProofFromHash(tree.LeafHash(i), proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyProofFromHashAgainstRoot(hashStrategy.HashLeaf(data[i].Bytes()), proof, tree.Root()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	proof, _ := tree.ProofByIndex(1)
+	if err := VerifyProofFromHash(tree.LeafHash(2), proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if err := VerifyProofFromHashAgainstRoot(tree.LeafHash(1), proof, tree.LeafHash(1)); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if err := VerifyProofFromHash(nil, proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if err := VerifyProofFromHash(tree.LeafHash(1), nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_Leaves(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	if tree.NumLeaves() != 3 {
+		t.Errorf("expected 3 leaves, got %d", tree.NumLeaves())
+	}
+
+	for i, x := range data {
+		if !bytes.Equal(tree.LeafHash(i), hashStrategy.HashLeaf(x.Bytes())) {
+			t.Errorf("hash of leaf %d not correct", i)
+		}
+	}
+
+	if tree.LeafHash(-1) != nil || tree.LeafHash(3) != nil {
+		t.Errorf("expected nil hash out of range")
+	}
+
+	n := 0
+	for i, h := range tree.Leaves() {
+		if i != n {
+			t.Errorf("expected index=%d, got %d", n, i)
+		}
+		if !bytes.Equal(h, hashStrategy.HashLeaf(data[i].Bytes())) {
+			t.Errorf("hash of leaf %d not correct", i)
+		}
+
+		// modifying the hash doesn't affect the tree
+		h[0] ^= 0xff
+		n++
+	}
+
+	if n != 3 {
+		t.Errorf("expected 3 leaves, got %d", n)
+	}
+
+	if !tree.Verify() {
+		t.Errorf("couldn't verify tree")
+	}
+
+	// stop early
+	for i := range tree.Leaves() {
+		if i > 0 {
+			t.Fatalf("expected iteration to stop")
+		}
+		break
+	}
+
+	var nilTree *MerkleTree
+	if nilTree.NumLeaves() != 0 {
+		t.Errorf("expected 0 leaves")
+	}
+
+	for range nilTree.Leaves() {
+		t.Errorf("expected no leaves")
+	}
+}
+
+func TestNode_Accessors(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	node, err := tree.VerifyExists(data[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(node.Hash(), hashStrategy.HashLeaf(data[0].Bytes())) {
+		t.Errorf("hash not correct")
+	}
+
+	if node.Left() != nil || node.Right() != nil {
+		t.Errorf("expected leaf without children")
+	}
+
+	parent := node.Parent()
+	if parent.Left() != node {
+		t.Errorf("expected node to be left child of parent")
+	}
+
+	if parent.Parent().Parent() != nil {
+		t.Errorf("expected root without parent")
+	}
+
+	if !bytes.Equal(parent.Parent().Hash(), tree.Root()) {
+		t.Errorf("root not correct")
+	}
+
+	var nilNode *Node
+	if nilNode.Hash() != nil || nilNode.Left() != nil || nilNode.Right() != nil || nilNode.Parent() != nil {
+		t.Errorf("expected nil results for nil node")
+	}
+}
+
+func TestTree_Walk(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	var visited []*Node
+	tree.Walk(func(n *Node) bool {
+		visited = append(visited, n)
+		return true
+	})
+
+	if len(visited) != tree.Len() {
+		t.Fatalf("expected %d nodes, got %d", tree.Len(), len(visited))
+	}
+
+	// depth-first, parents first
+	if visited[0] != tree.root || visited[2] != tree.leaves[0] || visited[4] != tree.leaves[2] {
+		t.Errorf("unexpected order")
+	}
+
+	// skip children
+	n := 0
+	tree.Walk(func(node *Node) bool {
+		n++
+		return node != tree.root
+	})
+
+	if n != 1 {
+		t.Errorf("expected 1 node, got %d", n)
+	}
+
+	// duplicated nodes are visited once
+	dup := mustBuildMerkleTree(t, data, WithDuplication())
+
+	n = 0
+	dup.Walk(func(*Node) bool {
+		n++
+		return true
+	})
+
+	if n != dup.Len() {
+		t.Errorf("expected %d nodes, got %d", dup.Len(), n)
+	}
+}
+
+func TestTree_WithProgress(t *testing.T) {
+	var data []Leaf
+	for i := range 3000 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	for _, opts := range [][]Option{nil, {WithDuplication()}} {
+		var calls, last, total int
+		opts = append(opts, WithProgress(func(done, n int) {
+			if done <= last {
+				t.Errorf("expected progress to increase, got %d after %d", done, last)
+			}
+			calls++
+			last, total = done, n
+		}))
+
+		tree := mustBuildMerkleTree(t, data, opts...)
+
+		if last != total || total != tree.Len() {
+			t.Errorf("expected to end at %d, got %d of %d", tree.Len(), last, total)
+		}
+
+		if calls < 2 || calls > total/progressInterval+1 {
+			t.Errorf("unexpected number of calls: %d", calls)
+		}
+	}
+
+	hashes := make([][]byte, len(data))
+	for i, x := range data {
+		hashes[i] = hashStrategy.HashLeaf(x.Bytes())
+	}
+
+	var last int
+	tree, err := BuildMerkleTreeFromHashes(hashes, WithProgress(func(done, total int) {
+		last = done
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if last != tree.Len() {
+		t.Errorf("expected to end at %d, got %d", tree.Len(), last)
+	}
+}
+
+func mustBuildMerkleTree(t testing.TB, data []Leaf, opts ...Option) *MerkleTree {
+	t.Helper()
+	tree, err := BuildMerkleTree(data, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tree
+}
+
+type nilLeaf struct{}
+
+func (nilLeaf) Bytes() []byte {
+	return nil
+}
+
+func TestTree_Build_Invalid(t *testing.T) {
+	for name, data := range map[string][]Leaf{
+		"empty":          {},
+		"nil leaf":       {&TestLeaf{"a"}, nil},
+		"nil leaf bytes": {&TestLeaf{"a"}, nilLeaf{}},
+	} {
+		if tree, err := BuildMerkleTree(data); err == nil || tree != nil {
+			t.Errorf("%s: expected error, got %v", name, err)
+		}
+		if _, err := BuildMerkleTreeCtx(context.Background(), data); err == nil {
+			t.Errorf("%s: expected error for BuildMerkleTreeCtx", name)
+		}
+		if _, err := BuildMerkleTreeWithHashStrategy(data, defaultHashStrategy{}); err == nil {
+			t.Errorf("%s: expected error for BuildMerkleTreeWithHashStrategy", name)
+		}
+		if _, err := BuildRFC6962MerkleTree(data); err == nil {
+			t.Errorf("%s: expected error for BuildRFC6962MerkleTree", name)
+		}
+		if _, err := BuildCompactMerkleTree(data); err == nil {
+			t.Errorf("%s: expected error for BuildCompactMerkleTree", name)
+		}
+
+		ch := make(chan Leaf, len(data))
+		for _, x := range data {
+			ch <- x
+		}
+		close(ch)
+		if _, err := BuildFromChannel(context.Background(), ch); err == nil {
+			t.Errorf("%s: expected error for BuildFromChannel", name)
+		}
+	}
+
+	// empty but non-nil bytes are fine
+	if _, err := BuildMerkleTree([]Leaf{&TestLeaf{""}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTree_InvalidLeaf(t *testing.T) {
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}})
+	root := tree.Root()
+
+	for name, x := range map[string]Leaf{"nil leaf": nil, "nil leaf bytes": nilLeaf{}} {
+		if err := tree.Append(x); err == nil {
+			t.Errorf("%s: expected error for Append", name)
+		}
+		if err := tree.Update(0, x); err == nil {
+			t.Errorf("%s: expected error for Update", name)
+		}
+		if _, err := tree.Proof(x); err == nil {
+			t.Errorf("%s: expected error for Proof", name)
+		}
+		if _, err := tree.ProofAt(x, 0); err == nil {
+			t.Errorf("%s: expected error for ProofAt", name)
+		}
+		if _, err := tree.Index(x); err == nil {
+			t.Errorf("%s: expected error for Index", name)
+		}
+	}
+
+	if tree.NumLeaves() != 2 || !bytes.Equal(tree.Root(), root) {
+		t.Errorf("tree changed by invalid leaves")
+	}
+}
+
+func BenchmarkBuildMerkleTree(b *testing.B) {
+	data := make([]Leaf, 1024)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+
+	b.ReportAllocs()
+	for range b.N {
+		BuildMerkleTree(data)
+	}
+}
+
+func TestTree_RootOwnership(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tree := mustBuildMerkleTree(t, data)
+
+	root := tree.Root()
+	root[0] ^= 0xff
+	if bytes.Equal(tree.Root(), root) {
+		t.Errorf("expected changing the returned root to leave the tree unchanged")
+	}
+	if !tree.Verify() {
+		t.Errorf("expected tree to stay valid")
+	}
+	if !bytes.Equal(tree.RootNoCopy(), tree.Root()) {
+		t.Errorf("expected RootNoCopy to return the root")
+	}
+
+	p, err := tree.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, sibling := range p.siblings {
+		for _, n := range []*Node{tree.leaves[1], tree.leaves[2], tree.leaves[0].parent} {
+			if &sibling[0] == &n.h[0] {
+				t.Errorf("expected proof not to share memory with the tree")
+			}
+		}
+	}
+	if &p.root[0] == &tree.root.h[0] {
+		t.Errorf("expected proof root not to share memory with the tree")
+	}
+
+	var nilTree *MerkleTree
+	if nilTree.Root() != nil || nilTree.RootNoCopy() != nil {
+		t.Errorf("expected nil root for nil tree")
+	}
+}
+
+func TestProof_MatchesIndex(t *testing.T) {
+	data := make([]Leaf, 7)
+	for i := range data {
+		data[i] = &TestLeaf{string(rune('a' + i))}
+	}
+	tree := mustBuildMerkleTree(t, data)
+
+	for i := range data {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for j := range data {
+			if got := proof.MatchesIndex(j, len(data)); got != (i == j) {
+				t.Errorf("proof of %d for index %d: expected %v, got %v", i, j, i == j, got)
+			}
+		}
+		if proof.MatchesIndex(i, math.MaxInt) {
+			t.Errorf("proof of %d: expected no match for tree size %d", i, math.MaxInt)
+		}
+	}
+	if (*Proof)(nil).MatchesIndex(0, 1) {
+		t.Errorf("expected no match for nil proof")
+	}
+}
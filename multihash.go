@@ -0,0 +1,95 @@
+package gomerkletree
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// Multihash codes of the hash functions of the hash strategies, from the multicodec table of multiformats.
+const (
+	multihashSHA2_256    = 0x12
+	multihashSHA3_256    = 0x16
+	multihashDblSHA2_256 = 0x56
+	multihashBlake3      = 0x1e
+	multihashSHA2_512256 = 0x1014
+	multihashBlake2b256  = 0xb220
+)
+
+// Codecs of the content a CID refers to, from the multicodec table of multiformats.
+const (
+	CodecRaw     = 0x55
+	CodecDagPB   = 0x70
+	CodecDagCBOR = 0x71
+	CodecDagJSON = 0x0129
+)
+
+// MultihashCoder is implemented by hash strategies that know the multihash code of their hash function, so roots of
+// trees built with them can be encoded as multihashes. The ready-made strategies don't need it.
+type MultihashCoder interface {
+	MultihashCode() uint64
+}
+
+// multihashCode returns the multihash code of the hash function of a hash strategy.
+func multihashCode(hash HashStrategy) (uint64, error) {
+	switch h := hash.(type) {
+	case MultihashCoder:
+		return h.MultihashCode(), nil
+	case defaultHashStrategy, RFC6962HashStrategy:
+		return multihashSHA2_256, nil
+	case BitcoinHashStrategy:
+		return multihashDblSHA2_256, nil
+	case hashing.SHA3Strategy:
+		return multihashSHA3_256, nil
+	case hashing.SHA512_256Strategy:
+		return multihashSHA2_512256, nil
+	case hashing.Blake2bStrategy:
+		return multihashBlake2b256, nil
+	case hashing.Blake3Strategy:
+		return multihashBlake3, nil
+	}
+	return 0, errors.New("no multihash code for hash strategy")
+}
+
+// RootMultihash returns the root as a multihash: varint(code of the hash function) | varint(length) | root.
+// The hash strategy has to be one of the ready-made ones or implement MultihashCoder.
+func (m *MerkleTree) RootMultihash() ([]byte, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	code, err := multihashCode(m.hashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return appendMultihash(nil, code, m.root.h), nil
+}
+
+// CID is a binary content identifier of IPFS and IPLD.
+type CID []byte
+
+// String returns the CID in its canonical text form for version 1: base32 in lower case without padding,
+// prefixed with the multibase code 'b'.
+func (c CID) String() string {
+	return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(c))
+}
+
+// RootCID returns the root as a version 1 CID of content with the given codec (e.g. CodecRaw or CodecDagCBOR):
+// varint(1) | varint(codec) | multihash of the root, like RootMultihash.
+func (m *MerkleTree) RootCID(codec uint64) (CID, error) {
+	mh, err := m.RootMultihash()
+	if err != nil {
+		return nil, err
+	}
+	b := binary.AppendUvarint(nil, 1)
+	b = binary.AppendUvarint(b, codec)
+	return append(b, mh...), nil
+}
+
+func appendMultihash(b []byte, code uint64, digest []byte) []byte {
+	b = binary.AppendUvarint(b, code)
+	b = binary.AppendUvarint(b, uint64(len(digest)))
+	return append(b, digest...)
+}
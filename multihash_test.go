@@ -0,0 +1,79 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+type multihashStrategy struct {
+	defaultHashStrategy
+}
+
+func (multihashStrategy) MultihashCode() uint64 {
+	return 0x1b
+}
+
+func TestTree_RootMultihash(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tests := []struct {
+		name   string
+		hash   HashStrategy
+		prefix []byte
+	}{
+		{"Default", hashStrategy, []byte{0x12, 0x20}},
+		{"SHA3", hashing.SHA3Strategy{}, []byte{0x16, 0x20}},
+		{"SHA512_256", hashing.SHA512_256Strategy{}, []byte{0x94, 0x20, 0x20}},
+		{"Blake2b", hashing.Blake2bStrategy{}, []byte{0xa0, 0xe4, 0x02, 0x20}},
+		{"Blake3", hashing.Blake3Strategy{}, []byte{0x1e, 0x20}},
+		{"Coder", multihashStrategy{}, []byte{0x1b, 0x20}},
+	}
+
+	for _, tt := range tests {
+		tree := mustBuildMerkleTree(t, data, WithHashStrategy(tt.hash))
+		mh, err := tree.RootMultihash()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := append(tt.prefix, tree.Root()...); !bytes.Equal(mh, want) {
+			t.Errorf("%s: expected %x, got %x", tt.name, want, mh)
+		}
+	}
+
+	tree := mustBuildMerkleTree(t, data, WithHashStrategy(hashing.NewStrategy(sha256.New)))
+	if _, err := tree.RootMultihash(); err == nil {
+		t.Errorf("expected error for hash strategy without multihash code")
+	}
+	var nilTree *MerkleTree
+	if _, err := nilTree.RootCID(CodecRaw); err == nil {
+		t.Errorf("expected error for nil tree")
+	}
+}
+
+func TestTree_RootCID(t *testing.T) {
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}})
+	cid, err := tree.RootCID(CodecDagCBOR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := append([]byte{0x01, 0x71, 0x12, 0x20}, tree.Root()...)
+	if !bytes.Equal(cid, want) {
+		t.Errorf("expected %x, got %x", want, cid)
+	}
+	if s := cid.String(); !strings.HasPrefix(s, "bafyrei") {
+		t.Errorf("expected dag-cbor sha2-256 prefix, got %s", s)
+	}
+}
+
+func TestCID_String(t *testing.T) {
+	// the CID of the raw bytes "hello world", as computed by IPFS
+	sum := sha256.Sum256([]byte("hello world"))
+	cid := CID(appendMultihash(binary.AppendUvarint([]byte{1}, CodecRaw), multihashSHA2_256, sum[:]))
+	if want := "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"; cid.String() != want {
+		t.Errorf("expected %s, got %s", want, cid)
+	}
+}
@@ -0,0 +1,223 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+// MultiProof proves the inclusion of several leaves at once.
+// Siblings shared between the individual paths, or computable from the proven leaves themselves,
+// are only included once (or not at all).
+type MultiProof struct {
+	root         []byte
+	size         int
+	indices      []int
+	hashes       [][]byte
+	hashStrategy HashStrategy
+}
+
+// NewMultiProof assembles a multiproof from its contents, e.g. after receiving them over the network.
+// A nil hash strategy means the default hash strategy.
+func NewMultiProof(root []byte, size int, indices []int, hashes [][]byte, hash HashStrategy) *MultiProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	p := &MultiProof{
+		root:         bytes.Clone(root),
+		size:         size,
+		indices:      append([]int(nil), indices...),
+		hashes:       make([][]byte, len(hashes)),
+		hashStrategy: hash,
+	}
+	for i, h := range hashes {
+		p.hashes[i] = bytes.Clone(h)
+	}
+	return p
+}
+
+// Root returns a copy of the root the proof was generated for.
+func (p *MultiProof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Size returns the number of leaves of the tree the proof was generated for.
+func (p *MultiProof) Size() int {
+	if p == nil {
+		return 0
+	}
+	return p.size
+}
+
+// Indices returns a copy of the positions of the proven leaves, in the order they were given.
+func (p *MultiProof) Indices() []int {
+	if p == nil {
+		return nil
+	}
+	return append([]int(nil), p.indices...)
+}
+
+// Hashes returns a copy of the hashes needed to recompute the root, in depth-first order.
+func (p *MultiProof) Hashes() [][]byte {
+	if p == nil {
+		return nil
+	}
+	hashes := make([][]byte, len(p.hashes))
+	for i, h := range p.hashes {
+		hashes[i] = bytes.Clone(h)
+	}
+	return hashes
+}
+
+// MultiProof generates a single proof for a batch of leaves.
+// Returns `MultiProof` object that contains the root, the number of leaves in the tree,
+// the position of every given leaf, and the hashes needed to recompute the root (in depth-first order).
+func (m *MerkleTree) MultiProof(leaves []Leaf) (*MultiProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if m.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if len(leaves) == 0 {
+		return nil, errors.New("no leaves")
+	}
+
+	indices := make([]int, len(leaves))
+	for i, x := range leaves {
+		hash, err := m.hashLeaf(x)
+		if err != nil {
+			return nil, err
+		}
+		index := m.leafIndex(hash)
+		if index < 0 {
+			return nil, errors.New("not in tree")
+		}
+		indices[i] = index
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	sorted := sortedIndices(indices)
+	var hashes [][]byte
+	var collect func(node *Node, lo, hi int)
+	collect = func(node *Node, lo, hi int) {
+		if !containsIndex(sorted, lo, hi) {
+			hashes = append(hashes, node.h)
+			return
+		}
+		if hi-lo == 1 {
+			return
+		}
+		k := split(hi - lo)
+		collect(node.left, lo, lo+k)
+		collect(node.right, lo+k, hi)
+	}
+	collect(m.root, 0, len(m.leaves))
+
+	return &MultiProof{
+		root:         m.Root(),
+		size:         len(m.leaves),
+		indices:      indices,
+		hashes:       cloneHashes(hashes),
+		hashStrategy: m.hashStrategy,
+	}, nil
+}
+
+// VerifyMultiProof checks if a multiproof is valid for the given leaves.
+// The leaves must be given in the same order as they were when generating the proof.
+func VerifyMultiProof(leaves []Leaf, p *MultiProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyMultiProof(leaves, p, p.root)
+}
+
+// VerifyMultiProofAgainstRoot checks if a multiproof is valid for the given leaves under a root the verifier already
+// trusts, like a client that fetches the leaves from an untrusted peer. The root stored in the proof is ignored.
+func VerifyMultiProofAgainstRoot(leaves []Leaf, p *MultiProof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyMultiProof(leaves, p, root)
+}
+
+func verifyMultiProof(leaves []Leaf, p *MultiProof, root []byte) error {
+	if len(leaves) != len(p.indices) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	known := make(map[int][]byte, len(leaves))
+	for i, x := range leaves {
+		index := p.indices[i]
+		if index < 0 || index >= p.size {
+			return errors.New("index out of range")
+		}
+		b, err := leafBytes(x)
+		if err != nil {
+			return err
+		}
+		hash := p.hashStrategy.HashLeaf(b)
+		if prev, ok := known[index]; ok && !hashEqual(prev, hash) {
+			return errors.New("conflicting leaves for index")
+		}
+		known[index] = hash
+	}
+
+	sorted := sortedIndices(p.indices)
+	hashes := p.hashes
+	var compute func(lo, hi int) ([]byte, error)
+	compute = func(lo, hi int) ([]byte, error) {
+		if !containsIndex(sorted, lo, hi) {
+			if len(hashes) == 0 {
+				return nil, errors.New("not enough hashes")
+			}
+			hash := hashes[0]
+			hashes = hashes[1:]
+			return hash, nil
+		}
+		if hi-lo == 1 {
+			return known[lo], nil
+		}
+		k := split(hi - lo)
+		left, err := compute(lo, lo+k)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compute(lo+k, hi)
+		if err != nil {
+			return nil, err
+		}
+		return p.hashStrategy.HashInternal(left, right), nil
+	}
+
+	hash, err := compute(0, p.size)
+	if err != nil {
+		return err
+	}
+	if len(hashes) != 0 {
+		return errors.New("too many hashes")
+	}
+
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root)
+	}
+	return nil
+}
+
+func sortedIndices(indices []int) []int {
+	sorted := append([]int(nil), indices...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// containsIndex reports whether any of the sorted indices lies in [lo, hi).
+func containsIndex(sorted []int, lo, hi int) bool {
+	i := sort.SearchInts(sorted, lo)
+	return i < len(sorted) && sorted[i] < hi
+}
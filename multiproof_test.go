@@ -0,0 +1,163 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTree_MultiProof(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+	data = append(data, &TestLeaf{"d"})
+	data = append(data, &TestLeaf{"e"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	// not in tree
+	if _, err := tree.MultiProof([]Leaf{data[0], &TestLeaf{"f"}}); err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	// no leaves
+	if _, err := tree.MultiProof(nil); err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	leafA := hashStrategy.HashLeaf(data[0].Bytes())
+	leafB := hashStrategy.HashLeaf(data[1].Bytes())
+	leafE := hashStrategy.HashLeaf(data[4].Bytes())
+	leftLeft := hashStrategy.HashInternal(leafA, leafB)
+
+	// c and d share their parent, so only the siblings of that parent are needed
+	proof, err := tree.MultiProof([]Leaf{data[3], data[2]})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(tree.Root(), proof.root) {
+		t.Errorf("root not correct")
+	}
+
+	if proof.size != 5 {
+		t.Errorf("expected size=5, got %d", proof.size)
+	}
+
+	if len(proof.hashes) != 2 {
+		t.Fatalf("expected 2 hashes, got %d", len(proof.hashes))
+	}
+
+	if !bytes.Equal(leftLeft, proof.hashes[0]) {
+		t.Errorf("first hash not correct")
+	}
+
+	if !bytes.Equal(leafE, proof.hashes[1]) {
+		t.Errorf("second hash not correct")
+	}
+
+	// all leaves need no hashes at all
+	proof, err = tree.MultiProof(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(proof.hashes) != 0 {
+		t.Errorf("expected 0 hashes, got %d", len(proof.hashes))
+	}
+}
+
+func TestMultiProof_Verify(t *testing.T) {
+	var data []Leaf
+	for i := range 13 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	batches := [][]Leaf{
+		{data[0]},
+		{data[12]},
+		{data[1], data[5], data[12]},
+		{data[7], data[6], data[6]},
+		data,
+	}
+
+	for _, batch := range batches {
+		proof, err := tree.MultiProof(batch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := VerifyMultiProof(batch, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyMultiProofAgainstRoot(batch, proof, tree.Root()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		// the root stored in the proof is ignored
+		assembled := NewMultiProof(nil, proof.Size(), proof.Indices(), proof.Hashes(), nil)
+		if err := VerifyMultiProofAgainstRoot(batch, assembled, tree.Root()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyMultiProofAgainstRoot(batch, proof, tree.Root()[1:]); !errors.Is(err, ErrRootMismatch) {
+			t.Errorf("expected root mismatch, got %v", err)
+		}
+	}
+
+	proof, _ := tree.MultiProof([]Leaf{data[1], data[5]})
+
+	// wrong leaves for proof
+	err := VerifyMultiProof([]Leaf{data[1], data[6]}, proof)
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRootMismatch) {
+		t.Errorf("expected root mismatch, got %v", err)
+	}
+
+	// wrong order
+	if err := VerifyMultiProof([]Leaf{data[5], data[1]}, proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// mismatch lengths
+	err = VerifyMultiProof([]Leaf{data[1]}, proof)
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	if err.Error() != "proof lengths mismatch" {
+		t.Errorf("expected lengths mismatch, got %s", err.Error())
+	}
+
+	// missing hashes
+	proof.hashes = proof.hashes[1:]
+	if err := VerifyMultiProof([]Leaf{data[1], data[5]}, proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// missing hashing strategy
+	proof.hashStrategy = nil
+	err = VerifyMultiProof([]Leaf{data[1], data[5]}, proof)
+	if err == nil {
+		t.Fatalf("expected err, got nil")
+	}
+
+	if err.Error() != "no proof/hash strategy" {
+		t.Errorf("expected missing proof/strategy, got %s", err.Error())
+	}
+}
+
+func TestMultiProof_VerifyMaxSize(t *testing.T) {
+	h := hashStrategy.HashLeaf([]byte("y"))
+	p := NewMultiProof(h, math.MaxInt, []int{0}, [][]byte{h}, nil)
+	if err := VerifyMultiProof([]Leaf{BytesLeaf("x")}, p); err == nil {
+		t.Errorf("expected error for proof of size %d", math.MaxInt)
+	}
+}
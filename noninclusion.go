@@ -0,0 +1,132 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+)
+
+// NonInclusionProof proves that a leaf is absent from a tree whose leaves are sorted by hash,
+// by proving the inclusion of the two adjacent leaves whose hashes bracket the hash of the absent leaf.
+// At the edges of the tree only one of the two leaves exists.
+type NonInclusionProof struct {
+	root         []byte
+	size         int
+	index        int // index of the first leaf after the absent one, the leaf before it is at index-1
+	leftHash     []byte
+	left         *Proof
+	rightHash    []byte
+	right        *Proof
+	hashStrategy HashStrategy
+}
+
+// NonInclusionProof generates a proof that a leaf is not in the tree.
+// The tree has to be built with WithSortedLeaves.
+func (m *MerkleTree) NonInclusionProof(x Leaf) (*NonInclusionProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if !m.sorted {
+		return nil, errors.New("tree is not sorted")
+	}
+	if m.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+
+	hash, err := m.hashLeaf(x)
+	if err != nil {
+		return nil, err
+	}
+	index := sort.Search(len(m.leaves), func(i int) bool {
+		return bytes.Compare(m.leaves[i].h, hash) >= 0
+	})
+	if index < len(m.leaves) && hashEqual(m.leaves[index].h, hash) {
+		return nil, errors.New("leaf is in tree")
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	p := &NonInclusionProof{
+		root:         m.Root(),
+		size:         len(m.leaves),
+		index:        index,
+		hashStrategy: m.hashStrategy,
+	}
+	if index > 0 {
+		p.leftHash = bytes.Clone(m.leaves[index-1].h)
+		p.left = m.proof(m.leaves[index-1])
+	}
+	if index < len(m.leaves) {
+		p.rightHash = bytes.Clone(m.leaves[index].h)
+		p.right = m.proof(m.leaves[index])
+	}
+	return p, nil
+}
+
+// VerifyNonInclusion checks if a non-inclusion proof is valid for a given leaf.
+// This relies on the tree having been built with sorted leaves, which the proof itself cannot show.
+func VerifyNonInclusion(x Leaf, p *NonInclusionProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	if p.size <= 0 || p.index < 0 || p.index > p.size {
+		return errors.New("index out of range")
+	}
+	if (p.index > 0) != (p.left != nil) || (p.index < p.size) != (p.right != nil) {
+		return errors.New("missing adjacent leaf")
+	}
+
+	b, err := leafBytes(x)
+	if err != nil {
+		return err
+	}
+	hash := p.hashStrategy.HashLeaf(b)
+	if p.left != nil {
+		if bytes.Compare(p.leftHash, hash) >= 0 {
+			return errors.New("leaf not bracketed")
+		}
+		if err := verifyAdjacent(p.leftHash, p.left, p.index-1, p); err != nil {
+			return err
+		}
+	}
+	if p.right != nil {
+		if bytes.Compare(hash, p.rightHash) >= 0 {
+			return errors.New("leaf not bracketed")
+		}
+		if err := verifyAdjacent(p.rightHash, p.right, p.index, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyAdjacent verifies the inclusion proof of one of the bracketing leaves,
+// and that its path is the path of the leaf at the given index.
+func verifyAdjacent(hash []byte, proof *Proof, index int, p *NonInclusionProof) error {
+	if !slices.Equal(proof.left, pathDirections(index, p.size)) {
+		return errors.New("leaves not adjacent")
+	}
+	return verifyProof(hash, proof, p.hashStrategy, p.root)
+}
+
+// pathDirections returns the directions of the siblings in the proof of the leaf at the given index,
+// in a tree with size leaves built with promotion.
+func pathDirections(index, size int) []bool {
+	var left []bool
+	lo, hi := 0, size
+	for hi-lo > 1 {
+		k := split(hi - lo)
+		if index < lo+k {
+			left = append(left, false)
+			hi = lo + k
+		} else {
+			left = append(left, true)
+			lo += k
+		}
+	}
+	slices.Reverse(left)
+	return left
+}
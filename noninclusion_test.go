@@ -0,0 +1,146 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestTree_Build_Sorted(t *testing.T) {
+	var data []Leaf
+	for i := range 9 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data, WithSortedLeaves())
+
+	for i := 1; i < len(tree.leaves); i++ {
+		if bytes.Compare(tree.leaves[i-1].h, tree.leaves[i].h) > 0 {
+			t.Fatalf("leaves not sorted")
+		}
+	}
+
+	// input order doesn't matter
+	reversed := slices.Clone(data)
+	slices.Reverse(reversed)
+
+	if !bytes.Equal(tree.Root(), mustBuildMerkleTree(t, reversed, WithSortedLeaves()).Root()) {
+		t.Errorf("expected same root for any input order")
+	}
+
+	// out of order append and update
+	last := tree.leaves[len(tree.leaves)-1].h
+	var smaller Leaf
+	for _, x := range data {
+		if bytes.Compare(hashStrategy.HashLeaf(x.Bytes()), last) < 0 {
+			smaller = x
+			break
+		}
+	}
+
+	if err := tree.Append(smaller); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if err := tree.Update(len(tree.leaves)-1, smaller); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_NonInclusionProof(t *testing.T) {
+	var data []Leaf
+	for i := range 9 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data, WithSortedLeaves())
+
+	// in tree
+	if _, err := tree.NonInclusionProof(data[3]); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// not sorted
+	if _, err := mustBuildMerkleTree(t, data).NonInclusionProof(&TestLeaf{"z"}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// absent leaves land everywhere, including both edges
+	edges := map[int]bool{}
+	for i := range 64 {
+		x := &TestLeaf{string(rune('A' + i))}
+		if _, err := tree.Proof(x); err == nil {
+			continue
+		}
+
+		proof, err := tree.NonInclusionProof(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		edges[proof.index] = true
+
+		if err := VerifyNonInclusion(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if !edges[0] || !edges[len(data)] {
+		t.Errorf("expected proofs at both edges")
+	}
+}
+
+func TestNonInclusionProof_Verify(t *testing.T) {
+	var data []Leaf
+	for i := range 9 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data, WithSortedLeaves())
+
+	var absent Leaf
+	var proof *NonInclusionProof
+	for i := 0; proof == nil || proof.index == 0 || proof.index == len(data); i++ {
+		absent = &TestLeaf{string(rune('A' + i))}
+		proof, _ = tree.NonInclusionProof(absent)
+	}
+
+	// a leaf that is in the tree
+	if err := VerifyNonInclusion(data[0], proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// leaves that are not adjacent
+	other, _ := tree.ProofByIndex(proof.index + 1)
+	if proof.index+1 < len(data) {
+		tampered := *proof
+		tampered.right = other
+		tampered.rightHash = tree.leaves[proof.index+1].h
+
+		err := VerifyNonInclusion(absent, &tampered)
+		if err == nil {
+			t.Fatalf("expected err, got nil")
+		}
+
+		if err.Error() != "leaves not adjacent" {
+			t.Errorf("expected leaves not adjacent, got %s", err.Error())
+		}
+	}
+
+	// missing adjacent leaf
+	tampered := *proof
+	tampered.left = nil
+	if err := VerifyNonInclusion(absent, &tampered); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// wrong root
+	tampered = *proof
+	tampered.root = []byte("a")
+	if err := VerifyNonInclusion(absent, &tampered); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if err := VerifyNonInclusion(absent, nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
@@ -0,0 +1,128 @@
+package gomerkletree
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"slices"
+
+	"golang.org/x/mod/sumdb/note"
+)
+
+// VerifierKey returns the verifier key of the witness in the format of golang.org/x/mod/sumdb/note,
+// name+hash+base64(0x01 | key), as used to configure witnesses and clients of the Go checksum database.
+func (w Witness) VerifierKey() (string, error) {
+	return note.NewEd25519VerifierKey(w.Name, w.Key)
+}
+
+// Verifier returns a verifier of the signatures of the witness, for note.Open.
+func (w Witness) Verifier() (note.Verifier, error) {
+	vkey, err := w.VerifierKey()
+	if err != nil {
+		return nil, err
+	}
+	return note.NewVerifier(vkey)
+}
+
+// Verifiers returns verifiers of the log and all witnesses of the policy, for note.Open.
+func (p WitnessPolicy) Verifiers() (note.Verifiers, error) {
+	verifiers := make([]note.Verifier, 0, len(p.Witnesses)+1)
+	for _, w := range append([]Witness{p.Log}, p.Witnesses...) {
+		v, err := w.Verifier()
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, v)
+	}
+	return note.VerifierList(verifiers...), nil
+}
+
+// NewNoteSigner returns a signer for note.Sign with an Ed25519 key under the given name.
+func NewNoteSigner(name string, key ed25519.PrivateKey) (note.Signer, error) {
+	if !validNoteName(name) {
+		return nil, errors.New("invalid name")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key")
+	}
+	return noteSigner{
+		name: name,
+		hash: NoteKeyHash(name, key.Public().(ed25519.PublicKey)),
+		key:  key,
+	}, nil
+}
+
+type noteSigner struct {
+	name string
+	hash uint32
+	key  ed25519.PrivateKey
+}
+
+func (s noteSigner) Name() string {
+	return s.name
+}
+
+func (s noteSigner) KeyHash() uint32 {
+	return s.hash
+}
+
+func (s noteSigner) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, msg), nil
+}
+
+// Note returns the signed checkpoint as a note, with all signatures unverified.
+func (s *SignedCheckpoint) Note() *note.Note {
+	if s == nil {
+		return nil
+	}
+	n := &note.Note{
+		Text: string(s.Bytes()),
+	}
+	for _, sig := range s.Signatures {
+		n.UnverifiedSigs = append(n.UnverifiedSigs, note.Signature{
+			Name:   sig.Name,
+			Hash:   sig.KeyHash,
+			Base64: base64.StdEncoding.EncodeToString(append(binary.BigEndian.AppendUint32(nil, sig.KeyHash), sig.Signature...)),
+		})
+	}
+	return n
+}
+
+// CheckpointFromNote parses the text of a note as a checkpoint, with both the verified and the unverified signatures
+// of the note. Use a WitnessPolicy to verify them.
+func CheckpointFromNote(n *note.Note) (*SignedCheckpoint, error) {
+	if n == nil {
+		return nil, errors.New("nil note")
+	}
+	c, err := ParseCheckpoint([]byte(n.Text))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SignedCheckpoint{
+		Checkpoint: c,
+	}
+	for _, sig := range slices.Concat(n.Sigs, n.UnverifiedSigs) {
+		b, err := base64.StdEncoding.DecodeString(sig.Base64)
+		if err != nil || len(b) < 4 {
+			return nil, errors.New("malformed note")
+		}
+		s.Signatures = append(s.Signatures, NoteSignature{
+			Name:      sig.Name,
+			KeyHash:   binary.BigEndian.Uint32(b),
+			Signature: b[4:],
+		})
+	}
+	return s, nil
+}
+
+// OpenCheckpoint opens a note-signed checkpoint with note.Open, which fails unless at least one of the known verifiers
+// signed it, and parses it as a checkpoint.
+func OpenCheckpoint(msg []byte, known note.Verifiers) (*SignedCheckpoint, error) {
+	n, err := note.Open(msg, known)
+	if err != nil {
+		return nil, err
+	}
+	return CheckpointFromNote(n)
+}
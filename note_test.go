@@ -0,0 +1,121 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestNote_Interop(t *testing.T) {
+	logPub, logKey, _ := ed25519.GenerateKey(nil)
+	witnessPub, witnessKey, _ := ed25519.GenerateKey(nil)
+	policy := WitnessPolicy{
+		Log:       Witness{Name: "example.com/log", Key: logPub},
+		Witnesses: []Witness{{Name: "witness", Key: witnessPub}},
+		Threshold: 1,
+	}
+
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}})
+	c := TreeHead{Size: 3, Root: tree.Root()}.Checkpoint("example.com/log")
+
+	signed, err := SignCheckpoint(c, "example.com/log", logKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, _ := signed.MarshalText()
+
+	// signing with note.Sign gives the same note
+	signer, err := NewNoteSigner("example.com/log", logKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, err := note.Sign(&note.Note{Text: string(c.Bytes())}, signer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(text, expected) {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+
+	// a witness cosigns with note.Sign, and the log opens it with note.Open
+	verifiers, err := policy.Verifiers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n, err := note.Open(text, verifiers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	witnessSigner, _ := NewNoteSigner("witness", witnessKey)
+	cosigned, err := note.Sign(n, witnessSigner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	opened, err := OpenCheckpoint(cosigned, verifiers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opened.Size != 3 || !bytes.Equal(opened.Root, tree.Root()) || len(opened.Signatures) != 2 {
+		t.Errorf("unexpected checkpoint %+v", opened)
+	}
+	if err := policy.Verify(opened); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// and the other way around
+	var decoded SignedCheckpoint
+	if err := decoded.UnmarshalText(cosigned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := policy.Verify(&decoded); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n := decoded.Note(); n.Text != string(c.Bytes()) || len(n.UnverifiedSigs) != 2 {
+		t.Errorf("unexpected note %+v", n)
+	}
+
+	if _, err := OpenCheckpoint(cosigned, note.VerifierList()); err == nil {
+		t.Errorf("expected error without known verifiers")
+	}
+}
+
+func TestWitness_VerifierKey(t *testing.T) {
+	// keys of the tests of golang.org/x/mod/sumdb/note
+	seed, _ := base64.StdEncoding.DecodeString("AYEKFALVFGyNhPJEMzD1QIDr+Y7hfZx09iUvxdXHKDFz")
+	key := ed25519.NewKeyFromSeed(seed[1:])
+
+	w := Witness{Name: "PeterNeumann", Key: key.Public().(ed25519.PublicKey)}
+	vkey, err := w.VerifierKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "PeterNeumann+c74f20a3+ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq/LDE4izWy10TW"; vkey != expected {
+		t.Errorf("expected %s, got %s", expected, vkey)
+	}
+
+	signer, err := note.NewSigner("PRIVATE+KEY+PeterNeumann+c74f20a3+AYEKFALVFGyNhPJEMzD1QIDr+Y7hfZx09iUvxdXHKDFz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := Checkpoint{Origin: "example.com/log", Size: 1, Root: bytes.Repeat([]byte{1}, 32)}
+	msg, err := note.Sign(&note.Note{Text: string(c.Bytes())}, signer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded SignedCheckpoint
+	if err := decoded.UnmarshalText(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (WitnessPolicy{Log: w}).Verify(&decoded); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := NewNoteSigner("Peter Neumann", key); err == nil {
+		t.Errorf("expected error for invalid name")
+	}
+}
@@ -0,0 +1,144 @@
+package gomerkletree
+
+// Option configures how a merkle tree is built.
+type Option func(*config)
+
+type config struct {
+	hashStrategy HashStrategy
+	duplicate    bool
+	sortPairs    bool
+	sorted       bool
+	dedup        bool
+	salt         []byte
+	progress     func(done, total int)
+	history      *RootHistory
+	trusted      bool
+	constantTime bool
+	workers      int
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		hashStrategy: defaultHashStrategy{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.salt != nil {
+		cfg.hashStrategy = SaltedHashStrategy{cfg.hashStrategy, cfg.salt}
+	}
+	if _, ok := cfg.hashStrategy.(SortedPairHashStrategy); cfg.sortPairs && !ok {
+		cfg.hashStrategy = SortedPairHashStrategy{cfg.hashStrategy}
+	}
+	return cfg
+}
+
+// WithHashStrategy builds the tree using a custom hash strategy instead of the default SHA-256 based one.
+func WithHashStrategy(hash HashStrategy) Option {
+	return func(c *config) {
+		c.hashStrategy = hash
+	}
+}
+
+// WithDuplication pads levels with an odd number of nodes by pairing the last node with itself (Bitcoin-style),
+// instead of promoting it. This matches the many implementations that use duplication.
+// Trees built this way don't support multiproofs and consistency proofs, which rely on the shape of promotion.
+func WithDuplication() Option {
+	return func(c *config) {
+		c.duplicate = true
+	}
+}
+
+// WithSortedLeaves sorts the leaves by their hash before building the tree, which allows proving that a leaf is absent
+// (see NonInclusionProof). Appends and updates that would break the order are rejected.
+func WithSortedLeaves() Option {
+	return func(c *config) {
+		c.sorted = true
+	}
+}
+
+// WithDedup drops leaves whose hash is already in the tree, keeping the first occurrence, so the tree commits
+// to a set. Combined with WithSortedLeaves, two parties given the same set in any order and with any repetitions
+// build the same tree. Appends and updates that would add a duplicate are rejected.
+func WithDedup() Option {
+	return func(c *config) {
+		c.dedup = true
+	}
+}
+
+// WithTrusted skips verifying the integrity of the whole tree before every proof, so proofs are generated in O(log n).
+// Use it when the tree's nodes can't be tampered with after building, e.g. a tree that is only kept in memory.
+func WithTrusted() Option {
+	return func(c *config) {
+		c.trusted = true
+	}
+}
+
+// WithConstantTime looks up leaves by comparing their hash with the hash of every leaf in constant time, instead of
+// with the index of the tree, so the time Proof, VerifyExists and Index take doesn't depend on the leaf or where it
+// is. Use it when leaves are derived from secrets. Lookups take O(n); proofs by index are unaffected.
+// Verifying proofs and trees always compares hashes in constant time.
+func WithConstantTime() Option {
+	return func(c *config) {
+		c.constantTime = true
+	}
+}
+
+// WithWorkers hashes the leaves, and the internal nodes of large levels, on up to n goroutines, allocating the nodes
+// of every goroutine in one batch. The hash strategy must be safe for concurrent use; the default strategy reuses
+// pooled digests, and hashing.NewPooledStrategy does the same for any hash.Hash. Progress is reported per batch,
+// possibly from the goroutines, but never concurrently. With n <= 1 the tree is built on the calling goroutine.
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
+
+// WithProgress calls fn periodically while building the tree, with the number of nodes hashed so far
+// and the total number of nodes to hash (leaves and internal nodes). The last call has done == total.
+func WithProgress(fn func(done, total int)) Option {
+	return func(c *config) {
+		c.progress = fn
+	}
+}
+
+// Number of nodes between progress reports.
+const progressInterval = 1024
+
+// progress reports the progress of a build. A nil progress reports nothing.
+type progress struct {
+	fn          func(done, total int)
+	done, total int
+}
+
+func newProgress(cfg config, leaves int) *progress {
+	if cfg.progress == nil || leaves == 0 {
+		return nil
+	}
+	return &progress{
+		fn:    cfg.progress,
+		total: nodeCount(leaves, cfg.duplicate),
+	}
+}
+
+// setTotal changes the total number of nodes, when leaves turn out to be dropped.
+func (p *progress) setTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.total = total
+	if p.done == p.total {
+		p.fn(p.done, p.total)
+	}
+}
+
+func (p *progress) add(n int) {
+	if p == nil {
+		return
+	}
+	before := p.done
+	p.done += n
+	if p.done/progressInterval != before/progressInterval || p.done == p.total {
+		p.fn(p.done, p.total)
+	}
+}
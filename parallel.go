@@ -0,0 +1,84 @@
+package gomerkletree
+
+import (
+	"context"
+	"sync"
+)
+
+// Minimum number of hashes per goroutine of a parallel build, so the goroutines cost less than they save.
+const minParallelChunk = 512
+
+// parallel splits [0, n) into chunks of at least minParallelChunk, calls fn for every chunk on up to workers
+// goroutines, and waits for them. It returns the error of the first chunk that failed.
+func parallel(n, workers int, fn func(lo, hi int) error) error {
+	chunks := min(workers, max(n/minParallelChunk, 1))
+	errs := make([]error, chunks)
+	var wg sync.WaitGroup
+	for c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[c] = fn(c*n/chunks, (c+1)*n/chunks)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashLeavesParallel hashes the leaves like hashLeaves, on up to workers goroutines.
+func hashLeavesParallel(ctx context.Context, data []Leaf, hash HashStrategy, workers int, p *progress) ([][]byte, error) {
+	hashes := make([][]byte, len(data))
+	var mu sync.Mutex // guards p
+	err := parallel(len(data), workers, func(lo, hi int) error {
+		if err := hashLeafRange(ctx, data[lo:hi], hashes[lo:hi], hash, nil); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		p.add(hi - lo)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// leafNodes returns the leaves for the hashes, allocated in one batch.
+func leafNodes(hashes [][]byte) []*Node {
+	nodes := make([]Node, len(hashes))
+	level := make([]*Node, len(hashes))
+	for i, h := range hashes {
+		nodes[i].h = h
+		level[i] = &nodes[i]
+	}
+	return level
+}
+
+// parentsParallel returns the parents of the pairs of nodes of a level on up to workers goroutines, which each
+// allocate their parents in one batch. The last node of an odd level is left to the caller.
+func parentsParallel(level []*Node, hash HashStrategy, workers int) []*Node {
+	next := make([]*Node, len(level)/2, (len(level)+1)/2)
+	parallel(len(next), workers, func(lo, hi int) error {
+		nodes := make([]Node, hi-lo)
+		for i := lo; i < hi; i++ {
+			left, right := level[2*i], level[2*i+1]
+			parent := &nodes[i-lo]
+			*parent = Node{
+				h:     hash.HashInternal(left.h, right.h),
+				left:  left,
+				right: right,
+			}
+			left.parent = parent
+			right.parent = parent
+			next[i] = parent
+		}
+		return nil
+	})
+	return next
+}
@@ -0,0 +1,105 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func TestWithWorkers_SameTree(t *testing.T) {
+	options := []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"Duplication", []Option{WithDuplication()}},
+		{"SortedDedup", []Option{WithSortedLeaves(), WithDedup()}},
+		{"Pooled", []Option{WithHashStrategy(hashing.NewPooledStrategy(sha256.New))}},
+	}
+
+	for _, o := range options {
+		for _, n := range []int{1, 2, 3, 1000, 2048, 5000} {
+			data := make([]Leaf, n)
+			for i := range data {
+				data[i] = BytesLeaf(fmt.Sprint(i % 4000))
+			}
+
+			expected := mustBuildMerkleTree(t, data, o.opts...)
+			tree := mustBuildMerkleTree(t, data, append(o.opts, WithWorkers(4))...)
+
+			if !bytes.Equal(tree.Root(), expected.Root()) {
+				t.Errorf("%s/%d: expected %x, got %x", o.name, n, expected.Root(), tree.Root())
+			}
+			if tree.Len() != expected.Len() || tree.NumLeaves() != expected.NumLeaves() {
+				t.Errorf("%s/%d: expected %d nodes, got %d", o.name, n, expected.Len(), tree.Len())
+			}
+			if !tree.Verify() {
+				t.Errorf("%s/%d: couldn't verify tree", o.name, n)
+			}
+
+			// the parents of the batched nodes must be linked
+			last := tree.NumLeaves() - 1
+			proof, err := tree.ProofByIndex(last)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := VerifyProofFromHash(tree.LeafHash(last), proof); err != nil {
+				t.Errorf("%s/%d: unexpected error: %v", o.name, n, err)
+			}
+		}
+	}
+}
+
+func TestWithWorkers_Errors(t *testing.T) {
+	data := make([]Leaf, 5000)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := BuildMerkleTreeCtx(ctx, data, WithWorkers(4)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	data[4000] = nil
+	if _, err := BuildMerkleTree(data, WithWorkers(4)); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+}
+
+func TestWithWorkers_Progress(t *testing.T) {
+	data := make([]Leaf, 5000)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+
+	var done, total int
+	tree := mustBuildMerkleTree(t, data, WithWorkers(4), WithProgress(func(d, t int) {
+		done, total = d, t
+	}))
+	if done != tree.Len() || total != tree.Len() {
+		t.Errorf("expected %d/%d, got %d/%d", tree.Len(), tree.Len(), done, total)
+	}
+}
+
+func BenchmarkBuildMerkleTree_Workers(b *testing.B) {
+	data := make([]Leaf, 1<<16)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprint(workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				BuildMerkleTree(data, WithWorkers(workers))
+			}
+		})
+	}
+}
@@ -1,9 +1,29 @@
 package hashing
 
-import "crypto/sha256"
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/sha3"
+)
 
 func HashSHA256(b []byte) []byte {
-	h := sha256.New()
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+// HashKeccak256 hashes with the original Keccak-256 used by Ethereum, which differs from the standardized SHA3-256.
+func HashKeccak256(b []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
 	h.Write(b)
 	return h.Sum(nil)
 }
+
+// HashTagged computes the BIP-340 tagged hash SHA-256(SHA-256(tag) || SHA-256(tag) || msg).
+func HashTagged(tag string, msg []byte) []byte {
+	t := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(t[:])
+	h.Write(msg)
+	return h.Sum(nil)
+}
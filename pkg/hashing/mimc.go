@@ -0,0 +1,95 @@
+package hashing
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// bn254Modulus is the order of the scalar field of the BN254 curve, the field of SNARK circuits on Ethereum.
+var bn254Modulus, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+const (
+	mimcRounds  = 110 // enough for x^5 over a 254 bit field
+	mimcSeed    = "seed"
+	elementSize = 32
+)
+
+var (
+	mimcOnce      sync.Once
+	mimcConstants [mimcRounds]*big.Int
+)
+
+// initMiMCConstants derives the round constants as a chain of Keccak-256 hashes, starting from Keccak-256 of the seed.
+func initMiMCConstants() {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(mimcSeed))
+	rnd := h.Sum(nil)
+	for i := range mimcConstants {
+		h.Reset()
+		h.Write(rnd)
+		rnd = h.Sum(nil)
+		mimcConstants[i] = new(big.Int).Mod(new(big.Int).SetBytes(rnd), bn254Modulus)
+	}
+}
+
+// MiMCBN254 is the MiMC hash over the scalar field of BN254: the block cipher x -> (x + k + c_i)^5 for 110 rounds,
+// with round constants derived from Keccak-256 of "seed", in the Miyaguchi-Preneel construction
+// h' = E_h(x) + h + x starting from h = 0. It costs a few hundred constraints per compression inside a circuit,
+// instead of tens of thousands for SHA-256.
+// Elements are 32 byte big-endian integers smaller than the modulus of the field.
+type MiMCBN254 struct{}
+
+func (MiMCBN254) ElementSize() int {
+	return elementSize
+}
+
+// CheckElement returns an error if b isn't the canonical encoding of an element of the field.
+func (MiMCBN254) CheckElement(b []byte) error {
+	if len(b) != elementSize {
+		return errors.New("invalid element length")
+	}
+	if new(big.Int).SetBytes(b).Cmp(bn254Modulus) >= 0 {
+		return errors.New("element not in field")
+	}
+	return nil
+}
+
+// HashElement hashes a single element, which is how leaves are hashed.
+func (MiMCBN254) HashElement(x []byte) []byte {
+	return mimc(x)
+}
+
+// Compress hashes two elements into one, which is how internal nodes are hashed.
+func (MiMCBN254) Compress(l, r []byte) []byte {
+	return mimc(l, r)
+}
+
+// mimc hashes the elements in the Miyaguchi-Preneel construction. Inputs are reduced modulo the field, so they must
+// have been checked to be canonical for the hash to be collision resistant.
+func mimc(elements ...[]byte) []byte {
+	mimcOnce.Do(initMiMCConstants)
+	h := new(big.Int)
+	for _, e := range elements {
+		x := new(big.Int).SetBytes(e)
+		x.Mod(x, bn254Modulus)
+		r := mimcEncrypt(x, h)
+		h.Add(h, r).Add(h, x).Mod(h, bn254Modulus)
+	}
+	return h.FillBytes(make([]byte, elementSize))
+}
+
+// mimcEncrypt encrypts m with key k.
+func mimcEncrypt(m, k *big.Int) *big.Int {
+	m = new(big.Int).Set(m)
+	tmp := new(big.Int)
+	for _, c := range mimcConstants {
+		tmp.Add(m, k).Add(tmp, c).Mod(tmp, bn254Modulus)
+		m.Mul(tmp, tmp).Mod(m, bn254Modulus)
+		m.Mul(m, m).Mod(m, bn254Modulus)
+		m.Mul(m, tmp).Mod(m, bn254Modulus)
+	}
+	return m.Add(m, k).Mod(m, bn254Modulus)
+}
@@ -0,0 +1,141 @@
+package hashing
+
+import (
+	"crypto/sha512"
+	"hash"
+
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Domain separation prefixes, so a leaf can never be mistaken for an internal node (second preimage attack).
+const (
+	leafPrefix     = 0x00
+	internalPrefix = 0x01
+)
+
+func HashSHA3_256(b []byte) []byte {
+	h := sha3.Sum256(b)
+	return h[:]
+}
+
+func HashSHA512_256(b []byte) []byte {
+	h := sha512.Sum512_256(b)
+	return h[:]
+}
+
+func HashBlake2b256(b []byte) []byte {
+	h := blake2b.Sum256(b)
+	return h[:]
+}
+
+func HashBlake3(b []byte) []byte {
+	h := blake3.Sum256(b)
+	return h[:]
+}
+
+// SHA3Strategy hashes leaves as SHA3-256(0x00 || leaf) and internal nodes as SHA3-256(0x01 || left || right).
+type SHA3Strategy struct{}
+
+var sha3Digests = NewPooledStrategy(func() hash.Hash { return sha3.New256() })
+
+func (h SHA3Strategy) HashLeaf(l []byte) []byte {
+	return sha3Digests.HashLeaf(l)
+}
+
+func (h SHA3Strategy) HashInternal(l, r []byte) []byte {
+	return sha3Digests.HashInternal(l, r)
+}
+
+func (h SHA3Strategy) AppendLeaf(dst, l []byte) []byte {
+	return sha3Digests.AppendLeaf(dst, l)
+}
+
+func (h SHA3Strategy) AppendInternal(dst, l, r []byte) []byte {
+	return sha3Digests.AppendInternal(dst, l, r)
+}
+
+func (h SHA3Strategy) NewLeafHash() hash.Hash {
+	return sha3Digests.NewLeafHash()
+}
+
+// SHA512_256Strategy hashes leaves as SHA-512/256(0x00 || leaf) and internal nodes as SHA-512/256(0x01 || left || right).
+type SHA512_256Strategy struct{}
+
+var sha512_256Digests = NewPooledStrategy(sha512.New512_256)
+
+func (h SHA512_256Strategy) HashLeaf(l []byte) []byte {
+	return sha512_256Digests.HashLeaf(l)
+}
+
+func (h SHA512_256Strategy) HashInternal(l, r []byte) []byte {
+	return sha512_256Digests.HashInternal(l, r)
+}
+
+func (h SHA512_256Strategy) AppendLeaf(dst, l []byte) []byte {
+	return sha512_256Digests.AppendLeaf(dst, l)
+}
+
+func (h SHA512_256Strategy) AppendInternal(dst, l, r []byte) []byte {
+	return sha512_256Digests.AppendInternal(dst, l, r)
+}
+
+func (h SHA512_256Strategy) NewLeafHash() hash.Hash {
+	return sha512_256Digests.NewLeafHash()
+}
+
+// Blake2bStrategy hashes leaves as BLAKE2b-256(0x00 || leaf) and internal nodes as BLAKE2b-256(0x01 || left || right).
+type Blake2bStrategy struct{}
+
+var blake2bDigests = NewPooledStrategy(newBlake2b256)
+
+func (h Blake2bStrategy) HashLeaf(l []byte) []byte {
+	return blake2bDigests.HashLeaf(l)
+}
+
+func (h Blake2bStrategy) HashInternal(l, r []byte) []byte {
+	return blake2bDigests.HashInternal(l, r)
+}
+
+func (h Blake2bStrategy) AppendLeaf(dst, l []byte) []byte {
+	return blake2bDigests.AppendLeaf(dst, l)
+}
+
+func (h Blake2bStrategy) AppendInternal(dst, l, r []byte) []byte {
+	return blake2bDigests.AppendInternal(dst, l, r)
+}
+
+func (h Blake2bStrategy) NewLeafHash() hash.Hash {
+	return blake2bDigests.NewLeafHash()
+}
+
+// Blake3Strategy hashes leaves as BLAKE3(0x00 || leaf) and internal nodes as BLAKE3(0x01 || left || right), with 32 byte digests.
+type Blake3Strategy struct{}
+
+var blake3Digests = NewPooledStrategy(func() hash.Hash { return blake3.New() })
+
+func (h Blake3Strategy) HashLeaf(l []byte) []byte {
+	return blake3Digests.HashLeaf(l)
+}
+
+func (h Blake3Strategy) HashInternal(l, r []byte) []byte {
+	return blake3Digests.HashInternal(l, r)
+}
+
+func (h Blake3Strategy) AppendLeaf(dst, l []byte) []byte {
+	return blake3Digests.AppendLeaf(dst, l)
+}
+
+func (h Blake3Strategy) AppendInternal(dst, l, r []byte) []byte {
+	return blake3Digests.AppendInternal(dst, l, r)
+}
+
+func (h Blake3Strategy) NewLeafHash() hash.Hash {
+	return blake3Digests.NewLeafHash()
+}
+
+func newBlake2b256() hash.Hash {
+	h, _ := blake2b.New256(nil) // can only fail for keys that are too long
+	return h
+}
@@ -0,0 +1,121 @@
+package hashing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHash_EmptyInputVectors(t *testing.T) {
+	tests := []struct {
+		name string
+		hash func([]byte) []byte
+		want string
+	}{
+		{"SHA3-256", HashSHA3_256, "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+		{"SHA-512/256", HashSHA512_256, "c672b8d1ef56ed28ab87c3622c5114069bdd3ad7b8f9737498d0c01ecef0967a"},
+		{"BLAKE2b-256", HashBlake2b256, "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8"},
+		{"BLAKE3", HashBlake3, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+	}
+
+	for _, tt := range tests {
+		if got := hex.EncodeToString(tt.hash(nil)); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+// strategy is the hash strategy of the merkle trees, with the append methods of the strategies of this package.
+type strategy interface {
+	HashLeaf([]byte) []byte
+	HashInternal([]byte, []byte) []byte
+	AppendLeaf(dst, l []byte) []byte
+	AppendInternal(dst, l, r []byte) []byte
+}
+
+func TestStrategies(t *testing.T) {
+	strategies := map[string]struct {
+		strategy strategy
+		hash     func([]byte) []byte
+	}{
+		"SHA3":       {SHA3Strategy{}, HashSHA3_256},
+		"SHA512_256": {SHA512_256Strategy{}, HashSHA512_256},
+		"Blake2b":    {Blake2bStrategy{}, HashBlake2b256},
+		"Blake3":     {Blake3Strategy{}, HashBlake3},
+	}
+
+	roots := make(map[string]string)
+	for name, s := range strategies {
+		if !bytes.Equal(s.strategy.HashLeaf([]byte("a")), s.hash([]byte("\x00a"))) {
+			t.Errorf("%s: leaf hash not prefixed", name)
+		}
+		if !bytes.Equal(s.strategy.HashInternal([]byte("a"), []byte("b")), s.hash([]byte("\x01ab"))) {
+			t.Errorf("%s: internal hash not prefixed", name)
+		}
+
+		long := bytes.Repeat([]byte("x"), 100)
+		if !bytes.Equal(s.strategy.HashLeaf(long), s.hash(append([]byte{0x00}, long...))) {
+			t.Errorf("%s: long leaf hash not correct", name)
+		}
+		if !bytes.Equal(s.strategy.HashInternal(long, long), s.hash(append(append([]byte{0x01}, long...), long...))) {
+			t.Errorf("%s: long internal hash not correct", name)
+		}
+
+		// appending into a buffer that overlaps the input gives the same hashes
+		for _, in := range [][]byte{[]byte("a"), long} {
+			buf := bytes.Clone(in)
+			if got := s.strategy.AppendLeaf(buf[:0], buf); !bytes.Equal(got, s.strategy.HashLeaf(in)) {
+				t.Errorf("%s: appended leaf hash not correct", name)
+			}
+			buf = bytes.Clone(in)
+			if got := s.strategy.AppendInternal(buf[:0], buf, in); !bytes.Equal(got, s.strategy.HashInternal(in, in)) {
+				t.Errorf("%s: appended internal hash not correct", name)
+			}
+		}
+
+		root := s.strategy.HashInternal(s.strategy.HashLeaf([]byte("a")), s.strategy.HashLeaf([]byte("b")))
+		if other, ok := roots[string(root)]; ok {
+			t.Errorf("%s and %s produce the same root", name, other)
+		}
+		roots[string(root)] = name
+	}
+}
+
+func BenchmarkStrategies(b *testing.B) {
+	strategies := []struct {
+		name     string
+		strategy strategy
+	}{
+		{"SHA3", SHA3Strategy{}},
+		{"SHA512_256", SHA512_256Strategy{}},
+		{"Blake2b", Blake2bStrategy{}},
+		{"Blake3", Blake3Strategy{}},
+		{"Pooled", NewPooledStrategy(sha256.New)},
+	}
+	leaf := bytes.Repeat([]byte{1}, 64)
+	node := bytes.Repeat([]byte{2}, 32)
+	long := bytes.Repeat([]byte{3}, 1024)
+
+	for _, s := range strategies {
+		b.Run(s.name+"/Leaf", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				s.strategy.HashLeaf(leaf)
+			}
+		})
+		b.Run(s.name+"/Internal", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				s.strategy.HashInternal(node, node)
+			}
+		})
+		b.Run(s.name+"/LongLeaf", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := make([]byte, 0, 64)
+			for range b.N {
+				s.strategy.AppendLeaf(buf[:0], long)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package hashing
+
+import (
+	"crypto/hmac"
+	"hash"
+	"sync"
+)
+
+// the prefixes as slices, so writing them into a digest doesn't allocate
+var (
+	leafPrefixBytes     = []byte{leafPrefix}
+	internalPrefixBytes = []byte{internalPrefix}
+)
+
+// DigestStrategy hashes leaves as H(0x00 || leaf) and internal nodes as H(0x01 || left || right) for any hash.Hash.
+// A single digest is reused between calls, guarded by a mutex so the strategy is safe for concurrent use.
+type DigestStrategy struct {
+	mu        sync.Mutex
+	digest    hash.Hash
+	newDigest func() hash.Hash
+}
+
+// NewStrategy returns a DigestStrategy for the hash function created by h, e.g. NewStrategy(sha256.New).
+func NewStrategy(h func() hash.Hash) *DigestStrategy {
+	return &DigestStrategy{digest: h(), newDigest: h}
+}
+
+// NewHMACStrategy returns a DigestStrategy that uses HMAC with the given key and hash function,
+// e.g. NewHMACStrategy(key, sha256.New). Roots can only be computed, and proofs only verified, by holders of the key.
+func NewHMACStrategy(key []byte, h func() hash.Hash) *DigestStrategy {
+	newDigest := func() hash.Hash {
+		return hmac.New(h, key)
+	}
+	return &DigestStrategy{digest: newDigest(), newDigest: newDigest}
+}
+
+func (s *DigestStrategy) HashLeaf(l []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.digest.Reset()
+	s.digest.Write(leafPrefixBytes)
+	s.digest.Write(l)
+	return s.digest.Sum(nil)
+}
+
+func (s *DigestStrategy) HashInternal(l, r []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.digest.Reset()
+	s.digest.Write(internalPrefixBytes)
+	s.digest.Write(l)
+	s.digest.Write(r)
+	return s.digest.Sum(nil)
+}
+
+// NewLeafHash returns a new digest that computes HashLeaf of the bytes written to it, for leaves that are streamed.
+func (s *DigestStrategy) NewLeafHash() hash.Hash {
+	return NewLeafDigest(s.newDigest())
+}
+
+// PooledStrategy hashes like DigestStrategy, but keeps a pool of digests instead of a single one, so goroutines
+// hashing at the same time (e.g. a build WithWorkers) each get their own digest instead of waiting for the lock.
+type PooledStrategy struct {
+	pool      sync.Pool
+	newDigest func() hash.Hash
+}
+
+// NewPooledStrategy returns a PooledStrategy for the hash function created by h, e.g. NewPooledStrategy(sha256.New).
+func NewPooledStrategy(h func() hash.Hash) *PooledStrategy {
+	return &PooledStrategy{
+		pool:      sync.Pool{New: func() any { return h() }},
+		newDigest: h,
+	}
+}
+
+func (s *PooledStrategy) HashLeaf(l []byte) []byte {
+	return s.append(nil, leafPrefixBytes, l, nil)
+}
+
+func (s *PooledStrategy) HashInternal(l, r []byte) []byte {
+	return s.append(nil, internalPrefixBytes, l, r)
+}
+
+// AppendLeaf appends HashLeaf(l) to dst, which may overlap l.
+func (s *PooledStrategy) AppendLeaf(dst, l []byte) []byte {
+	return s.append(dst, leafPrefixBytes, l, nil)
+}
+
+// AppendInternal appends HashInternal(l, r) to dst, which may overlap l and r.
+func (s *PooledStrategy) AppendInternal(dst, l, r []byte) []byte {
+	return s.append(dst, internalPrefixBytes, l, r)
+}
+
+// append appends H(prefix || l || r) to dst. The inputs are written into the digest before anything is appended,
+// so dst may overlap them.
+func (s *PooledStrategy) append(dst, prefix, l, r []byte) []byte {
+	d := s.pool.Get().(hash.Hash)
+	defer s.pool.Put(d)
+	d.Reset()
+	d.Write(prefix)
+	d.Write(l)
+	d.Write(r)
+	return d.Sum(dst)
+}
+
+// NewLeafHash returns a new digest that computes HashLeaf of the bytes written to it, for leaves that are streamed.
+func (s *PooledStrategy) NewLeafHash() hash.Hash {
+	return NewLeafDigest(s.newDigest())
+}
+
+// NewLeafDigest returns h with the leaf prefix written to it, so it computes H(0x00 || leaf) of the leaf written next.
+// Reset writes the prefix again.
+func NewLeafDigest(h hash.Hash) hash.Hash {
+	d := leafDigest{h}
+	d.Reset()
+	return d
+}
+
+type leafDigest struct {
+	hash.Hash
+}
+
+func (d leafDigest) Reset() {
+	d.Hash.Reset()
+	d.Hash.Write(leafPrefixBytes)
+}
@@ -0,0 +1,77 @@
+package hashing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"sync"
+	"testing"
+)
+
+func TestNewStrategy(t *testing.T) {
+	strategy := NewStrategy(sha256.New)
+
+	if !bytes.Equal(strategy.HashLeaf([]byte("a")), HashSHA256([]byte("\x00a"))) {
+		t.Errorf("leaf hash not prefixed")
+	}
+	if !bytes.Equal(strategy.HashInternal([]byte("a"), []byte("b")), HashSHA256([]byte("\x01ab"))) {
+		t.Errorf("internal hash not prefixed")
+	}
+
+	// the digest is shared, so concurrent use must not mix up hashes
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if !bytes.Equal(strategy.HashLeaf([]byte("a")), HashSHA256([]byte("\x00a"))) {
+					t.Errorf("leaf hash not correct")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewPooledStrategy(t *testing.T) {
+	strategy := NewPooledStrategy(sha256.New)
+
+	if !bytes.Equal(strategy.HashLeaf([]byte("a")), HashSHA256([]byte("\x00a"))) {
+		t.Errorf("leaf hash not prefixed")
+	}
+	buf := []byte("ab")
+	if got := strategy.AppendInternal(buf[:0], buf[:1], buf[1:]); !bytes.Equal(got, HashSHA256([]byte("\x01ab"))) {
+		t.Errorf("appended internal hash not correct")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if !bytes.Equal(strategy.HashInternal([]byte("a"), []byte("b")), HashSHA256([]byte("\x01ab"))) {
+					t.Errorf("internal hash not correct")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewHMACStrategy(t *testing.T) {
+	key := []byte("key")
+	strategy := NewHMACStrategy(key, sha256.New)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("\x00a"))
+	if expected := mac.Sum(nil); !bytes.Equal(strategy.HashLeaf([]byte("a")), expected) {
+		t.Errorf("expected %x, got %x", expected, strategy.HashLeaf([]byte("a")))
+	}
+	if other := NewHMACStrategy([]byte("other"), sha256.New); bytes.Equal(other.HashLeaf([]byte("a")), mac.Sum(nil)) {
+		t.Errorf("expected other key to give another hash")
+	}
+}
@@ -0,0 +1,250 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"slices"
+)
+
+const proofBundleEncodingVersion = 1
+
+// ProofBundle holds many proofs of the same tree, like the proofs of all claims of an airdrop, compactly:
+// every distinct hash is stored once in a dictionary shared by the proofs, which reference their siblings by index.
+// Proofs of the same tree share the hashes near the root, and the leaf-level siblings of neighbouring leaves,
+// so shipping thousands of proofs as a bundle takes a fraction of the bandwidth of encoding them one by one.
+type ProofBundle struct {
+	root         []byte
+	dict         [][]byte
+	proofs       []bundledProof
+	hashStrategy HashStrategy
+}
+
+type bundledProof struct {
+	refs []int // indices into the dictionary, from the leaf up to the root
+	left []bool
+}
+
+// NewProofBundle bundles proofs of the same tree, in the given order. All proofs need the same root and hash strategy.
+// The dictionary is ordered by how many proofs reference a hash, so the most shared hashes get the smallest indices.
+func NewProofBundle(proofs []*Proof) (*ProofBundle, error) {
+	if len(proofs) == 0 {
+		return nil, errors.New("no proofs")
+	}
+	for _, p := range proofs {
+		if p == nil {
+			return nil, errors.New("nil proof")
+		}
+		if !bytes.Equal(p.root, proofs[0].root) {
+			return nil, errors.New("proofs of different roots")
+		}
+		// DeepEqual, because strategies like SaltedHashStrategy can't be compared with ==
+		if !reflect.DeepEqual(p.hashStrategy, proofs[0].hashStrategy) {
+			return nil, errors.New("proofs of different hash strategies")
+		}
+		if len(p.siblings) != len(p.left) {
+			return nil, errors.New("proof lengths mismatch")
+		}
+	}
+
+	// count the references of every distinct hash, keeping the order they are first seen in for ties
+	count := make(map[string]int)
+	var dict [][]byte
+	for _, p := range proofs {
+		for _, sibling := range p.siblings {
+			if count[string(sibling)] == 0 {
+				dict = append(dict, bytes.Clone(sibling))
+			}
+			count[string(sibling)]++
+		}
+	}
+	slices.SortStableFunc(dict, func(a, b []byte) int {
+		return cmp.Compare(count[string(b)], count[string(a)])
+	})
+	index := make(map[string]int, len(dict))
+	for i, h := range dict {
+		index[string(h)] = i
+	}
+
+	b := &ProofBundle{
+		root:         bytes.Clone(proofs[0].root),
+		dict:         dict,
+		proofs:       make([]bundledProof, len(proofs)),
+		hashStrategy: proofs[0].hashStrategy,
+	}
+	for i, p := range proofs {
+		refs := make([]int, len(p.siblings))
+		for j, sibling := range p.siblings {
+			refs[j] = index[string(sibling)]
+		}
+		b.proofs[i] = bundledProof{refs: refs, left: slices.Clone(p.left)}
+	}
+	return b, nil
+}
+
+// ProofBundle generates the proofs of the leaves and bundles them, in the given order, verifying the tree only once.
+// If a leaf occurs multiple times, the proof is for its first occurrence, like with Proof.
+func (m *MerkleTree) ProofBundle(leaves []Leaf) (*ProofBundle, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	proofs := make([]*Proof, len(leaves))
+	nodes := make([]*Node, len(leaves))
+	for i, x := range leaves {
+		hash, err := m.hashLeaf(x)
+		if err != nil {
+			return nil, err
+		}
+		j := m.leafIndex(hash)
+		if j < 0 {
+			return nil, errors.New("not in tree")
+		}
+		nodes[i] = m.leaves[j]
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	for i, n := range nodes {
+		proofs[i] = m.proof(n)
+	}
+	return NewProofBundle(proofs)
+}
+
+// Root returns a copy of the root the proofs were generated for.
+func (b *ProofBundle) Root() []byte {
+	if b == nil {
+		return nil
+	}
+	return bytes.Clone(b.root)
+}
+
+// Len returns the number of proofs in the bundle.
+func (b *ProofBundle) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.proofs)
+}
+
+// Proof returns the i-th proof of the bundle.
+func (b *ProofBundle) Proof(i int) (*Proof, error) {
+	if b == nil {
+		return nil, errors.New("nil bundle")
+	}
+	if i < 0 || i >= len(b.proofs) {
+		return nil, errors.New("index out of range")
+	}
+	bp := b.proofs[i]
+	siblings := make([][]byte, len(bp.refs))
+	for j, ref := range bp.refs {
+		siblings[j] = bytes.Clone(b.dict[ref])
+	}
+	return &Proof{
+		root:         bytes.Clone(b.root),
+		siblings:     siblings,
+		left:         slices.Clone(bp.left),
+		hashStrategy: b.hashStrategy,
+	}, nil
+}
+
+// Proofs returns all proofs of the bundle, in the order they were bundled.
+func (b *ProofBundle) Proofs() []*Proof {
+	proofs := make([]*Proof, b.Len())
+	for i := range proofs {
+		proofs[i], _ = b.Proof(i)
+	}
+	return proofs
+}
+
+// MarshalBinary encodes the bundle as
+// version (1 byte) | root length (uvarint) | root | hash length (uvarint) | hash count (uvarint) | hashes... |
+// proof count (uvarint) | (sibling count (uvarint) | (hash index (uvarint))... | direction bits)...,
+// with the direction bits of every proof packed like in Proof.MarshalBinary.
+// All hashes of the dictionary must have the same length. The hash strategy is not part of the encoding.
+func (b *ProofBundle) MarshalBinary() ([]byte, error) {
+	if b == nil {
+		return nil, errors.New("nil bundle")
+	}
+	size := 0
+	if len(b.dict) > 0 {
+		size = len(b.dict[0])
+	}
+	for _, h := range b.dict {
+		if len(h) != size {
+			return nil, errors.New("hashes of different lengths")
+		}
+	}
+
+	out := []byte{proofBundleEncodingVersion}
+	out = appendBytes(out, b.root)
+	out = binary.AppendUvarint(out, uint64(size))
+	out = binary.AppendUvarint(out, uint64(len(b.dict)))
+	for _, h := range b.dict {
+		out = append(out, h...)
+	}
+	out = binary.AppendUvarint(out, uint64(len(b.proofs)))
+	for _, p := range b.proofs {
+		out = binary.AppendUvarint(out, uint64(len(p.refs)))
+		for _, ref := range p.refs {
+			out = binary.AppendUvarint(out, uint64(ref))
+		}
+		out = appendDirections(out, p.left)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes a bundle encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proofs use the default hash strategy.
+func (b *ProofBundle) UnmarshalBinary(data []byte) error {
+	if b == nil {
+		return errors.New("nil bundle")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != proofBundleEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	root := d.bytes()
+	size := d.length()
+	n := d.length()
+	if d.err == nil && size > 0 && n > len(d.b)/size {
+		return errors.New("unexpected end of data")
+	}
+	dict := make([][]byte, n)
+	for i := range dict {
+		dict[i] = bytes.Clone(d.next(size))
+	}
+	proofs := make([]bundledProof, d.length())
+	for i := range proofs {
+		refs := make([]int, d.length())
+		for j := range refs {
+			refs[j] = d.index(len(dict))
+		}
+		left := d.directions(len(refs))
+		if d.err != nil {
+			return d.err
+		}
+		proofs[i] = bundledProof{refs: refs, left: left}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+	if len(proofs) == 0 {
+		return errors.New("no proofs")
+	}
+
+	*b = ProofBundle{
+		root:         root,
+		dict:         dict,
+		proofs:       proofs,
+		hashStrategy: defaultHashStrategy{},
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestProofBundle(t *testing.T) {
+	data := make([]Leaf, 1000)
+	for i := range data {
+		data[i] = &TestLeaf{fmt.Sprintf("claim %d", i)}
+	}
+	tree := mustBuildMerkleTree(t, data)
+	proofs, err := tree.AllProofs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := NewProofBundle(proofs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoded, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded ProofBundle
+	if err := decoded.UnmarshalBinary(encoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Len() != len(data) || !bytes.Equal(decoded.Root(), tree.Root()) {
+		t.Fatalf("expected %d proofs of the root, got %d", len(data), decoded.Len())
+	}
+
+	separate := 0
+	for i, x := range data {
+		p, err := decoded.Proof(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := p.Verify(x); err != nil {
+			t.Errorf("proof %d: unexpected error: %v", i, err)
+		}
+		pb, err := proofs[i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b2, _ := p.MarshalBinary(); !bytes.Equal(pb, b2) {
+			t.Errorf("proof %d: expected the bundled proof", i)
+		}
+		separate += len(pb)
+	}
+	if len(encoded)*3 > separate {
+		t.Errorf("expected the bundle to be less than a third of %d bytes, got %d", separate, len(encoded))
+	}
+
+	// the hashes shared by most proofs, the children of the root, come first
+	if !bytes.Equal(b.dict[0], tree.root.left.h) && !bytes.Equal(b.dict[0], tree.root.right.h) {
+		t.Errorf("expected a child of the root first")
+	}
+}
+
+func TestTree_ProofBundle(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}, &TestLeaf{"e"}}
+	tree := mustBuildMerkleTree(t, data)
+	b, err := tree.ProofBundle([]Leaf{data[4], data[1]})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proofs := b.Proofs()
+	if len(proofs) != 2 {
+		t.Fatalf("expected 2 proofs, got %d", len(proofs))
+	}
+	if err := proofs[0].Verify(data[4]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := proofs[1].Verify(data[1]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := tree.ProofBundle([]Leaf{&TestLeaf{"f"}}); err == nil {
+		t.Errorf("expected error for leaf not in tree")
+	}
+	if _, err := b.Proof(2); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+}
+
+func TestProofBundle_Errors(t *testing.T) {
+	a := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}})
+	other := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"c"}})
+	pa, err := a.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	po, err := other.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewProofBundle(nil); err == nil {
+		t.Errorf("expected error for no proofs")
+	}
+	if _, err := NewProofBundle([]*Proof{pa, po}); err == nil {
+		t.Errorf("expected error for proofs of different roots")
+	}
+	salted := NewProof(pa.Root(), pa.Siblings(), pa.Directions(), SaltedHashStrategy{hashStrategy, []byte("salt")})
+	if _, err := NewProofBundle([]*Proof{pa, salted}); err == nil {
+		t.Errorf("expected error for proofs of different hash strategies")
+	}
+	if _, err := NewProofBundle([]*Proof{salted, salted}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	b, err := NewProofBundle([]*Proof{pa})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoded, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded ProofBundle
+	if err := decoded.UnmarshalBinary(encoded[:len(encoded)-1]); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+	if err := decoded.UnmarshalBinary(append(bytes.Clone(encoded), 0)); err == nil {
+		t.Errorf("expected error for trailing data")
+	}
+	// the reference to the only hash points past the dictionary
+	invalid := bytes.Clone(encoded)
+	invalid[len(invalid)-2] = 1
+	if err := decoded.UnmarshalBinary(invalid); err == nil {
+		t.Errorf("expected error for reference out of range")
+	}
+}
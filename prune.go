@@ -0,0 +1,150 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"slices"
+)
+
+// PrunedMerkleTree is a partial merkle tree that only keeps the paths of some of its leaves. The subtrees next to
+// the paths are replaced by their root hashes, so an SPV-style light client can hold a tree of O(k log n) nodes for
+// k of the n leaves, and still generate proofs for its own leaves.
+type PrunedMerkleTree struct {
+	root         *Node
+	n            int // number of nodes kept
+	numLeaves    int // number of leaves of the full tree
+	leaves       map[int]*Node
+	hashStrategy HashStrategy
+}
+
+// Prune returns a partial copy of the tree with only the leaves at the given indices and the nodes needed to prove
+// them; every other subtree is reduced to its root hash. The tree itself is not changed.
+func (m *MerkleTree) Prune(keep []int) (*PrunedMerkleTree, error) {
+	if m == nil || m.root == nil {
+		return nil, errors.New("nil tree")
+	}
+	for _, i := range keep {
+		if i < 0 || i >= len(m.leaves) {
+			return nil, errors.New("index out of range")
+		}
+	}
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	// the nodes on the paths of the kept leaves keep their children, all other nodes become boundaries
+	paths := make(map[*Node]bool)
+	for _, i := range keep {
+		for n := m.leaves[i]; n != nil && !paths[n]; n = n.parent {
+			paths[n] = true
+		}
+	}
+
+	p := &PrunedMerkleTree{
+		numLeaves:    len(m.leaves),
+		leaves:       make(map[int]*Node, len(keep)),
+		hashStrategy: m.hashStrategy,
+	}
+	copies := make(map[*Node]*Node, len(paths))
+	var prune func(n *Node) *Node
+	prune = func(n *Node) *Node {
+		c := &Node{h: n.h}
+		p.n++
+		if paths[n] {
+			copies[n] = c
+			if n.left != nil {
+				c.left = prune(n.left)
+				c.right = c.left
+				if n.right != n.left {
+					c.right = prune(n.right)
+				}
+				c.left.parent = c
+				c.right.parent = c
+			}
+		}
+		return c
+	}
+	p.root = prune(m.root)
+	for _, i := range keep {
+		p.leaves[i] = copies[m.leaves[i]]
+	}
+	return p, nil
+}
+
+// Root returns the bytes of the root.
+func (p *PrunedMerkleTree) Root() []byte {
+	if p == nil || p.root == nil {
+		return nil
+	}
+	return bytes.Clone(p.root.h)
+}
+
+// Len returns the number of nodes kept, including the roots of pruned subtrees.
+func (p *PrunedMerkleTree) Len() int {
+	if p == nil {
+		return -1
+	}
+	return p.n
+}
+
+// NumLeaves returns the number of leaves of the full tree.
+func (p *PrunedMerkleTree) NumLeaves() int {
+	if p == nil {
+		return 0
+	}
+	return p.numLeaves
+}
+
+// Kept returns the indices of the kept leaves, in ascending order.
+func (p *PrunedMerkleTree) Kept() []int {
+	if p == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(p.leaves))
+}
+
+// Verify verifies the integrity of the kept nodes: every node on a kept path must be the hash of its children.
+func (p *PrunedMerkleTree) Verify() bool {
+	if p == nil || p.root == nil || p.hashStrategy == nil {
+		return false
+	}
+	return p.root.verify(p.hashStrategy)
+}
+
+// Proof generates a proof for a kept leaf.
+func (p *PrunedMerkleTree) Proof(x Leaf) (*Proof, error) {
+	if p == nil {
+		return nil, errors.New("nil tree")
+	}
+	b, err := leafBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	hash := p.hashStrategy.HashLeaf(b)
+	for _, i := range p.Kept() {
+		if hashEqual(p.leaves[i].h, hash) {
+			return p.ProofByIndex(i)
+		}
+	}
+	return nil, errors.New("not in tree")
+}
+
+// ProofByIndex generates a proof for the i-th leaf of the full tree, which must have been kept.
+func (p *PrunedMerkleTree) ProofByIndex(i int) (*Proof, error) {
+	if p == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= p.numLeaves {
+		return nil, errors.New("index out of range")
+	}
+	node, ok := p.leaves[i]
+	if !ok {
+		return nil, errors.New("leaf pruned")
+	}
+	if !p.Verify() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	return nodeProof(node, p.root.h, p.hashStrategy), nil
+}
@@ -0,0 +1,101 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestMerkleTree_Prune(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithDuplication()}} {
+		data := make([]Leaf, 1000)
+		for i := range data {
+			data[i] = BytesLeaf(fmt.Sprint(i))
+		}
+		tree := mustBuildMerkleTree(t, data, opts...)
+		size := tree.Len()
+
+		keep := []int{999, 3, 500, 3}
+		pruned, err := tree.Prune(keep)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(pruned.Root(), tree.Root()) {
+			t.Errorf("expected %x, got %x", tree.Root(), pruned.Root())
+		}
+		if pruned.NumLeaves() != 1000 || !slices.Equal(pruned.Kept(), []int{3, 500, 999}) {
+			t.Errorf("unexpected leaves %d %v", pruned.NumLeaves(), pruned.Kept())
+		}
+		// at most two nodes per level for every kept leaf
+		if pruned.Len() > 3*2*11 {
+			t.Errorf("expected a small tree, got %d nodes", pruned.Len())
+		}
+		if !pruned.Verify() {
+			t.Errorf("couldn't verify pruned tree")
+		}
+
+		for _, i := range keep {
+			proof, err := pruned.ProofByIndex(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected, _ := tree.ProofByIndex(i)
+			if !slices.EqualFunc(proof.Siblings(), expected.Siblings(), bytes.Equal) || !slices.Equal(proof.Directions(), expected.Directions()) {
+				t.Errorf("proof of %d differs", i)
+			}
+			if err := VerifyProof(data[i], proof); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		proof, err := pruned.Proof(data[500])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyProof(data[500], proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if _, err := pruned.ProofByIndex(4); err == nil {
+			t.Errorf("expected error for pruned leaf")
+		}
+		if _, err := pruned.Proof(data[4]); err == nil {
+			t.Errorf("expected error for pruned leaf")
+		}
+
+		// the tree itself is not changed
+		if tree.Len() != size || !tree.Verify() {
+			t.Errorf("tree changed")
+		}
+	}
+}
+
+func TestMerkleTree_PruneErrors(t *testing.T) {
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}})
+
+	if _, err := tree.Prune([]int{3}); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+
+	// keeping nothing keeps only the root
+	pruned, err := tree.Prune(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pruned.Len() != 1 || !bytes.Equal(pruned.Root(), tree.Root()) {
+		t.Errorf("expected only the root, got %d nodes", pruned.Len())
+	}
+
+	// a tampered kept path doesn't verify
+	pruned, _ = tree.Prune([]int{0})
+	pruned.leaves[0].h = hashStrategy.HashLeaf([]byte("x"))
+	if _, err := pruned.ProofByIndex(0); err == nil {
+		t.Errorf("expected error for tampered tree")
+	}
+
+	var nilTree *MerkleTree
+	if _, err := nilTree.Prune([]int{0}); err == nil {
+		t.Errorf("expected error for nil tree")
+	}
+}
@@ -0,0 +1,188 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+)
+
+// RangeProof proves that a contiguous span of leaves are exactly the leaves at those positions of the tree.
+// It only contains the hashes of the subtrees left and right of the span, at most two per level.
+type RangeProof struct {
+	root         []byte
+	size         int
+	start        int
+	end          int
+	hashes       [][]byte
+	hashStrategy HashStrategy
+}
+
+// NewRangeProof assembles a range proof from its contents, e.g. after receiving them over the network.
+// A nil hash strategy means the default hash strategy.
+func NewRangeProof(root []byte, size, start, end int, hashes [][]byte, hash HashStrategy) *RangeProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	return &RangeProof{
+		root:         bytes.Clone(root),
+		size:         size,
+		start:        start,
+		end:          end,
+		hashes:       cloneHashes(hashes),
+		hashStrategy: hash,
+	}
+}
+
+// Root returns a copy of the root the proof was generated for.
+func (p *RangeProof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Size returns the number of leaves of the tree the proof was generated for.
+func (p *RangeProof) Size() int {
+	if p == nil {
+		return 0
+	}
+	return p.size
+}
+
+// Start returns the index of the first leaf of the span.
+func (p *RangeProof) Start() int {
+	if p == nil {
+		return 0
+	}
+	return p.start
+}
+
+// End returns the index after the last leaf of the span.
+func (p *RangeProof) End() int {
+	if p == nil {
+		return 0
+	}
+	return p.end
+}
+
+// Hashes returns a copy of the hashes of the subtrees left and right of the span, in depth-first order.
+func (p *RangeProof) Hashes() [][]byte {
+	if p == nil {
+		return nil
+	}
+	return cloneHashes(p.hashes)
+}
+
+// RangeProof generates a proof for the leaves in [start, end), e.g. to authenticate a batch of leaves
+// fetched by a syncing client.
+func (m *MerkleTree) RangeProof(start, end int) (*RangeProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if m.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if start < 0 || end > len(m.leaves) || start >= end {
+		return nil, errors.New("index out of range")
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	var hashes [][]byte
+	var collect func(node *Node, lo, hi int)
+	collect = func(node *Node, lo, hi int) {
+		if hi <= start || lo >= end {
+			hashes = append(hashes, bytes.Clone(node.h))
+			return
+		}
+		if start <= lo && hi <= end {
+			return
+		}
+		k := split(hi - lo)
+		collect(node.left, lo, lo+k)
+		collect(node.right, lo+k, hi)
+	}
+	collect(m.root, 0, len(m.leaves))
+
+	return &RangeProof{
+		root:         bytes.Clone(m.root.h),
+		size:         len(m.leaves),
+		start:        start,
+		end:          end,
+		hashes:       hashes,
+		hashStrategy: m.hashStrategy,
+	}, nil
+}
+
+// VerifyRangeProof checks if a range proof is valid for the given leaves, in order.
+func VerifyRangeProof(leaves []Leaf, p *RangeProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyRangeProof(leaves, p, p.root)
+}
+
+// VerifyRangeProofAgainstRoot checks if a range proof is valid for the given leaves under a root the verifier already
+// trusts, like a syncing client that fetches the leaves from an untrusted peer. The root stored in the proof is ignored.
+func VerifyRangeProofAgainstRoot(leaves []Leaf, p *RangeProof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyRangeProof(leaves, p, root)
+}
+
+func verifyRangeProof(leaves []Leaf, p *RangeProof, root []byte) error {
+	if len(leaves) != p.end-p.start {
+		return errors.New("proof lengths mismatch")
+	}
+	if p.size > maxTreeSize {
+		return errors.New("invalid tree size")
+	}
+	if p.start < 0 || p.end > p.size || p.start >= p.end {
+		return errors.New("index out of range")
+	}
+
+	hashes := p.hashes
+	var compute func(lo, hi int) ([]byte, error)
+	compute = func(lo, hi int) ([]byte, error) {
+		if hi <= p.start || lo >= p.end {
+			if len(hashes) == 0 {
+				return nil, errors.New("not enough hashes")
+			}
+			hash := hashes[0]
+			hashes = hashes[1:]
+			return hash, nil
+		}
+		if hi-lo == 1 {
+			b, err := leafBytes(leaves[lo-p.start])
+			if err != nil {
+				return nil, err
+			}
+			return p.hashStrategy.HashLeaf(b), nil
+		}
+		k := split(hi - lo)
+		left, err := compute(lo, lo+k)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compute(lo+k, hi)
+		if err != nil {
+			return nil, err
+		}
+		return p.hashStrategy.HashInternal(left, right), nil
+	}
+
+	hash, err := compute(0, p.size)
+	if err != nil {
+		return err
+	}
+	if len(hashes) != 0 {
+		return errors.New("too many hashes")
+	}
+
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTree_RangeProof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		var data []Leaf
+		for i := range n {
+			data = append(data, &TestLeaf{fmt.Sprint(i)})
+		}
+
+		tree := mustBuildMerkleTree(t, data)
+
+		for start := 0; start < n; start++ {
+			for end := start + 1; end <= n; end++ {
+				proof, err := tree.RangeProof(start, end)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if err := VerifyRangeProof(data[start:end], proof); err != nil {
+					t.Errorf("n=%d [%d, %d): unexpected error: %v", n, start, end, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTree_RangeProofWrongLeaves(t *testing.T) {
+	var data []Leaf
+	for i := range 8 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	proof, err := tree.RangeProof(2, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if proof.Start() != 2 || proof.End() != 5 {
+		t.Errorf("expected [2, 5), got [%d, %d)", proof.Start(), proof.End())
+	}
+
+	// shifted span
+	if err := VerifyRangeProof(data[3:6], proof); err == nil {
+		t.Errorf("expected error")
+	}
+
+	// swapped leaves
+	swapped := []Leaf{data[3], data[2], data[4]}
+	if err := VerifyRangeProof(swapped, proof); err == nil {
+		t.Errorf("expected error")
+	}
+
+	// missing leaf
+	if err := VerifyRangeProof(data[2:4], proof); err == nil {
+		t.Errorf("expected error")
+	}
+
+	proof.hashes = proof.hashes[1:]
+	if err := VerifyRangeProof(data[2:5], proof); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestTree_RangeProofErrors(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for _, r := range [][2]int{{-1, 1}, {0, 4}, {2, 2}, {2, 1}} {
+		if _, err := tree.RangeProof(r[0], r[1]); err == nil {
+			t.Errorf("[%d, %d): expected error", r[0], r[1])
+		}
+	}
+
+	dup := mustBuildMerkleTree(t, data, WithDuplication())
+	if _, err := dup.RangeProof(0, 1); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if err := VerifyRangeProof(data, nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestRangeProof_Encoding(t *testing.T) {
+	var data []Leaf
+	for i := range 11 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+	tree := mustBuildMerkleTree(t, data)
+	proof, err := tree.RangeProof(3, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromBinary RangeProof
+	if err := fromBinary.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	j, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromJSON RangeProof
+	if err := json.Unmarshal(j, &fromJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assembled := NewRangeProof(nil, proof.Size(), proof.Start(), proof.End(), proof.Hashes(), nil)
+
+	// a client checks the fetched leaves against the root it trusts, not the one of the peer
+	for _, p := range []*RangeProof{&fromBinary, &fromJSON, assembled} {
+		if err := VerifyRangeProofAgainstRoot(data[3:7], p, tree.Root()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyRangeProofAgainstRoot(data[3:7], p, tree.Root()[1:]); err == nil {
+			t.Errorf("expected error for another root")
+		}
+	}
+
+	if err := fromBinary.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+	if err := fromBinary.UnmarshalBinary(append(bytes.Clone(b), 0)); err == nil {
+		t.Errorf("expected error for trailing data")
+	}
+	if err := json.Unmarshal([]byte(`{"root":"","size":4,"start":3,"end":5,"hashes":[]}`), &fromJSON); err == nil {
+		t.Errorf("expected error for range out of the tree")
+	}
+}
+
+func TestRangeProof_MaxSize(t *testing.T) {
+	h := hashStrategy.HashLeaf([]byte("y"))
+	p := NewRangeProof(h, math.MaxInt, 0, 1, [][]byte{h}, nil)
+	if err := VerifyRangeProofAgainstRoot([]Leaf{BytesLeaf("x")}, p, h); err == nil {
+		t.Errorf("expected error for proof of size %d", math.MaxInt)
+	}
+
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded RangeProof
+	if err := decoded.UnmarshalBinary(b); err == nil {
+		t.Errorf("expected error for proof of size %d", math.MaxInt)
+	}
+	j, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(j, &decoded); err == nil {
+		t.Errorf("expected error for proof of size %d", math.MaxInt)
+	}
+}
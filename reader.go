@@ -0,0 +1,50 @@
+package gomerkletree
+
+import (
+	"errors"
+	"io"
+)
+
+// Chunk is a leaf holding a chunk of a stream, to verify chunks of trees built by BuildFromReader.
+type Chunk []byte
+
+func (c Chunk) Bytes() []byte {
+	return c
+}
+
+// BuildFromReader splits a stream into chunks of chunkSize bytes and builds a merkle tree with the chunks as leaves.
+// The last chunk can be shorter. Chunks are hashed while reading, so only their hashes are kept in memory.
+// Returns an error for an empty stream.
+func BuildFromReader(r io.Reader, chunkSize int, opts ...Option) (*MerkleTree, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("invalid chunk size")
+	}
+	cfg := newConfig(opts)
+
+	hashes, err := readChunkHashes(r, chunkSize, cfg.hashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return nil, errors.New("no data")
+	}
+	return buildFromLeafHashes(hashes, cfg), nil
+}
+
+// readChunkHashes reads the stream in chunks of chunkSize bytes and returns their leaf hashes.
+func readChunkHashes(r io.Reader, chunkSize int, hash HashStrategy) ([][]byte, error) {
+	var hashes [][]byte
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			hashes = append(hashes, hash.HashLeaf(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return hashes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestTree_BuildFromReader(t *testing.T) {
+	input := "abcdefghij"
+
+	for _, chunkSize := range []int{1, 3, 4, 10, 16} {
+		tree, err := BuildFromReader(iotest.HalfReader(strings.NewReader(input)), chunkSize)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var data []Leaf
+		for i := 0; i < len(input); i += chunkSize {
+			data = append(data, Chunk(input[i:min(i+chunkSize, len(input))]))
+		}
+
+		if expected := mustBuildMerkleTree(t, data).Root(); !bytes.Equal(tree.Root(), expected) {
+			t.Errorf("chunk size %d: expected %x, got %x", chunkSize, expected, tree.Root())
+		}
+
+		proof, err := tree.ProofByIndex(len(data) - 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := VerifyProof(data[len(data)-1], proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestTree_BuildFromReaderErrors(t *testing.T) {
+	if _, err := BuildFromReader(strings.NewReader("abc"), 0); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := BuildFromReader(strings.NewReader(""), 4); err == nil {
+		t.Errorf("expected error")
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := BuildFromReader(iotest.ErrReader(readErr), 4); !errors.Is(err, readErr) {
+		t.Errorf("expected %v, got %v", readErr, err)
+	}
+}
@@ -0,0 +1,260 @@
+// Package recon reconciles two sets between peers, so each learns the symmetric difference of the sets while only
+// exchanging the parts of them that differ.
+//
+// The elements of a set are hashes of equal length, e.g. SHA-256 of the elements of the application, kept sorted.
+// A range of the set is the elements whose hashes start with the same bits, and its fingerprint is the root of a
+// merkle tree over the sorted hashes in the range. Peers compare the fingerprint of the whole set first, and split
+// ranges whose fingerprints differ in two, until ranges are small enough to send their elements. Ranges that match
+// are not looked at again, so d differences among n elements take O(log n) round trips of O(d) ranges each.
+//
+// The initiator sends the message of Start, and both peers pass every message they receive to Handle and send
+// back its reply, until Handle returns nil. Then both peers are Done and have the difference.
+package recon
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+// DefaultThreshold is the number of elements up to which a range is sent in full instead of split.
+const DefaultThreshold = 8
+
+// Range is the range of elements whose hashes start with the first Depth bits of Prefix.
+type Range struct {
+	Prefix []byte
+	Depth  int
+}
+
+// Fingerprint asks the receiver to compare the fingerprint of a range with its own.
+type Fingerprint struct {
+	Range       Range
+	Count       int
+	Fingerprint []byte
+}
+
+// Items sends the elements of a range. Unless the items are final, the receiver answers with its own elements
+// of the range, so both peers can compute the difference in the range.
+type Items struct {
+	Range Range
+	Items [][]byte
+	Final bool
+}
+
+// Message is a message between peers. An empty message ends the reconciliation.
+type Message struct {
+	Fingerprints []Fingerprint
+	Items        []Items
+}
+
+// expectsReply reports whether the receiver of the message has to reply.
+func (m *Message) expectsReply() bool {
+	if len(m.Fingerprints) > 0 {
+		return true
+	}
+	for _, items := range m.Items {
+		if !items.Final {
+			return true
+		}
+	}
+	return false
+}
+
+// Peer is one side of a reconciliation. It is not safe for concurrent use.
+type Peer struct {
+	items     [][]byte
+	size      int // length of the hashes
+	threshold int
+	done      bool
+	missing   [][]byte // elements only the other peer has
+	extra     [][]byte // elements only this peer has
+}
+
+// NewPeer returns a peer for the set of element hashes, which must all have the same length. The hashes are copied,
+// sorted and deduplicated. Ranges of up to threshold elements are sent in full; a threshold below 1 means
+// DefaultThreshold. Both peers should use the same threshold.
+func NewPeer(hashes [][]byte, threshold int) (*Peer, error) {
+	if threshold < 1 {
+		threshold = DefaultThreshold
+	}
+	p := &Peer{threshold: threshold}
+	for _, h := range hashes {
+		if len(h) == 0 {
+			return nil, errors.New("empty hash")
+		}
+		if p.size == 0 {
+			p.size = len(h)
+		} else if len(h) != p.size {
+			return nil, errors.New("hashes of different lengths")
+		}
+		p.items = append(p.items, bytes.Clone(h))
+	}
+	slices.SortFunc(p.items, bytes.Compare)
+	p.items = slices.CompactFunc(p.items, bytes.Equal)
+	return p, nil
+}
+
+// Start returns the first message of the reconciliation, with the fingerprint of the whole set.
+func (p *Peer) Start() *Message {
+	return &Message{Fingerprints: []Fingerprint{p.fingerprint(Range{})}}
+}
+
+// Handle processes a message of the other peer and returns the reply to send back, or nil when the reconciliation
+// is done and nothing has to be sent.
+func (p *Peer) Handle(m *Message) (*Message, error) {
+	if m == nil {
+		return nil, errors.New("nil message")
+	}
+	if p.done {
+		return nil, errors.New("reconciliation done")
+	}
+
+	reply := &Message{}
+	for _, f := range m.Fingerprints {
+		if err := p.checkRange(f.Range); err != nil {
+			return nil, err
+		}
+		local := p.fingerprint(f.Range)
+		switch {
+		case local.Count == f.Count && bytes.Equal(local.Fingerprint, f.Fingerprint):
+		case local.Count <= p.threshold || f.Range.Depth == 8*p.size || p.size == 0:
+			reply.Items = append(reply.Items, Items{Range: f.Range, Items: p.rangeItems(f.Range)})
+		default:
+			for bit := range 2 {
+				reply.Fingerprints = append(reply.Fingerprints, p.fingerprint(child(f.Range, bit)))
+			}
+		}
+	}
+	for _, items := range m.Items {
+		if err := p.checkRange(items.Range); err != nil {
+			return nil, err
+		}
+		for _, h := range items.Items {
+			if (p.size != 0 && len(h) != p.size) || 8*len(h) < items.Range.Depth {
+				return nil, errors.New("hash of different length")
+			}
+			if !inRange(h, items.Range) {
+				return nil, errors.New("item not in range")
+			}
+		}
+		local := p.rangeItems(items.Range)
+		p.diff(local, items.Items)
+		if !items.Final {
+			reply.Items = append(reply.Items, Items{Range: items.Range, Items: local, Final: true})
+		}
+	}
+
+	if !m.expectsReply() {
+		p.done = true
+		return nil, nil
+	}
+	if !reply.expectsReply() {
+		p.done = true // the other peer won't reply to this
+	}
+	return reply, nil
+}
+
+// Done reports whether the reconciliation is done.
+func (p *Peer) Done() bool {
+	return p.done
+}
+
+// Difference returns the elements only the other peer has, and the elements only this peer has, sorted.
+// It is complete once the reconciliation is done.
+func (p *Peer) Difference() (theirs, ours [][]byte) {
+	theirs = slices.Clone(p.missing)
+	ours = slices.Clone(p.extra)
+	slices.SortFunc(theirs, bytes.Compare)
+	slices.SortFunc(ours, bytes.Compare)
+	return theirs, ours
+}
+
+// diff records the difference between the local and remote elements of a range, which are both sorted.
+func (p *Peer) diff(local, remote [][]byte) {
+	remote = slices.Clone(remote)
+	slices.SortFunc(remote, bytes.Compare)
+	i, j := 0, 0
+	for i < len(local) || j < len(remote) {
+		switch {
+		case j == len(remote) || i < len(local) && bytes.Compare(local[i], remote[j]) < 0:
+			p.extra = append(p.extra, local[i])
+			i++
+		case i == len(local) || bytes.Compare(local[i], remote[j]) > 0:
+			p.missing = append(p.missing, bytes.Clone(remote[j]))
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+}
+
+func (p *Peer) checkRange(r Range) error {
+	if r.Depth < 0 || r.Depth > 8*len(r.Prefix) || (p.size != 0 && r.Depth > 8*p.size) {
+		return errors.New("invalid range")
+	}
+	return nil
+}
+
+// fingerprint returns the number of elements in the range and the root of a merkle tree over them,
+// or nil for an empty range.
+func (p *Peer) fingerprint(r Range) Fingerprint {
+	items := p.rangeItems(r)
+	f := Fingerprint{Range: r, Count: len(items)}
+	if len(items) > 0 {
+		tree, _ := gomerkletree.BuildMerkleTreeFromHashes(items) // NewPeer rejects empty hashes
+		f.Fingerprint = tree.Root()
+	}
+	return f
+}
+
+// rangeItems returns the sorted elements in the range.
+func (p *Peer) rangeItems(r Range) [][]byte {
+	lo := sort.Search(len(p.items), func(i int) bool {
+		return inRange(p.items[i], r) || comparePrefix(p.items[i], r) > 0
+	})
+	hi := lo + sort.Search(len(p.items)-lo, func(i int) bool {
+		return comparePrefix(p.items[lo+i], r) > 0
+	})
+	return p.items[lo:hi:hi]
+}
+
+// child returns the half of the range whose next bit is bit.
+func child(r Range, bit int) Range {
+	prefix := make([]byte, r.Depth/8+1)
+	copy(prefix, r.Prefix)
+	prefix[r.Depth/8] &= ^(byte(0xff) >> (r.Depth % 8)) // clear the bits after the prefix
+	if bit == 1 {
+		prefix[r.Depth/8] |= 0x80 >> (r.Depth % 8)
+	}
+	return Range{Prefix: prefix, Depth: r.Depth + 1}
+}
+
+func inRange(h []byte, r Range) bool {
+	return comparePrefix(h, r) == 0
+}
+
+// comparePrefix compares the first Depth bits of h with those of the prefix of the range.
+func comparePrefix(h []byte, r Range) int {
+	full := r.Depth / 8
+	if len(h) < full {
+		return bytes.Compare(h, r.Prefix[:len(h)])
+	}
+	if c := bytes.Compare(h[:full], r.Prefix[:full]); c != 0 {
+		return c
+	}
+	if rest := r.Depth % 8; rest != 0 {
+		mask := byte(0xff) << (8 - rest)
+		a, b := h[full]&mask, r.Prefix[full]&mask
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
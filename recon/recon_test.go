@@ -0,0 +1,181 @@
+package recon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func hashes(elements ...string) [][]byte {
+	var hs [][]byte
+	for _, e := range elements {
+		h := sha256.Sum256([]byte(e))
+		hs = append(hs, h[:])
+	}
+	return hs
+}
+
+func elements(prefix string, n int) []string {
+	var es []string
+	for i := range n {
+		es = append(es, fmt.Sprint(prefix, i))
+	}
+	return es
+}
+
+// reconcile runs the protocol between two peers, returning the number of messages sent.
+func reconcile(t *testing.T, a, b *Peer) int {
+	t.Helper()
+	m := a.Start()
+	n := 1
+	for receiver := b; m != nil; n++ {
+		reply, err := receiver.Handle(m)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n > 1000 {
+			t.Fatalf("reconciliation does not end")
+		}
+		m = reply
+		if receiver == a {
+			receiver = b
+		} else {
+			receiver = a
+		}
+	}
+	n-- // the last Handle returned nil
+	if !a.Done() || !b.Done() {
+		t.Fatalf("expected both peers to be done")
+	}
+	return n
+}
+
+func equalHashes(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustNewPeer(t *testing.T, hs [][]byte) *Peer {
+	t.Helper()
+	p, err := NewPeer(hs, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestReconcile(t *testing.T) {
+	common := elements("common", 1000)
+	tests := []struct {
+		name         string
+		onlyA, onlyB []string
+	}{
+		{"Equal", nil, nil},
+		{"OneSided", elements("a", 3), nil},
+		{"BothSides", elements("a", 5), elements("b", 7)},
+		{"Many", elements("a", 100), elements("b", 50)},
+	}
+
+	for _, tt := range tests {
+		a := mustNewPeer(t, hashes(append(common, tt.onlyA...)...))
+		b := mustNewPeer(t, hashes(append(common, tt.onlyB...)...))
+		reconcile(t, a, b)
+
+		onlyA, onlyB := sortedHashes(tt.onlyA), sortedHashes(tt.onlyB)
+		theirs, ours := a.Difference()
+		if !equalHashes(theirs, onlyB) || !equalHashes(ours, onlyA) {
+			t.Errorf("%s: expected a to find %d and %d elements, got %d and %d", tt.name, len(onlyB), len(onlyA), len(theirs), len(ours))
+		}
+		theirs, ours = b.Difference()
+		if !equalHashes(theirs, onlyA) || !equalHashes(ours, onlyB) {
+			t.Errorf("%s: expected b to find %d and %d elements, got %d and %d", tt.name, len(onlyA), len(onlyB), len(theirs), len(ours))
+		}
+	}
+}
+
+func sortedHashes(es []string) [][]byte {
+	p, _ := NewPeer(hashes(es...), 0)
+	return p.items
+}
+
+func TestReconcile_Empty(t *testing.T) {
+	a := mustNewPeer(t, nil)
+	b := mustNewPeer(t, hashes(elements("b", 20)...))
+	reconcile(t, a, b)
+
+	theirs, ours := a.Difference()
+	if len(theirs) != 20 || len(ours) != 0 {
+		t.Errorf("expected 20 and 0 elements, got %d and %d", len(theirs), len(ours))
+	}
+}
+
+func TestReconcile_Rounds(t *testing.T) {
+	// a single difference among many elements takes a number of round trips logarithmic in the size of the set
+	common := elements("common", 1<<14)
+	a := mustNewPeer(t, hashes(append(common, "extra")...))
+	b := mustNewPeer(t, hashes(common...))
+	if n := reconcile(t, a, b); n > 2*16 {
+		t.Errorf("expected at most %d messages, got %d", 2*16, n)
+	}
+	if theirs, _ := b.Difference(); len(theirs) != 1 {
+		t.Errorf("expected 1 element, got %d", len(theirs))
+	}
+}
+
+func TestPeer_Errors(t *testing.T) {
+	if _, err := NewPeer([][]byte{{1, 2}, {1}}, 0); err == nil {
+		t.Errorf("expected error for hashes of different lengths")
+	}
+	if _, err := NewPeer([][]byte{{}}, 0); err == nil {
+		t.Errorf("expected error for empty hash")
+	}
+
+	p := mustNewPeer(t, hashes("a", "b"))
+	if _, err := p.Handle(nil); err == nil {
+		t.Errorf("expected error for nil message")
+	}
+	if _, err := p.Handle(&Message{Fingerprints: []Fingerprint{{Range: Range{Depth: 9, Prefix: []byte{0}}}}}); err == nil {
+		t.Errorf("expected error for invalid range")
+	}
+	outside := &Message{Items: []Items{{Range: Range{Prefix: []byte{0x80}, Depth: 1}, Items: [][]byte{make([]byte, 32)}}}}
+	if _, err := p.Handle(outside); err == nil {
+		t.Errorf("expected error for item outside its range")
+	}
+
+	if _, err := p.Handle(&Message{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.Handle(p.Start()); err == nil {
+		t.Errorf("expected error after the reconciliation is done")
+	}
+}
+
+func TestComparePrefix(t *testing.T) {
+	r := Range{Prefix: []byte{0xa0}, Depth: 3}
+	tests := []struct {
+		h    byte
+		want int
+	}{
+		{0xa0, 0},
+		{0xbf, 0},
+		{0x9f, -1},
+		{0xc0, 1},
+	}
+	for _, tt := range tests {
+		if got := comparePrefix([]byte{tt.h}, r); got != tt.want {
+			t.Errorf("%x: expected %d, got %d", tt.h, tt.want, got)
+		}
+	}
+
+	if c := child(Range{Prefix: []byte{0xff}, Depth: 3}, 0); c.Prefix[0] != 0xe0 {
+		t.Errorf("expected prefix e0, got %x", c.Prefix)
+	}
+}
@@ -0,0 +1,23 @@
+package gomerkletree
+
+// RFC6962HashStrategy hashes leaves and internal nodes as specified by RFC 6962 (Certificate Transparency),
+// i.e. SHA-256(0x00 || leaf) and SHA-256(0x01 || left || right).
+// This is the same scheme the default hash strategy uses.
+type RFC6962HashStrategy struct{}
+
+func (h RFC6962HashStrategy) HashLeaf(l []byte) []byte {
+	return defaultHashStrategy{}.HashLeaf(l)
+}
+
+func (h RFC6962HashStrategy) HashInternal(l, r []byte) []byte {
+	return defaultHashStrategy{}.HashInternal(l, r)
+}
+
+// BuildRFC6962MerkleTree takes a slice of leaves and builds a merkle tree that matches the
+// RFC 6962 Merkle Tree Hash, so roots and proofs interoperate with Certificate Transparency logs.
+// RFC 6962 splits n leaves into a left subtree of the largest power of two smaller than n, and a right subtree
+// of the remaining leaves. Building bottom-up with promotion results in exactly this shape.
+// Returns an error for the same input as BuildMerkleTree.
+func BuildRFC6962MerkleTree(data []Leaf) (*MerkleTree, error) {
+	return BuildMerkleTree(data, WithHashStrategy(RFC6962HashStrategy{}))
+}
@@ -0,0 +1,277 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// The versioned types of the TransItem structure of RFC 9162 (Certificate Transparency 2.0) for proofs.
+const (
+	transItemConsistencyProofV2 = 6
+	transItemInclusionProofV2   = 7
+)
+
+// InclusionProofV2 is the inclusion_proof_v2 structure of RFC 9162, with which CT v2 logs prove that a leaf is in a
+// tree: the hashes in the path are the siblings of the leaf from the bottom up.
+// The log ID is the DER encoding of the OID of the log, without its tag and length.
+type InclusionProofV2 struct {
+	LogID         []byte
+	TreeSize      uint64
+	LeafIndex     uint64
+	InclusionPath [][]byte
+}
+
+// ConsistencyProofV2 is the consistency_proof_v2 structure of RFC 9162, with which CT v2 logs prove that the tree
+// of TreeSize2 leaves extends the tree of TreeSize1 leaves.
+type ConsistencyProofV2 struct {
+	LogID           []byte
+	TreeSize1       uint64
+	TreeSize2       uint64
+	ConsistencyPath [][]byte
+}
+
+// InclusionProofV2 returns the proof of the i-th leaf as an RFC 9162 inclusion proof of the log with the given ID.
+// The tree must have been built with the RFC 6962 hash strategy (or the default one, which is the same) and promotion.
+func (m *MerkleTree) InclusionProofV2(logID []byte, i int) (*InclusionProofV2, error) {
+	if err := m.checkRFC6962(); err != nil {
+		return nil, err
+	}
+	p, err := m.ProofByIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return &InclusionProofV2{
+		LogID:         bytes.Clone(logID),
+		TreeSize:      uint64(len(m.leaves)),
+		LeafIndex:     uint64(i),
+		InclusionPath: p.Siblings(),
+	}, nil
+}
+
+// ConsistencyProofV2 returns the proof that the first newSize leaves extend the first oldSize leaves as an
+// RFC 9162 consistency proof of the log with the given ID, like ConsistencyProof.
+func (m *MerkleTree) ConsistencyProofV2(logID []byte, oldSize, newSize int) (*ConsistencyProofV2, error) {
+	if err := m.checkRFC6962(); err != nil {
+		return nil, err
+	}
+	p, err := m.ConsistencyProof(oldSize, newSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsistencyProofV2{
+		LogID:           bytes.Clone(logID),
+		TreeSize1:       uint64(oldSize),
+		TreeSize2:       uint64(newSize),
+		ConsistencyPath: p.hashes,
+	}, nil
+}
+
+func (m *MerkleTree) checkRFC6962() error {
+	if m == nil {
+		return errors.New("nil tree")
+	}
+	if m.duplicate {
+		return errors.New("tree built with duplication")
+	}
+	switch m.hashStrategy.(type) {
+	case defaultHashStrategy, RFC6962HashStrategy:
+		return nil
+	}
+	return errors.New("tree not built with the RFC 6962 hash strategy")
+}
+
+// Verify checks that the proof proves the leaf under root, following the algorithm of RFC 9162.
+// The leaf is hashed as SHA-256(0x00 || leaf), so it is the encoded entry the log committed to.
+func (p *InclusionProofV2) Verify(leaf, root []byte) error {
+	proof, err := p.Proof(root)
+	if err != nil {
+		return err
+	}
+	return verifyLeafProof(leaf, proof, RFC6962HashStrategy{}, root)
+}
+
+// Proof converts the proof into a Proof for root, which can be verified with VerifyProof.
+func (p *InclusionProofV2) Proof(root []byte) (*Proof, error) {
+	if p == nil {
+		return nil, errors.New("no proof")
+	}
+	return rfc6962Proof(p.LeafIndex, p.TreeSize, p.InclusionPath, root)
+}
+
+// rfc6962Proof returns the Proof of the leaf at index in a tree of size leaves, with the audit path of RFC 6962.
+// The size usually comes from an untrusted peer, so it is bounded before the directions of the path are computed.
+func rfc6962Proof(index, size uint64, path [][]byte, root []byte) (*Proof, error) {
+	if size > maxTreeSize {
+		return nil, errors.New("invalid tree size")
+	}
+	if index >= size {
+		return nil, errors.New("index out of range")
+	}
+	left := pathDirections(int(index), int(size))
+	if len(left) != len(path) {
+		return nil, errors.New("proof not for index")
+	}
+	return NewProof(root, path, left, RFC6962HashStrategy{}), nil
+}
+
+// Verify checks that the proof proves the tree with newRoot extends the tree with oldRoot, like VerifyConsistency.
+func (p *ConsistencyProofV2) Verify(oldRoot, newRoot []byte) error {
+	proof, err := p.ConsistencyProof()
+	if err != nil {
+		return err
+	}
+	return VerifyConsistency(oldRoot, newRoot, proof)
+}
+
+// ConsistencyProof converts the proof into a ConsistencyProof, which can be verified with VerifyConsistency.
+func (p *ConsistencyProofV2) ConsistencyProof() (*ConsistencyProof, error) {
+	if p == nil {
+		return nil, errors.New("no proof")
+	}
+	if p.TreeSize1 == 0 || p.TreeSize1 > p.TreeSize2 || p.TreeSize2 > maxTreeSize {
+		return nil, errors.New("invalid tree sizes")
+	}
+	return NewConsistencyProof(int(p.TreeSize1), int(p.TreeSize2), p.ConsistencyPath, RFC6962HashStrategy{}), nil
+}
+
+// MarshalBinary encodes the proof as a TransItem of RFC 9162, in the presentation language of TLS:
+// versioned type (2 bytes) | log ID length (1 byte) | log ID | tree size (8 bytes) | leaf index (8 bytes) |
+// path length in bytes (2 bytes) | (hash length (1 byte) | hash)..., with integers in big endian.
+// The inclusion path can't be empty, so proofs of trees of a single leaf can't be encoded.
+func (p *InclusionProofV2) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	b, err := appendTransItemHeader(nil, transItemInclusionProofV2, p.LogID)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.BigEndian.AppendUint64(b, p.TreeSize)
+	b = binary.BigEndian.AppendUint64(b, p.LeafIndex)
+	return appendNodeHashes(b, p.InclusionPath, 1)
+}
+
+// UnmarshalBinary decodes a TransItem with an inclusion proof, as encoded by MarshalBinary.
+func (p *InclusionProofV2) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	d := decoder{b: data}
+	logID := readTransItemHeader(&d, transItemInclusionProofV2)
+	size := d.uint64()
+	index := d.uint64()
+	path := readNodeHashes(&d, 1)
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+	*p = InclusionProofV2{LogID: logID, TreeSize: size, LeafIndex: index, InclusionPath: path}
+	return nil
+}
+
+// MarshalBinary encodes the proof as a TransItem of RFC 9162, like InclusionProofV2.MarshalBinary, with the two
+// tree sizes instead of the tree size and leaf index.
+func (p *ConsistencyProofV2) MarshalBinary() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	b, err := appendTransItemHeader(nil, transItemConsistencyProofV2, p.LogID)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.BigEndian.AppendUint64(b, p.TreeSize1)
+	b = binary.BigEndian.AppendUint64(b, p.TreeSize2)
+	return appendNodeHashes(b, p.ConsistencyPath, 0)
+}
+
+// UnmarshalBinary decodes a TransItem with a consistency proof, as encoded by MarshalBinary.
+func (p *ConsistencyProofV2) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	d := decoder{b: data}
+	logID := readTransItemHeader(&d, transItemConsistencyProofV2)
+	size1 := d.uint64()
+	size2 := d.uint64()
+	path := readNodeHashes(&d, 0)
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return errors.New("trailing data")
+	}
+	*p = ConsistencyProofV2{LogID: logID, TreeSize1: size1, TreeSize2: size2, ConsistencyPath: path}
+	return nil
+}
+
+// appendTransItemHeader appends the versioned type and the log ID, which is LogID<2..127> in RFC 9162.
+func appendTransItemHeader(b []byte, versionedType uint16, logID []byte) ([]byte, error) {
+	if len(logID) < 2 || len(logID) > 127 {
+		return nil, errors.New("invalid log ID length")
+	}
+	b = binary.BigEndian.AppendUint16(b, versionedType)
+	b = append(b, byte(len(logID)))
+	return append(b, logID...), nil
+}
+
+func readTransItemHeader(d *decoder, versionedType int) []byte {
+	if t := d.uint16(); d.err == nil && t != versionedType {
+		d.err = errors.New("unexpected versioned type")
+		return nil
+	}
+	n := int(d.byte())
+	if d.err == nil && (n < 2 || n > 127) {
+		d.err = errors.New("invalid log ID length")
+		return nil
+	}
+	return bytes.Clone(d.next(n))
+}
+
+// appendNodeHashes appends a path of hashes, which is NodeHash<floor..2^16-1> with NodeHash<32..2^8-1> in RFC 9162.
+// The floor is 1 for inclusion paths, which can't be empty, and 0 for consistency paths.
+func appendNodeHashes(b []byte, hashes [][]byte, floor int) ([]byte, error) {
+	n := 0
+	for _, h := range hashes {
+		if len(h) < 32 || len(h) > 255 {
+			return nil, errors.New("invalid hash length")
+		}
+		n += 1 + len(h)
+	}
+	if n < floor {
+		return nil, errors.New("path too short")
+	}
+	if n > math.MaxUint16 {
+		return nil, errors.New("path too long")
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(n))
+	for _, h := range hashes {
+		b = append(b, byte(len(h)))
+		b = append(b, h...)
+	}
+	return b, nil
+}
+
+func readNodeHashes(d *decoder, floor int) [][]byte {
+	n := d.uint16()
+	if d.err == nil && n < floor {
+		d.err = errors.New("path too short")
+	}
+	path := decoder{b: d.next(n)}
+	if d.err != nil {
+		return nil
+	}
+	var hashes [][]byte
+	for len(path.b) > 0 && path.err == nil {
+		n := int(path.byte())
+		if path.err == nil && n < 32 {
+			path.err = errors.New("invalid hash length")
+		}
+		hashes = append(hashes, bytes.Clone(path.next(n)))
+	}
+	d.err = path.err
+	return hashes
+}
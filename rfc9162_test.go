@@ -0,0 +1,203 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+var testLogID = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xd6, 0x79, 0x02} // OID 1.3.6.1.4.1.11129.2
+
+func TestRFC9162_InclusionProof(t *testing.T) {
+	data := rfc6962Data(t)
+	for size := 1; size <= len(data); size++ {
+		tree, err := BuildRFC6962MerkleTree(data[:size])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		root := mustDecodeHex(t, rfc6962Roots[size-1])
+		for i := range size {
+			p, err := tree.InclusionProofV2(testLogID, i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.TreeSize != uint64(size) || p.LeafIndex != uint64(i) {
+				t.Errorf("expected size %d and index %d, got %d and %d", size, i, p.TreeSize, p.LeafIndex)
+			}
+			if err := p.Verify(data[i].Bytes(), root); err != nil {
+				t.Errorf("%d/%d: expected valid proof, got %v", i, size, err)
+			}
+			if err := p.Verify([]byte("other"), root); err == nil {
+				t.Errorf("%d/%d: expected error for other leaf", i, size)
+			}
+
+			b, err := p.MarshalBinary()
+			if size == 1 {
+				if err == nil {
+					t.Errorf("expected error for empty inclusion path")
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var decoded InclusionProofV2
+			if err := decoded.UnmarshalBinary(b); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decoded.LogID == nil || len(decoded.InclusionPath) != len(p.InclusionPath) {
+				t.Errorf("expected %+v, got %+v", p, decoded)
+			}
+			if again, _ := decoded.MarshalBinary(); !bytes.Equal(again, b) {
+				t.Errorf("expected %x, got %x", b, again)
+			}
+		}
+	}
+}
+
+func TestRFC9162_InclusionProofErrors(t *testing.T) {
+	data := rfc6962Data(t)
+	tree, err := BuildRFC6962MerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := tree.Root()
+	p, err := tree.InclusionProofV2(testLogID, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wrongIndex := *p
+	wrongIndex.LeafIndex = 2
+	if err := wrongIndex.Verify(data[3].Bytes(), root); err == nil {
+		t.Errorf("expected error for wrong index")
+	}
+	outOfRange := *p
+	outOfRange.LeafIndex = 8
+	if err := outOfRange.Verify(data[3].Bytes(), root); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+	short := *p
+	short.InclusionPath = p.InclusionPath[:2]
+	if err := short.Verify(data[3].Bytes(), root); err == nil {
+		t.Errorf("expected error for short path")
+	}
+	for _, size := range []uint64{math.MaxInt, math.MaxUint64} {
+		huge := InclusionProofV2{LogID: testLogID, TreeSize: size, InclusionPath: p.InclusionPath[:1]}
+		if err := huge.Verify(data[0].Bytes(), root); err == nil {
+			t.Errorf("expected error for tree size %d", size)
+		}
+	}
+
+	dup, err := BuildMerkleTree(data, WithDuplication())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := dup.InclusionProofV2(testLogID, 0); err == nil {
+		t.Errorf("expected error for tree with duplication")
+	}
+	sha3, err := BuildMerkleTree(data, WithHashStrategy(hashing.SHA3Strategy{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := sha3.InclusionProofV2(testLogID, 0); err == nil {
+		t.Errorf("expected error for tree with other hash strategy")
+	}
+}
+
+func TestRFC9162_ConsistencyProof(t *testing.T) {
+	data := rfc6962Data(t)
+	tree, err := BuildRFC6962MerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for oldSize := 1; oldSize <= len(data); oldSize++ {
+		for newSize := oldSize; newSize <= len(data); newSize++ {
+			p, err := tree.ConsistencyProofV2(testLogID, oldSize, newSize)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oldRoot := mustDecodeHex(t, rfc6962Roots[oldSize-1])
+			newRoot := mustDecodeHex(t, rfc6962Roots[newSize-1])
+			if err := p.Verify(oldRoot, newRoot); err != nil {
+				t.Errorf("%d -> %d: expected valid proof, got %v", oldSize, newSize, err)
+			}
+			if oldSize != newSize {
+				if err := p.Verify(newRoot, oldRoot); err == nil {
+					t.Errorf("%d -> %d: expected error for swapped roots", oldSize, newSize)
+				}
+			}
+
+			b, err := p.MarshalBinary()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var decoded ConsistencyProofV2
+			if err := decoded.UnmarshalBinary(b); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decoded.LogID == nil || len(decoded.ConsistencyPath) != len(p.ConsistencyPath) {
+				t.Errorf("expected %+v, got %+v", p, decoded)
+			}
+			if again, _ := decoded.MarshalBinary(); !bytes.Equal(again, b) {
+				t.Errorf("expected %x, got %x", b, again)
+			}
+		}
+	}
+
+	invalid := &ConsistencyProofV2{LogID: testLogID, TreeSize1: 3, TreeSize2: 2}
+	if err := invalid.Verify(tree.Root(), tree.Root()); err == nil {
+		t.Errorf("expected error for invalid tree sizes")
+	}
+}
+
+func TestRFC9162_MarshalBinary(t *testing.T) {
+	h := bytes.Repeat([]byte{0xaa}, 32)
+	p := &InclusionProofV2{LogID: []byte{1, 2}, TreeSize: 2, LeafIndex: 1, InclusionPath: [][]byte{h}}
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "0007" + "020102" + "0000000000000002" + "0000000000000001" + "0021" + "20" + hex.EncodeToString(h)
+	if got := hex.EncodeToString(b); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	if _, err := (&InclusionProofV2{LogID: []byte{1}}).MarshalBinary(); err == nil {
+		t.Errorf("expected error for short log ID")
+	}
+	if _, err := (&InclusionProofV2{LogID: []byte{1, 2}, InclusionPath: [][]byte{{1}}}).MarshalBinary(); err == nil {
+		t.Errorf("expected error for short hash")
+	}
+	if _, err := (&InclusionProofV2{LogID: []byte{1, 2}}).MarshalBinary(); err == nil {
+		t.Errorf("expected error for empty inclusion path")
+	}
+	empty, err := (&ConsistencyProofV2{LogID: []byte{1, 2}}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var e ConsistencyProofV2
+	if err := e.UnmarshalBinary(empty); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var c ConsistencyProofV2
+	if err := c.UnmarshalBinary(b); err == nil {
+		t.Errorf("expected error for inclusion proof decoded as consistency proof")
+	}
+	var decoded InclusionProofV2
+	for _, n := range []int{1, 3, 10, len(b) - 1} {
+		if err := decoded.UnmarshalBinary(b[:n]); err == nil {
+			t.Errorf("expected error for truncated data of length %d", n)
+		}
+	}
+	if err := decoded.UnmarshalBinary(append(b, 0)); err == nil {
+		t.Errorf("expected error for trailing data")
+	}
+	if err := decoded.UnmarshalBinary(mustDecodeHex(t, "0007"+"020102"+"0000000000000001"+"0000000000000000"+"0000")); err == nil {
+		t.Errorf("expected error for empty inclusion path")
+	}
+}
@@ -0,0 +1,153 @@
+package gomerkletree
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// RowIterator iterates over the rows of a query, like *sql.Rows, which implements it.
+type RowIterator interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// Tags of the values of canonical rows.
+const (
+	rowNull = iota
+	rowInt
+	rowUint
+	rowFloat
+	rowBool
+	rowBytes
+	rowTime
+)
+
+// BuildMerkleTreeFromRows builds a merkle tree with a leaf for every row, encoded by CanonicalRow, like
+// BuildMerkleTree. The rows should be ordered by a unique key, so the same table in two databases gives the same
+// tree. Databases can then be compared by exchanging roots, and descending into the ranges of rows whose
+// SubtreeRoots differ, or with Diff if both trees are at hand.
+// The values are scanned into an any, so their types are whatever the driver returns: roots are only comparable
+// between databases read with the same driver. To compare across drivers, scan into typed destinations and build
+// the leaves with CanonicalRow instead.
+// The rows are not closed; *sql.Rows closes itself once Next returns false.
+func BuildMerkleTreeFromRows(rows RowIterator, opts ...Option) (*MerkleTree, error) {
+	if rows == nil {
+		return nil, errors.New("nil rows")
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var data []Leaf
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		b, err := CanonicalRow(values)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, BytesLeaf(b))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return BuildMerkleTree(data, opts...)
+}
+
+// CanonicalRow encodes the values of a row as the number of values (uvarint), followed by a tag (1 byte) and the value
+// for every value. Integers of every size are encoded as 8 bytes in big endian, floats as the bits of a float64,
+// strings and byte slices as a uvarint length and their bytes, and times as the seconds and nanoseconds of the
+// instant in UTC. Values implementing driver.Valuer are encoded as the value they return.
+//
+// Equal values encode the same regardless of their Go type within these groups, but a value of another kind does
+// not: drivers scanning into an any may return e.g. an integer column as int64 or as its text in a []byte.
+// Rows only encode the same across drivers if they are scanned into the same typed destinations.
+func CanonicalRow(values []any) ([]byte, error) {
+	b := binary.AppendUvarint(nil, uint64(len(values)))
+	for _, v := range values {
+		var err error
+		if b, err = appendRowValue(b, v); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendRowValue(b []byte, v any) ([]byte, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = value
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return append(b, rowNull), nil
+	case int:
+		return appendRowInt(b, int64(v)), nil
+	case int8:
+		return appendRowInt(b, int64(v)), nil
+	case int16:
+		return appendRowInt(b, int64(v)), nil
+	case int32:
+		return appendRowInt(b, int64(v)), nil
+	case int64:
+		return appendRowInt(b, v), nil
+	case uint:
+		return appendRowUint(b, uint64(v)), nil
+	case uint8:
+		return appendRowUint(b, uint64(v)), nil
+	case uint16:
+		return appendRowUint(b, uint64(v)), nil
+	case uint32:
+		return appendRowUint(b, uint64(v)), nil
+	case uint64:
+		return appendRowUint(b, v), nil
+	case float32:
+		return binary.BigEndian.AppendUint64(append(b, rowFloat), math.Float64bits(float64(v))), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, rowFloat), math.Float64bits(v)), nil
+	case bool:
+		if v {
+			return append(b, rowBool, 1), nil
+		}
+		return append(b, rowBool, 0), nil
+	case string:
+		b = binary.AppendUvarint(append(b, rowBytes), uint64(len(v)))
+		return append(b, v...), nil
+	case []byte:
+		b = binary.AppendUvarint(append(b, rowBytes), uint64(len(v)))
+		return append(b, v...), nil
+	case time.Time:
+		b = binary.BigEndian.AppendUint64(append(b, rowTime), uint64(v.Unix()))
+		return binary.BigEndian.AppendUint32(b, uint32(v.Nanosecond())), nil
+	}
+	return nil, errors.New("unsupported column type")
+}
+
+func appendRowInt(b []byte, v int64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, rowInt), uint64(v))
+}
+
+// appendRowUint encodes unsigned integers that fit in an int64 like signed ones, so the signedness of a column
+// doesn't change the encoding.
+func appendRowUint(b []byte, v uint64) []byte {
+	if v <= math.MaxInt64 {
+		return appendRowInt(b, int64(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, rowUint), v)
+}
@@ -0,0 +1,170 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// testRows is a RowIterator over rows in memory.
+type testRows struct {
+	columns []string
+	rows    [][]any
+	i       int
+	err     error
+}
+
+func (r *testRows) Columns() ([]string, error) { return r.columns, nil }
+func (r *testRows) Next() bool                 { r.i++; return r.i <= len(r.rows) }
+func (r *testRows) Err() error                 { return r.err }
+
+func (r *testRows) Scan(dest ...any) error {
+	for i, v := range r.rows[r.i-1] {
+		*dest[i].(*any) = v
+	}
+	return nil
+}
+
+// testDriver serves the rows of testDriverRows to any query, so *sql.Rows can be tested without a database.
+type testDriver struct{}
+
+var testDriverRows [][]driver.Value
+
+func init() {
+	sql.Register("gomerkletree-test", testDriver{})
+}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(string) (driver.Stmt, error) { return testStmt{}, nil }
+func (testConn) Close() error                        { return nil }
+func (testConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type testStmt struct{}
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return 0 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("not supported") }
+func (testStmt) Query([]driver.Value) (driver.Rows, error)  { return &testDriverRowsIter{}, nil }
+
+type testDriverRowsIter struct {
+	i int
+}
+
+func (r *testDriverRowsIter) Columns() []string { return []string{"id", "name", "balance"} }
+func (r *testDriverRowsIter) Close() error      { return nil }
+
+func (r *testDriverRowsIter) Next(dest []driver.Value) error {
+	if r.i == len(testDriverRows) {
+		return io.EOF
+	}
+	copy(dest, testDriverRows[r.i])
+	r.i++
+	return nil
+}
+
+func TestBuildMerkleTreeFromRows(t *testing.T) {
+	testDriverRows = [][]driver.Value{
+		{int64(1), []byte("alice"), 10.5},
+		{int64(2), []byte("bob"), nil},
+		{int64(3), []byte("carol"), 0.0},
+	}
+	db, err := sql.Open("gomerkletree-test", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT id, name, balance FROM accounts ORDER BY id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromSQL, err := BuildMerkleTreeFromRows(rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the same table from another database, with other types for the same values
+	other := &testRows{columns: []string{"id", "name", "balance"}, rows: [][]any{
+		{int32(1), "alice", 10.5},
+		{uint8(2), "bob", nil},
+		{3, "carol", float32(0)},
+	}}
+	fromOther, err := BuildMerkleTreeFromRows(other)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(fromSQL.Root(), fromOther.Root()) {
+		t.Errorf("expected the same root for the same rows")
+	}
+
+	changed := &testRows{columns: []string{"id", "name", "balance"}, rows: [][]any{
+		{1, "alice", 10.5},
+		{2, "bob", 0.0},
+		{3, "carol", 0.0},
+	}}
+	fromChanged, err := BuildMerkleTreeFromRows(changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := Diff(fromSQL, fromChanged); len(diff) != 1 || diff[0] != 1 {
+		t.Errorf("expected row 1 to differ, got %v", diff)
+	}
+}
+
+func TestBuildMerkleTreeFromRows_Errors(t *testing.T) {
+	if _, err := BuildMerkleTreeFromRows(nil); err == nil {
+		t.Errorf("expected error for nil rows")
+	}
+	if _, err := BuildMerkleTreeFromRows(&testRows{columns: []string{"id"}}); err == nil {
+		t.Errorf("expected error for no rows")
+	}
+	failing := &testRows{columns: []string{"id"}, rows: [][]any{{1}}, err: errors.New("connection lost")}
+	if _, err := BuildMerkleTreeFromRows(failing); err == nil {
+		t.Errorf("expected error of the rows")
+	}
+	unsupported := &testRows{columns: []string{"id"}, rows: [][]any{{struct{}{}}}}
+	if _, err := BuildMerkleTreeFromRows(unsupported); err == nil {
+		t.Errorf("expected error for unsupported column type")
+	}
+}
+
+func TestCanonicalRow(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	tests := []struct {
+		name string
+		a, b []any
+		same bool
+	}{
+		{"StringBytes", []any{"x"}, []any{[]byte("x")}, true},
+		{"IntSizes", []any{int8(-1)}, []any{int64(-1)}, true},
+		{"Unsigned", []any{uint64(7)}, []any{7}, true},
+		{"LargeUnsigned", []any{uint64(math.MaxUint64)}, []any{int64(-1)}, false},
+		{"TimeZone", []any{at}, []any{at.In(time.FixedZone("X", 3600))}, true},
+		{"NullValuer", []any{sql.NullString{}}, []any{nil}, true},
+		{"Valuer", []any{sql.NullInt64{Int64: 5, Valid: true}}, []any{5}, true},
+		{"NullEmpty", []any{nil}, []any{""}, false},
+		{"Split", []any{"ab", "c"}, []any{"a", "bc"}, false},
+		{"IntFloat", []any{1}, []any{1.0}, false},
+		{"Columns", []any{1}, []any{1, nil}, false},
+	}
+	for _, tt := range tests {
+		a, err := CanonicalRow(tt.a)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := CanonicalRow(tt.b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if bytes.Equal(a, b) != tt.same {
+			t.Errorf("%s: expected same encoding to be %v, got %x and %x", tt.name, tt.same, a, b)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gomerkletree
+
+import "crypto/rand"
+
+// SaltSize is the size of the salts generated by NewSaltedLeaf.
+const SaltSize = 32
+
+// SaltedHashStrategy wraps a hash strategy so that a salt is prepended to every leaf before hashing,
+// i.e. HashLeaf(salt || leaf). Without the salt, leaf hashes in published proofs can't be matched against
+// guesses of low-entropy leaf data such as email addresses. Internal nodes are hashed unchanged.
+type SaltedHashStrategy struct {
+	HashStrategy
+	Salt []byte
+}
+
+func (h SaltedHashStrategy) HashLeaf(l []byte) []byte {
+	bytes := make([]byte, 0, len(h.Salt)+len(l))
+	bytes = append(bytes, h.Salt...)
+	bytes = append(bytes, l...)
+	return h.HashStrategy.HashLeaf(bytes)
+}
+
+// WithLeafSalt mixes a secret per-tree salt into every leaf hash (see SaltedHashStrategy).
+// Proofs can then only be verified by those who know the salt, using VerifyProofWithStrategy.
+// It applies to the hash strategy of the tree, regardless of the order of the options.
+func WithLeafSalt(salt []byte) Option {
+	return func(c *config) {
+		c.salt = append([]byte{}, salt...)
+	}
+}
+
+// SaltedLeaf is a leaf with its own salt, encoded as salt || leaf.
+// Each leaf owner only learns their own salt, so they can verify their leaf without being able to
+// guess the data of other leaves. Use salts of the same size for all leaves of a tree.
+type SaltedLeaf struct {
+	Leaf
+	Salt []byte
+}
+
+// NewSaltedLeaf wraps a leaf with a random salt of SaltSize bytes.
+func NewSaltedLeaf(x Leaf) (SaltedLeaf, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return SaltedLeaf{}, err
+	}
+	return SaltedLeaf{Leaf: x, Salt: salt}, nil
+}
+
+func (l SaltedLeaf) Bytes() []byte {
+	b := l.Leaf.Bytes()
+	bytes := make([]byte, 0, len(l.Salt)+len(b))
+	bytes = append(bytes, l.Salt...)
+	return append(bytes, b...)
+}
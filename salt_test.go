@@ -0,0 +1,80 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_WithLeafSalt(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"alice@example.com"})
+	data = append(data, &TestLeaf{"bob@example.com"})
+	data = append(data, &TestLeaf{"carol@example.com"})
+
+	salt := []byte("secret")
+	tree := mustBuildMerkleTree(t, data, WithLeafSalt(salt), WithSortedPairs())
+	unsalted := mustBuildMerkleTree(t, data, WithSortedPairs())
+
+	if bytes.Equal(tree.Root(), unsalted.Root()) {
+		t.Errorf("expected salt to change the root")
+	}
+
+	expected := hashStrategy.HashLeaf([]byte("secretalice@example.com"))
+	if !bytes.Equal(tree.LeafHash(0), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.LeafHash(0))
+	}
+
+	proof, err := tree.Proof(data[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(data[0], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// without the salt, the leaf can't be verified
+	if err := VerifyProofWithStrategy(data[0], proof, hashStrategy, tree.Root()); err == nil {
+		t.Errorf("expected error")
+	}
+
+	strategy := SortedPairHashStrategy{SaltedHashStrategy{hashStrategy, salt}}
+	if err := VerifyProofWithStrategy(data[0], proof, strategy, tree.Root()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTree_SaltedLeaf(t *testing.T) {
+	var data []Leaf
+	for _, s := range []string{"a", "b", "c"} {
+		leaf, err := NewSaltedLeaf(&TestLeaf{s})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(leaf.Salt) != SaltSize {
+			t.Fatalf("expected salt of %d bytes, got %d", SaltSize, len(leaf.Salt))
+		}
+		data = append(data, leaf)
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	proof, err := tree.Proof(data[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(data[1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the same data with a different salt is not in the tree
+	other, err := NewSaltedLeaf(&TestLeaf{"b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyProof(other, proof); err == nil {
+		t.Errorf("expected error")
+	}
+}
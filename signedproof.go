@@ -0,0 +1,217 @@
+package gomerkletree
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"time"
+)
+
+const signedProofEncodingVersion = 1
+
+// SignedProof bundles an inclusion proof with the size of the tree, a timestamp, and the signature of the owner of the
+// tree over its tree head, so a verifier that only knows the public key of the owner can check it on its own.
+//
+// The signature is over TreeHead.Bytes of the head with the root of the proof. With an Ed25519 key, it is the
+// signature SignTreeHead makes.
+type SignedProof struct {
+	Proof     *Proof
+	Size      int
+	Timestamp time.Time
+	Signature []byte
+}
+
+// SignProof signs the tree head of a proof of a tree with size leaves, at the current time, with millisecond precision.
+// Ed25519 keys sign the tree head itself; other keys, like ECDSA and RSA (PKCS #1 v1.5) keys, sign its SHA-256 digest.
+func SignProof(p *Proof, size int, signer crypto.Signer) (*SignedProof, error) {
+	if p == nil {
+		return nil, errors.New("nil proof")
+	}
+	if signer == nil {
+		return nil, errors.New("nil signer")
+	}
+	if size <= 0 || len(p.siblings) > bits.Len(uint(size-1)) {
+		return nil, errors.New("invalid tree size")
+	}
+	sp := &SignedProof{
+		Proof:     NewProof(p.root, p.siblings, p.left, p.hashStrategy),
+		Size:      size,
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	message, opts := signedMessage(sp.TreeHead(), signer.Public())
+	signature, err := signer.Sign(rand.Reader, message, opts)
+	if err != nil {
+		return nil, err
+	}
+	sp.Signature = signature
+	return sp, nil
+}
+
+// SignedProof generates a proof for a leaf, like Proof, and signs it with the size of the tree (see SignProof).
+func (m *MerkleTree) SignedProof(x Leaf, signer crypto.Signer) (*SignedProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	p, err := m.Proof(x)
+	if err != nil {
+		return nil, err
+	}
+	return SignProof(p, len(m.leaves), signer)
+}
+
+// TreeHead returns the tree head that is signed.
+func (sp *SignedProof) TreeHead() TreeHead {
+	return TreeHead{
+		Size:      sp.Size,
+		Root:      sp.Proof.Root(),
+		Timestamp: sp.Timestamp,
+	}
+}
+
+// signedMessage returns what a key signs for a tree head, and the options to sign it with.
+func signedMessage(h TreeHead, key crypto.PublicKey) ([]byte, crypto.SignerOpts) {
+	if _, ok := key.(ed25519.PublicKey); ok {
+		return h.Bytes(), crypto.Hash(0)
+	}
+	digest := sha256.Sum256(h.Bytes())
+	return digest[:], crypto.SHA256
+}
+
+// VerifySignedProof checks if the signature of a signed proof is valid for the public key of the owner of the tree,
+// and if the proof is valid for the leaf under the signed root. Ed25519, ECDSA and RSA (PKCS #1 v1.5) keys are supported.
+func VerifySignedProof(x Leaf, sp *SignedProof, key crypto.PublicKey) error {
+	if sp == nil || sp.Proof == nil {
+		return errors.New("nil proof")
+	}
+	if sp.Size <= 0 || len(sp.Proof.siblings) > bits.Len(uint(sp.Size-1)) {
+		return errors.New("invalid tree size")
+	}
+
+	message, _ := signedMessage(sp.TreeHead(), key)
+	valid := false
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		valid = len(key) == ed25519.PublicKeySize && ed25519.Verify(key, message, sp.Signature)
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, message, sp.Signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, message, sp.Signature) == nil
+	default:
+		return errors.New("unsupported public key")
+	}
+	if !valid {
+		return errors.New("invalid signature")
+	}
+	return VerifyProofAgainstRoot(x, sp.Proof, sp.Proof.root)
+}
+
+// MarshalBinary encodes the signed proof as
+// version (1 byte) | size (8 bytes) | timestamp (8 bytes) | signature length (uvarint) | signature | proof,
+// with the timestamp in milliseconds since the Unix epoch, integers in big endian, and the proof encoded by
+// Proof.MarshalBinary. The hash strategy is not part of the encoding.
+func (sp *SignedProof) MarshalBinary() ([]byte, error) {
+	if sp == nil || sp.Proof == nil {
+		return nil, errors.New("nil proof")
+	}
+	proof, err := sp.Proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b := []byte{signedProofEncodingVersion}
+	b = binary.BigEndian.AppendUint64(b, uint64(sp.Size))
+	b = binary.BigEndian.AppendUint64(b, uint64(sp.Timestamp.UnixMilli()))
+	b = appendBytes(b, sp.Signature)
+	return append(b, proof...), nil
+}
+
+// UnmarshalBinary decodes a signed proof encoded by MarshalBinary.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (sp *SignedProof) UnmarshalBinary(data []byte) error {
+	if sp == nil {
+		return errors.New("nil proof")
+	}
+
+	d := decoder{b: data}
+	if version := d.byte(); d.err == nil && version != signedProofEncodingVersion {
+		return errors.New("unsupported encoding version")
+	}
+	size := d.uint64()
+	timestamp := d.uint64()
+	signature := d.bytes()
+	if d.err != nil {
+		return d.err
+	}
+	if size > maxTreeSize {
+		return errors.New("invalid tree size")
+	}
+	p := &Proof{}
+	if err := p.UnmarshalBinary(d.b); err != nil {
+		return err
+	}
+
+	*sp = SignedProof{
+		Proof:     p,
+		Size:      int(size),
+		Timestamp: time.UnixMilli(int64(timestamp)).UTC(),
+		Signature: signature,
+	}
+	return nil
+}
+
+type signedProofJSON struct {
+	Proof     *Proof `json:"proof"`
+	Size      int    `json:"size"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+// MarshalJSON encodes the signed proof as {"proof": {...}, "size": ..., "timestamp": ..., "signature": "..."},
+// with the proof encoded by Proof.MarshalJSON, the timestamp in milliseconds since the Unix epoch, and a hex-encoded
+// signature. The hash strategy is not part of the encoding.
+func (sp *SignedProof) MarshalJSON() ([]byte, error) {
+	if sp == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(signedProofJSON{
+		Proof:     sp.Proof,
+		Size:      sp.Size,
+		Timestamp: sp.Timestamp.UnixMilli(),
+		Signature: hex.EncodeToString(sp.Signature),
+	})
+}
+
+// UnmarshalJSON decodes a signed proof encoded by MarshalJSON.
+// Since the hash strategy cannot be encoded, the decoded proof uses the default hash strategy.
+func (sp *SignedProof) UnmarshalJSON(data []byte) error {
+	if sp == nil {
+		return errors.New("nil proof")
+	}
+
+	var v signedProofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Proof == nil {
+		return errors.New("nil proof")
+	}
+	signature, err := hex.DecodeString(v.Signature)
+	if err != nil {
+		return err
+	}
+
+	*sp = SignedProof{
+		Proof:     v.Proof,
+		Size:      v.Size,
+		Timestamp: time.UnixMilli(v.Timestamp).UTC(),
+		Signature: signature,
+	}
+	return nil
+}
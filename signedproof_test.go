@@ -0,0 +1,139 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+)
+
+func TestSignedProof(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}, &TestLeaf{"e"}}
+	tree := mustBuildMerkleTree(t, data)
+	for _, signer := range []crypto.Signer{edKey, ecKey, rsaKey} {
+		sp, err := tree.SignedProof(data[2], signer)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sp.Size != 5 || !bytes.Equal(sp.TreeHead().Root, tree.Root()) {
+			t.Errorf("expected the head of the tree")
+		}
+		if err := VerifySignedProof(data[2], sp, signer.Public()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifySignedProof(data[3], sp, signer.Public()); err == nil {
+			t.Errorf("expected error for another leaf")
+		}
+
+		tampered := *sp
+		tampered.Size = 6
+		if err := VerifySignedProof(data[2], &tampered, signer.Public()); err == nil {
+			t.Errorf("expected error for tampered size")
+		}
+	}
+
+	sp, err := tree.SignedProof(data[0], edKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// an Ed25519 signature is the signature of the signed tree head
+	sth, err := SignTreeHead(sp.TreeHead(), edKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(sth.Signature, sp.Signature) {
+		t.Errorf("expected the signature of SignTreeHead")
+	}
+	if err := VerifySignedProof(data[0], sp, ecKey.Public()); err == nil {
+		t.Errorf("expected error for another key")
+	}
+	if err := VerifySignedProof(data[0], sp, "key"); err == nil {
+		t.Errorf("expected error for unsupported key")
+	}
+}
+
+func TestSignedProof_Encoding(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	sp, err := mustBuildMerkleTree(t, data).SignedProof(data[1], key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := sp.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromBinary SignedProof
+	if err := fromBinary.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fromBinary.Timestamp.Equal(sp.Timestamp) {
+		t.Errorf("expected timestamp %v, got %v", sp.Timestamp, fromBinary.Timestamp)
+	}
+	if err := VerifySignedProof(data[1], &fromBinary, key.Public()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for i := range b {
+		if err := new(SignedProof).UnmarshalBinary(b[:i]); err == nil {
+			t.Errorf("expected error for truncated data of length %d", i)
+		}
+	}
+
+	j, err := json.Marshal(sp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromJSON SignedProof
+	if err := json.Unmarshal(j, &fromJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifySignedProof(data[1], &fromJSON, key.Public()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"size": 3}`), &fromJSON); err == nil {
+		t.Errorf("expected error for missing proof")
+	}
+}
+
+func TestSignProof_Errors(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	p, err := mustBuildMerkleTree(t, data).ProofByIndex(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := SignProof(p, 1, key); err == nil {
+		t.Errorf("expected error for a tree too small for the proof")
+	}
+	if _, err := SignProof(nil, 3, key); err == nil {
+		t.Errorf("expected error for nil proof")
+	}
+	if _, err := SignProof(p, 3, nil); err == nil {
+		t.Errorf("expected error for nil signer")
+	}
+}
@@ -0,0 +1,124 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+)
+
+// Snapshot is an immutable view of a tree as it was when the snapshot was taken.
+// Snapshots share their nodes with the tree, which never modifies a node after creating it
+// (appends and updates replace the affected nodes instead), so taking a snapshot is O(1).
+type Snapshot struct {
+	root         *Node
+	size         int
+	hashStrategy HashStrategy
+	duplicate    bool
+}
+
+// Snapshot returns an immutable view of the tree at its current root.
+func (m *MerkleTree) Snapshot() *Snapshot {
+	if m == nil || m.root == nil {
+		return nil
+	}
+	return &Snapshot{
+		root:         m.root,
+		size:         len(m.leaves),
+		hashStrategy: m.hashStrategy,
+		duplicate:    m.duplicate,
+	}
+}
+
+// Root returns the bytes of the root.
+func (s *Snapshot) Root() []byte {
+	if s == nil {
+		return nil
+	}
+	return bytes.Clone(s.root.h)
+}
+
+// Size returns the number of leaves in the snapshot.
+func (s *Snapshot) Size() int {
+	if s == nil {
+		return 0
+	}
+	return s.size
+}
+
+// Verify verifies the integrity of the snapshot.
+func (s *Snapshot) Verify() bool {
+	return s != nil && s.hashStrategy != nil && s.root.verify(s.hashStrategy)
+}
+
+// ProofByIndex generates a proof for the i-th leaf in O(log n).
+func (s *Snapshot) ProofByIndex(i int) (*Proof, error) {
+	if s == nil {
+		return nil, errors.New("nil snapshot")
+	}
+	if i < 0 || i >= s.size {
+		return nil, errors.New("index out of range")
+	}
+
+	_, siblings, left := s.descend(i)
+	return &Proof{
+		root:         s.Root(),
+		siblings:     cloneHashes(siblings),
+		left:         left,
+		hashStrategy: s.hashStrategy,
+	}, nil
+}
+
+// Proof generates a proof for a given leaf, finding the leaf with a linear scan over the leaves.
+func (s *Snapshot) Proof(x Leaf) (*Proof, error) {
+	if s == nil {
+		return nil, errors.New("nil snapshot")
+	}
+
+	b, err := leafBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	hash := s.hashStrategy.HashLeaf(b)
+	for i := range s.size {
+		if leaf, _, _ := s.descend(i); hashEqual(leaf.h, hash) {
+			return s.ProofByIndex(i)
+		}
+	}
+	return nil, errors.New("not in tree")
+}
+
+// descend walks from the root to the i-th leaf without using parent pointers (which aren't shared),
+// returning the leaf and the siblings along the way, ordered from the leaf up to the root.
+func (s *Snapshot) descend(i int) (*Node, [][]byte, []bool) {
+	var siblings [][]byte
+	var left []bool
+
+	node, lo, width := s.root, 0, s.size
+	if s.duplicate {
+		// with duplication every level is padded, so subtrees always split in half
+		width = 1
+		for width < s.size {
+			width *= 2
+		}
+	}
+
+	for node.left != nil {
+		k := width / 2
+		if !s.duplicate {
+			k = split(width)
+		}
+		if i < lo+k {
+			siblings = append(siblings, node.right.h)
+			left = append(left, false)
+			node, width = node.left, k
+		} else {
+			siblings = append(siblings, node.left.h)
+			left = append(left, true)
+			node, lo, width = node.right, lo+k, width-k
+		}
+	}
+
+	slices.Reverse(siblings)
+	slices.Reverse(left)
+	return node, siblings, left
+}
@@ -0,0 +1,77 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_Snapshot(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithDuplication()}} {
+		var data []Leaf
+		for i := range 11 {
+			data = append(data, &TestLeaf{string(rune('a' + i))})
+		}
+
+		tree := mustBuildMerkleTree(t, data, opts...)
+		snapshot := tree.Snapshot()
+		root := bytes.Clone(tree.Root())
+
+		// keep changing the tree
+		for i := range 7 {
+			tree.Append(&TestLeaf{string(rune('A' + i))})
+		}
+		tree.Update(0, &TestLeaf{"z"})
+		tree.Update(10, &TestLeaf{"y"})
+
+		if !tree.Verify() {
+			t.Fatalf("couldn't verify tree")
+		}
+
+		if !bytes.Equal(snapshot.Root(), root) {
+			t.Fatalf("snapshot root changed")
+		}
+
+		if snapshot.Size() != len(data) {
+			t.Errorf("expected size=%d, got %d", len(data), snapshot.Size())
+		}
+
+		if !snapshot.Verify() {
+			t.Fatalf("couldn't verify snapshot")
+		}
+
+		expected := mustBuildMerkleTree(t, data, opts...)
+		for i, x := range data {
+			proof, err := snapshot.ProofByIndex(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expectedProof, _ := expected.ProofByIndex(i)
+			if len(proof.siblings) != len(expectedProof.siblings) {
+				t.Fatalf("expected %d siblings, got %d", len(expectedProof.siblings), len(proof.siblings))
+			}
+
+			for j := range proof.siblings {
+				if !bytes.Equal(proof.siblings[j], expectedProof.siblings[j]) || proof.left[j] != expectedProof.left[j] {
+					t.Errorf("sibling %d of leaf %d not correct", j, i)
+				}
+			}
+
+			if err := VerifyProofAgainstRoot(x, proof, root); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		if _, err := snapshot.Proof(data[10]); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if _, err := snapshot.Proof(&TestLeaf{"y"}); err == nil {
+			t.Errorf("expected err, got nil")
+		}
+
+		if _, err := snapshot.ProofByIndex(len(data)); err == nil {
+			t.Errorf("expected err, got nil")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package gomerkletree
+
+import "bytes"
+
+// SortedPairHashStrategy wraps a hash strategy so that the children of every internal node are sorted before hashing,
+// which makes hashing internal nodes commutative. This is the scheme OpenZeppelin's `MerkleProof.verify` expects,
+// where proofs consist of sibling hashes only and their directions don't matter.
+type SortedPairHashStrategy struct {
+	HashStrategy
+}
+
+func (h SortedPairHashStrategy) HashInternal(l, r []byte) []byte {
+	if bytes.Compare(l, r) > 0 {
+		l, r = r, l
+	}
+	return h.HashStrategy.HashInternal(l, r)
+}
+
+// WithSortedPairs sorts the children of every internal node before hashing them (see SortedPairHashStrategy).
+// It applies to the hash strategy of the tree, regardless of the order of the options.
+func WithSortedPairs() Option {
+	return func(c *config) {
+		c.sortPairs = true
+	}
+}
+
+// VerifySortedPairProof checks if a proof is valid for a given leaf in a tree built with sorted pairs,
+// ignoring the directions of the siblings like OpenZeppelin does.
+func VerifySortedPairProof(x Leaf, p *Proof) error {
+	if p == nil || p.hashStrategy == nil {
+		return VerifyProof(x, p)
+	}
+	strategy, ok := p.hashStrategy.(SortedPairHashStrategy)
+	if !ok {
+		strategy = SortedPairHashStrategy{p.hashStrategy}
+	}
+	return VerifyProof(x, &Proof{
+		root:         p.root,
+		siblings:     p.siblings,
+		left:         make([]bool, len(p.siblings)),
+		hashStrategy: strategy,
+	})
+}
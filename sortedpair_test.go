@@ -0,0 +1,85 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSortedPairs_Build(t *testing.T) {
+	var data []Leaf
+	data = append(data, &TestLeaf{"a"})
+	data = append(data, &TestLeaf{"b"})
+	data = append(data, &TestLeaf{"c"})
+
+	tree := mustBuildMerkleTree(t, data, WithSortedPairs())
+
+	leftLeft := hashStrategy.HashLeaf(data[0].Bytes())
+	leftRight := hashStrategy.HashLeaf(data[1].Bytes())
+	right := hashStrategy.HashLeaf(data[2].Bytes())
+
+	sortedHash := func(l, r []byte) []byte {
+		if bytes.Compare(l, r) > 0 {
+			return hashStrategy.HashInternal(r, l)
+		}
+		return hashStrategy.HashInternal(l, r)
+	}
+	root := sortedHash(sortedHash(leftLeft, leftRight), right)
+
+	if !bytes.Equal(tree.Root(), root) {
+		t.Errorf("root not correct")
+	}
+
+	if !tree.Verify() {
+		t.Fatalf("couldn't verify tree")
+	}
+
+	// reordering siblings doesn't change the root
+	reordered := mustBuildMerkleTree(t, []Leaf{data[1], data[0], data[2]}, WithSortedPairs())
+	if !bytes.Equal(tree.Root(), reordered.Root()) {
+		t.Errorf("expected same root for swapped siblings")
+	}
+
+	// option order doesn't matter
+	other := mustBuildMerkleTree(t, data, WithSortedPairs(), WithHashStrategy(defaultHashStrategy{}))
+	if !bytes.Equal(tree.Root(), other.Root()) {
+		t.Errorf("expected same root regardless of option order")
+	}
+}
+
+func TestSortedPairs_Proof(t *testing.T) {
+	var data []Leaf
+	for i := range 9 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	tree := mustBuildMerkleTree(t, data, WithSortedPairs())
+
+	for _, x := range data {
+		proof, err := tree.Proof(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := VerifyProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		// directions are irrelevant
+		for i := range proof.left {
+			proof.left[i] = !proof.left[i]
+		}
+
+		if err := VerifySortedPairProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	proof, _ := tree.Proof(data[0])
+	if err := VerifySortedPairProof(data[1], proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if err := VerifySortedPairProof(data[0], nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
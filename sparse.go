@@ -0,0 +1,445 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// SparseKeySize is the size of the keys of a SparseMerkleTree, which has a leaf for every one of the 2^256 keys.
+const SparseKeySize = 32
+
+const sparseDepth = 8 * SparseKeySize
+
+// SparseMerkleTree is a sparse merkle tree: a perfect tree with a leaf for every possible 256-bit key, where the leaf
+// of a key with a value is HashLeaf(key || value), and the leaves of all other keys are empty (HashLeaf of nothing).
+// The path to the leaf of a key follows the bits of the key, from the most significant bit at the root.
+// It commits to a key-value map, and proves both the value of a key and that a key has no value.
+//
+// All empty subtrees at the same depth have the same root, which is computed once, so only non-empty subtrees
+// are materialized. Chains of nodes with a single non-empty child are collapsed into one node,
+// so a tree with n keys holds less than 2n nodes. Updates still hash all 256 levels of the path.
+type SparseMerkleTree struct {
+	root         *sparseNode
+	n            int
+	defaults     [][]byte // defaults[d] is the root of an empty subtree whose root is at depth d
+	hashStrategy HashStrategy
+}
+
+// sparseNode is the root of a non-empty subtree, collapsed with the chain of nodes with a single non-empty child above it.
+// The chain runs from depth top down to depth bottom, which is the depth of the leaves for a leaf,
+// or the depth at which the paths to the keys of the subtree split.
+type sparseNode struct {
+	top, bottom int
+	key         []byte      // the key of the leaf, or the key of any leaf in the subtree
+	value       []byte      // nil for splits
+	left, right *sparseNode // nil for leaves
+	h           []byte      // the root of the subtree at depth bottom
+	topHash     []byte      // the root of the subtree at depth top
+}
+
+// SparseProof proves the value of a key in a sparse merkle tree, or that the key has no value.
+// Siblings that are roots of empty subtrees are left out, a bitmap marks the depths of the siblings that are included.
+type SparseProof struct {
+	root         []byte
+	bitmap       []byte   // bit d is set if the sibling of the child at depth d+1 on the path is included
+	siblings     [][]byte // from the leaf up
+	hashStrategy HashStrategy
+}
+
+// NewSparseProof assembles a sparse proof from its contents, e.g. after receiving them over the network.
+// A nil hash strategy means the default hash strategy.
+func NewSparseProof(root, bitmap []byte, siblings [][]byte, hash HashStrategy) *SparseProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	return &SparseProof{
+		root:         bytes.Clone(root),
+		bitmap:       bytes.Clone(bitmap),
+		siblings:     cloneHashes(siblings),
+		hashStrategy: hash,
+	}
+}
+
+// Root returns a copy of the root the proof was generated for.
+func (p *SparseProof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Bitmap returns a copy of the bitmap of the included siblings: bit d, counted from the most significant bit of the
+// first byte, is set if the sibling of the node at depth d+1 on the path is included.
+func (p *SparseProof) Bitmap() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.bitmap)
+}
+
+// Siblings returns a copy of the included siblings, ordered from the leaf up to the root.
+func (p *SparseProof) Siblings() [][]byte {
+	if p == nil {
+		return nil
+	}
+	return cloneHashes(p.siblings)
+}
+
+// NewSparseMerkleTree returns an empty sparse merkle tree, using the hash strategy set by the options.
+// Options that change the shape of the tree are ignored.
+func NewSparseMerkleTree(opts ...Option) *SparseMerkleTree {
+	cfg := newConfig(opts)
+	defaults := make([][]byte, sparseDepth+1)
+	defaults[sparseDepth] = cfg.hashStrategy.HashLeaf(nil)
+	for d := sparseDepth - 1; d >= 0; d-- {
+		defaults[d] = cfg.hashStrategy.HashInternal(defaults[d+1], defaults[d+1])
+	}
+	return &SparseMerkleTree{
+		defaults:     defaults,
+		hashStrategy: cfg.hashStrategy,
+	}
+}
+
+// Root returns the bytes of the root. The root of an empty tree is the root of an empty subtree at depth 0.
+func (t *SparseMerkleTree) Root() []byte {
+	if t == nil {
+		return nil
+	}
+	if t.root == nil {
+		return bytes.Clone(t.defaults[0])
+	}
+	return bytes.Clone(t.root.topHash)
+}
+
+// Len returns the number of keys with a value.
+func (t *SparseMerkleTree) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.n
+}
+
+// Get returns a copy of the value of a key, and whether the key has a value.
+func (t *SparseMerkleTree) Get(key []byte) ([]byte, bool) {
+	if t == nil || len(key) != SparseKeySize {
+		return nil, false
+	}
+	for n := t.root; n != nil; n = n.child(key) {
+		if sparseSplit(key, n.key, n.top, n.bottom) < n.bottom {
+			return nil, false
+		}
+		if n.left == nil {
+			return bytes.Clone(n.value), true
+		}
+	}
+	return nil, false
+}
+
+// Update sets the value of a key in O(log n) nodes, rehashing the 256 levels of its path.
+// The value can be empty, but not nil; use Delete to remove a key.
+func (t *SparseMerkleTree) Update(key, value []byte) error {
+	if t == nil {
+		return errors.New("nil tree")
+	}
+	if len(key) != SparseKeySize {
+		return errors.New("invalid key size")
+	}
+	if value == nil {
+		return errors.New("nil value")
+	}
+	var added bool
+	t.root, added = t.insert(t.root, 0, bytes.Clone(key), bytes.Clone(value))
+	if added {
+		t.n++
+	}
+	return nil
+}
+
+// Delete removes the value of a key, if it has one.
+func (t *SparseMerkleTree) Delete(key []byte) error {
+	if t == nil {
+		return errors.New("nil tree")
+	}
+	if len(key) != SparseKeySize {
+		return errors.New("invalid key size")
+	}
+	var removed bool
+	t.root, removed = t.remove(t.root, key)
+	if removed {
+		t.n--
+	}
+	return nil
+}
+
+// Proof generates a proof for a key: of its value if it has one, or that it has no value otherwise.
+func (t *SparseMerkleTree) Proof(key []byte) (*SparseProof, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	if len(key) != SparseKeySize {
+		return nil, errors.New("invalid key size")
+	}
+
+	bitmap := make([]byte, SparseKeySize)
+	var siblings [][]byte // from the root down
+	add := func(d int, h []byte) {
+		bitmap[d/8] |= 0x80 >> (d % 8)
+		siblings = append(siblings, h)
+	}
+
+	for n := t.root; n != nil; n = n.child(key) {
+		if d := sparseSplit(key, n.key, n.top, n.bottom); d < n.bottom {
+			// the path to the key leaves the subtree at depth d, so the whole subtree is the sibling there
+			add(d, t.lift(n.h, n.key, n.bottom, d+1))
+			break
+		}
+		if n.left == nil {
+			break // the leaf of the key
+		}
+		if sparseBit(key, n.bottom) == 0 {
+			add(n.bottom, n.right.topHash)
+		} else {
+			add(n.bottom, n.left.topHash)
+		}
+	}
+
+	p := &SparseProof{
+		root:         t.Root(),
+		bitmap:       bitmap,
+		siblings:     make([][]byte, len(siblings)),
+		hashStrategy: t.hashStrategy,
+	}
+	for i, h := range siblings {
+		p.siblings[len(siblings)-1-i] = bytes.Clone(h)
+	}
+	return p, nil
+}
+
+// VerifySparseProof checks if a proof is valid for a key and its value, or for a key without a value if value is nil.
+func VerifySparseProof(key, value []byte, p *SparseProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifySparseProof(key, value, p, p.root)
+}
+
+// VerifySparseProofAgainstRoot checks if a proof is valid for a key and its value like VerifySparseProof, under a root
+// the verifier already trusts. The root stored in the proof is ignored.
+func VerifySparseProofAgainstRoot(key, value []byte, p *SparseProof, root []byte) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifySparseProof(key, value, p, root)
+}
+
+func verifySparseProof(key, value []byte, p *SparseProof, root []byte) error {
+	if len(key) != SparseKeySize {
+		return errors.New("invalid key size")
+	}
+	if len(p.bitmap) != SparseKeySize {
+		return errors.New("invalid bitmap")
+	}
+
+	// the roots of empty subtrees are computed along the way, def is the root of an empty subtree at depth d+1
+	def := p.hashStrategy.HashLeaf(nil)
+	hash := def
+	if value != nil {
+		hash = p.hashStrategy.HashLeaf(append(bytes.Clone(key), value...))
+	}
+
+	var i int
+	for d := sparseDepth - 1; d >= 0; d-- {
+		sibling := def
+		if p.bitmap[d/8]&(0x80>>(d%8)) != 0 {
+			if i == len(p.siblings) {
+				return errors.New("not enough hashes")
+			}
+			sibling = p.siblings[i]
+			i++
+		}
+		if sparseBit(key, d) == 0 {
+			hash = p.hashStrategy.HashInternal(hash, sibling)
+		} else {
+			hash = p.hashStrategy.HashInternal(sibling, hash)
+		}
+		def = p.hashStrategy.HashInternal(def, def)
+	}
+	if i != len(p.siblings) {
+		return errors.New("too many hashes")
+	}
+
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the keys and values of the tree in key order, as
+//
+//	count (uvarint) | count * (key | value length (uvarint) | value)
+//
+// The nodes are not part of the encoding, DecodeSparseMerkleTree rebuilds them.
+func (t *SparseMerkleTree) MarshalBinary() ([]byte, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	b := binary.AppendUvarint(nil, uint64(t.n))
+	var walk func(n *sparseNode)
+	walk = func(n *sparseNode) {
+		if n == nil {
+			return
+		}
+		if n.left == nil {
+			b = append(b, n.key...)
+			b = appendBytes(b, n.value)
+			return
+		}
+		walk(n.left)
+		walk(n.right)
+	}
+	walk(t.root)
+	return b, nil
+}
+
+// DecodeSparseMerkleTree decodes a tree encoded by MarshalBinary, using the hash strategy set by the options.
+func DecodeSparseMerkleTree(data []byte, opts ...Option) (*SparseMerkleTree, error) {
+	t := NewSparseMerkleTree(opts...)
+	d := &decoder{b: data}
+
+	var prev []byte
+	for range d.length() {
+		key := d.next(SparseKeySize)
+		value := d.bytes()
+		if d.err != nil {
+			return nil, d.err
+		}
+		if value == nil {
+			value = []byte{}
+		}
+		if prev != nil && bytes.Compare(prev, key) >= 0 {
+			return nil, errors.New("keys not sorted")
+		}
+		prev = key
+		if err := t.Update(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.b) != 0 {
+		return nil, errors.New("trailing data")
+	}
+	return t, nil
+}
+
+func (t *SparseMerkleTree) insert(n *sparseNode, top int, key, value []byte) (*sparseNode, bool) {
+	if n == nil {
+		leaf := &sparseNode{top: top, bottom: sparseDepth, key: key, value: value}
+		t.rehash(leaf)
+		return leaf, true
+	}
+
+	if d := sparseSplit(key, n.key, top, n.bottom); d < n.bottom {
+		// the paths split above the bottom of the node, so a new split is inserted at depth d
+		leaf := &sparseNode{top: d + 1, bottom: sparseDepth, key: key, value: value}
+		t.rehash(leaf)
+		n.top = d + 1
+		n.topHash = t.lift(n.h, n.key, n.bottom, n.top)
+
+		split := &sparseNode{top: top, bottom: d, key: n.key, left: n, right: leaf}
+		if sparseBit(key, d) == 0 {
+			split.left, split.right = leaf, n
+		}
+		t.rehash(split)
+		return split, true
+	}
+
+	if n.left == nil {
+		n.value = value
+		t.rehash(n)
+		return n, false
+	}
+
+	var added bool
+	if sparseBit(key, n.bottom) == 0 {
+		n.left, added = t.insert(n.left, n.bottom+1, key, value)
+	} else {
+		n.right, added = t.insert(n.right, n.bottom+1, key, value)
+	}
+	t.rehash(n)
+	return n, added
+}
+
+func (t *SparseMerkleTree) remove(n *sparseNode, key []byte) (*sparseNode, bool) {
+	if n == nil || sparseSplit(key, n.key, n.top, n.bottom) < n.bottom {
+		return n, false
+	}
+	if n.left == nil {
+		return nil, true
+	}
+
+	child, other := &n.left, n.right
+	if sparseBit(key, n.bottom) != 0 {
+		child, other = &n.right, n.left
+	}
+	next, removed := t.remove(*child, key)
+	if !removed {
+		return n, false
+	}
+	if next == nil {
+		// the split has a single non-empty child left, which takes over its chain
+		other.top = n.top
+		other.topHash = t.lift(other.h, other.key, other.bottom, other.top)
+		return other, true
+	}
+	*child = next
+	t.rehash(n)
+	return n, true
+}
+
+// rehash recomputes the hashes of a node from its value or its children.
+func (t *SparseMerkleTree) rehash(n *sparseNode) {
+	if n.left == nil {
+		n.h = t.hashStrategy.HashLeaf(append(bytes.Clone(n.key), n.value...))
+	} else {
+		n.h = t.hashStrategy.HashInternal(n.left.topHash, n.right.topHash)
+	}
+	n.topHash = t.lift(n.h, n.key, n.bottom, n.top)
+}
+
+// lift hashes the root of a subtree at depth from up to depth to, with empty subtrees as siblings.
+func (t *SparseMerkleTree) lift(h, key []byte, from, to int) []byte {
+	for d := from; d > to; d-- {
+		if sparseBit(key, d-1) == 0 {
+			h = t.hashStrategy.HashInternal(h, t.defaults[d])
+		} else {
+			h = t.hashStrategy.HashInternal(t.defaults[d], h)
+		}
+	}
+	return h
+}
+
+// child returns the child of a split on the path to the key.
+func (n *sparseNode) child(key []byte) *sparseNode {
+	if sparseBit(key, n.bottom) == 0 {
+		return n.left
+	}
+	return n.right
+}
+
+// sparseBit returns the bit of the key that chooses the child of a node at depth d.
+func sparseBit(key []byte, d int) byte {
+	return key[d/8] >> (7 - d%8) & 1
+}
+
+// sparseSplit returns the first depth in [from, to) at which the paths to both keys split, or to if they don't.
+func sparseSplit(a, b []byte, from, to int) int {
+	for d := from; d < to; d++ {
+		if sparseBit(a, d) != sparseBit(b, d) {
+			return d
+		}
+	}
+	return to
+}
@@ -0,0 +1,267 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// naiveSparseRoot computes the root of a sparse merkle tree by recursing over all levels.
+func naiveSparseRoot(t *SparseMerkleTree, values map[string][]byte, keys [][]byte, depth int) []byte {
+	if len(keys) == 0 {
+		return t.defaults[depth]
+	}
+	if depth == sparseDepth {
+		return hashStrategy.HashLeaf(append(bytes.Clone(keys[0]), values[string(keys[0])]...))
+	}
+	i := sort.Search(len(keys), func(i int) bool { return sparseBit(keys[i], depth) == 1 })
+	return hashStrategy.HashInternal(naiveSparseRoot(t, values, keys[:i], depth+1), naiveSparseRoot(t, values, keys[i:], depth+1))
+}
+
+func sparseKey(i int) []byte {
+	key := sha256.Sum256([]byte(fmt.Sprint(i)))
+	return key[:]
+}
+
+func TestSparseTree_Root(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	values := make(map[string][]byte)
+
+	check := func() {
+		t.Helper()
+		var keys [][]byte
+		for k := range values {
+			keys = append(keys, []byte(k))
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+		if expected := naiveSparseRoot(tree, values, keys, 0); !bytes.Equal(tree.Root(), expected) {
+			t.Fatalf("expected %x, got %x", expected, tree.Root())
+		}
+		if tree.Len() != len(values) {
+			t.Fatalf("expected %d keys, got %d", len(values), tree.Len())
+		}
+	}
+	check()
+
+	// keys that only differ in their last bit
+	near := sparseKey(0)
+	near[SparseKeySize-1] ^= 1
+
+	keys := [][]byte{sparseKey(0), near}
+	for i := 1; i < 50; i++ {
+		keys = append(keys, sparseKey(i))
+	}
+
+	for i, key := range keys {
+		value := []byte(fmt.Sprint("value", i))
+		if err := tree.Update(key, value); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		values[string(key)] = value
+		check()
+	}
+
+	// overwrite, with an empty value
+	if err := tree.Update(keys[3], []byte{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values[string(keys[3])] = []byte{}
+	check()
+
+	for i, key := range keys {
+		if i%3 != 0 {
+			continue
+		}
+		if err := tree.Delete(key); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		delete(values, string(key))
+		check()
+	}
+
+	// deleting a missing key is a no-op
+	if err := tree.Delete(sparseKey(1000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check()
+
+	for _, key := range keys {
+		value, ok := tree.Get(key)
+		if expected, exists := values[string(key)]; ok != exists || !bytes.Equal(value, expected) {
+			t.Errorf("expected %q (%t), got %q (%t)", expected, exists, value, ok)
+		}
+	}
+
+	for _, key := range keys {
+		if err := tree.Delete(key); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		delete(values, string(key))
+	}
+	check()
+
+	if err := tree.Update([]byte("short"), []byte("a")); err == nil {
+		t.Errorf("expected error for invalid key size")
+	}
+	if err := tree.Update(sparseKey(0), nil); err == nil {
+		t.Errorf("expected error for nil value")
+	}
+}
+
+func TestSparseTree_Proof(t *testing.T) {
+	tree := NewSparseMerkleTree()
+
+	near := sparseKey(0)
+	near[SparseKeySize-1] ^= 1
+
+	// proofs of an empty tree
+	proof, err := tree.Proof(near)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifySparseProof(near, nil, proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for i := range 20 {
+		if err := tree.Update(sparseKey(i), []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for i := range 20 {
+		proof, err := tree.Proof(sparseKey(i))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifySparseProof(sparseKey(i), []byte(fmt.Sprint(i)), proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifySparseProof(sparseKey(i), []byte("wrong"), proof); err == nil {
+			t.Errorf("expected error for wrong value")
+		}
+		if err := VerifySparseProof(sparseKey(i), nil, proof); err == nil {
+			t.Errorf("expected error for absence of a key with a value")
+		}
+	}
+
+	// absence, next to a key with a value and elsewhere
+	for _, key := range [][]byte{near, sparseKey(100)} {
+		proof, err := tree.Proof(key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifySparseProof(key, nil, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifySparseProof(key, []byte("0"), proof); err == nil {
+			t.Errorf("expected error for a value of a key without one")
+		}
+	}
+
+	// a proof for one key doesn't prove another
+	proof, _ = tree.Proof(sparseKey(1))
+	var verr *VerificationError
+	if err := VerifySparseProof(sparseKey(2), []byte("1"), proof); !errors.As(err, &verr) {
+		t.Errorf("expected verification error, got %v", err)
+	}
+}
+
+func TestSparseTree_Encoding(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	for i := range 20 {
+		value := []byte(fmt.Sprint(i))
+		if i == 7 {
+			value = []byte{}
+		}
+		if err := tree.Update(sparseKey(i), value); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded, err := DecodeSparseMerkleTree(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(decoded.Root(), tree.Root()) || decoded.Len() != tree.Len() {
+		t.Errorf("expected the same tree after decoding")
+	}
+
+	if _, err := DecodeSparseMerkleTree(b[:len(b)-1]); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+	if _, err := DecodeSparseMerkleTree(append(b, 0)); err == nil {
+		t.Errorf("expected error for trailing data")
+	}
+}
+
+func TestSparseProof_Encoding(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	for i := range 20 {
+		if err := tree.Update(sparseKey(i), []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// a proof of a value and a proof of absence
+	for _, kv := range []struct{ key, value []byte }{{sparseKey(3), []byte("3")}, {sparseKey(100), nil}} {
+		proof, err := tree.Proof(kv.key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fromBinary SparseProof
+		if err := fromBinary.UnmarshalBinary(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		j, err := json.Marshal(proof)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fromJSON SparseProof
+		if err := json.Unmarshal(j, &fromJSON); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assembled := NewSparseProof(nil, proof.Bitmap(), proof.Siblings(), nil)
+
+		for _, p := range []*SparseProof{&fromBinary, &fromJSON, assembled} {
+			if err := VerifySparseProofAgainstRoot(kv.key, kv.value, p, tree.Root()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err := VerifySparseProofAgainstRoot(kv.key, kv.value, p, sparseKey(0)); err == nil {
+				t.Errorf("expected error for another root")
+			}
+		}
+
+		if err := fromBinary.UnmarshalBinary(b[:len(b)-1]); err == nil {
+			t.Errorf("expected error for truncated data")
+		}
+	}
+
+	// the bitmap has to account for every sibling
+	proof, err := tree.Proof(sparseKey(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := NewSparseProof(proof.Root(), make([]byte, SparseKeySize), proof.Siblings(), nil)
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.UnmarshalBinary(b); err == nil {
+		t.Errorf("expected error for siblings missing from the bitmap")
+	}
+}
@@ -0,0 +1,61 @@
+package gomerkletree
+
+import "unsafe"
+
+// Stats describes the shape and memory use of a tree.
+type Stats struct {
+	Leaves   int // number of leaves
+	Internal int // number of internal nodes
+	MaxDepth int // depth of the deepest leaf, i.e. the number of siblings of the longest proof
+	MinDepth int // depth of the shallowest leaf, i.e. the number of siblings of the shortest proof
+	Bytes    int // rough estimate of the memory held by the nodes, their hashes and the index of the leaves
+}
+
+// Depth returns the depth of the deepest leaf, which bounds the size of proofs. It is -1 for a nil tree.
+// The first leaf is never promoted, so it is always at the largest depth.
+func (m *MerkleTree) Depth() int {
+	if m == nil || len(m.leaves) == 0 {
+		return -1
+	}
+	return nodeDepth(m.leaves[0])
+}
+
+// MinDepth returns the depth of the shallowest leaf. With promotion it is smaller than Depth when the last leaf is
+// carried up unchanged, since only the right edge of the tree is promoted. It is -1 for a nil tree.
+func (m *MerkleTree) MinDepth() int {
+	if m == nil || len(m.leaves) == 0 {
+		return -1
+	}
+	return nodeDepth(m.leaves[len(m.leaves)-1])
+}
+
+func nodeDepth(n *Node) int {
+	depth := 0
+	for ; n.parent != nil; n = n.parent {
+		depth++
+	}
+	return depth
+}
+
+// Stats returns the shape and memory use of the tree in O(n).
+func (m *MerkleTree) Stats() Stats {
+	if m == nil || m.root == nil {
+		return Stats{}
+	}
+	s := Stats{
+		Leaves:   len(m.leaves),
+		Internal: m.n - len(m.leaves),
+		MaxDepth: m.Depth(),
+		MinDepth: m.MinDepth(),
+		Bytes:    cap(m.leaves) * int(unsafe.Sizeof((*Node)(nil))),
+	}
+	m.Walk(func(n *Node) bool {
+		s.Bytes += int(unsafe.Sizeof(*n)) + cap(n.h)
+		return true
+	})
+	// every entry of the index holds a copy of the hash as key and a slice of positions
+	for h, positions := range m.index {
+		s.Bytes += int(unsafe.Sizeof(h)+unsafe.Sizeof(positions)) + len(h) + cap(positions)*int(unsafe.Sizeof(0))
+	}
+	return s
+}
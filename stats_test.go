@@ -0,0 +1,81 @@
+package gomerkletree
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+)
+
+func TestMerkleTree_Depth(t *testing.T) {
+	tests := []struct {
+		n, duplicate, promoted, minPromoted int
+	}{
+		{1, 0, 0, 0},
+		{2, 1, 1, 1},
+		{3, 2, 2, 1},
+		{4, 2, 2, 2},
+		{5, 3, 3, 1},
+		{6, 3, 3, 2},
+		{7, 3, 3, 2},
+		{1000, 10, 10, 8},
+	}
+
+	for _, tt := range tests {
+		data := make([]Leaf, tt.n)
+		for i := range data {
+			data[i] = BytesLeaf(fmt.Sprint(i))
+		}
+
+		tree := mustBuildMerkleTree(t, data)
+		if tree.Depth() != tt.promoted || tree.MinDepth() != tt.minPromoted {
+			t.Errorf("%d: expected depths %d/%d, got %d/%d", tt.n, tt.promoted, tt.minPromoted, tree.Depth(), tree.MinDepth())
+		}
+
+		// the depths bound the proofs
+		for i := range tt.n {
+			proof, _ := tree.ProofByIndex(i)
+			if d := len(proof.Siblings()); d < tree.MinDepth() || d > tree.Depth() {
+				t.Errorf("%d: proof of %d has %d siblings", tt.n, i, d)
+			}
+		}
+
+		duplicated := mustBuildMerkleTree(t, data, WithDuplication())
+		if duplicated.Depth() != tt.duplicate || duplicated.MinDepth() != tt.duplicate {
+			t.Errorf("%d: expected depths %d/%d, got %d/%d", tt.n, tt.duplicate, tt.duplicate, duplicated.Depth(), duplicated.MinDepth())
+		}
+	}
+
+	var nilTree *MerkleTree
+	if nilTree.Depth() != -1 || nilTree.MinDepth() != -1 {
+		t.Errorf("expected -1 for nil tree")
+	}
+}
+
+func TestMerkleTree_Stats(t *testing.T) {
+	data := make([]Leaf, 5)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+	tree := mustBuildMerkleTree(t, data)
+
+	s := tree.Stats()
+	if s.Leaves != 5 || s.Internal != 4 || s.MaxDepth != 3 || s.MinDepth != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	// at least the 9 hashes and nodes
+	if s.Bytes < 9*(32+int(unsafe.Sizeof(Node{}))) {
+		t.Errorf("expected at least %d bytes, got %d", 9*(32+int(unsafe.Sizeof(Node{}))), s.Bytes)
+	}
+
+	if err := tree.Append(BytesLeaf("5")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next := tree.Stats(); next.Leaves != 6 || next.Internal != 5 || next.Bytes <= s.Bytes {
+		t.Errorf("unexpected stats %+v", next)
+	}
+
+	var nilTree *MerkleTree
+	if nilTree.Stats() != (Stats{}) {
+		t.Errorf("expected empty stats")
+	}
+}
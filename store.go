@@ -0,0 +1,69 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrNodeNotFound is returned by a NodeStore when it has no hash at the requested position.
+var ErrNodeNotFound = errors.New("node not found")
+
+// Position addresses a node that is the root of a perfect subtree:
+// the Index-th node on its Level, covering leaves [Index*2^Level, (Index+1)*2^Level).
+// Leaves are at level 0.
+type Position struct {
+	Level int
+	Index int
+}
+
+// NodeStore stores the hashes of a StoredTree by position, along with the number of leaves in the tree.
+// Only roots of perfect subtrees are stored; other nodes are derived from them when needed.
+type NodeStore interface {
+	Get(pos Position) ([]byte, error)
+	Put(pos Position, hash []byte) error
+	Size() (int, error)
+	SetSize(n int) error
+}
+
+// BatchNodeStore is a NodeStore that can write many hashes and the new size at once,
+// which StoredTree uses to write everything an append changes in a single batch.
+type BatchNodeStore interface {
+	NodeStore
+	PutBatch(hashes map[Position][]byte, size int) error
+}
+
+// MemoryStore is a NodeStore that keeps all hashes in memory.
+// It is not safe for concurrent use.
+type MemoryStore struct {
+	nodes map[Position][]byte
+	size  int
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nodes: make(map[Position][]byte),
+	}
+}
+
+func (s *MemoryStore) Get(pos Position) ([]byte, error) {
+	hash, ok := s.nodes[pos]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+	return hash, nil
+}
+
+func (s *MemoryStore) Put(pos Position, hash []byte) error {
+	s.nodes[pos] = bytes.Clone(hash)
+	return nil
+}
+
+func (s *MemoryStore) Size() (int, error) {
+	return s.size, nil
+}
+
+func (s *MemoryStore) SetSize(n int) error {
+	s.size = n
+	return nil
+}
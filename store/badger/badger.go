@@ -0,0 +1,176 @@
+// Package badger implements a gomerkletree.NodeStore on top of BadgerDB,
+// so a StoredTree can persist billions of leaves to disk.
+package badger
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	badgerdb "github.com/dgraph-io/badger/v4"
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+// DefaultCacheLevel caches every node covering at least 2^16 leaves, which is about 30k nodes for a billion leaves.
+const DefaultCacheLevel = 16
+
+var sizeKey = []byte("size")
+
+// Store is a gomerkletree.BatchNodeStore backed by BadgerDB.
+// Nodes in the upper levels of the tree, which every proof touches, are cached in memory.
+// Store is safe for concurrent use.
+type Store struct {
+	db         *badgerdb.DB
+	cacheLevel int
+
+	mu    sync.RWMutex
+	cache map[gomerkletree.Position][]byte
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithCacheLevel caches all nodes at or above the given level in memory. A negative level disables the cache.
+func WithCacheLevel(level int) Option {
+	return func(s *Store) {
+		s.cacheLevel = level
+	}
+}
+
+// Open opens (or creates) a Badger database in the given directory and returns a store on top of it.
+func Open(dir string, opts ...Option) (*Store, error) {
+	db, err := badgerdb.Open(badgerdb.DefaultOptions(dir).WithLogger(nil))
+	if err != nil {
+		return nil, err
+	}
+	return New(db, opts...), nil
+}
+
+// New returns a store on top of an open Badger database.
+func New(db *badgerdb.DB, opts ...Option) *Store {
+	s := &Store{
+		db:         db,
+		cacheLevel: DefaultCacheLevel,
+		cache:      make(map[gomerkletree.Position][]byte),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) cached(pos gomerkletree.Position) bool {
+	return s.cacheLevel >= 0 && pos.Level >= s.cacheLevel
+}
+
+func (s *Store) Get(pos gomerkletree.Position) ([]byte, error) {
+	if s.cached(pos) {
+		s.mu.RLock()
+		hash, ok := s.cache[pos]
+		s.mu.RUnlock()
+		if ok {
+			return hash, nil
+		}
+	}
+
+	var hash []byte
+	err := s.db.View(func(txn *badgerdb.Txn) error {
+		item, err := txn.Get(nodeKey(pos))
+		if err != nil {
+			return err
+		}
+		hash, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badgerdb.ErrKeyNotFound) {
+		return nil, gomerkletree.ErrNodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.remember(pos, hash)
+	return hash, nil
+}
+
+func (s *Store) Put(pos gomerkletree.Position, hash []byte) error {
+	err := s.db.Update(func(txn *badgerdb.Txn) error {
+		return txn.Set(nodeKey(pos), hash)
+	})
+	if err != nil {
+		return err
+	}
+	s.remember(pos, hash)
+	return nil
+}
+
+// PutBatch writes all hashes and the new size in one batch.
+func (s *Store) PutBatch(hashes map[gomerkletree.Position][]byte, size int) error {
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for pos, hash := range hashes {
+		if err := wb.Set(nodeKey(pos), hash); err != nil {
+			return err
+		}
+	}
+	if err := wb.Set(sizeKey, binary.BigEndian.AppendUint64(nil, uint64(size))); err != nil {
+		return err
+	}
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+
+	for pos, hash := range hashes {
+		s.remember(pos, hash)
+	}
+	return nil
+}
+
+func (s *Store) Size() (int, error) {
+	var size int
+	err := s.db.View(func(txn *badgerdb.Txn) error {
+		item, err := txn.Get(sizeKey)
+		if err != nil {
+			return err
+		}
+		return item.Value(func(v []byte) error {
+			if len(v) != 8 {
+				return errors.New("invalid size")
+			}
+			size = int(binary.BigEndian.Uint64(v))
+			return nil
+		})
+	})
+	if errors.Is(err, badgerdb.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return size, err
+}
+
+func (s *Store) SetSize(n int) error {
+	return s.db.Update(func(txn *badgerdb.Txn) error {
+		return txn.Set(sizeKey, binary.BigEndian.AppendUint64(nil, uint64(n)))
+	})
+}
+
+func (s *Store) remember(pos gomerkletree.Position, hash []byte) {
+	if !s.cached(pos) {
+		return
+	}
+	s.mu.Lock()
+	s.cache[pos] = append([]byte(nil), hash...)
+	s.mu.Unlock()
+}
+
+// nodeKey encodes a position as 'n' | level (1 byte) | index (8 bytes, big endian),
+// so the nodes of a level are stored next to each other.
+func nodeKey(pos gomerkletree.Position) []byte {
+	key := []byte{'n', byte(pos.Level)}
+	return binary.BigEndian.AppendUint64(key, uint64(pos.Index))
+}
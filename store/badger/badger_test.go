@@ -0,0 +1,133 @@
+package badger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	badgerdb "github.com/dgraph-io/badger/v4"
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+type testLeaf struct {
+	x string
+}
+
+func (t *testLeaf) Bytes() []byte {
+	return []byte(t.x)
+}
+
+func openInMemory(t *testing.T, opts ...Option) *Store {
+	t.Helper()
+	db, err := badgerdb.Open(badgerdb.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := New(db, opts...)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore_GetPut(t *testing.T) {
+	s := openInMemory(t, WithCacheLevel(1))
+
+	if _, err := s.Get(gomerkletree.Position{Level: 0, Index: 0}); !errors.Is(err, gomerkletree.ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+
+	if size, err := s.Size(); err != nil || size != 0 {
+		t.Errorf("expected size=0, got %d (%v)", size, err)
+	}
+
+	for _, pos := range []gomerkletree.Position{{Level: 0, Index: 7}, {Level: 3, Index: 1}} {
+		if err := s.Put(pos, []byte("hash")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		hash, err := s.Get(pos)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(hash, []byte("hash")) {
+			t.Errorf("hash not correct")
+		}
+	}
+
+	// only upper levels are cached
+	if len(s.cache) != 1 {
+		t.Errorf("expected 1 cached node, got %d", len(s.cache))
+	}
+
+	if err := s.SetSize(42); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if size, _ := s.Size(); size != 42 {
+		t.Errorf("expected size=42, got %d", size)
+	}
+}
+
+func TestStore_StoredTree(t *testing.T) {
+	s := openInMemory(t, WithCacheLevel(2))
+
+	tree, err := gomerkletree.NewStoredTree(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data []gomerkletree.Leaf
+	for i := range 37 {
+		data = append(data, &testLeaf{string(rune('a' + i))})
+	}
+
+	if err := tree.AppendBatch(data[:20]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, x := range data[20:] {
+		if err := tree.Append(x); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := mustBuildMerkleTree(t, data)
+	root, err := tree.Root()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(root, expected.Root()) {
+		t.Errorf("root not correct")
+	}
+
+	// reopening reads the size back
+	reopened, err := gomerkletree.NewStoredTree(New(s.db))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reopened.Size() != len(data) {
+		t.Errorf("expected size=%d, got %d", len(data), reopened.Size())
+	}
+
+	for i, x := range data {
+		proof, err := reopened.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := gomerkletree.VerifyProofAgainstRoot(x, proof, expected.Root()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func mustBuildMerkleTree(t testing.TB, data []gomerkletree.Leaf) *gomerkletree.MerkleTree {
+	t.Helper()
+	tree, err := gomerkletree.BuildMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tree
+}
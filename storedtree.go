@@ -0,0 +1,231 @@
+package gomerkletree
+
+import (
+	"errors"
+	"slices"
+)
+
+// StoredTree is an append-only merkle tree that keeps its hashes in a NodeStore instead of in memory,
+// so it can grow larger than RAM. It has the same shape as MerkleTree (and RFC 6962),
+// so it produces the same roots and proofs.
+// A StoredTree is not safe for concurrent use.
+type StoredTree struct {
+	store        NodeStore
+	size         int
+	hashStrategy HashStrategy
+	history      *RootHistory
+}
+
+// NewStoredTree opens the tree kept in the store, which can be empty.
+// Options can change the hash strategy, but duplication and sorted leaves are not supported.
+func NewStoredTree(store NodeStore, opts ...Option) (*StoredTree, error) {
+	if store == nil {
+		return nil, errors.New("nil store")
+	}
+	cfg := newConfig(opts)
+	if cfg.duplicate || cfg.sorted {
+		return nil, errors.New("not supported by stored trees")
+	}
+
+	size, err := store.Size()
+	if err != nil {
+		return nil, err
+	}
+	return &StoredTree{
+		store:        store,
+		size:         size,
+		hashStrategy: cfg.hashStrategy,
+		history:      cfg.history,
+	}, nil
+}
+
+// Size returns the number of leaves in the tree.
+func (t *StoredTree) Size() int {
+	return t.size
+}
+
+// Append adds a leaf to the end of the tree, writing O(log n) hashes to the store.
+func (t *StoredTree) Append(x Leaf) error {
+	return t.AppendBatch([]Leaf{x})
+}
+
+// AppendBatch adds leaves to the end of the tree, writing all changes to the store at once
+// if it is a BatchNodeStore.
+func (t *StoredTree) AppendBatch(leaves []Leaf) error {
+	hashes := make([][]byte, len(leaves))
+	for i, x := range leaves {
+		b, err := leafBytes(x)
+		if err != nil {
+			return err
+		}
+		hashes[i] = t.hashStrategy.HashLeaf(b)
+	}
+	return t.AppendHashes(hashes)
+}
+
+// AppendHashes adds leaf hashes to the end of the tree, without hashing them again.
+func (t *StoredTree) AppendHashes(hashes [][]byte) error {
+	pending := make(map[Position][]byte)
+	get := func(pos Position) ([]byte, error) {
+		if hash, ok := pending[pos]; ok {
+			return hash, nil
+		}
+		return t.store.Get(pos)
+	}
+
+	size := t.size
+	for _, hash := range hashes {
+		pos := Position{0, size}
+		pending[pos] = hash
+
+		// complete every perfect subtree the new leaf finishes
+		for pos.Index%2 != 0 {
+			left, err := get(Position{pos.Level, pos.Index - 1})
+			if err != nil {
+				return err
+			}
+			hash = t.hashStrategy.HashInternal(left, hash)
+			pos = Position{pos.Level + 1, pos.Index / 2}
+			pending[pos] = hash
+		}
+		size++
+	}
+
+	if err := t.write(pending, size); err != nil {
+		return err
+	}
+	t.size = size
+
+	if t.history != nil {
+		root, err := t.Root()
+		if err != nil {
+			return err
+		}
+		t.history.record(size, root)
+	}
+	return nil
+}
+
+func (t *StoredTree) write(hashes map[Position][]byte, size int) error {
+	if store, ok := t.store.(BatchNodeStore); ok {
+		return store.PutBatch(hashes, size)
+	}
+	for pos, hash := range hashes {
+		if err := t.store.Put(pos, hash); err != nil {
+			return err
+		}
+	}
+	return t.store.SetSize(size)
+}
+
+// Root returns the root of the tree, or nil if the tree is empty.
+func (t *StoredTree) Root() ([]byte, error) {
+	if t.size == 0 {
+		return nil, nil
+	}
+	return t.subtreeHash(0, t.size)
+}
+
+// LeafHash returns the hash of the i-th leaf.
+func (t *StoredTree) LeafHash(i int) ([]byte, error) {
+	if i < 0 || i >= t.size {
+		return nil, errors.New("index out of range")
+	}
+	return t.subtreeHash(i, i+1)
+}
+
+// ProofByIndex generates a proof for the i-th leaf.
+func (t *StoredTree) ProofByIndex(i int) (*Proof, error) {
+	return t.ProofByIndexAtSize(i, t.size)
+}
+
+// ProofByIndexAtSize generates a proof for the i-th leaf in the tree as it was when it had size leaves,
+// i.e. against an older root.
+func (t *StoredTree) ProofByIndexAtSize(i, size int) (*Proof, error) {
+	if size <= 0 || size > t.size {
+		return nil, errors.New("invalid tree size")
+	}
+	if i < 0 || i >= size {
+		return nil, errors.New("index out of range")
+	}
+
+	var siblings [][]byte
+	var left []bool
+
+	lo, hi := 0, size
+	for hi-lo > 1 {
+		k := split(hi - lo)
+		var sibling []byte
+		var err error
+		if i < lo+k {
+			sibling, err = t.subtreeHash(lo+k, hi)
+			left = append(left, false)
+			hi = lo + k
+		} else {
+			sibling, err = t.subtreeHash(lo, lo+k)
+			left = append(left, true)
+			lo += k
+		}
+		if err != nil {
+			return nil, err
+		}
+		siblings = append(siblings, sibling)
+	}
+
+	root, err := t.subtreeHash(0, size)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(siblings)
+	slices.Reverse(left)
+	return &Proof{
+		root:         root,
+		siblings:     siblings,
+		left:         left,
+		hashStrategy: t.hashStrategy,
+	}, nil
+}
+
+// ConsistencyProof generates a proof that the first newSize leaves of the tree extend the first oldSize leaves.
+func (t *StoredTree) ConsistencyProof(oldSize, newSize int) (*ConsistencyProof, error) {
+	if oldSize <= 0 || oldSize > newSize || newSize > t.size {
+		return nil, errors.New("invalid tree sizes")
+	}
+
+	hashes, err := consistencyPath(oldSize, newSize, t.subtreeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsistencyProof{
+		oldSize:      oldSize,
+		newSize:      newSize,
+		hashes:       hashes,
+		hashStrategy: t.hashStrategy,
+	}, nil
+}
+
+// subtreeHash returns the root of the tree over leaves [lo, hi).
+// Perfect subtrees are read from the store, so only the right edge of the range is hashed.
+func (t *StoredTree) subtreeHash(lo, hi int) ([]byte, error) {
+	size := hi - lo
+	if size&(size-1) == 0 && lo%size == 0 {
+		level := 0
+		for ; size > 1; size /= 2 {
+			level++
+		}
+		return t.store.Get(Position{level, lo >> level})
+	}
+
+	k := split(size)
+	left, err := t.subtreeHash(lo, lo+k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.subtreeHash(lo+k, hi)
+	if err != nil {
+		return nil, err
+	}
+	return t.hashStrategy.HashInternal(left, right), nil
+}
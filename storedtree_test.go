@@ -0,0 +1,198 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestStoredTree_Append(t *testing.T) {
+	store := NewMemoryStore()
+	tree, err := NewStoredTree(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if root, _ := tree.Root(); root != nil {
+		t.Errorf("expected nil root")
+	}
+
+	var data []Leaf
+	for i := range 33 {
+		x := &TestLeaf{string(rune('a' + i))}
+		data = append(data, x)
+
+		if err := tree.Append(x); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		root, err := tree.Root()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(root, mustBuildMerkleTree(t, data).Root()) {
+			t.Errorf("root not correct after %d appends", i+1)
+		}
+	}
+
+	// only perfect subtrees are stored
+	if len(store.nodes) != 2*32-1+1 {
+		t.Errorf("expected %d stored nodes, got %d", 2*32, len(store.nodes))
+	}
+
+	// reopening the store restores the tree
+	reopened, err := NewStoredTree(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reopened.Size() != len(data) {
+		t.Errorf("expected size=%d, got %d", len(data), reopened.Size())
+	}
+
+	if _, err := NewStoredTree(store, WithDuplication()); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestStoredTree_Proof(t *testing.T) {
+	tree, _ := NewStoredTree(NewMemoryStore())
+
+	var data []Leaf
+	for i := range 21 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+		tree.Append(data[i])
+	}
+
+	expected := mustBuildMerkleTree(t, data)
+	for i, x := range data {
+		proof, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expectedProof, _ := expected.ProofByIndex(i)
+		if len(proof.siblings) != len(expectedProof.siblings) {
+			t.Fatalf("expected %d siblings, got %d", len(expectedProof.siblings), len(proof.siblings))
+		}
+
+		for j := range proof.siblings {
+			if !bytes.Equal(proof.siblings[j], expectedProof.siblings[j]) || proof.left[j] != expectedProof.left[j] {
+				t.Errorf("sibling %d of leaf %d not correct", j, i)
+			}
+		}
+
+		if err := VerifyProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if h, err := tree.LeafHash(i); err != nil || !bytes.Equal(h, expected.LeafHash(i)) {
+			t.Errorf("expected leaf hash %x, got %x (%v)", expected.LeafHash(i), h, err)
+		}
+	}
+
+	for oldSize := 1; oldSize <= len(data); oldSize++ {
+		proof, err := tree.ConsistencyProof(oldSize, len(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		oldRoot := mustBuildMerkleTree(t, data[:oldSize]).Root()
+		if err := VerifyConsistency(oldRoot, expected.Root(), proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// proofs against older roots
+	for _, size := range []int{1, 6, 16} {
+		oldRoot := mustBuildMerkleTree(t, data[:size]).Root()
+		for i := range size {
+			proof, err := tree.ProofByIndexAtSize(i, size)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := VerifyProofAgainstRoot(data[i], proof, oldRoot); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	if _, err := tree.ProofByIndex(len(data)); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.LeafHash(len(data)); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ProofByIndexAtSize(0, len(data)+1); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := tree.ConsistencyProof(1, len(data)+1); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestStoredTree_MissingNode(t *testing.T) {
+	store := NewMemoryStore()
+	tree, _ := NewStoredTree(store)
+	tree.Append(&TestLeaf{"a"})
+	tree.Append(&TestLeaf{"b"})
+	tree.Append(&TestLeaf{"c"})
+
+	delete(store.nodes, Position{1, 0})
+
+	if _, err := tree.Root(); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+
+	if _, err := tree.ProofByIndex(2); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+}
+
+type batchStore struct {
+	*MemoryStore
+	batches int
+}
+
+func (s *batchStore) PutBatch(hashes map[Position][]byte, size int) error {
+	s.batches++
+	for pos, hash := range hashes {
+		s.Put(pos, hash)
+	}
+	return s.SetSize(size)
+}
+
+func TestStoredTree_AppendBatch(t *testing.T) {
+	store := &batchStore{MemoryStore: NewMemoryStore()}
+	tree, _ := NewStoredTree(store)
+
+	var data []Leaf
+	for i := range 21 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	if err := tree.AppendBatch(data[:10]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tree.AppendBatch(data[10:]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if store.batches != 2 {
+		t.Errorf("expected 2 batches, got %d", store.batches)
+	}
+
+	root, _ := tree.Root()
+	if !bytes.Equal(root, mustBuildMerkleTree(t, data).Root()) {
+		t.Errorf("root not correct")
+	}
+
+	if size, _ := store.Size(); size != len(data) {
+		t.Errorf("expected size=%d, got %d", len(data), size)
+	}
+}
@@ -0,0 +1,117 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// StreamLeaf is a leaf that writes its bytes to a stream, so huge leaves (e.g. whole files) can be hashed
+// without holding them in memory.
+type StreamLeaf interface {
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// LeafHasher is implemented by hash strategies that can hash leaves as a stream.
+// Streamed leaves are hashed with other hash strategies by reading them into memory first.
+type LeafHasher interface {
+	// NewLeafHash returns a digest whose sum is the HashLeaf of the bytes written to it.
+	NewLeafHash() hash.Hash
+}
+
+func (h defaultHashStrategy) NewLeafHash() hash.Hash {
+	return hashing.NewLeafDigest(sha256.New())
+}
+
+func (h RFC6962HashStrategy) NewLeafHash() hash.Hash {
+	return defaultHashStrategy{}.NewLeafHash()
+}
+
+// FileLeaf is a leaf with the contents of the file at its path, which are streamed when the leaf is hashed.
+type FileLeaf string
+
+func (f FileLeaf) WriteTo(w io.Writer) (int64, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return io.Copy(w, file)
+}
+
+// HashStreamLeaf returns the HashLeaf of the bytes of a streamed leaf. A nil hash strategy means the default one.
+func HashStreamLeaf(x StreamLeaf, hash HashStrategy) ([]byte, error) {
+	if x == nil {
+		return nil, errors.New("nil leaf")
+	}
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	if h, ok := hash.(LeafHasher); ok {
+		digest := h.NewLeafHash()
+		if _, err := x.WriteTo(digest); err != nil {
+			return nil, err
+		}
+		return digest.Sum(nil), nil
+	}
+
+	var b bytes.Buffer
+	if _, err := x.WriteTo(&b); err != nil {
+		return nil, err
+	}
+	return hash.HashLeaf(b.Bytes()), nil
+}
+
+// BuildMerkleTreeFromStreams builds a merkle tree with streamed leaves, like BuildMerkleTree.
+// Every leaf is streamed into the digest of the hash strategy, so only one buffer of it is in memory at a time.
+func BuildMerkleTreeFromStreams(data []StreamLeaf, opts ...Option) (*MerkleTree, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	cfg := newConfig(opts)
+	p := newProgress(cfg, len(data))
+	hashes := make([][]byte, len(data))
+	for i, x := range data {
+		h, err := HashStreamLeaf(x, cfg.hashStrategy)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h
+		p.add(1)
+	}
+	return buildFromLeafHashesCtx(context.Background(), hashes, cfg, p) // can't be cancelled
+}
+
+// ProofStream generates a proof for a streamed leaf, like Proof.
+func (m *MerkleTree) ProofStream(x StreamLeaf) (*Proof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	h, err := HashStreamLeaf(x, m.hashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	i := m.leafIndex(h)
+	if i < 0 {
+		return nil, errors.New("not in tree")
+	}
+	return m.ProofByIndex(i)
+}
+
+// VerifyStreamProof checks if a proof is valid for a streamed leaf, like VerifyProof.
+func VerifyStreamProof(x StreamLeaf, p *Proof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	h, err := HashStreamLeaf(x, p.hashStrategy)
+	if err != nil {
+		return err
+	}
+	return verifyProof(h, p, p.hashStrategy, p.root)
+}
@@ -0,0 +1,118 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// repeatLeaf streams n copies of a byte, without holding them in memory.
+type repeatLeaf struct {
+	b byte
+	n int64
+}
+
+func (r repeatLeaf) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, io.LimitReader(repeatReader(r.b), r.n))
+}
+
+type repeatReader byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestTree_BuildFromStreams(t *testing.T) {
+	data := []StreamLeaf{repeatLeaf{'a', 1 << 20}, repeatLeaf{'b', 3}, repeatLeaf{'c', 0}}
+
+	tree, err := BuildMerkleTreeFromStreams(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := mustBuildMerkleTree(t, []Leaf{
+		BytesLeaf(bytes.Repeat([]byte{'a'}, 1<<20)),
+		BytesLeaf("bbb"),
+		BytesLeaf{},
+	})
+	if !bytes.Equal(tree.Root(), expected.Root()) {
+		t.Errorf("expected root %x, got %x", expected.Root(), tree.Root())
+	}
+
+	for _, x := range data {
+		proof, err := tree.ProofStream(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyStreamProof(x, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := tree.ProofStream(repeatLeaf{'b', 4}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	proof, _ := tree.ProofByIndex(1)
+	if err := VerifyStreamProof(repeatLeaf{'b', 4}, proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	if _, err := BuildMerkleTreeFromStreams(nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if _, err := BuildMerkleTreeFromStreams([]StreamLeaf{nil}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_BuildFromStreamsStrategies(t *testing.T) {
+	data := []StreamLeaf{repeatLeaf{'a', 100}, repeatLeaf{'b', 5000}}
+	leaves := []Leaf{BytesLeaf(bytes.Repeat([]byte{'a'}, 100)), BytesLeaf(bytes.Repeat([]byte{'b'}, 5000))}
+
+	for name, h := range map[string]HashStrategy{
+		"rfc6962":    RFC6962HashStrategy{},
+		"sha3":       hashing.SHA3Strategy{},
+		"sha512_256": hashing.SHA512_256Strategy{},
+		"blake2b":    hashing.Blake2bStrategy{},
+		"blake3":     hashing.Blake3Strategy{},
+		"digest":     hashing.NewStrategy(sha256.New),
+		"hmac":       hashing.NewHMACStrategy([]byte("key"), sha256.New),
+		"keccak":     Keccak256HashStrategy{}, // not a LeafHasher, streamed into memory
+	} {
+		tree, err := BuildMerkleTreeFromStreams(data, WithHashStrategy(h))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if expected := mustBuildMerkleTree(t, leaves, WithHashStrategy(h)); !bytes.Equal(tree.Root(), expected.Root()) {
+			t.Errorf("%s: expected root %x, got %x", name, expected.Root(), tree.Root())
+		}
+	}
+}
+
+func TestFileLeaf(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "leaf")
+	if err := os.WriteFile(path, []byte("contents"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h, err := HashStreamLeaf(FileLeaf(path), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := hashStrategy.HashLeaf([]byte("contents")); !bytes.Equal(h, expected) {
+		t.Errorf("expected %x, got %x", expected, h)
+	}
+
+	if _, err := HashStreamLeaf(FileLeaf(filepath.Join(dir, "missing")), nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
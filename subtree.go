@@ -0,0 +1,67 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+)
+
+// SubtreeRoot returns the root of the subtree that spans exactly the leaves in [start, end).
+// Subtrees follow the shape of the tree: the left subtree of n leaves holds the largest power of two smaller than n,
+// so e.g. in a tree of 6 leaves [0, 4), [4, 6) and [2, 4) are subtrees, but [1, 3) is not.
+func (m *MerkleTree) SubtreeRoot(start, end int) ([]byte, error) {
+	node, err := m.subtree(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(node.h), nil
+}
+
+// SubtreeProof generates a proof that the root of the subtree spanning [start, end) is contained in the root of the tree,
+// so verification of the leaves in the span can be delegated to anyone holding the authenticated subtree root.
+func (m *MerkleTree) SubtreeProof(start, end int) (*Proof, error) {
+	node, err := m.subtree(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.verifiedForProof() {
+		return nil, errors.New("unable to verify tree")
+	}
+
+	return m.proof(node), nil
+}
+
+// VerifySubtreeProof checks if a proof is valid for a given subtree root.
+func VerifySubtreeProof(subtreeRoot []byte, p *Proof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifyProof(subtreeRoot, p, p.hashStrategy, p.root)
+}
+
+// subtree finds the node spanning exactly the leaves in [start, end).
+func (m *MerkleTree) subtree(start, end int) (*Node, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if m.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if start < 0 || end > len(m.leaves) || start >= end {
+		return nil, errors.New("index out of range")
+	}
+
+	node, lo, hi := m.root, 0, len(m.leaves)
+	for lo != start || hi != end {
+		k := split(hi - lo)
+		switch {
+		case end <= lo+k:
+			node, hi = node.left, lo+k
+		case start >= lo+k:
+			node, lo = node.right, lo+k
+		default:
+			return nil, errors.New("not a subtree")
+		}
+	}
+	return node, nil
+}
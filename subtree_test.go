@@ -0,0 +1,88 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestTree_SubtreeRoot(t *testing.T) {
+	var data []Leaf
+	for i := range 6 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for _, r := range [][2]int{{0, 6}, {0, 4}, {4, 6}, {2, 4}, {3, 4}} {
+		root, err := tree.SubtreeRoot(r[0], r[1])
+		if err != nil {
+			t.Fatalf("[%d, %d): unexpected error: %v", r[0], r[1], err)
+		}
+
+		expected := mustBuildMerkleTree(t, data[r[0]:r[1]]).Root()
+		if !bytes.Equal(root, expected) {
+			t.Errorf("[%d, %d): expected %x, got %x", r[0], r[1], expected, root)
+		}
+
+		proof, err := tree.SubtreeProof(r[0], r[1])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := VerifySubtreeProof(root, proof); err != nil {
+			t.Errorf("[%d, %d): unexpected error: %v", r[0], r[1], err)
+		}
+
+		if !bytes.Equal(proof.Root(), tree.Root()) {
+			t.Errorf("expected proof for the root of the tree")
+		}
+	}
+}
+
+func TestTree_SubtreeProofWrongRoot(t *testing.T) {
+	var data []Leaf
+	for i := range 6 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	proof, err := tree.SubtreeProof(0, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other, err := tree.SubtreeRoot(4, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifySubtreeProof(other, proof); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if err := VerifySubtreeProof(other, nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestTree_SubtreeErrors(t *testing.T) {
+	var data []Leaf
+	for i := range 6 {
+		data = append(data, &TestLeaf{fmt.Sprint(i)})
+	}
+
+	tree := mustBuildMerkleTree(t, data)
+
+	for _, r := range [][2]int{{1, 3}, {0, 5}, {-1, 2}, {4, 7}, {2, 2}} {
+		if _, err := tree.SubtreeRoot(r[0], r[1]); err == nil {
+			t.Errorf("[%d, %d): expected error", r[0], r[1])
+		}
+	}
+
+	dup := mustBuildMerkleTree(t, data, WithDuplication())
+	if _, err := dup.SubtreeProof(0, 4); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,313 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"slices"
+)
+
+// SumLeaf is a leaf that carries a value, e.g. the balance of an account.
+type SumLeaf interface {
+	Leaf
+	Value() uint64
+}
+
+// SumMerkleTree is a merkle sum tree: every node commits to a hash and to the sum of the values of the leaves below it.
+// Leaves are hashed as HashLeaf(value || leaf), internal nodes as HashInternal(left || leftSum, right || rightSum),
+// with sums encoded as 8 byte big endian integers. The root commits to the total, so a proof shows that a leaf
+// is included with its value, and that it counts towards the published total (e.g. for proof of reserves).
+type SumMerkleTree struct {
+	levels       [][]sumNode // the leaves, followed by every level above, up to the root
+	hashStrategy HashStrategy
+}
+
+type sumNode struct {
+	h   []byte
+	sum uint64
+}
+
+// SumProof is the proof for a leaf of a sum tree, with the hash and sum of every sibling on the path.
+type SumProof struct {
+	root         []byte
+	sum          uint64
+	siblings     [][]byte
+	sums         []uint64
+	left         []bool
+	hashStrategy HashStrategy
+}
+
+// NewSumProof assembles a sum proof from its contents, e.g. after receiving them over the network.
+// A nil hash strategy means the default hash strategy.
+func NewSumProof(root []byte, sum uint64, siblings [][]byte, sums []uint64, directions []bool, hash HashStrategy) *SumProof {
+	if hash == nil {
+		hash = defaultHashStrategy{}
+	}
+	return &SumProof{
+		root:         bytes.Clone(root),
+		sum:          sum,
+		siblings:     cloneHashes(siblings),
+		sums:         slices.Clone(sums),
+		left:         slices.Clone(directions),
+		hashStrategy: hash,
+	}
+}
+
+// Root returns a copy of the root the proof was generated for.
+func (p *SumProof) Root() []byte {
+	if p == nil {
+		return nil
+	}
+	return bytes.Clone(p.root)
+}
+
+// Sum returns the total of the tree the proof was generated for.
+func (p *SumProof) Sum() uint64 {
+	if p == nil {
+		return 0
+	}
+	return p.sum
+}
+
+// Siblings returns a copy of the sibling hashes, ordered from the leaf up to the root.
+func (p *SumProof) Siblings() [][]byte {
+	if p == nil {
+		return nil
+	}
+	return cloneHashes(p.siblings)
+}
+
+// Sums returns a copy of the sums of the siblings.
+func (p *SumProof) Sums() []uint64 {
+	if p == nil {
+		return nil
+	}
+	return slices.Clone(p.sums)
+}
+
+// Directions returns, for every sibling, whether it is a left child (true) or a right child (false).
+func (p *SumProof) Directions() []bool {
+	if p == nil {
+		return nil
+	}
+	return slices.Clone(p.left)
+}
+
+// BuildSumMerkleTree takes a slice of leaves with values and builds a merkle sum tree.
+// Returns an error if the total doesn't fit in an uint64. Duplication and sorted leaves are not supported.
+func BuildSumMerkleTree(data []SumLeaf, opts ...Option) (*SumMerkleTree, error) {
+	cfg := newConfig(opts)
+	if cfg.duplicate {
+		return nil, errors.New("not supported with duplication")
+	}
+	if cfg.sorted {
+		return nil, errors.New("not supported with sorted leaves")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("no leaves")
+	}
+
+	m := &SumMerkleTree{hashStrategy: cfg.hashStrategy}
+	level := make([]sumNode, len(data))
+	for i, x := range data {
+		hash, err := hashSumLeaf(m.hashStrategy, x)
+		if err != nil {
+			return nil, err
+		}
+		level[i] = sumNode{hash, x.Value()}
+	}
+	m.levels = append(m.levels, level)
+
+	for len(level) > 1 {
+		next := make([]sumNode, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			parent, err := hashSumNodes(m.hashStrategy, level[i], level[i+1])
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, parent)
+		}
+		if len(level)%2 != 0 {
+			next = append(next, level[len(level)-1])
+		}
+		m.levels = append(m.levels, next)
+		level = next
+	}
+
+	return m, nil
+}
+
+// hashSumLeaf hashes a leaf with its value, rejecting the same leaves as BuildMerkleTree.
+func hashSumLeaf(hash HashStrategy, x SumLeaf) ([]byte, error) {
+	b, err := leafBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	bytes := make([]byte, 0, 8+len(b))
+	bytes = binary.BigEndian.AppendUint64(bytes, x.Value())
+	return hash.HashLeaf(append(bytes, b...)), nil
+}
+
+func hashSumNodes(hash HashStrategy, l, r sumNode) (sumNode, error) {
+	sum, carry := bits.Add64(l.sum, r.sum, 0)
+	if carry != 0 {
+		return sumNode{}, errors.New("sum overflows")
+	}
+	return sumNode{hash.HashInternal(appendSum(l), appendSum(r)), sum}, nil
+}
+
+func appendSum(n sumNode) []byte {
+	b := make([]byte, 0, len(n.h)+8)
+	b = append(b, n.h...)
+	return binary.BigEndian.AppendUint64(b, n.sum)
+}
+
+// Root returns the bytes of the root.
+func (m *SumMerkleTree) Root() []byte {
+	if m == nil {
+		return nil
+	}
+	return bytes.Clone(m.levels[len(m.levels)-1][0].h)
+}
+
+// Sum returns the sum of the values of all leaves.
+func (m *SumMerkleTree) Sum() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.levels[len(m.levels)-1][0].sum
+}
+
+// NumLeaves returns the number of leaves in the tree.
+func (m *SumMerkleTree) NumLeaves() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.levels[0])
+}
+
+// Verify verifies the integrity of the tree.
+func (m *SumMerkleTree) Verify() bool {
+	if m == nil || m.hashStrategy == nil {
+		return false
+	}
+	for l := 1; l < len(m.levels); l++ {
+		below := m.levels[l-1]
+		for i, n := range m.levels[l] {
+			expected := below[2*i]
+			if 2*i+1 < len(below) {
+				var err error
+				if expected, err = hashSumNodes(m.hashStrategy, below[2*i], below[2*i+1]); err != nil {
+					return false
+				}
+			}
+			if n.sum != expected.sum || !hashEqual(n.h, expected.h) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Proof generates a proof for a given leaf, finding the leaf with a linear scan over the leaves.
+func (m *SumMerkleTree) Proof(x SumLeaf) (*SumProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+
+	hash, err := hashSumLeaf(m.hashStrategy, x)
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range m.levels[0] {
+		if hashEqual(hash, n.h) {
+			return m.ProofByIndex(i)
+		}
+	}
+	return nil, errors.New("not in tree")
+}
+
+// ProofByIndex generates a proof for the i-th leaf in O(log n).
+func (m *SumMerkleTree) ProofByIndex(i int) (*SumProof, error) {
+	if m == nil {
+		return nil, errors.New("nil tree")
+	}
+	if i < 0 || i >= len(m.levels[0]) {
+		return nil, errors.New("index out of range")
+	}
+
+	p := &SumProof{
+		root:         m.Root(),
+		sum:          m.Sum(),
+		hashStrategy: m.hashStrategy,
+	}
+	for _, level := range m.levels[:len(m.levels)-1] {
+		var sibling sumNode
+		if i%2 != 0 {
+			sibling = level[i-1]
+			p.left = append(p.left, true) // maps to sibling hash
+		} else if i+1 < len(level) {
+			sibling = level[i+1]
+			p.left = append(p.left, false) // maps to sibling hash
+		} else {
+			i /= 2
+			continue // promoted
+		}
+		p.siblings = append(p.siblings, bytes.Clone(sibling.h))
+		p.sums = append(p.sums, sibling.sum)
+		i /= 2
+	}
+	return p, nil
+}
+
+// VerifySumProof checks if a proof is valid for a given leaf, i.e. that the leaf with its value is included
+// in a tree with the root and total of the proof.
+func VerifySumProof(x SumLeaf, p *SumProof) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifySumProof(x, p, p.root, p.sum)
+}
+
+// VerifySumProofAgainstRoot checks if a proof is valid for a given leaf under the root and total published by the
+// prover, like a customer checking that their balance counts towards the reserves. The root and total stored in the
+// proof are ignored.
+func VerifySumProofAgainstRoot(x SumLeaf, p *SumProof, root []byte, sum uint64) error {
+	if p == nil || p.hashStrategy == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	return verifySumProof(x, p, root, sum)
+}
+
+func verifySumProof(x SumLeaf, p *SumProof, root []byte, sum uint64) error {
+	if len(p.siblings) != len(p.left) || len(p.sums) != len(p.left) {
+		return errors.New("proof lengths mismatch")
+	}
+
+	hash, err := hashSumLeaf(p.hashStrategy, x)
+	if err != nil {
+		return err
+	}
+	node := sumNode{hash, x.Value()}
+	for i, isLeft := range p.left {
+		sibling := sumNode{p.siblings[i], p.sums[i]}
+		var err error
+		if isLeft {
+			node, err = hashSumNodes(p.hashStrategy, sibling, node)
+		} else {
+			node, err = hashSumNodes(p.hashStrategy, node, sibling)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !hashEqual(node.h, root) {
+		return rootMismatch(node.h, root)
+	}
+	if node.sum != sum {
+		return errors.New("sum does not match")
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+type testSumLeaf struct {
+	account string
+	balance uint64
+}
+
+func (l testSumLeaf) Bytes() []byte {
+	return []byte(l.account)
+}
+
+func (l testSumLeaf) Value() uint64 {
+	return l.balance
+}
+
+func TestSumTree_Root(t *testing.T) {
+	var data []SumLeaf
+	data = append(data, testSumLeaf{"a", 10})
+	data = append(data, testSumLeaf{"b", 20})
+	data = append(data, testSumLeaf{"c", 5})
+
+	tree, err := BuildSumMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tree.Sum() != 35 {
+		t.Errorf("expected 35, got %d", tree.Sum())
+	}
+
+	leaf := func(x SumLeaf) []byte {
+		return hashStrategy.HashLeaf(append(binary.BigEndian.AppendUint64(nil, x.Value()), x.Bytes()...))
+	}
+	withSum := func(h []byte, sum uint64) []byte {
+		return binary.BigEndian.AppendUint64(append([]byte{}, h...), sum)
+	}
+	ab := hashStrategy.HashInternal(withSum(leaf(data[0]), 10), withSum(leaf(data[1]), 20))
+	expected := hashStrategy.HashInternal(withSum(ab, 30), withSum(leaf(data[2]), 5))
+
+	if !bytes.Equal(tree.Root(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.Root())
+	}
+
+	if !tree.Verify() {
+		t.Errorf("expected tree to verify")
+	}
+
+	tree.levels[1][0].sum = 29
+	if tree.Verify() {
+		t.Errorf("expected tampered tree not to verify")
+	}
+}
+
+func TestSumTree_Proof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		var data []SumLeaf
+		for i := range n {
+			data = append(data, testSumLeaf{fmt.Sprint(i), uint64(i * 100)})
+		}
+
+		tree, err := BuildSumMerkleTree(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for i, x := range data {
+			proof, err := tree.Proof(x)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if proof.Sum() != tree.Sum() {
+				t.Errorf("expected sum %d, got %d", tree.Sum(), proof.Sum())
+			}
+
+			if err := VerifySumProof(x, proof); err != nil {
+				t.Errorf("n=%d i=%d: unexpected error: %v", n, i, err)
+			}
+
+			// the same account with a different balance
+			if err := VerifySumProof(testSumLeaf{fmt.Sprint(i), x.Value() + 1}, proof); err == nil {
+				t.Errorf("n=%d i=%d: expected error", n, i)
+			}
+		}
+	}
+}
+
+func TestSumTree_ProofSumMismatch(t *testing.T) {
+	var data []SumLeaf
+	data = append(data, testSumLeaf{"a", 10})
+	data = append(data, testSumLeaf{"b", 20})
+
+	tree, err := BuildSumMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	proof, err := tree.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// hiding a liability by claiming a lower sibling sum changes the root
+	proof.sums[0] = 0
+	if err := VerifySumProof(data[0], proof); err == nil {
+		t.Errorf("expected error")
+	}
+
+	proof.sums[0] = 20
+	proof.sum = 25
+	if err := VerifySumProof(data[0], proof); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestSumTree_Errors(t *testing.T) {
+	var data []SumLeaf
+	data = append(data, testSumLeaf{"a", math.MaxUint64})
+	data = append(data, testSumLeaf{"b", 1})
+
+	if _, err := BuildSumMerkleTree(data); err == nil {
+		t.Errorf("expected overflow error")
+	}
+
+	if _, err := BuildSumMerkleTree(nil); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := BuildSumMerkleTree([]SumLeaf{data[1], nil}); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+
+	if _, err := BuildSumMerkleTree(data[:1], WithDuplication()); err == nil {
+		t.Errorf("expected error")
+	}
+
+	tree, err := BuildSumMerkleTree(data[:1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := tree.ProofByIndex(1); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if _, err := tree.Proof(data[1]); err == nil {
+		t.Errorf("expected error")
+	}
+
+	if err := VerifySumProof(data[0], nil); err == nil {
+		t.Errorf("expected error")
+	}
+
+	proof, err := tree.ProofByIndex(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tree.Proof(nil); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+	if err := VerifySumProof(nil, proof); err == nil {
+		t.Errorf("expected error for nil leaf")
+	}
+}
+
+func TestSumProof_Encoding(t *testing.T) {
+	var data []SumLeaf
+	for i := range 7 {
+		data = append(data, testSumLeaf{fmt.Sprint(i), uint64(i) << 58})
+	}
+	tree, err := BuildSumMerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proof, err := tree.ProofByIndex(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromBinary SumProof
+	if err := fromBinary.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	j, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromJSON SumProof
+	if err := json.Unmarshal(j, &fromJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assembled := NewSumProof(nil, 0, proof.Siblings(), proof.Sums(), proof.Directions(), nil)
+
+	// the customer checks against the published root and total, not the ones of the proof
+	for _, p := range []*SumProof{&fromBinary, &fromJSON, assembled} {
+		if err := VerifySumProofAgainstRoot(data[3], p, tree.Root(), tree.Sum()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifySumProofAgainstRoot(data[3], p, tree.Root(), tree.Sum()-1); err == nil {
+			t.Errorf("expected error for another total")
+		}
+	}
+
+	if err := fromBinary.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+	if err := json.Unmarshal([]byte(`{"root":"","sum":0,"siblings":["00"],"sums":[],"directions":["left"]}`), &fromJSON); err == nil {
+		t.Errorf("expected error for missing sums")
+	}
+}
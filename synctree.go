@@ -0,0 +1,147 @@
+package gomerkletree
+
+import "sync"
+
+// SyncTree wraps a MerkleTree to make it safe for concurrent use.
+// Reads, such as generating proofs, can happen concurrently, while mutations are exclusive.
+type SyncTree struct {
+	mu   sync.RWMutex
+	tree *MerkleTree
+}
+
+// NewSyncTree wraps a tree for concurrent use. The tree should not be used directly afterwards.
+// A nil tree starts out empty, using the default hash strategy.
+func NewSyncTree(m *MerkleTree) *SyncTree {
+	if m == nil {
+		m = &MerkleTree{}
+	}
+	return &SyncTree{
+		tree: m,
+	}
+}
+
+// Append adds a leaf to the end of the tree (see MerkleTree.Append).
+func (s *SyncTree) Append(x Leaf) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Append(x)
+}
+
+// Update replaces the leaf at the given index (see MerkleTree.Update).
+func (s *SyncTree) Update(index int, x Leaf) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Update(index, x)
+}
+
+// Root returns the bytes of the root.
+func (s *SyncTree) Root() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Root()
+}
+
+// Len returns the total number of nodes in the tree.
+func (s *SyncTree) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Len()
+}
+
+// Verify verifies the integrity of the tree.
+func (s *SyncTree) Verify() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Verify()
+}
+
+// VerifyExists looks up a leaf and verifies the integrity of the tree (see MerkleTree.VerifyExists).
+func (s *SyncTree) VerifyExists(x Leaf) (*Node, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.VerifyExists(x)
+}
+
+// Proof generates a proof for a given leaf.
+func (s *SyncTree) Proof(x Leaf) (*Proof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Proof(x)
+}
+
+// Index returns the position of the first occurrence of a leaf.
+func (s *SyncTree) Index(x Leaf) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Index(x)
+}
+
+// FindAll returns the indices of all occurrences of a leaf.
+func (s *SyncTree) FindAll(x Leaf) []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.FindAll(x)
+}
+
+// ProofAt generates a proof for a leaf at the given index.
+func (s *SyncTree) ProofAt(x Leaf, i int) (*Proof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.ProofAt(x, i)
+}
+
+// ProofBytes generates a proof for a leaf of raw bytes.
+func (s *SyncTree) ProofBytes(b []byte) (*Proof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.ProofBytes(b)
+}
+
+// VerifyExistsBytes looks up a leaf of raw bytes and verifies the tree.
+func (s *SyncTree) VerifyExistsBytes(b []byte) (*Node, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.VerifyExistsBytes(b)
+}
+
+// ProofByIndex generates a proof for the i-th leaf.
+func (s *SyncTree) ProofByIndex(i int) (*Proof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.ProofByIndex(i)
+}
+
+// MultiProof generates a single proof for a batch of leaves.
+func (s *SyncTree) MultiProof(leaves []Leaf) (*MultiProof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.MultiProof(leaves)
+}
+
+// NonInclusionProof generates a proof that a leaf is not in the tree.
+func (s *SyncTree) NonInclusionProof(x Leaf) (*NonInclusionProof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.NonInclusionProof(x)
+}
+
+// ConsistencyProof generates a proof that the first newSize leaves extend the first oldSize leaves.
+func (s *SyncTree) ConsistencyProof(oldSize, newSize int) (*ConsistencyProof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.ConsistencyProof(oldSize, newSize)
+}
+
+// Snapshot returns an immutable view of the tree at its current root, which stays valid while the tree changes.
+func (s *SyncTree) Snapshot() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Snapshot()
+}
+
+// Clone returns a deep copy of the tree, as a MerkleTree owned by the caller.
+func (s *SyncTree) Clone() *MerkleTree {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Clone()
+}
@@ -0,0 +1,62 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestSyncTree_Concurrent(t *testing.T) {
+	tree := NewSyncTree(nil)
+
+	var data []Leaf
+	for i := range 64 {
+		data = append(data, &TestLeaf{string(rune('a' + i))})
+	}
+
+	if err := tree.Append(data[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, x := range data[1:] {
+			if err := tree.Append(x); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}()
+
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				proof, err := tree.Proof(data[0])
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if err := VerifyProof(data[0], proof); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if !bytes.Equal(tree.Root(), mustBuildMerkleTree(t, data).Root()) {
+		t.Errorf("root not correct")
+	}
+
+	if !tree.Verify() {
+		t.Errorf("couldn't verify tree")
+	}
+
+	if clone := tree.Clone(); !clone.DeepEqual(mustBuildMerkleTree(t, data)) {
+		t.Errorf("clone not correct")
+	}
+}
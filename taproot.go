@@ -0,0 +1,72 @@
+package gomerkletree
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// TapLeafVersion is the leaf version of tapscript (BIP-342).
+const TapLeafVersion = 0xc0
+
+// TaggedHashStrategy returns a hash strategy using BIP-340 tagged hashes, SHA-256(SHA-256(tag) || SHA-256(tag) || msg).
+// Leaves are hashed with the tag `tag + "Leaf"` and internal nodes with the tag `tag + "Branch"`,
+// so TaggedHashStrategy("Tap") produces the TapLeaf and TapBranch hashes of BIP-341.
+// Taproot sorts the children of every branch, so combine it with WithSortedPairs and use TapLeaf leaves
+// to build trees that are compatible with taproot script trees.
+func TaggedHashStrategy(tag string) HashStrategy {
+	leafTag := sha256.Sum256([]byte(tag + "Leaf"))
+	branchTag := sha256.Sum256([]byte(tag + "Branch"))
+	return taggedHashStrategy{leafTag: leafTag, branchTag: branchTag}
+}
+
+type taggedHashStrategy struct {
+	leafTag   [sha256.Size]byte
+	branchTag [sha256.Size]byte
+}
+
+func (h taggedHashStrategy) HashLeaf(l []byte) []byte {
+	return taggedHash(h.leafTag, l)
+}
+
+func (h taggedHashStrategy) HashInternal(l, r []byte) []byte {
+	return taggedHash(h.branchTag, l, r)
+}
+
+// taggedHash computes a tagged hash with a precomputed tag hash.
+func taggedHash(tag [sha256.Size]byte, msg ...[]byte) []byte {
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// TapLeaf is a leaf of a taproot script tree.
+type TapLeaf struct {
+	Version byte
+	Script  []byte
+}
+
+// Bytes returns the leaf encoded as leaf_version || compact_size(script) || script, as hashed by TapLeaf.
+func (l TapLeaf) Bytes() []byte {
+	b := make([]byte, 0, 1+9+len(l.Script))
+	b = append(b, l.Version)
+	b = appendCompactSize(b, uint64(len(l.Script)))
+	return append(b, l.Script...)
+}
+
+// appendCompactSize appends n encoded as a Bitcoin variable length integer.
+func appendCompactSize(b []byte, n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return append(b, byte(n))
+	case n <= 0xffff:
+		return binary.LittleEndian.AppendUint16(append(b, 0xfd), uint16(n))
+	case n <= 0xffffffff:
+		return binary.LittleEndian.AppendUint32(append(b, 0xfe), uint32(n))
+	default:
+		return binary.LittleEndian.AppendUint64(append(b, 0xff), n)
+	}
+}
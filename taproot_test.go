@@ -0,0 +1,80 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func TestTaggedHashStrategy(t *testing.T) {
+	h := TaggedHashStrategy("Tap")
+
+	if !bytes.Equal(h.HashLeaf([]byte("a")), hashing.HashTagged("TapLeaf", []byte("a"))) {
+		t.Errorf("leaf hash not correct")
+	}
+
+	if !bytes.Equal(h.HashInternal([]byte("a"), []byte("b")), hashing.HashTagged("TapBranch", []byte("ab"))) {
+		t.Errorf("internal hash not correct")
+	}
+}
+
+func TestTaggedHashStrategy_TapLeaf(t *testing.T) {
+	// BIP-341 wallet test vector with a single script leaf
+	leaf := TapLeaf{TapLeafVersion, mustDecodeHex(t, "20d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac")}
+	expected := mustDecodeHex(t, "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21")
+
+	if got := TaggedHashStrategy("Tap").HashLeaf(leaf.Bytes()); !bytes.Equal(got, expected) {
+		t.Errorf("expected %x, got %x", expected, got)
+	}
+}
+
+func TestTaggedHashStrategy_Tree(t *testing.T) {
+	var data []Leaf
+	data = append(data, TapLeaf{TapLeafVersion, []byte{0x51}})
+	data = append(data, TapLeaf{TapLeafVersion, []byte{0x52}})
+	data = append(data, TapLeaf{TapLeafVersion, []byte{0x53}})
+
+	h := TaggedHashStrategy("Tap")
+	tree := mustBuildMerkleTree(t, data, WithHashStrategy(h), WithSortedPairs())
+
+	a, b, c := h.HashLeaf(data[0].Bytes()), h.HashLeaf(data[1].Bytes()), h.HashLeaf(data[2].Bytes())
+	branch := func(l, r []byte) []byte {
+		if bytes.Compare(l, r) > 0 {
+			l, r = r, l
+		}
+		return hashing.HashTagged("TapBranch", append(append([]byte{}, l...), r...))
+	}
+
+	if expected := branch(branch(a, b), c); !bytes.Equal(tree.Root(), expected) {
+		t.Errorf("expected %x, got %x", expected, tree.Root())
+	}
+
+	proof, err := tree.Proof(data[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifySortedPairProof(data[1], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTapLeaf_Bytes(t *testing.T) {
+	tests := []struct {
+		size   int
+		prefix []byte
+	}{
+		{0, []byte{0xc0, 0x00}},
+		{0xfc, []byte{0xc0, 0xfc}},
+		{0xfd, []byte{0xc0, 0xfd, 0xfd, 0x00}},
+		{0x10000, []byte{0xc0, 0xfe, 0x00, 0x00, 0x01, 0x00}},
+	}
+
+	for _, tt := range tests {
+		b := TapLeaf{TapLeafVersion, make([]byte, tt.size)}.Bytes()
+		if !bytes.HasPrefix(b, tt.prefix) || len(b) != len(tt.prefix)+tt.size {
+			t.Errorf("size %d: unexpected encoding %x", tt.size, b[:len(tt.prefix)])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package gomerkletree
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// RootHex returns the root in lower case hex, or "" for a nil tree.
+func (m *MerkleTree) RootHex() string {
+	return hex.EncodeToString(m.RootNoCopy())
+}
+
+// RootBase64 returns the root in standard base64 with padding, or "" for a nil tree.
+func (m *MerkleTree) RootBase64() string {
+	return base64.StdEncoding.EncodeToString(m.RootNoCopy())
+}
+
+// ParseHex decodes a root or hash encoded in hex, like by RootHex. An "0x" prefix, as used by Ethereum tooling,
+// and upper case digits are accepted.
+func ParseHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if s == "" {
+		return nil, errors.New("empty hash")
+	}
+	return hex.DecodeString(s)
+}
+
+// ParseBase64 decodes a root or hash encoded in standard base64 with padding, like by RootBase64.
+func ParseBase64(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty hash")
+	}
+	return base64.StdEncoding.Strict().DecodeString(s)
+}
+
+// Hex returns the binary encoding of the proof (see MarshalBinary) in hex.
+func (p *Proof) Hex() (string, error) {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Base64 returns the binary encoding of the proof (see MarshalBinary) in standard base64 with padding.
+func (p *Proof) Base64() (string, error) {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// ParseProofHex decodes a proof encoded by Hex, using the default hash strategy like UnmarshalBinary.
+func ParseProofHex(s string) (*Proof, error) {
+	b, err := ParseHex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := new(Proof)
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseProofBase64 decodes a proof encoded by Base64, using the default hash strategy like UnmarshalBinary.
+func ParseProofBase64(s string) (*Proof, error) {
+	b, err := ParseBase64(s)
+	if err != nil {
+		return nil, err
+	}
+	p := new(Proof)
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,83 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_RootHex(t *testing.T) {
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}})
+
+	for _, s := range []string{tree.RootHex(), "0x" + tree.RootHex(), "0X" + tree.RootHex()} {
+		root, err := ParseHex(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(root, tree.Root()) {
+			t.Errorf("expected %x, got %x", tree.Root(), root)
+		}
+	}
+	root, err := ParseBase64(tree.RootBase64())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(root, tree.Root()) {
+		t.Errorf("expected %x, got %x", tree.Root(), root)
+	}
+
+	var nilTree *MerkleTree
+	if nilTree.RootHex() != "" || nilTree.RootBase64() != "" {
+		t.Errorf("expected empty encodings for nil tree")
+	}
+	for _, s := range []string{"", "0x", "abc", "zz"} {
+		if _, err := ParseHex(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+	for _, s := range []string{"", "AQ", "AQ=", "!!!!"} {
+		if _, err := ParseBase64(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestProof_Hex(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}, &TestLeaf{"d"}, &TestLeaf{"e"}}
+	tree := mustBuildMerkleTree(t, data)
+	p, err := tree.ProofByIndex(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h, err := p.Hex()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := ParseProofHex(h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(data[4], decoded); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+
+	b, err := p.Base64()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err = ParseProofBase64(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(data[4], decoded); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+
+	if _, err := ParseProofHex(h[:len(h)-2]); err == nil {
+		t.Errorf("expected error for truncated proof")
+	}
+	var nilProof *Proof
+	if _, err := nilProof.Base64(); err == nil {
+		t.Errorf("expected error for nil proof")
+	}
+}
@@ -0,0 +1,163 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TreeHead commits to the state of a log: the root of its tree with Size leaves, at the time it was recorded.
+type TreeHead struct {
+	Size      int
+	Root      []byte
+	Timestamp time.Time
+}
+
+// Bytes returns the encoding of the tree head that is signed, which is the TreeHeadSignature of RFC 6962:
+//
+//	version (1 byte, 0) | signature type (1 byte, 1) | timestamp (8 bytes) | size (8 bytes) | root
+//
+// with the timestamp in milliseconds since the Unix epoch, and integers in big endian.
+func (h TreeHead) Bytes() []byte {
+	b := make([]byte, 0, 18+len(h.Root))
+	b = append(b, 0, 1)
+	b = binary.BigEndian.AppendUint64(b, uint64(h.Timestamp.UnixMilli()))
+	b = binary.BigEndian.AppendUint64(b, uint64(h.Size))
+	return append(b, h.Root...)
+}
+
+// SignedTreeHead is a tree head with the Ed25519 signature of the log over it.
+type SignedTreeHead struct {
+	TreeHead
+	Signature []byte
+}
+
+// SignTreeHead signs the encoding of the tree head (see TreeHead.Bytes) with the private key of the log.
+func SignTreeHead(h TreeHead, key ed25519.PrivateKey) (*SignedTreeHead, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key")
+	}
+	if h.Size < 0 {
+		return nil, errors.New("invalid tree size")
+	}
+	h.Root = bytes.Clone(h.Root)
+	return &SignedTreeHead{
+		TreeHead:  h,
+		Signature: ed25519.Sign(key, h.Bytes()),
+	}, nil
+}
+
+// VerifyTreeHead checks if the signature of a signed tree head is valid for the public key of the log.
+func VerifyTreeHead(s *SignedTreeHead, key ed25519.PublicKey) error {
+	if s == nil {
+		return errors.New("nil tree head")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid public key")
+	}
+	if s.Size < 0 {
+		return errors.New("invalid tree size")
+	}
+	if !ed25519.Verify(key, s.Bytes(), s.Signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// RootHistory records the tree heads of a tree, when it is built and after every append and update.
+// An update rewrites the tree, so its head has the size of the head before it and another root: heads recorded before
+// an update aren't consistent with the heads recorded after it.
+// Set it with WithRootHistory. It is safe for concurrent use.
+type RootHistory struct {
+	mu    sync.RWMutex
+	heads []TreeHead
+}
+
+// NewRootHistory returns an empty history.
+func NewRootHistory() *RootHistory {
+	return &RootHistory{}
+}
+
+// WithRootHistory records the tree heads of the tree in h, when it is built and after every append and update.
+// Timestamps are rounded down to milliseconds, which is the precision of signed tree heads.
+func WithRootHistory(h *RootHistory) Option {
+	return func(c *config) {
+		c.history = h
+	}
+}
+
+// record adds the head of a tree with the given size and root. A nil history records nothing.
+func (h *RootHistory) record(size int, root []byte) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.heads = append(h.heads, TreeHead{
+		Size:      size,
+		Root:      bytes.Clone(root),
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+	})
+}
+
+// clone returns a copy of the history, or nil for a nil history.
+func (h *RootHistory) clone() *RootHistory {
+	if h == nil {
+		return nil
+	}
+	return &RootHistory{heads: h.Heads()}
+}
+
+// Heads returns a copy of all recorded heads, from the oldest to the latest.
+func (h *RootHistory) Heads() []TreeHead {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	heads := make([]TreeHead, len(h.heads))
+	for i, head := range h.heads {
+		heads[i] = head
+		heads[i].Root = bytes.Clone(head.Root)
+	}
+	return heads
+}
+
+// Latest returns the latest recorded head, and false if no head was recorded yet.
+func (h *RootHistory) Latest() (TreeHead, bool) {
+	if h == nil {
+		return TreeHead{}, false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.heads) == 0 {
+		return TreeHead{}, false
+	}
+	head := h.heads[len(h.heads)-1]
+	head.Root = bytes.Clone(head.Root)
+	return head, true
+}
+
+// AtSize returns the latest recorded head of a tree with the given size, and false if there is none.
+// After updates there are multiple heads with the same size; only the latest is consistent with later heads.
+func (h *RootHistory) AtSize(size int) (TreeHead, bool) {
+	if h == nil {
+		return TreeHead{}, false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	// appends only grow the tree and updates keep its size, so the heads are sorted by size
+	i := sort.Search(len(h.heads), func(i int) bool {
+		return h.heads[i].Size > size
+	}) - 1
+	if i < 0 || h.heads[i].Size != size {
+		return TreeHead{}, false
+	}
+	head := h.heads[i]
+	head.Root = bytes.Clone(head.Root)
+	return head, true
+}
@@ -0,0 +1,113 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+	"time"
+)
+
+func TestTreeHead_Sign(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tree := mustBuildMerkleTree(t, []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}})
+	head := TreeHead{Size: 3, Root: tree.Root(), Timestamp: time.UnixMilli(1700000000000)}
+
+	if b := head.Bytes(); len(b) != 18+32 || b[0] != 0 || b[1] != 1 {
+		t.Errorf("unexpected encoding %x", b)
+	}
+
+	sth, err := SignTreeHead(head, priv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyTreeHead(sth, pub); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if err := VerifyTreeHead(sth, other); err == nil {
+		t.Errorf("expected error for wrong key")
+	}
+
+	sth.Size = 4
+	if err := VerifyTreeHead(sth, pub); err == nil {
+		t.Errorf("expected error for tampered size")
+	}
+
+	if _, err := SignTreeHead(head, priv[:10]); err == nil {
+		t.Errorf("expected error for invalid key")
+	}
+}
+
+func TestRootHistory(t *testing.T) {
+	history := NewRootHistory()
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+
+	tree := mustBuildMerkleTree(t, data, WithRootHistory(history))
+	for _, x := range []string{"d", "e"} {
+		if err := tree.Append(&TestLeaf{x}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	heads := history.Heads()
+	if len(heads) != 3 {
+		t.Fatalf("expected 3 heads, got %d", len(heads))
+	}
+	for i, head := range heads {
+		if head.Size != 3+i {
+			t.Errorf("expected size %d, got %d", 3+i, head.Size)
+		}
+	}
+
+	latest, ok := history.Latest()
+	if !ok || !bytes.Equal(latest.Root, tree.Root()) {
+		t.Errorf("expected latest root %x, got %x", tree.Root(), latest.Root)
+	}
+
+	head, ok := history.AtSize(3)
+	if expected := mustBuildMerkleTree(t, data).Root(); !ok || !bytes.Equal(head.Root, expected) {
+		t.Errorf("expected root %x, got %x", expected, head.Root)
+	}
+	if _, ok := history.AtSize(6); ok {
+		t.Errorf("expected no head of size 6")
+	}
+
+	// an update records a head of the same size, which the next append is consistent with
+	if err := tree.Update(0, &TestLeaf{"x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest, _ := history.Latest(); latest.Size != 5 || !bytes.Equal(latest.Root, tree.Root()) {
+		t.Errorf("expected head of the updated tree, got %v", latest)
+	}
+	updated, _ := history.AtSize(5)
+	if err := tree.Append(&TestLeaf{"f"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proof, err := tree.ConsistencyProof(5, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyConsistency(updated.Root, tree.Root(), proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// stored trees record a head per batch
+	stored, err := NewStoredTree(NewMemoryStore(), WithRootHistory(NewRootHistory()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stored.AppendBatch(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stored.Append(&TestLeaf{"d"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if heads := stored.history.Heads(); len(heads) != 2 || heads[0].Size != 3 || heads[1].Size != 4 {
+		t.Errorf("expected heads of size 3 and 4, got %v", heads)
+	}
+}
@@ -0,0 +1,100 @@
+package gomerkletree
+
+import (
+	"errors"
+)
+
+// TrillianHashStrategy is the hashing profile of Trillian and Tessera logs: rfc6962.DefaultHasher of
+// github.com/transparency-dev/merkle, which hashes leaves as SHA-256(0x00 || leaf) and internal nodes as
+// SHA-256(0x01 || left || right). Their trees have the RFC 6962 shape, the one promotion produces.
+type TrillianHashStrategy = RFC6962HashStrategy
+
+// TrillianProof is a proof in the format of Trillian's trillian.Proof, whose protobuf JSON encoding it shares:
+// the hashes are the audit path from the leaf up. Consistency proofs leave the leaf index at zero.
+type TrillianProof struct {
+	LeafIndex int64    `json:"leafIndex,string"`
+	Hashes    [][]byte `json:"hashes"`
+}
+
+// TrillianProof returns the inclusion proof of the i-th leaf in the format of Trillian. The tree must have been built
+// with the RFC 6962 hash strategy (or the default one, which is the same) and promotion.
+func (m *MerkleTree) TrillianProof(i int) (*TrillianProof, error) {
+	if err := m.checkRFC6962(); err != nil {
+		return nil, err
+	}
+	p, err := m.ProofByIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return &TrillianProof{LeafIndex: int64(i), Hashes: p.Siblings()}, nil
+}
+
+// TrillianConsistencyProof returns the proof that the first newSize leaves extend the first oldSize leaves in the
+// format of Trillian, like ConsistencyProof.
+func (m *MerkleTree) TrillianConsistencyProof(oldSize, newSize int) (*TrillianProof, error) {
+	if err := m.checkRFC6962(); err != nil {
+		return nil, err
+	}
+	p, err := m.ConsistencyProof(oldSize, newSize)
+	if err != nil {
+		return nil, err
+	}
+	return &TrillianProof{Hashes: p.hashes}, nil
+}
+
+// Proof converts the inclusion proof into a Proof for root in a tree of size leaves, which can be verified with
+// VerifyProof.
+func (p *TrillianProof) Proof(size int64, root []byte) (*Proof, error) {
+	if p == nil {
+		return nil, errors.New("no proof")
+	}
+	if p.LeafIndex < 0 || size < 0 {
+		return nil, errors.New("index out of range")
+	}
+	return rfc6962Proof(uint64(p.LeafIndex), uint64(size), p.Hashes, root)
+}
+
+// VerifyTrillianInclusion checks that proof proves the leaf with the given hash at index in the tree of size leaves
+// with root, like proof.VerifyInclusion of github.com/transparency-dev/merkle with the RFC 6962 hasher, with which
+// Trillian and Tessera clients verify inclusion.
+func VerifyTrillianInclusion(index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	if leafHash == nil {
+		return errors.New("nil leaf hash")
+	}
+	p, err := rfc6962Proof(index, size, proof, root)
+	if err != nil {
+		return err
+	}
+	return verifyProof(leafHash, p, TrillianHashStrategy{}, root)
+}
+
+// VerifyTrillianConsistency checks that proof proves the tree of size2 leaves with root2 extends the tree of size1
+// leaves with root1, like proof.VerifyConsistency of github.com/transparency-dev/merkle with the RFC 6962 hasher.
+// As there, trees of the same size need an empty proof and equal roots, and every larger tree extends the empty tree,
+// with an empty proof.
+func VerifyTrillianConsistency(size1, size2 uint64, proof [][]byte, root1, root2 []byte) error {
+	switch {
+	case size2 < size1 || size2 > maxTreeSize:
+		return errors.New("invalid tree sizes")
+	case size1 == size2:
+		if len(proof) != 0 {
+			return errors.New("too many hashes")
+		}
+		if !hashEqual(root1, root2) {
+			return rootMismatch(root1, root2)
+		}
+		return nil
+	case size1 == 0:
+		if len(proof) != 0 {
+			return errors.New("too many hashes")
+		}
+		return nil
+	}
+	return VerifyConsistency(root1, root2, NewConsistencyProof(int(size1), int(size2), proof, TrillianHashStrategy{}))
+}
+
+// TrillianLeafHash returns the hash of leaf data as Trillian and Tessera compute it, e.g. to verify a proof with
+// VerifyTrillianInclusion.
+func TrillianLeafHash(leaf []byte) []byte {
+	return TrillianHashStrategy{}.HashLeaf(leaf)
+}
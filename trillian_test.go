@@ -0,0 +1,139 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTrillian_Inclusion(t *testing.T) {
+	data := rfc6962Data(t)
+	for size := 1; size <= len(data); size++ {
+		tree, err := BuildRFC6962MerkleTree(data[:size])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		root := mustDecodeHex(t, rfc6962Roots[size-1])
+		for i := range size {
+			p, err := tree.TrillianProof(i)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			hash := TrillianLeafHash(data[i].Bytes())
+			if err := VerifyTrillianInclusion(uint64(i), uint64(size), hash, p.Hashes, root); err != nil {
+				t.Errorf("%d/%d: expected valid proof, got %v", i, size, err)
+			}
+			if size > 1 {
+				if err := VerifyTrillianInclusion(uint64((i+1)%size), uint64(size), hash, p.Hashes, root); err == nil {
+					t.Errorf("%d/%d: expected error for other index", i, size)
+				}
+			}
+
+			proof, err := p.Proof(int64(size), root)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := VerifyProof(data[i], proof); err != nil {
+				t.Errorf("%d/%d: expected valid converted proof, got %v", i, size, err)
+			}
+		}
+	}
+}
+
+func TestTrillian_InclusionVector(t *testing.T) {
+	// the audit path of leaf 0 in the tree of 8 leaves of the RFC 6962 test vectors
+	path := [][]byte{
+		mustDecodeHex(t, "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7"),
+		mustDecodeHex(t, "5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e"),
+		mustDecodeHex(t, "6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4"),
+	}
+	root := mustDecodeHex(t, rfc6962Roots[7])
+	hash := TrillianLeafHash(nil)
+	if err := VerifyTrillianInclusion(0, 8, hash, path, root); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+	if err := VerifyTrillianInclusion(0, 4, hash, path, root); err == nil {
+		t.Errorf("expected error for other tree size")
+	}
+	if err := VerifyTrillianInclusion(8, 8, hash, path, root); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+	if err := VerifyTrillianInclusion(0, 8, nil, path, root); err == nil {
+		t.Errorf("expected error for nil leaf hash")
+	}
+	if err := VerifyTrillianInclusion(0, math.MaxInt64, hash, path[:1], root); err == nil {
+		t.Errorf("expected error for tree size %d", int64(math.MaxInt64))
+	}
+	if _, err := (&TrillianProof{Hashes: path[:1]}).Proof(math.MaxInt64, root); err == nil {
+		t.Errorf("expected error for tree size %d", int64(math.MaxInt64))
+	}
+	if err := VerifyTrillianConsistency(1, math.MaxInt64, path[:1], root, root); err == nil {
+		t.Errorf("expected error for tree size %d", int64(math.MaxInt64))
+	}
+}
+
+func TestTrillian_Consistency(t *testing.T) {
+	data := rfc6962Data(t)
+	tree, err := BuildRFC6962MerkleTree(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for oldSize := 1; oldSize <= len(data); oldSize++ {
+		for newSize := oldSize; newSize <= len(data); newSize++ {
+			p, err := tree.TrillianConsistencyProof(oldSize, newSize)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oldRoot := mustDecodeHex(t, rfc6962Roots[oldSize-1])
+			newRoot := mustDecodeHex(t, rfc6962Roots[newSize-1])
+			if err := VerifyTrillianConsistency(uint64(oldSize), uint64(newSize), p.Hashes, oldRoot, newRoot); err != nil {
+				t.Errorf("%d -> %d: expected valid proof, got %v", oldSize, newSize, err)
+			}
+		}
+	}
+
+	root := tree.Root()
+	if err := VerifyTrillianConsistency(0, 8, nil, nil, root); err != nil {
+		t.Errorf("expected empty tree to be extended, got %v", err)
+	}
+	if err := VerifyTrillianConsistency(0, 8, [][]byte{root}, nil, root); err == nil {
+		t.Errorf("expected error for proof from the empty tree")
+	}
+	if err := VerifyTrillianConsistency(8, 7, nil, root, root); err == nil {
+		t.Errorf("expected error for shrinking tree")
+	}
+
+	// trees of the same size, including the empty tree, must have the same root
+	other := TrillianLeafHash(nil)
+	for _, size := range []uint64{0, 8} {
+		if err := VerifyTrillianConsistency(size, size, nil, root, root); err != nil {
+			t.Errorf("%d -> %d: unexpected error: %v", size, size, err)
+		}
+		if err := VerifyTrillianConsistency(size, size, nil, root, other); !errors.Is(err, ErrRootMismatch) {
+			t.Errorf("%d -> %d: expected root mismatch, got %v", size, size, err)
+		}
+		if err := VerifyTrillianConsistency(size, size, [][]byte{root}, root, root); err == nil {
+			t.Errorf("%d -> %d: expected error for non-empty proof", size, size)
+		}
+	}
+}
+
+func TestTrillian_JSON(t *testing.T) {
+	p := &TrillianProof{LeafIndex: 3, Hashes: [][]byte{{1, 2}}}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"leafIndex":"3","hashes":["AQI="]}`; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+	var decoded TrillianProof
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.LeafIndex != 3 || !bytes.Equal(decoded.Hashes[0], []byte{1, 2}) {
+		t.Errorf("expected %+v, got %+v", p, decoded)
+	}
+}
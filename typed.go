@@ -0,0 +1,155 @@
+package gomerkletree
+
+import (
+	"errors"
+	"iter"
+)
+
+type typedLeaf[T any] struct {
+	value   T
+	marshal func(T) []byte
+}
+
+func (l typedLeaf[T]) Bytes() []byte {
+	return l.marshal(l.value)
+}
+
+// LeafOf returns a leaf for a value of any type, whose bytes are the value encoded with marshal.
+func LeafOf[T any](value T, marshal func(T) []byte) Leaf {
+	return typedLeaf[T]{
+		value:   value,
+		marshal: marshal,
+	}
+}
+
+// Tree is a merkle tree over values of type T, encoded as leaves with marshal.
+// Unlike MerkleTree it keeps the values, so they can be read back with Get.
+type Tree[T any] struct {
+	tree    *MerkleTree
+	values  []T // in the order of the leaves of the tree
+	marshal func(T) []byte
+}
+
+// BuildTree builds a merkle tree over the values encoded with marshal, like BuildMerkleTree.
+// With WithSortedLeaves or WithDedup the values are kept in the order of the leaves, without repeated values.
+func BuildTree[T any](values []T, marshal func(T) []byte, opts ...Option) (*Tree[T], error) {
+	if marshal == nil {
+		return nil, errors.New("nil marshal")
+	}
+	data := make([]Leaf, len(values))
+	for i, v := range values {
+		data[i] = LeafOf(v, marshal)
+	}
+	tree, err := BuildMerkleTree(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]T, len(tree.leaves))
+	if tree.sorted || tree.dedup {
+		byHash := make(map[string]T, len(values))
+		for _, v := range values {
+			byHash[string(tree.hashStrategy.HashLeaf(marshal(v)))] = v
+		}
+		for i, l := range tree.leaves {
+			ordered[i] = byHash[string(l.h)]
+		}
+	} else {
+		copy(ordered, values)
+	}
+
+	return &Tree[T]{
+		tree:    tree,
+		values:  ordered,
+		marshal: marshal,
+	}, nil
+}
+
+// MerkleTree returns the underlying merkle tree, for methods that Tree doesn't have. It shares the state of the Tree,
+// so it must not be changed directly.
+func (t *Tree[T]) MerkleTree() *MerkleTree {
+	if t == nil {
+		return nil
+	}
+	return t.tree
+}
+
+// Root returns the bytes of the root.
+func (t *Tree[T]) Root() []byte {
+	if t == nil {
+		return nil
+	}
+	return t.tree.Root()
+}
+
+// NumLeaves returns the number of values in the tree.
+func (t *Tree[T]) NumLeaves() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.values)
+}
+
+// Get returns the i-th value. It panics if i is out of range, like indexing a slice.
+func (t *Tree[T]) Get(i int) T {
+	return t.values[i]
+}
+
+// All returns an iterator over the indices and values of the tree.
+func (t *Tree[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		if t == nil {
+			return
+		}
+		for i, v := range t.values {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// Append appends a value in O(log n), like MerkleTree.Append.
+func (t *Tree[T]) Append(v T) error {
+	if t == nil {
+		return errors.New("nil tree")
+	}
+	if err := t.tree.Append(LeafOf(v, t.marshal)); err != nil {
+		return err
+	}
+	t.values = append(t.values, v)
+	return nil
+}
+
+// Index returns the position of a value, like MerkleTree.Index.
+func (t *Tree[T]) Index(v T) (int, error) {
+	if t == nil {
+		return -1, errors.New("nil tree")
+	}
+	return t.tree.Index(LeafOf(v, t.marshal))
+}
+
+// Proof generates a proof for a value, like MerkleTree.Proof.
+func (t *Tree[T]) Proof(v T) (*Proof, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	return t.tree.Proof(LeafOf(v, t.marshal))
+}
+
+// ProofByIndex generates a proof for the i-th value.
+func (t *Tree[T]) ProofByIndex(i int) (*Proof, error) {
+	if t == nil {
+		return nil, errors.New("nil tree")
+	}
+	return t.tree.ProofByIndex(i)
+}
+
+// Verify checks if a proof is valid for a value, encoded with the marshal function of the tree,
+// under the current root of the tree. The root stored in the proof is ignored.
+func (t *Tree[T]) Verify(v T, p *Proof) error {
+	if t == nil {
+		return errors.New("nil tree")
+	}
+	return VerifyProofAgainstRoot(LeafOf(v, t.marshal), p, t.tree.RootNoCopy())
+}
@@ -0,0 +1,109 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+type account struct {
+	name    string
+	balance uint64
+}
+
+func marshalAccount(a account) []byte {
+	return binary.BigEndian.AppendUint64([]byte(a.name), a.balance)
+}
+
+func TestTree_Typed(t *testing.T) {
+	accounts := []account{{"alice", 10}, {"bob", 20}, {"carol", 30}}
+
+	tree, err := BuildTree(accounts, marshalAccount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := mustBuildMerkleTree(t, []Leaf{
+		LeafOf(accounts[0], marshalAccount),
+		LeafOf(accounts[1], marshalAccount),
+		LeafOf(accounts[2], marshalAccount),
+	})
+	if !bytes.Equal(tree.Root(), expected.Root()) {
+		t.Errorf("expected root %x, got %x", expected.Root(), tree.Root())
+	}
+
+	if err := tree.Append(account{"dave", 40}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	accounts = append(accounts, account{"dave", 40})
+
+	if tree.NumLeaves() != 4 {
+		t.Errorf("expected 4 leaves, got %d", tree.NumLeaves())
+	}
+	for i, a := range tree.All() {
+		if a != accounts[i] || tree.Get(i) != accounts[i] {
+			t.Errorf("expected %v at index %d, got %v", accounts[i], i, a)
+		}
+		if j, err := tree.Index(a); err != nil || j != i {
+			t.Errorf("expected index %d, got %d (%v)", i, j, err)
+		}
+
+		proof, err := tree.Proof(a)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tree.Verify(a, proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := VerifyProof(LeafOf(a, marshalAccount), proof); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	proof, _ := tree.ProofByIndex(1)
+	if err := tree.Verify(account{"bob", 21}, proof); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+	if _, err := tree.Proof(account{"eve", 0}); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+
+	// a valid proof from another tree isn't valid for this one
+	other, err := BuildTree(append(accounts, account{"eve", 0}), marshalAccount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proof, err = other.Proof(accounts[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tree.Verify(accounts[0], proof); err == nil {
+		t.Errorf("expected err for proof of another tree, got nil")
+	}
+	if tree.MerkleTree().NumLeaves() != 4 {
+		t.Errorf("expected underlying tree with 4 leaves")
+	}
+
+	if _, err := BuildTree(accounts, nil); err == nil {
+		t.Errorf("expected err, got nil")
+	}
+}
+
+func TestTree_TypedSorted(t *testing.T) {
+	accounts := []account{{"alice", 10}, {"bob", 20}, {"carol", 30}, {"bob", 20}}
+
+	tree, err := BuildTree(accounts, marshalAccount, WithSortedLeaves(), WithDedup())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tree.NumLeaves() != 3 {
+		t.Fatalf("expected 3 leaves, got %d", tree.NumLeaves())
+	}
+
+	// values follow the leaves of the tree
+	for i, a := range tree.All() {
+		if !bytes.Equal(tree.MerkleTree().LeafHash(i), hashStrategy.HashLeaf(marshalAccount(a))) {
+			t.Errorf("value %v not at the leaf of index %d", a, i)
+		}
+	}
+}
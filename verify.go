@@ -0,0 +1,78 @@
+package gomerkletree
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sync"
+)
+
+// AppendHasher is implemented by hash strategies that can append hashes to a buffer instead of allocating them,
+// so proofs are verified without allocating. The buffer may overlap the input, as the running hash of a proof does.
+type AppendHasher interface {
+	AppendLeaf(dst, l []byte) []byte
+	AppendInternal(dst, l, r []byte) []byte
+}
+
+func (h defaultHashStrategy) AppendLeaf(dst, l []byte) []byte {
+	return appendSHA256Prefixed(dst, 0x00, l, nil)
+}
+
+func (h defaultHashStrategy) AppendInternal(dst, l, r []byte) []byte {
+	return appendSHA256Prefixed(dst, 0x01, l, r)
+}
+
+func (h RFC6962HashStrategy) AppendLeaf(dst, l []byte) []byte {
+	return defaultHashStrategy{}.AppendLeaf(dst, l)
+}
+
+func (h RFC6962HashStrategy) AppendInternal(dst, l, r []byte) []byte {
+	return defaultHashStrategy{}.AppendInternal(dst, l, r)
+}
+
+// scratchPool holds the buffers of the running hash of proof verification, large enough for 64 byte hashes.
+var scratchPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 64)
+		return &b
+	},
+}
+
+// verifyLeafProof checks if a proof is valid for the bytes of a leaf under the given root, hashing the leaf into
+// the scratch buffer of the proof if the hash strategy is an AppendHasher.
+func verifyLeafProof(leaf []byte, p *Proof, strategy HashStrategy, root []byte) error {
+	s, ok := strategy.(AppendHasher)
+	if !ok {
+		return verifyProof(strategy.HashLeaf(leaf), p, strategy, root)
+	}
+	if len(p.siblings) != len(p.left) {
+		return errors.New("proof lengths mismatch")
+	}
+	scratch := scratchPool.Get().(*[]byte)
+	defer scratchPool.Put(scratch)
+	*scratch = s.AppendLeaf((*scratch)[:0], leaf)
+	return verifyProofAppend(*scratch, p, s, root, scratch)
+}
+
+// verifyProofAppend verifies a proof like verifyProof, computing the running hash in a single scratch buffer.
+// The buffer is kept in scratch, also when it grows, so it can be reused.
+func verifyProofAppend(hash []byte, p *Proof, strategy AppendHasher, root []byte, scratch *[]byte) error {
+	for i, isLeft := range p.left {
+		if isLeft {
+			hash = strategy.AppendInternal((*scratch)[:0], p.siblings[i], hash)
+		} else {
+			hash = strategy.AppendInternal((*scratch)[:0], hash, p.siblings[i])
+		}
+		*scratch = hash
+	}
+
+	if !hashEqual(hash, root) {
+		return rootMismatch(hash, root) // copies the hash out of the scratch buffer
+	}
+	return nil
+}
+
+// hashEqual compares hashes in constant time, so verifying doesn't leak how much of a hash derived from a secret
+// leaf matches. Only the lengths, which aren't secret, are compared with an early return.
+func hashEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
@@ -0,0 +1,138 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func TestVerifyProof_NoAllocs(t *testing.T) {
+	data := make([]Leaf, 1000)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+	tree := mustBuildMerkleTree(t, data, WithTrusted())
+	proof, err := tree.ProofByIndex(500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	leafHash := tree.LeafHash(500)
+
+	if allocs := testing.AllocsPerRun(100, func() { VerifyProof(data[500], proof) }); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { VerifyProofFromHash(leafHash, proof) }); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestVerifyProof_Scratch(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"c"}}
+	tree := mustBuildMerkleTree(t, data)
+	proof, err := tree.Proof(data[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the computed root of a failed verification must not be overwritten when the scratch buffer is reused
+	var verr *VerificationError
+	if err := VerifyProof(&TestLeaf{"d"}, proof); !errors.As(err, &verr) {
+		t.Fatalf("expected *VerificationError, got %v", err)
+	}
+	computed := bytes.Clone(verr.Computed)
+	if err := VerifyProof(data[2], proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(verr.Computed, computed) {
+		t.Errorf("computed root changed")
+	}
+
+	// leaves too large for the stack are hashed with a digest
+	large := &TestLeaf{string(bytes.Repeat([]byte{1}, 200))}
+	tree = mustBuildMerkleTree(t, append(data, large))
+	proof, err = tree.Proof(large)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(large, proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHashEqual(t *testing.T) {
+	tests := []struct {
+		a, b []byte
+		want bool
+	}{
+		{[]byte{1, 2}, []byte{1, 2}, true},
+		{[]byte{1, 2}, []byte{1, 3}, false},
+		{[]byte{1, 2}, []byte{1}, false},
+		{nil, []byte{}, true},
+	}
+	for _, tt := range tests {
+		if got := hashEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("%x == %x: expected %v, got %v", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestTree_WithConstantTime(t *testing.T) {
+	data := []Leaf{&TestLeaf{"a"}, &TestLeaf{"b"}, &TestLeaf{"a"}, &TestLeaf{"c"}, &TestLeaf{"d"}}
+	tree := mustBuildMerkleTree(t, data)
+	strict := mustBuildMerkleTree(t, data, WithConstantTime())
+
+	for _, x := range append(data, &TestLeaf{"e"}) {
+		want, wantErr := tree.Index(x)
+		got, err := strict.Index(x)
+		if got != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("%v: expected %d (%v), got %d (%v)", x, want, wantErr, got, err)
+		}
+	}
+
+	p, err := strict.Proof(&TestLeaf{"c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyProof(&TestLeaf{"c"}, p); err != nil {
+		t.Errorf("expected valid proof, got %v", err)
+	}
+	if _, err := strict.VerifyExists(&TestLeaf{"e"}); err == nil {
+		t.Errorf("expected error for leaf not in tree")
+	}
+
+	if err := strict.Append(&TestLeaf{"e"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if i, err := strict.Clone().Index(&TestLeaf{"e"}); err != nil || i != 5 {
+		t.Errorf("expected index 5, got %d (%v)", i, err)
+	}
+}
+
+func BenchmarkVerifyProof(b *testing.B) {
+	strategies := []struct {
+		name     string
+		strategy HashStrategy
+	}{
+		{"Default", defaultHashStrategy{}},
+		{"SHA3", hashing.SHA3Strategy{}},
+	}
+	data := make([]Leaf, 1<<16)
+	for i := range data {
+		data[i] = BytesLeaf(fmt.Sprint(i))
+	}
+
+	for _, s := range strategies {
+		tree, _ := BuildMerkleTree(data, WithHashStrategy(s.strategy), WithTrusted())
+		proof, _ := tree.ProofByIndex(12345)
+
+		b.Run(s.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				VerifyProof(data[12345], proof)
+			}
+		})
+	}
+}
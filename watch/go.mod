@@ -0,0 +1,18 @@
+module github.com/jeltjongsma/go-merkletree/watch
+
+go 1.23.0
+
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/jeltjongsma/go-merkletree v0.1.0
+)
+
+require (
+	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
+	github.com/zeebo/blake3 v0.2.4 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+replace github.com/jeltjongsma/go-merkletree => ../
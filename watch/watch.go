@@ -0,0 +1,327 @@
+// Package watch keeps the manifest of a directory up to date as its files change, using fsnotify.
+// A Watcher maintains the same root as gomerkletree.HashDirectory over the directory, rehashing only the files that
+// changed, and feeds every change together with the new root to a channel, for continuous integrity monitoring.
+package watch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+// Change is a change of a file of the watched directory, with the root of the directory after the change.
+// All changes caused by the same event, like the files of a removed directory, carry the same root.
+type Change struct {
+	gomerkletree.FileChange
+	Root []byte
+}
+
+// Watcher watches a directory and its subdirectories. It is safe for concurrent use.
+//
+// Like fsnotify.Watcher, both Changes and Errors must be received from until Close, or the watcher stalls.
+type Watcher struct {
+	dir  string
+	fsys fs.FS
+	fsw  *fsnotify.Watcher
+
+	mu    sync.RWMutex
+	files []gomerkletree.FileEntry // sorted by path, like Manifest.Files
+	tree  *gomerkletree.MerkleTree // nil without files
+
+	changes chan Change
+	errors  chan error
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// New hashes the files of a directory, like gomerkletree.HashDirectory, and starts watching it for changes.
+func New(dir string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(dir); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	w := &Watcher{
+		dir:     dir,
+		fsys:    os.DirFS(dir),
+		fsw:     fsw,
+		changes: make(chan Change),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	// watches are added while scanning, so files changed during the scan are synced again by their events
+	files, err := w.scan(".")
+	if err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	w.files = files
+	w.tree = manifestTree(files)
+
+	go w.run()
+	return w, nil
+}
+
+// manifestTree builds the tree over the files of a directory, nil if it has no files.
+func manifestTree(files []gomerkletree.FileEntry) *gomerkletree.MerkleTree {
+	if len(files) == 0 {
+		return nil
+	}
+	tree, _ := (&gomerkletree.Manifest{Files: files}).Tree() // only fails without files
+	return tree
+}
+
+// Root returns the current root of the directory, nil if it has no files.
+func (w *Watcher) Root() []byte {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.tree.Root()
+}
+
+// Manifest returns a copy of the current manifest of the directory, e.g. to verify it later with
+// gomerkletree.VerifyDirectory.
+func (w *Watcher) Manifest() *gomerkletree.Manifest {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	files := make([]gomerkletree.FileEntry, len(w.files))
+	for i, f := range w.files {
+		files[i] = gomerkletree.FileEntry{Path: f.Path, Digest: bytes.Clone(f.Digest)}
+	}
+	return &gomerkletree.Manifest{Files: files}
+}
+
+// Proof generates a proof for the file with the given path against the current root.
+func (w *Watcher) Proof(path string) (*gomerkletree.Proof, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	i, ok := w.search(path)
+	if !ok {
+		return nil, errors.New("not in tree")
+	}
+	return w.tree.ProofByIndex(i)
+}
+
+// Changes returns the feed of changes, in the order they were applied. It is closed by Close.
+func (w *Watcher) Changes() <-chan Change {
+	return w.changes
+}
+
+// Errors returns the errors of watching and hashing files. It is closed by Close.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops watching the directory and closes the channels of changes and errors.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+		<-w.stopped
+	})
+	return err
+}
+
+func (w *Watcher) run() {
+	defer close(w.stopped)
+	defer close(w.errors)
+	defer close(w.changes)
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.sendError(err)
+		}
+	}
+}
+
+// handle syncs the path of an event with the manifest. The state of the path is read again rather than taken from
+// the event, since events may be coalesced or arrive after the path changed again.
+func (w *Watcher) handle(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod {
+		return // contents are unchanged
+	}
+	rel, err := filepath.Rel(w.dir, event.Name)
+	if err != nil {
+		w.sendError(err)
+		return
+	}
+	path := filepath.ToSlash(rel)
+	if path == "." || strings.HasPrefix(path, "../") {
+		return
+	}
+	// the files of a directory are only scanned when it is created, or moved in; changes of them have their own events
+	if info, err := os.Lstat(event.Name); err == nil && info.IsDir() && !event.Has(fsnotify.Create) {
+		return
+	}
+
+	current, err := w.scan(path)
+	if err != nil {
+		w.sendError(err)
+		return
+	}
+	changes, root := w.apply(path, current)
+	for _, c := range changes {
+		select {
+		case w.changes <- Change{FileChange: c, Root: root}:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) sendError(err error) {
+	select {
+	case w.errors <- err:
+	case <-w.done:
+	}
+}
+
+// scan hashes the regular files at or below path, adding watches for the directories, and returns them sorted by path.
+// A path that doesn't exist has no files.
+func (w *Watcher) scan(path string) ([]gomerkletree.FileEntry, error) {
+	var files []gomerkletree.FileEntry
+	err := fs.WalkDir(w.fsys, path, func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil // removed while scanning, which has its own event
+		case err != nil:
+			return err
+		case d.IsDir():
+			return w.fsw.Add(filepath.Join(w.dir, filepath.FromSlash(p)))
+		case !d.Type().IsRegular():
+			return nil
+		}
+		digest, err := w.hashFile(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, gomerkletree.FileEntry{Path: p, Digest: digest})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// WalkDir visits files in lexical order per directory, which differs from sorting by full path
+	slices.SortFunc(files, func(a, b gomerkletree.FileEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, nil
+}
+
+func (w *Watcher) hashFile(path string) ([]byte, error) {
+	f, err := w.fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// apply replaces the files at or below path with the current ones, and returns the changes and the new root.
+// Modified files are updated in the tree in O(log n); added and removed files change the shape of the tree,
+// which is then rebuilt from the entries.
+func (w *Watcher) apply(path string, current []gomerkletree.FileEntry) ([]gomerkletree.FileChange, []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lo, hi := w.below(path)
+	var old []gomerkletree.FileEntry
+	segment := slices.Clone(current)
+	for _, f := range w.files[lo:hi] {
+		if f.Path == path || strings.HasPrefix(f.Path, path+"/") {
+			old = append(old, f)
+		} else {
+			segment = append(segment, f)
+		}
+	}
+	slices.SortFunc(segment, func(a, b gomerkletree.FileEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	var changes []gomerkletree.FileChange
+	reshaped := false // files were added or removed
+	cur := current
+	for len(old) > 0 || len(cur) > 0 {
+		switch {
+		case len(cur) == 0 || (len(old) > 0 && old[0].Path < cur[0].Path):
+			changes = append(changes, gomerkletree.FileChange{Path: old[0].Path, Kind: gomerkletree.FileRemoved})
+			old, reshaped = old[1:], true
+		case len(old) == 0 || cur[0].Path < old[0].Path:
+			changes = append(changes, gomerkletree.FileChange{Path: cur[0].Path, Kind: gomerkletree.FileAdded})
+			cur, reshaped = cur[1:], true
+		default:
+			if !bytes.Equal(old[0].Digest, cur[0].Digest) {
+				changes = append(changes, gomerkletree.FileChange{Path: cur[0].Path, Kind: gomerkletree.FileModified})
+			}
+			old, cur = old[1:], cur[1:]
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	if !reshaped {
+		for i, f := range segment {
+			if !bytes.Equal(w.files[lo+i].Digest, f.Digest) {
+				w.files[lo+i] = f
+				w.tree.Update(lo+i, f) // in range, and the tree has no sorted or deduplicated leaves
+			}
+		}
+	} else {
+		w.files = slices.Concat(w.files[:lo], segment, w.files[hi:])
+		w.tree = manifestTree(w.files)
+	}
+	return changes, w.tree.Root()
+}
+
+// below returns the range of the files at path or below it. Paths with the prefix path + "/" are adjacent when
+// sorted, but siblings like path + ".txt" sort between them and path itself, so the range may contain other files.
+func (w *Watcher) below(path string) (int, int) {
+	lo, _ := w.search(path)
+	prefix := path + "/"
+	hi, _ := w.search(prefix)
+	for hi < len(w.files) && strings.HasPrefix(w.files[hi].Path, prefix) {
+		hi++
+	}
+	return lo, hi
+}
+
+// search returns the index of the file with the given path, or of the first file after it if there is none.
+func (w *Watcher) search(path string) (int, bool) {
+	return slices.BinarySearchFunc(w.files, path, func(f gomerkletree.FileEntry, path string) int {
+		return strings.Compare(f.Path, path)
+	})
+}
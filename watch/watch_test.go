@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gomerkletree "github.com/jeltjongsma/go-merkletree"
+)
+
+func writeFile(t *testing.T, dir, path, contents string) {
+	t.Helper()
+	name := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(name, []byte(contents), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func directoryRoot(t *testing.T, dir string) []byte {
+	t.Helper()
+	m, err := gomerkletree.HashDirectory(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return m.Root()
+}
+
+// waitFor receives changes until the root of the watcher is the root of the directory,
+// and returns the kinds of the received changes by path.
+func waitFor(t *testing.T, w *Watcher, dir string) map[string]gomerkletree.ChangeKind {
+	t.Helper()
+	want := directoryRoot(t, dir)
+	kinds := make(map[string]gomerkletree.ChangeKind)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case c := <-w.Changes():
+			kinds[c.Path] = c.Kind
+			if bytes.Equal(c.Root, want) {
+				if !bytes.Equal(w.Root(), want) {
+					t.Fatalf("expected root %x, got %x", want, w.Root())
+				}
+				return kinds
+			}
+		case err := <-w.Errors():
+			t.Fatalf("unexpected error: %v", err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for root %x, got %x", want, w.Root())
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a", "a")
+	writeFile(t, dir, "a.txt", "a.txt")
+	writeFile(t, dir, "sub/b", "b")
+
+	w, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+	if !bytes.Equal(w.Root(), directoryRoot(t, dir)) {
+		t.Fatalf("expected the root of the directory")
+	}
+	root := w.Root()
+	m := w.Manifest()
+
+	writeFile(t, dir, "sub/b", "modified")
+	if kinds := waitFor(t, w, dir); kinds["sub/b"] != gomerkletree.FileModified {
+		t.Errorf("expected sub/b to be modified, got %v", kinds)
+	}
+
+	writeFile(t, dir, "new/deep/c", "c")
+	if kinds := waitFor(t, w, dir); kinds["new/deep/c"] != gomerkletree.FileAdded {
+		t.Errorf("expected new/deep/c to be added, got %v", kinds)
+	}
+	// files in directories created after the watcher started are watched too
+	writeFile(t, dir, "new/deep/c", "modified")
+	waitFor(t, w, dir)
+
+	if err := os.RemoveAll(filepath.Join(dir, "sub")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kinds := waitFor(t, w, dir); kinds["sub/b"] != gomerkletree.FileRemoved {
+		t.Errorf("expected sub/b to be removed, got %v", kinds)
+	}
+	if err := os.Remove(filepath.Join(dir, "a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kinds := waitFor(t, w, dir); kinds["a"] != gomerkletree.FileRemoved {
+		t.Errorf("expected a to be removed, got %v", kinds)
+	}
+
+	p, err := w.Proof("a.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry := w.Manifest().Files[0]
+	if entry.Path != "a.txt" {
+		t.Fatalf("expected a.txt, got %s", entry.Path)
+	}
+	if err := gomerkletree.VerifyProof(entry, p); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the manifest taken at the start still shows what changed since
+	changes, err := gomerkletree.VerifyDirectory(os.DirFS(dir), m, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected 3 changes, got %v", changes)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, ok := <-w.Changes(); ok {
+		t.Errorf("expected changes to be closed")
+	}
+}
+
+func TestWatcher_Empty(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+	if w.Root() != nil {
+		t.Errorf("expected no root, got %x", w.Root())
+	}
+	if _, err := w.Proof("a"); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+
+	writeFile(t, dir, "a", "a")
+	waitFor(t, w, dir)
+}
+
+func TestNew_Error(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected error for missing directory")
+	}
+}
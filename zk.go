@@ -0,0 +1,64 @@
+package gomerkletree
+
+import (
+	"errors"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+// FieldCompressor is the extension point for zk-friendly hash functions like Poseidon or MiMC, whose roots can be
+// recomputed cheaply inside SNARK circuits. They hash elements of a prime field, encoded as fixed-width bytes,
+// and compress two elements into one for internal nodes. hashing.MiMCBN254 implements it.
+type FieldCompressor interface {
+	// ElementSize returns the width of encoded elements in bytes.
+	ElementSize() int
+	// CheckElement returns an error if b isn't the canonical encoding of an element.
+	CheckElement(b []byte) error
+	// HashElement hashes a leaf element into an element.
+	HashElement(x []byte) []byte
+	// Compress hashes two elements into one.
+	Compress(l, r []byte) []byte
+}
+
+// FieldHashStrategy hashes leaves with HashElement and internal nodes with Compress of a FieldCompressor.
+// Leaves must be canonical elements, which BuildFieldMerkleTree checks: other leaves would be reduced into the field,
+// so distinct leaves could hash the same.
+type FieldHashStrategy struct {
+	FieldCompressor
+}
+
+func (h FieldHashStrategy) HashLeaf(l []byte) []byte {
+	return h.HashElement(l)
+}
+
+func (h FieldHashStrategy) HashInternal(l, r []byte) []byte {
+	return h.Compress(l, r)
+}
+
+// MiMCHashStrategy hashes with MiMC over the scalar field of BN254, see hashing.MiMCBN254.
+var MiMCHashStrategy = FieldHashStrategy{hashing.MiMCBN254{}}
+
+// BuildFieldMerkleTree builds a merkle tree over field elements with the FieldHashStrategy of c, like
+// BuildMerkleTreeBytes, after checking that every element is canonical.
+func BuildFieldMerkleTree(elements [][]byte, c FieldCompressor, opts ...Option) (*MerkleTree, error) {
+	if c == nil {
+		return nil, errors.New("nil field compressor")
+	}
+	for _, e := range elements {
+		if err := c.CheckElement(e); err != nil {
+			return nil, err
+		}
+	}
+	return BuildMerkleTreeBytes(elements, append(opts[:len(opts):len(opts)], WithHashStrategy(FieldHashStrategy{c}))...)
+}
+
+// VerifyFieldProof checks if a proof is valid for a field element under root, with the FieldHashStrategy of c.
+func VerifyFieldProof(element []byte, p *Proof, c FieldCompressor, root []byte) error {
+	if p == nil || c == nil {
+		return errors.New("no proof/hash strategy")
+	}
+	if err := c.CheckElement(element); err != nil {
+		return err
+	}
+	return verifyLeafProof(element, p, FieldHashStrategy{c}, root)
+}
@@ -0,0 +1,99 @@
+package gomerkletree
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/jeltjongsma/go-merkletree/pkg/hashing"
+)
+
+func fieldElement(i int64) []byte {
+	return big.NewInt(i).FillBytes(make([]byte, 32))
+}
+
+func TestMiMC_Hash(t *testing.T) {
+	m := hashing.MiMCBN254{}
+	a, b := fieldElement(1), fieldElement(2)
+
+	if !bytes.Equal(m.Compress(a, b), m.Compress(a, b)) {
+		t.Errorf("expected deterministic hash")
+	}
+	if bytes.Equal(m.Compress(a, b), m.Compress(b, a)) {
+		t.Errorf("expected order of children to matter")
+	}
+	if bytes.Equal(m.HashElement(a), a) || bytes.Equal(m.HashElement(a), m.HashElement(b)) {
+		t.Errorf("expected distinct leaf hashes")
+	}
+	for _, h := range [][]byte{m.HashElement(a), m.Compress(a, b)} {
+		if err := m.CheckElement(h); err != nil {
+			t.Errorf("expected hash to be an element, got %v", err)
+		}
+	}
+}
+
+func TestMiMC_CheckElement(t *testing.T) {
+	m := hashing.MiMCBN254{}
+	modulus, _ := new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+	if err := m.CheckElement(new(big.Int).Sub(modulus, big.NewInt(1)).FillBytes(make([]byte, 32))); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := m.CheckElement(modulus.FillBytes(make([]byte, 32))); err == nil {
+		t.Errorf("expected error for modulus")
+	}
+	if err := m.CheckElement([]byte{1}); err == nil {
+		t.Errorf("expected error for short element")
+	}
+}
+
+func TestBuildFieldMerkleTree(t *testing.T) {
+	var elements [][]byte
+	for i := range 7 {
+		elements = append(elements, fieldElement(int64(i)))
+	}
+	tree, err := BuildFieldMerkleTree(elements, hashing.MiMCBN254{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (hashing.MiMCBN254{}).CheckElement(tree.Root()); err != nil {
+		t.Errorf("expected root to be an element, got %v", err)
+	}
+
+	for i, e := range elements {
+		p, err := tree.ProofByIndex(i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := VerifyFieldProof(e, p, hashing.MiMCBN254{}, tree.Root()); err != nil {
+			t.Errorf("%d: expected valid proof, got %v", i, err)
+		}
+		if err := VerifyFieldProof(fieldElement(100), p, hashing.MiMCBN254{}, tree.Root()); err == nil {
+			t.Errorf("%d: expected error for other element", i)
+		}
+	}
+
+	same, err := BuildMerkleTreeBytes(elements, WithHashStrategy(MiMCHashStrategy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(same.Root(), tree.Root()) {
+		t.Errorf("expected MiMCHashStrategy to build the same root")
+	}
+
+	if _, err := BuildFieldMerkleTree([][]byte{{1, 2, 3}}, hashing.MiMCBN254{}); err == nil {
+		t.Errorf("expected error for non-canonical element")
+	}
+	if _, err := BuildFieldMerkleTree(elements, nil); err == nil {
+		t.Errorf("expected error for nil compressor")
+	}
+}
+
+func BenchmarkMiMC_Compress(b *testing.B) {
+	m := hashing.MiMCBN254{}
+	l, r := fieldElement(1), fieldElement(2)
+	b.ReportAllocs()
+	for range b.N {
+		m.Compress(l, r)
+	}
+}